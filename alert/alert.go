@@ -0,0 +1,157 @@
+// Package alert checks global status values against thresholds
+// and optionally sends any breaches to a webhook.
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minSendInterval is the minimum time between sending alerts for the same metric
+	minSendInterval = 5 * time.Minute
+	// sendTimeout is the maximum time we wait for the webhook to respond
+	sendTimeout = 10 * time.Second
+)
+
+// Threshold holds the maximum expected value of a global status variable
+type Threshold struct {
+	Metric string
+	Value  int
+}
+
+// Alert holds the details of a threshold breach
+type Alert struct {
+	Text      string    `json:"text"` // used by Slack compatible webhooks
+	Metric    string    `json:"metric"`
+	Value     int       `json:"value"`
+	Threshold int       `json:"threshold"`
+	Server    string    `json:"server"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ParseThresholds converts a comma-separated list of
+// metric:value pairs, e.g. Threads_running:50,Threads_connected:500
+func ParseThresholds(s string) ([]Threshold, error) {
+	var thresholds []Threshold
+
+	if s == "" {
+		return nil, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid threshold %q, expected <metric>:<value>", item)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold value in %q: %v", item, err)
+		}
+		thresholds = append(thresholds, Threshold{Metric: strings.TrimSpace(parts[0]), Value: value})
+	}
+
+	return thresholds, nil
+}
+
+// Metrics returns the names of the metrics the thresholds are checked against
+func Metrics(thresholds []Threshold) []string {
+	metrics := make([]string, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		metrics = append(metrics, threshold.Metric)
+	}
+	return metrics
+}
+
+// Check returns an Alert for each threshold which has been exceeded.
+// values holds the current value of each metric by lower-cased name, as
+// returned by global.Status.Values. A metric without a value, e.g. one which
+// is mistyped, is logged and skipped so the other thresholds are still checked.
+func Check(thresholds []Threshold, values map[string]int, server string, now time.Time) []Alert {
+	var alerts []Alert
+
+	for _, threshold := range thresholds {
+		value, found := values[strings.ToLower(threshold.Metric)]
+		if !found {
+			log.Printf("alert.Check() skipping %s: no status with this name", threshold.Metric)
+			continue
+		}
+		if value > threshold.Value {
+			alerts = append(alerts, Alert{
+				Text:      fmt.Sprintf("%s: %s = %d exceeds threshold %d", server, threshold.Metric, value, threshold.Value),
+				Metric:    threshold.Metric,
+				Value:     value,
+				Threshold: threshold.Value,
+				Server:    server,
+				Timestamp: now,
+			})
+		}
+	}
+
+	return alerts
+}
+
+// Webhook sends alerts as JSON to a webhook url
+type Webhook struct {
+	url      string
+	client   *http.Client
+	lastSent map[string]time.Time // when we last sent an alert for each metric
+}
+
+// NewWebhook returns a Webhook which will post to the given url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:      url,
+		client:   &http.Client{Timeout: sendTimeout},
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+// allow returns true if an alert for the metric may be sent at the given time
+func (w *Webhook) allow(metric string, now time.Time) bool {
+	if last, ok := w.lastSent[metric]; ok && now.Sub(last) < minSendInterval {
+		return false
+	}
+	w.lastSent[metric] = now
+
+	return true
+}
+
+// Send posts the alert to the webhook in the background unless an alert
+// for the same metric was sent recently. Failures are logged.
+func (w *Webhook) Send(alert Alert) {
+	if !w.allow(alert.Metric, alert.Timestamp) {
+		log.Println("Webhook.Send() not sending recently sent alert for", alert.Metric)
+		return
+	}
+	go func() {
+		if err := w.post(alert); err != nil {
+			log.Println("Webhook.Send() failed:", err)
+		}
+	}()
+}
+
+// post sends the alert to the webhook
+func (w *Webhook) post(alert Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	response, err := w.client.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected response from %s: %s", w.url, response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseThresholds(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []Threshold
+		err      bool
+	}{
+		{"", nil, false},
+		{"Threads_running:50", []Threshold{{"Threads_running", 50}}, false},
+		{"Threads_running:50, Threads_connected:500", []Threshold{{"Threads_running", 50}, {"Threads_connected", 500}}, false},
+		{"Threads_running", nil, true},
+		{"Threads_running:lots", nil, true},
+		{":10", nil, true},
+	}
+	for _, test := range tests {
+		got, err := ParseThresholds(test.input)
+		if (err != nil) != test.err {
+			t.Errorf("ParseThresholds(%q) failed: expected error: %v, got %v", test.input, test.err, err)
+			continue
+		}
+		if len(got) != len(test.expected) {
+			t.Errorf("ParseThresholds(%q) failed: expected: %v, got %v", test.input, test.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expected[i] {
+				t.Errorf("ParseThresholds(%q) failed: expected: %v, got %v", test.input, test.expected, got)
+			}
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	values := map[string]int{"threads_running": 60, "threads_connected": 100}
+	thresholds := []Threshold{{"Threads_running", 50}, {"Threads_connected", 500}}
+
+	alerts := Check(thresholds, values, "myhost", time.Now())
+	if len(alerts) != 1 {
+		t.Fatalf("Check() failed: expected 1 alert, got %d", len(alerts))
+	}
+	if alerts[0].Metric != "Threads_running" || alerts[0].Value != 60 || alerts[0].Threshold != 50 || alerts[0].Server != "myhost" {
+		t.Errorf("Check() failed: unexpected alert %+v", alerts[0])
+	}
+}
+
+func TestCheckMissing(t *testing.T) {
+	values := map[string]int{"threads_running": 60}
+	thresholds := []Threshold{{"Threads_runing", 50}, {"Threads_running", 50}}
+
+	alerts := Check(thresholds, values, "myhost", time.Now())
+	if len(alerts) != 1 || alerts[0].Metric != "Threads_running" {
+		t.Errorf("Check() failed: expected an alert for Threads_running only, got %+v", alerts)
+	}
+	if metrics := Metrics(thresholds); len(metrics) != 2 || metrics[0] != "Threads_runing" {
+		t.Errorf("Metrics() failed: got %v", metrics)
+	}
+}
+
+func TestAllow(t *testing.T) {
+	w := NewWebhook("http://localhost")
+	now := time.Now()
+
+	tests := []struct {
+		metric   string
+		when     time.Time
+		expected bool
+	}{
+		{"Threads_running", now, true},
+		{"Threads_running", now.Add(time.Minute), false},
+		{"Threads_connected", now.Add(time.Minute), true},
+		{"Threads_running", now.Add(minSendInterval), true},
+	}
+	for _, test := range tests {
+		if got := w.allow(test.metric, test.when); got != test.expected {
+			t.Errorf("allow(%q,%v) failed: expected: %v, got %v", test.metric, test.when, test.expected, got)
+		}
+	}
+}
+
+func TestPost(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("post() sent invalid JSON: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	alert := Alert{Text: "text", Metric: "Threads_running", Value: 60, Threshold: 50, Server: "myhost"}
+	if err := NewWebhook(server.URL).post(alert); err != nil {
+		t.Fatalf("post() failed: %v", err)
+	}
+	if received != alert {
+		t.Errorf("post() failed: expected: %+v, got %+v", alert, received)
+	}
+}
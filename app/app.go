@@ -2,16 +2,19 @@
 package app
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/sjmudd/anonymiser"
-	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/alert"
 	"github.com/sjmudd/ps-top/connector"
 	"github.com/sjmudd/ps-top/display"
 	"github.com/sjmudd/ps-top/event"
@@ -19,47 +22,66 @@ import (
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/filter"
 	"github.com/sjmudd/ps-top/mylog"
+	"github.com/sjmudd/ps-top/output"
 	"github.com/sjmudd/ps-top/pstable"
-	"github.com/sjmudd/ps-top/setupinstruments"
+	"github.com/sjmudd/ps-top/replay"
+	"github.com/sjmudd/ps-top/screen"
+	"github.com/sjmudd/ps-top/statusline"
 	"github.com/sjmudd/ps-top/view"
 	"github.com/sjmudd/ps-top/wait"
-	"github.com/sjmudd/ps-top/wrapper/fileinfolatency"
-	"github.com/sjmudd/ps-top/wrapper/memoryusage"
-	"github.com/sjmudd/ps-top/wrapper/mutexlatency"
-	"github.com/sjmudd/ps-top/wrapper/stageslatency"
-	"github.com/sjmudd/ps-top/wrapper/tableiolatency"
-	"github.com/sjmudd/ps-top/wrapper/tableioops"
-	"github.com/sjmudd/ps-top/wrapper/tablelocklatency"
-	"github.com/sjmudd/ps-top/wrapper/userlatency"
 )
 
 // Settings holds the application configuration settingss from the command line.
 type Settings struct {
-	Anonymise bool                   // Do we want to anonymise data shown?
-	Filter    *filter.DatabaseFilter // optional names of databases to filter on
-	Interval  int                    // default interval to poll information
-	ViewName  string                 // name of the view to start with
+	AdaptiveInterval *wait.Adaptive         // optional bounds of an interval adjusted to the cost of collecting
+	AlertThresholds  []alert.Threshold      // optional thresholds to alert on
+	AlertWebhook     string                 // optional url to send alerts to
+	Anonymise        bool                   // Do we want to anonymise data shown?
+	CSV              output.CSVOptions      // how to write csv output
+	Filter           *filter.DatabaseFilter // optional names of databases to filter on
+	Format           string                 // batch output format, empty for interactive use
+	FreezeColumns    bool                   // keep the column widths stable across intervals
+	Highlight        screen.Highlight       // how to show the selected row
+	InfluxURL        string                 // optional destination of influx output instead of stdout
+	Interval         time.Duration          // default interval to poll information
+	NameFilter       *regexp.Regexp         // optional regexp the names of the rows shown must match
+	NoColor          bool                   // use the terminal's default colours
+	Output           string                 // optional file to append the batch output to instead of stdout
+	PrometheusListen string                 // optional address to serve Prometheus metrics on instead of a view
+	Record           string                 // optional file to record the data of every view to each interval
+	Replay           string                 // optional file of recorded data to show instead of connecting to a server
+	ReplayBucket     time.Duration          // optional size of the buckets the replayed records are averaged over
+	ServerVersion    *global.ServerVersion  // optional server version, avoiding the need to probe the variables and status tables
+	Smooth           int                    // number of intervals to average rates over, 0 to disable
+	StatusLine       bool                   // write a single summary line each interval instead of a view
+	ViewName         string                 // name of the view to start with
 }
 
 // App holds the data needed by an application
 type App struct {
-	cfg              *config.Config                     // some config needed by the display
-	display          *display.Display                   // display displays the information to the screen
-	sigChan          chan os.Signal                     // signal handler channel
-	waitHandler      wait.Handler                       // for handling waits
-	Finished         bool                               // has the app finished?
-	db               *sql.DB                            // connection to MySQL
-	Help             bool                               // show help (during runtime)
-	fileinfolatency  pstable.Tabler                     // file i/o latency information
-	tableiolatency   pstable.Tabler                     // table i/o latency information
-	tableioops       pstable.Tabler                     // table i/o operations information
-	tablelocklatency pstable.Tabler                     // table lock information
-	mutexlatency     pstable.Tabler                     // mutex latency information
-	stageslatency    pstable.Tabler                     // stages latency information
-	memory           pstable.Tabler                     // memory usage information
-	users            pstable.Tabler                     // user information
-	currentView      view.View                          // holds the view we are currently using
-	setupInstruments *setupinstruments.SetupInstruments // for setting up and restoring performance_schema configuration.
+	*server                                       // the server shown, one of servers
+	adaptiveInterval *wait.Adaptive               // adjust the interval to the cost of collecting if set
+	alertThresholds  []alert.Threshold            // global status thresholds to alert on
+	alertWebhook     *alert.Webhook               // optional destination of alerts
+	csv              output.CSVOptions            // how to write csv output
+	csvHeader        bool                         // has the csv header been written?
+	display          *display.Display             // display displays the information to the screen
+	format           string                       // batch output format, empty if interactive
+	influxURL        string                       // where to send influx output
+	prometheusListen string                       // address to serve Prometheus metrics on, if set
+	metrics          *metrics                     // the latest Prometheus metrics
+	outputFile       *os.File                     // where to append the batch output, if set
+	paused           bool                         // is collecting the data paused, keeping the screen as it is?
+	recordFile       *os.File                     // where to record the data of every view, if set
+	replay           *replay.Source               // the recorded data shown instead of collecting it, if set
+	replayed         map[view.Code]pstable.Tabler // the recorded data of each view when replaying
+	sigChan          chan os.Signal               // signal handler channel
+	servers          []*server                    // the servers monitored, switched between with [ and ]
+	statusLine       bool                         // write a status line each interval
+	waitHandler      wait.Handler                 // for handling waits
+	Finished         bool                         // has the app finished?
+	Help             bool                         // show help (during runtime)
+	currentView      view.View                    // holds the view we are currently using
 }
 
 // ensure performance_schema is enabled
@@ -68,14 +90,71 @@ func ensurePerformanceSchemaEnabled(variables *global.Variables) {
 	if variables == nil {
 		mylog.Fatal("ensurePerformanceSchemaEnabled() variables is nil")
 	}
+	if err := checkPerformanceSchema(variables); err != nil {
+		mylog.Fatal("ensurePerformanceSchemaEnabled(): " + err.Error())
+	}
+}
 
-	// check that performance_schema = ON
-	if value := variables.Get("performance_schema"); value != "ON" {
-		mylog.Fatal(fmt.Sprintf("ensurePerformanceSchemaEnabled(): performance_schema = '%s'. Please configure performance_schema = 1 in /etc/my.cnf (or equivalent) and restart mysqld to use %s.",
-			value, lib.ProgName))
-	} else {
-		log.Println("performance_schema = ON check succeeds")
+// checkPerformanceSchema returns an error if performance_schema is not enabled
+func checkPerformanceSchema(variables *global.Variables) error {
+	if !variables.PerformanceSchemaEnabled() {
+		return fmt.Errorf("performance_schema = '%s'. Please configure performance_schema = 1 in /etc/my.cnf (or equivalent) and restart mysqld to use %s",
+			variables.Get("performance_schema"), lib.ProgName)
 	}
+	log.Println("performance_schema = ON check succeeds")
+	return nil
+}
+
+// clockSkewWarning is the clock skew between the server and this host above which we warn
+const clockSkewWarning = time.Second
+
+// checkClockSkew returns a description of the clock skew between the server and this host,
+// warning if it is large enough to give misleading timestamps or lag values
+func checkClockSkew(db *sql.DB) string {
+	skew, err := global.ClockSkew(db)
+	if err != nil {
+		log.Println("checkClockSkew(): unable to determine the clock skew:", err)
+		return "unknown"
+	}
+
+	description := fmt.Sprintf("the server's clock is %v ahead of this host", skew.Round(time.Millisecond))
+	if skew < 0 {
+		description = fmt.Sprintf("the server's clock is %v behind this host", (-skew).Round(time.Millisecond))
+	}
+	if skew > clockSkewWarning || skew < -clockSkewWarning {
+		description = "WARNING: " + description + ", timestamps and lag values may be misleading"
+	}
+	log.Println("checkClockSkew():", description)
+
+	return description
+}
+
+// localDatadir returns the server's datadir if the server appears to be
+// running on this host, otherwise an empty string
+func localDatadir(variables *global.Variables) string {
+	datadir := variables.Get("datadir")
+	hostname, err := os.Hostname()
+	if err != nil || datadir == "" {
+		return ""
+	}
+	if shortHostname(hostname) != shortHostname(variables.Get("hostname")) {
+		log.Println("localDatadir(): server is not on this host, not checking free space of", datadir)
+		return ""
+	}
+	if _, err := os.Stat(datadir); err != nil {
+		log.Println("localDatadir(): can not access datadir:", err)
+		return ""
+	}
+
+	return datadir
+}
+
+// shortHostname returns the hostname without the domain
+func shortHostname(hostname string) string {
+	if index := strings.Index(hostname, "."); index >= 0 {
+		return hostname[0:index]
+	}
+	return hostname
 }
 
 // NewApp sets up the application given various parameters.
@@ -83,103 +162,217 @@ func NewApp(
 	connectorFlags connector.Config,
 	settings Settings) *App {
 	log.Println("app.NewApp()")
+	if settings.Replay != "" {
+		return newReplayApp(settings)
+	}
 	app := new(App)
 
 	anonymiser.Enable(settings.Anonymise)
-	app.db = connector.NewConnector(connectorFlags).DB
-
-	status := global.NewStatus(app.db)
-	variables := global.NewVariables(app.db).SelectAll()
-	// Prior to setting up screen check that performance_schema is enabled.
-	// On MariaDB this is not the default setting so it will confuse people.
-	ensurePerformanceSchemaEnabled(variables)
+	flags, err := connectorFlags.Servers()
+	if err != nil {
+		mylog.Fatal(err)
+	}
+	for _, serverFlags := range flags {
+		app.servers = append(app.servers, newServer(serverFlags, settings))
+	}
+	app.useServer(app.servers[0])
 
-	app.cfg = config.NewConfig(status, variables, settings.Filter, true)
+	app.alertThresholds = settings.AlertThresholds
+	if settings.AlertWebhook != "" {
+		app.alertWebhook = alert.NewWebhook(settings.AlertWebhook)
+	}
 	app.Finished = false
-	app.display = display.NewDisplay(app.cfg)
-	app.SetHelp(false)
+	app.format = settings.Format
+	app.influxURL = settings.InfluxURL
+	app.csv = settings.CSV
+	app.openOutput(settings.Output)
+	app.statusLine = settings.StatusLine
+	app.prometheusListen = settings.PrometheusListen
+	if settings.Record != "" {
+		if app.recordFile, err = os.Create(settings.Record); err != nil {
+			mylog.Fatal(err)
+		}
+	}
+	if app.format == "" && !app.statusLine && app.prometheusListen == "" {
+		app.display = display.NewDisplay(app.cfg)
+		mylog.SetCleanup(app.display.Close) // restore the terminal before exiting on a fatal error
+		app.display.SetHighlight(settings.Highlight, settings.NoColor)
+		app.display.SetFreezeColumns(settings.FreezeColumns)
+		app.loadColumns()
+		if settings.NameFilter != nil {
+			app.display.SetFilter(settings.NameFilter.String())
+		}
+		app.SetHelp(false)
+	} else {
+		for _, s := range app.servers {
+			if strings.HasPrefix(s.clockSkew, "WARNING") {
+				fmt.Fprintln(os.Stderr, s.clockSkew)
+			}
+		}
+	}
 
 	app.currentView = view.SetupAndValidate(settings.ViewName, app.db) // if empty will use the default
+	if app.display != nil {
+		app.display.SetViewNames(view.Names())
+	}
+
+	app.waitHandler.SetWaitInterval(settings.Interval)
+	app.adaptiveInterval = settings.AdaptiveInterval
 
-	app.setupInstruments = setupinstruments.NewSetupInstruments(app.db)
-	app.setupInstruments.EnableMonitoring()
-	app.waitHandler.SetWaitInterval(time.Second * time.Duration(settings.Interval))
-
-	// setup to their initial types/values
-	log.Println("app.NewApp() Setup models")
-	app.fileinfolatency = fileinfolatency.NewFileSummaryByInstance(app.cfg, app.db)
-	temptableiolatency := tableiolatency.NewTableIoLatency(app.cfg, app.db) // shared backend/metrics
-	app.tableiolatency = temptableiolatency
-	app.tableioops = tableioops.NewTableIoOps(temptableiolatency)
-	app.tablelocklatency = tablelocklatency.NewTableLockLatency(app.cfg, app.db)
-	app.mutexlatency = mutexlatency.NewMutexLatency(app.cfg, app.db)
-	app.stageslatency = stageslatency.NewStagesLatency(app.cfg, app.db)
-	app.memory = memoryusage.NewMemoryUsage(app.cfg, app.db)
-	app.users = userlatency.NewUserLatency(app.cfg, app.db)
-	log.Println("app.NewApp() Finished initialising models")
-
-	app.resetDBStatistics()
+	for _, s := range app.servers {
+		app.useServer(s)
+		app.resetDBStatistics()
+	}
+	app.useServer(app.servers[0])
 
 	log.Println("app.NewApp() finishes")
 	return app
 }
 
-// CollectAll collects all the stats together in one go
-func (app *App) collectAll() {
+// collectAll collects all the stats together in one go. A failing view
+// does not stop the others being collected and the first error is returned.
+func (app *App) collectAll() error {
 	log.Println("app.collectAll() start")
-	app.fileinfolatency.Collect()
-	app.tablelocklatency.Collect()
-	app.tableiolatency.Collect()
-	app.users.Collect()
-	app.stageslatency.Collect()
-	app.mutexlatency.Collect()
-	app.memory.Collect()
+	var first error
+	for _, def := range view.Definitions() {
+		if def.Shared {
+			continue // collected with another view
+		}
+		if err := app.collectView(def.Code); err != nil && first == nil {
+			first = err
+		}
+	}
 	log.Println("app.collectAll() finished")
+	return first
 }
 
 // resetDBStatistics does a fresh collection of data and then updates the initial values based on that.
 func (app *App) resetDBStatistics() {
 	log.Println("app.resetDBStatistcs()")
-	app.collectAll()
+	if app.replay != nil {
+		app.replay.Rewind() // start the replay again
+		return
+	}
+	app.showCollectError(app.collectAll())
 	app.resetStatistics()
 }
 
 func (app *App) resetStatistics() {
 	start := time.Now()
-	app.fileinfolatency.ResetStatistics()
-	app.tablelocklatency.ResetStatistics()
-	app.tableiolatency.ResetStatistics()
-	app.users.ResetStatistics()
-	app.stageslatency.ResetStatistics()
-	app.mutexlatency.ResetStatistics()
-	app.memory.ResetStatistics()
+	for _, def := range view.Definitions() {
+		if !def.Shared {
+			app.server.tabler(def.Code).ResetStatistics()
+		}
+	}
 
 	log.Println("app.resetStatistics() took", time.Duration(time.Since(start)).String())
 }
 
-// Collect the data we are looking at.
-func (app *App) Collect() {
+// Collect the data we are looking at. Any error is returned after
+// logging it and showing it on the screen.
+func (app *App) Collect() error {
 	log.Println("app.Collect()")
+	if app.replay != nil {
+		app.nextRecord()
+		return nil
+	}
+	if err := app.connected(); err != nil {
+		app.waitHandler.CollectedNow() // try again next interval
+		app.showCollectError(err)
+		return err
+	}
 	start := time.Now()
 
-	switch app.currentView.Get() {
-	case view.ViewLatency, view.ViewOps:
-		app.tableiolatency.Collect()
-	case view.ViewIO:
-		app.fileinfolatency.Collect()
-	case view.ViewLocks:
-		app.tablelocklatency.Collect()
-	case view.ViewUsers:
-		app.users.Collect()
-	case view.ViewMutex:
-		app.mutexlatency.Collect()
-	case view.ViewStages:
-		app.stageslatency.Collect()
-	case view.ViewMemory:
-		app.memory.Collect()
-	}
-	app.waitHandler.CollectedNow()
+	var err error
+	if app.recordFile != nil {
+		err = app.collectAll() // every view is recorded
+		if err == nil && app.currentView.Get() == view.ViewOps {
+			err = app.collectView(view.ViewOps) // sort the shared table_io_latency data by operations
+		}
+		app.waitHandler.CollectedNow()
+		if err == nil {
+			app.record()
+		}
+	} else {
+		err = app.collectView(app.currentView.Get())
+		app.waitHandler.CollectedNow()
+	}
+	app.showCollectError(err)
+	app.adaptInterval(time.Since(start))
+	app.checkAlerts()
 	log.Println("app.Collect() took", time.Duration(time.Since(start)).String())
+
+	return err
+}
+
+// showCollectError logs a collection error and shows it on the screen
+// until a later collection succeeds, when err is nil. If the connection
+// to the server was lost we start reconnecting.
+func (app *App) showCollectError(err error) {
+	if err != nil {
+		log.Println("app: collection failed:", err)
+		app.checkConnection(err)
+	}
+	if app.display == nil {
+		return
+	}
+	if err != nil {
+		app.display.SetError(err.Error())
+	} else {
+		app.display.SetError("")
+	}
+}
+
+// collectView collects the data of the given view
+func (app *App) collectView(code view.Code) error {
+	tabler := app.server.tabler(code)
+	if tabler == nil {
+		return nil
+	}
+	if err := tabler.Collect(); err != nil {
+		return fmt.Errorf("%s: %w", code, err)
+	}
+	return nil
+}
+
+// busyThreadsRunning is the number of running threads, including our own, above which the server is considered busy
+const busyThreadsRunning = 4
+
+// adaptInterval adjusts the interval to the time the last collection took if wanted
+func (app *App) adaptInterval(took time.Duration) {
+	if app.adaptiveInterval == nil {
+		return
+	}
+
+	values, err := app.cfg.Status().Values("threads_running")
+	if err != nil {
+		log.Println("app.adaptInterval():", err) // treat the server as not busy
+	}
+	busy := values["threads_running"] >= busyThreadsRunning
+	interval := app.adaptiveInterval.Next(app.waitHandler.WaitInterval(), took, busy)
+	if interval != app.waitHandler.WaitInterval() {
+		log.Println("app.adaptInterval() collection took", took, "busy:", busy, "changing interval to", interval)
+		app.waitHandler.SetWaitInterval(interval)
+	}
+}
+
+// checkAlerts checks the alert thresholds and sends any alerts to the webhook if configured
+func (app *App) checkAlerts() {
+	if len(app.alertThresholds) == 0 {
+		return
+	}
+
+	values, err := app.cfg.Status().Values(alert.Metrics(app.alertThresholds)...)
+	if err != nil {
+		log.Println("app.checkAlerts():", err)
+		return
+	}
+	for _, a := range alert.Check(app.alertThresholds, values, app.cfg.Hostname(), time.Now()) {
+		log.Println("app.checkAlerts():", a.Text)
+		if app.alertWebhook != nil {
+			app.alertWebhook.Send(a)
+		}
+	}
 }
 
 // SetHelp determines if we need to display help
@@ -191,50 +384,196 @@ func (app *App) SetHelp(help bool) {
 
 // Display shows the output appropriate to the corresponding view and device
 func (app *App) Display() {
-	if app.Help {
+	switch {
+	case app.Help:
 		app.display.DisplayHelp()
-	} else {
-		switch app.currentView.Get() {
-		case view.ViewLatency:
-			app.display.Display(app.tableiolatency)
-		case view.ViewOps:
-			app.display.Display(app.tableioops)
-		case view.ViewIO:
-			app.display.Display(app.fileinfolatency)
-		case view.ViewLocks:
-			app.display.Display(app.tablelocklatency)
-		case view.ViewUsers:
-			app.display.Display(app.users)
-		case view.ViewMutex:
-			app.display.Display(app.mutexlatency)
-		case view.ViewStages:
-			app.display.Display(app.stageslatency)
-		case view.ViewMemory:
-			app.display.Display(app.memory)
-		}
+	case app.display.ColumnChooserShown():
+		app.display.DisplayColumnChooser()
+	case app.display.PaneShown():
+		app.display.DisplayPane()
+	default:
+		app.display.SetView(app.currentView.Name())
+		app.display.Display(app.currentTabler())
 	}
 }
 
+// currentTabler returns the data of the current view
+func (app *App) currentTabler() pstable.Tabler {
+	return app.tabler(app.currentView.Get())
+}
+
+// selectedRowKey returns the key of the row selected in the current view
+// and false if no row of data is selected or its rows have no keys
+func (app *App) selectedRowKey() (string, bool) {
+	keyer, ok := app.currentTabler().(pstable.RowKeyer)
+	if !ok {
+		return "", false
+	}
+	selected, ok := app.display.Selected()
+	if !ok {
+		return "", false
+	}
+	return keyer.RowKey(selected)
+}
+
+// tabler returns the data of the given view
+func (app *App) tabler(code view.Code) pstable.Tabler {
+	if app.replay != nil {
+		return app.replayed[code]
+	}
+	return app.server.tabler(code)
+}
+
 // change to the previous display mode
 func (app *App) displayPrevious() {
 	app.currentView.SetPrev()
-	app.display.ClearScreen()
-	app.Display()
+	app.displayChanged()
 }
 
 // change to the next display mode
 func (app *App) displayNext() {
 	app.currentView.SetNext()
+	app.displayChanged()
+}
+
+// change to the display mode with the given name
+func (app *App) displayByName(name string) {
+	app.currentView.SetByName(name)
+	app.displayChanged()
+}
+
+// setNameFilter shows only the rows whose names match the regexp filter, or all rows if it is empty,
+// recollecting the current view so it is shown straight away
+func (app *App) setNameFilter(filter string) {
+	var re *regexp.Regexp
+	if filter != "" {
+		var err error
+		if re, err = regexp.Compile(filter); err != nil {
+			log.Printf("app.setNameFilter() ignoring invalid filter %q: %v\n", filter, err)
+			return
+		}
+	}
+	log.Printf("app.setNameFilter() filter: %q\n", filter)
+	for _, s := range app.servers {
+		s.cfg.SetNameFilter(re)
+	}
+
+	app.showCollectError(app.currentTabler().Collect())
+	app.display.ResetSelection()
+	app.Display()
+}
+
+// changeSort sorts the current view on the next or previous column
+// if it can be sorted and redisplays it
+func (app *App) changeSort(next bool) {
+	t := app.currentTabler()
+	sorter, ok := t.(pstable.Sorter)
+	if !ok {
+		return
+	}
+	sorters := []pstable.Sorter{sorter}
+	for _, s := range app.servers {
+		if other, ok := s.tabler(app.currentView.Get()).(pstable.Sorter); ok && s != app.server {
+			sorters = append(sorters, other) // keep the same order when switching servers
+		}
+	}
+	for _, each := range sorters {
+		if next {
+			each.SortNext()
+		} else {
+			each.SortPrev()
+		}
+	}
+	log.Printf("app.changeSort() view %s sorted on %q\n", app.currentView.Name(), sorter.SortColumn())
+
+	app.showCollectError(t.Collect())
+	app.display.ResetSelection()
+	app.Display()
+}
+
+// explainer is implemented by views whose rows are statements which may be explained
+type explainer interface {
+	Explain(key string) ([]string, error)
+}
+
+// toggleExplain shows the query plan of the statement selected in the
+// current view, or why it can not be explained, or stops showing the plan
+func (app *App) toggleExplain() {
+	if app.display.PaneShown() {
+		app.display.HidePane()
+		app.display.ClearScreen()
+		app.Display()
+		return
+	}
+
+	var lines []string
+	ex, ok := app.currentTabler().(explainer)
+	key, selected := app.selectedRowKey()
+	switch {
+	case !ok:
+		lines = []string{"Only the statements of the statements view can be explained"}
+	case !selected:
+		lines = []string{"No statement is selected"}
+	default:
+		plan, err := ex.Explain(key)
+		if err != nil {
+			log.Println("app.toggleExplain():", err)
+			lines = []string{"The statement can not be explained: " + err.Error()}
+			break
+		}
+		lines = plan
+	}
+
+	app.display.ShowPane("EXPLAIN of the selected statement", lines)
+	app.display.ClearScreen()
+	app.Display()
+}
+
+// accountGrouper is implemented by views which may group users by account
+type accountGrouper interface {
+	ToggleByAccount()
+}
+
+// toggleByAccount switches user_latency between grouping by user and by account on every server,
+// collecting the data again if shown
+func (app *App) toggleByAccount() {
+	for _, s := range app.servers {
+		if grouper, ok := s.tabler(view.ViewUsers).(accountGrouper); ok {
+			grouper.ToggleByAccount()
+		}
+	}
+	if app.currentView.Get() == view.ViewUsers && app.replay == nil {
+		_ = app.Collect() // any error is shown until the next collection
+		app.display.ResetColumnWidths()
+		app.Display()
+	}
+}
+
+// displayChanged redisplays the screen after the view has changed
+func (app *App) displayChanged() {
+	app.display.ResetSelection()
+	app.display.ResetColumnWidths()
 	app.display.ClearScreen()
 	app.Display()
 }
 
 // Cleanup prepares the application prior to shutting down
 func (app *App) Cleanup() {
-	app.display.Close()
-	if app.db != nil {
-		app.setupInstruments.RestoreConfiguration()
-		_ = app.db.Close()
+	if app.display != nil {
+		mylog.SetCleanup(nil)
+		app.display.Close()
+	}
+	if app.recordFile != nil {
+		_ = app.recordFile.Close()
+	}
+	if app.outputFile != nil {
+		_ = app.outputFile.Close()
+	}
+	for _, s := range app.servers {
+		if s.db != nil {
+			s.setupInstruments.RestoreConfiguration()
+			_ = s.db.Close()
+		}
 	}
 	log.Println("App.Cleanup completed")
 }
@@ -246,15 +585,32 @@ func (app *App) Run() {
 	app.sigChan = make(chan os.Signal, 10) // 10 entries
 	signal.Notify(app.sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	if app.statusLine {
+		app.runStatusLine()
+		return
+	}
+	if app.prometheusListen != "" {
+		app.runPrometheus()
+		return
+	}
+	if app.display == nil {
+		app.runBatch()
+		return
+	}
+
 	eventChan := app.display.EventChan()
 
 	for !app.Finished {
+		var nextPeriod <-chan time.Time // never ready while paused
+		if !app.paused {
+			nextPeriod = app.waitHandler.WaitUntilNextPeriod()
+		}
 		select {
 		case sig := <-app.sigChan:
 			fmt.Println("Caught signal: ", sig)
 			app.Finished = true
-		case <-app.waitHandler.WaitUntilNextPeriod():
-			app.Collect()
+		case <-nextPeriod:
+			_ = app.Collect() // any error is shown until the next collection
 			app.Display()
 		case inputEvent := <-eventChan:
 			switch inputEvent.Type {
@@ -266,16 +622,84 @@ func (app *App) Run() {
 				app.displayNext()
 			case event.EventViewPrev:
 				app.displayPrevious()
+			case event.EventGotoViewPrompt:
+				app.display.ShowPrompt(inputEvent.Text)
+				app.Display()
+			case event.EventGotoView:
+				app.display.HidePrompt()
+				if inputEvent.Text != "" {
+					app.displayByName(inputEvent.Text)
+				} else {
+					app.Display()
+				}
 			case event.EventDecreasePollTime:
 				if app.waitHandler.WaitInterval() > time.Second {
 					app.waitHandler.SetWaitInterval(app.waitHandler.WaitInterval() - time.Second)
 				}
 			case event.EventIncreasePollTime:
 				app.waitHandler.SetWaitInterval(app.waitHandler.WaitInterval() + time.Second)
+			case event.EventSelectUp:
+				app.display.SelectUp()
+				app.Display()
+			case event.EventSelectDown:
+				app.display.SelectDown()
+				app.Display()
 			case event.EventHelp:
 				app.SetHelp(!app.Help)
+			case event.EventToggleFreezeColumns:
+				app.display.ToggleFreezeColumns()
+				app.Display()
+			case event.EventFilterPrompt:
+				app.display.ShowFilterPrompt(inputEvent.Text)
+				app.Display()
+			case event.EventFilter:
+				app.display.HideFilterPrompt()
+				app.setNameFilter(inputEvent.Text)
+			case event.EventSortNext:
+				app.changeSort(true)
+			case event.EventSortPrev:
+				app.changeSort(false)
+			case event.EventToggleSmoothing:
+				smoothed := !app.cfg.WantSmoothedRates()
+				for _, s := range app.servers {
+					s.cfg.SetWantSmoothedRates(smoothed)
+				}
+				app.Display()
 			case event.EventToggleWantRelative:
-				app.cfg.SetWantRelativeStats(!app.cfg.WantRelativeStats())
+				relative := !app.cfg.WantRelativeStats()
+				for _, s := range app.servers {
+					s.cfg.SetWantRelativeStats(relative)
+				}
+				app.Display()
+			case event.EventServerNext:
+				app.switchServer(1)
+			case event.EventServerPrev:
+				app.switchServer(-1)
+			case event.EventToggleByAccount:
+				app.toggleByAccount()
+			case event.EventTogglePause:
+				app.paused = !app.paused
+				log.Println("app.Run() paused:", app.paused)
+				app.display.SetPaused(app.paused)
+				app.Display()
+			case event.EventCollectNow:
+				_ = app.Collect() // any error is shown until the next collection
+				app.Display()
+			case event.EventColumnChooser:
+				app.toggleColumnChooser()
+			case event.EventExplain:
+				app.toggleExplain()
+			case event.EventColumnToggle:
+				app.display.ToggleColumn()
+				app.Display()
+			case event.EventColumnMoveUp:
+				app.display.MoveColumn(-1)
+				app.Display()
+			case event.EventColumnMoveDown:
+				app.display.MoveColumn(1)
+				app.Display()
+			case event.EventColumnDefault:
+				app.display.DefaultColumns()
 				app.Display()
 			case event.EventResetStatistics:
 				app.resetDBStatistics()
@@ -290,3 +714,144 @@ func (app *App) Run() {
 		}
 	}
 }
+
+// runBatch collects the current view each interval and writes it out
+// in the wanted format until we are interrupted
+func (app *App) runBatch() {
+	log.Println("app.runBatch() format:", app.format)
+
+	for !app.Finished {
+		select {
+		case sig := <-app.sigChan:
+			log.Println("Caught signal: ", sig)
+			app.Finished = true
+		case <-app.waitHandler.WaitUntilNextPeriod():
+			if err := app.Collect(); err != nil {
+				continue // already logged, try again next interval
+			}
+			app.write()
+		}
+	}
+}
+
+// runStatusLine writes a single line summary each interval until we are
+// interrupted, updating the line in place if writing to a terminal
+func (app *App) runStatusLine() {
+	log.Println("app.runStatusLine()")
+
+	format := "%s\n"
+	if stat, err := os.Stdout.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		format = "\r%s\x1b[K" // carriage return and clear to the end of the line
+	}
+
+	var previous statusline.Sample
+	for !app.Finished {
+		select {
+		case sig := <-app.sigChan:
+			log.Println("Caught signal: ", sig)
+			app.Finished = true
+		case <-app.waitHandler.WaitUntilNextPeriod():
+			app.waitHandler.CollectedNow()
+			if err := app.connected(); err != nil {
+				log.Println("app.runStatusLine():", err)
+				continue // try again next interval
+			}
+			current, err := app.statusLineSample()
+			if err != nil {
+				app.checkConnection(err)
+				log.Println("app.runStatusLine():", err)
+				continue // try again next interval
+			}
+			fmt.Printf(format, statusline.Line(app.cfg.Hostname(), previous, current))
+			previous = current
+		}
+	}
+	if format != "%s\n" {
+		fmt.Println()
+	}
+}
+
+// statusLineSample collects the values shown in the status line
+func (app *App) statusLineSample() (statusline.Sample, error) {
+	values, err := app.cfg.Status().Values("questions", "threads_connected", "threads_running")
+	if err != nil {
+		return statusline.Sample{}, err
+	}
+	sample := statusline.Sample{
+		Collected: time.Now(),
+		Questions: uint64(values["questions"]),
+		Connected: uint64(values["threads_connected"]),
+		Running:   uint64(values["threads_running"]),
+	}
+
+	// the totals hold the largest lag and the number of workers
+	applierworkers := app.server.tabler(view.ViewApplierWorkers)
+	if err := applierworkers.Collect(); err != nil {
+		return statusline.Sample{}, err
+	}
+	data := applierworkers.Data()
+	if workers, ok := data.Total("workers"); ok && workers > 0 {
+		lag, _ := data.Total("lag")
+		sample.Replica = true
+		sample.Lag = uint64(lag)
+	}
+
+	return sample, nil
+}
+
+// write writes the data of the current view in the wanted format
+func (app *App) write() {
+	var buf bytes.Buffer
+	var destination string
+
+	data := app.currentTabler().Data()
+	switch app.format {
+	case "csv":
+		if err := output.CSV(&buf, app.currentView.Name(), data, app.csv, !app.csvHeader); err != nil {
+			mylog.Fatalln("app.write():", err)
+		}
+		app.csvHeader = true
+	case "influx":
+		tags := map[string]string{"host": app.cfg.Hostname()}
+		if err := output.Influx(&buf, "ps_top_"+app.currentView.Name(), tags, data); err != nil {
+			mylog.Fatalln("app.write():", err)
+		}
+		destination = app.influxURL
+	case "json":
+		if err := output.JSON(&buf, app.cfg.Hostname(), app.currentView.Name(), data); err != nil {
+			mylog.Fatalln("app.write():", err)
+		}
+	default:
+		mylog.Fatalln("app.write(): unknown format", app.format)
+	}
+
+	if app.outputFile != nil {
+		if _, err := app.outputFile.Write(buf.Bytes()); err != nil {
+			mylog.Fatalln("app.write(): failed to write output:", err)
+		}
+		return
+	}
+	if err := output.Send(destination, buf.Bytes()); err != nil {
+		log.Println("app.write(): failed to send output:", err)
+	}
+}
+
+// openOutput opens the file to append the batch output to, if given.
+// The csv header is only written if the file is empty so that
+// appending to the output of an earlier run keeps a single header.
+func (app *App) openOutput(name string) {
+	if name == "" {
+		return
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		mylog.Fatal(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		mylog.Fatal(err)
+	}
+	app.csvHeader = info.Size() > 0
+	app.outputFile = file
+	log.Println("app.openOutput() appending output to", name)
+}
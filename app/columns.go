@@ -0,0 +1,40 @@
+package app
+
+import (
+	"log"
+
+	"github.com/sjmudd/ps-top/rc"
+)
+
+// loadColumns gives the display the columns chosen for each view in ~/.pstoprc
+func (app *App) loadColumns() {
+	columns, err := rc.Columns()
+	if err != nil {
+		log.Println("app.loadColumns():", err)
+		return
+	}
+	app.display.SetLayouts(columns)
+}
+
+// toggleColumnChooser shows the column chooser of the current view or, if shown,
+// hides it saving the columns chosen in ~/.pstoprc
+func (app *App) toggleColumnChooser() {
+	if !app.display.ColumnChooserShown() {
+		app.Help = false
+		app.display.ShowColumnChooser(app.currentTabler().Headings())
+		app.display.ClearScreen()
+		app.Display()
+		return
+	}
+
+	view, columns, changed := app.display.HideColumnChooser()
+	if changed {
+		log.Printf("app.toggleColumnChooser() view %s shows the columns %q\n", view, columns)
+		if err := rc.SaveColumns(view, columns); err != nil {
+			log.Println("app.toggleColumnChooser():", err)
+			app.display.SetError(err.Error())
+		}
+	}
+	app.display.ClearScreen()
+	app.Display()
+}
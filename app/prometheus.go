@@ -0,0 +1,106 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/sjmudd/ps-top/mylog"
+	"github.com/sjmudd/ps-top/output"
+	"github.com/sjmudd/ps-top/view"
+)
+
+// metrics holds the Prometheus metrics served, replaced after each collection
+type metrics struct {
+	mu   sync.Mutex
+	text []byte
+}
+
+// set replaces the metrics served
+func (m *metrics) set(text []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.text = text
+}
+
+// ServeHTTP writes the latest metrics in the Prometheus text exposition format
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	text := m.text
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(text)
+}
+
+// runPrometheus collects all the views each interval and serves them as
+// Prometheus metrics on /metrics until we are interrupted
+func (app *App) runPrometheus() {
+	log.Println("app.runPrometheus() listening on", app.prometheusListen)
+
+	listener, err := net.Listen("tcp", app.prometheusListen)
+	if err != nil {
+		mylog.Fatal(err)
+	}
+	app.metrics = new(metrics)
+	app.metrics.set(app.prometheusMetrics()) // the views were collected when starting
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", app.metrics)
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			mylog.Fatal(err)
+		}
+	}()
+
+	for !app.Finished {
+		select {
+		case sig := <-app.sigChan:
+			log.Println("Caught signal: ", sig)
+			app.Finished = true
+		case <-app.waitHandler.WaitUntilNextPeriod():
+			if err := app.connected(); err != nil {
+				app.waitHandler.CollectedNow()
+				log.Println("app.runPrometheus(): keeping the previous metrics:", err)
+				continue
+			}
+			if err := app.collectAll(); err != nil {
+				app.waitHandler.CollectedNow()
+				app.checkConnection(err)
+				log.Println("app.runPrometheus(): keeping the previous metrics:", err)
+				continue
+			}
+			app.waitHandler.CollectedNow()
+			app.metrics.set(app.prometheusMetrics())
+		}
+	}
+
+	if err := server.Close(); err != nil {
+		log.Println("app.runPrometheus(): failed to close the server:", err)
+	}
+}
+
+// prometheusMetrics returns the metrics of all the selectable views and the global status
+func (app *App) prometheusMetrics() []byte {
+	var buf bytes.Buffer
+
+	labels := map[string]string{"host": app.cfg.Hostname()}
+	for _, code := range view.Codes() {
+		tabler := app.tabler(code)
+		if tabler == nil {
+			continue
+		}
+		if err := output.Prometheus(&buf, "ps_top_"+code.String(), labels, tabler.Data()); err != nil {
+			log.Println("app.prometheusMetrics():", code.String(), err)
+		}
+	}
+	if err := app.cfg.Status().WritePrometheus(&buf); err != nil {
+		log.Println("app.prometheusMetrics(): global status:", err)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/global"
+)
+
+// the delays between attempts to reconnect to the server
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// checkConnection starts reconnecting to the server if err shows the connection was lost
+func (app *App) checkConnection(err error) {
+	if app.reconnecting || !global.IsConnectionLost(err) {
+		return
+	}
+	log.Println("app.checkConnection() lost the connection to the server:", err)
+	app.reconnecting = true
+	app.reconnectAt = time.Now() // try at the next collection
+	app.reconnectErr = err
+	app.reconnectBackoff.Reset()
+	if app.display != nil {
+		app.display.SetReconnecting(true)
+	}
+}
+
+// connected returns nil if the server may be queried. If the connection was
+// lost it tries to reconnect once the backoff delay has passed, returning
+// why the last attempt failed until one succeeds.
+func (app *App) connected() error {
+	if !app.reconnecting {
+		return nil
+	}
+	if time.Now().Before(app.reconnectAt) {
+		return app.reconnectErr
+	}
+	if err := app.setupConnection(); err != nil {
+		delay := app.reconnectBackoff.Next()
+		app.reconnectAt = time.Now().Add(delay)
+		app.reconnectErr = fmt.Errorf("next attempt in %v: %w", delay, err)
+		log.Println("app.connected() unable to reconnect:", app.reconnectErr)
+		return app.reconnectErr
+	}
+
+	log.Println("app.connected() reconnected to the server")
+	app.reconnecting = false
+	app.reconnectErr = nil
+	if app.display != nil {
+		app.display.SetReconnecting(false)
+	}
+	app.resetDBStatistics() // the server may have restarted so start again
+
+	return nil
+}
+
+// setupConnection repeats the checks done at startup so the server may be
+// used again after reconnecting. It may have been restarted or upgraded.
+func (app *App) setupConnection() error {
+	if err := app.db.Ping(); err != nil {
+		return err
+	}
+	app.detectServer()
+
+	variables, err := global.NewVariables(app.db).SelectAll()
+	if err != nil {
+		return err
+	}
+	defer variables.Close()
+	if err := checkPerformanceSchema(variables); err != nil {
+		return err
+	}
+	app.cfg.SetVariables(variables)
+	app.setupInstruments.EnableMonitoring() // a restart forgets the changes
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"log"
+	"os"
+
+	"github.com/sjmudd/anonymiser"
+	"github.com/sjmudd/ps-top/collector"
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/display"
+	"github.com/sjmudd/ps-top/global"
+	"github.com/sjmudd/ps-top/mylog"
+	"github.com/sjmudd/ps-top/pstable"
+	"github.com/sjmudd/ps-top/replay"
+	"github.com/sjmudd/ps-top/view"
+)
+
+// newReplayApp sets up the application to show the data recorded in
+// settings.Replay instead of collecting it from a server
+func newReplayApp(settings Settings) *App {
+	log.Println("app.newReplayApp() replaying", settings.Replay)
+	app := new(App)
+	app.server = new(server)
+	app.servers = []*server{app.server}
+
+	anonymiser.Enable(settings.Anonymise)
+	file, err := os.Open(settings.Replay)
+	if err != nil {
+		mylog.Fatal(err)
+	}
+	records, err := replay.Read(file)
+	_ = file.Close()
+	if err != nil {
+		mylog.Fatalf("Unable to read the recorded data from %s: %v", settings.Replay, err)
+	}
+	if len(records) == 0 {
+		mylog.Fatalf("No recorded data found in %s", settings.Replay)
+	}
+	log.Println("app.newReplayApp() read", len(records), "records")
+	if settings.ReplayBucket > 0 {
+		records = replay.Records(replay.Bucket(replay.Samples(records), settings.ReplayBucket), records[0].Host, records[0].Version)
+		log.Println("app.newReplayApp() averaged into", len(records), "buckets of", settings.ReplayBucket)
+	}
+	app.replay = replay.NewSource(records)
+
+	variables := global.NewRecordedVariables(map[string]string{
+		"hostname": records[0].Host,
+		"version":  records[0].Version,
+	})
+	app.cfg = config.NewConfig(nil, variables, settings.Filter, false)
+	app.cfg.SetNameFilter(settings.NameFilter)
+	app.format = settings.Format
+	app.influxURL = settings.InfluxURL
+	app.csv = settings.CSV
+	app.openOutput(settings.Output)
+	if app.format == "" {
+		app.display = display.NewDisplay(app.cfg)
+		mylog.SetCleanup(app.display.Close) // restore the terminal before exiting on a fatal error
+		app.display.SetHighlight(settings.Highlight, settings.NoColor)
+		app.display.SetFreezeColumns(settings.FreezeColumns)
+		app.loadColumns()
+		if settings.NameFilter != nil {
+			app.display.SetFilter(settings.NameFilter.String())
+		}
+		app.SetHelp(false)
+	}
+
+	app.currentView = view.SetupRecorded(settings.ViewName, app.replay.Views())
+	if app.display != nil {
+		app.display.SetViewNames(view.Names())
+	}
+	app.waitHandler.SetWaitInterval(settings.Interval)
+
+	app.replayed = make(map[view.Code]pstable.Tabler)
+	for _, code := range view.Codes() {
+		app.replayed[code] = app.replay.NewTabler(code.String(), app.cfg)
+	}
+
+	log.Println("app.newReplayApp() finishes")
+	return app
+}
+
+// nextRecord moves to the next recorded data. Batch output finishes after the last record
+// while the screen keeps showing it.
+func (app *App) nextRecord() {
+	if !app.replay.Next() && app.display == nil {
+		app.Finished = true
+	}
+}
+
+// record writes the data of every view to the record file
+func (app *App) record() {
+	record := replay.Record{
+		Collected: app.waitHandler.LastCollected(),
+		Host:      app.cfg.Hostname(),
+		Version:   app.cfg.MySQLVersion(),
+	}
+	for _, code := range view.Codes() {
+		record.Views = append(record.Views, collector.ViewResult{View: code.String(), Data: app.tabler(code).Data()})
+	}
+
+	if err := replay.Write(app.recordFile, record); err != nil {
+		mylog.Fatalln("app.record():", err)
+	}
+}
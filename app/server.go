@@ -0,0 +1,165 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/connector"
+	"github.com/sjmudd/ps-top/global"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/mylog"
+	"github.com/sjmudd/ps-top/pstable"
+	"github.com/sjmudd/ps-top/setupinstruments"
+	"github.com/sjmudd/ps-top/view"
+	"github.com/sjmudd/ps-top/wait"
+)
+
+// server holds the connection to one of the servers monitored and the data collected from it
+type server struct {
+	cfg              *config.Config                     // some config needed by the display
+	db               *sql.DB                            // connection to MySQL
+	tablers          view.Tablers                       // the data collected for each view
+	serverVersion    *global.ServerVersion              // the server version given instead of detecting it, if set
+	reconnecting     bool                               // has the connection to the server been lost?
+	reconnectAt      time.Time                          // when to next try to reconnect
+	reconnectBackoff wait.Backoff                       // the delays between attempts to reconnect
+	reconnectErr     error                              // why the last attempt to reconnect failed
+	setupInstruments *setupinstruments.SetupInstruments // for setting up and restoring performance_schema configuration.
+	version          *global.ServerVersion              // the given or detected server version, nil if not known
+	source           global.Source                      // where the variables and status are read from, saved when switching servers
+	schema           string                             // the default schema of the connection
+	datadir          string                             // the server's data directory if local, otherwise empty
+	clockSkew        string                             // description of the clock skew between the server and this host
+}
+
+// newServer connects to the server given in flags and sets up its models
+func newServer(flags connector.Config, settings Settings) *server {
+	s := new(server)
+	s.db = connector.NewConnector(flags).DB
+
+	s.serverVersion = settings.ServerVersion
+	s.detectServer()
+	status := global.NewStatus(s.db)
+	variables, err := global.NewVariables(s.db).SelectAll()
+	if errors.Is(err, global.ErrPerformanceSchemaDisabled) {
+		mylog.Fatal(fmt.Sprintf("%s to use %s.", global.ErrPerformanceSchemaDisabled, lib.ProgName))
+	}
+	if err != nil {
+		mylog.Fatal(err)
+	}
+	defer variables.Close() // the variables are not collected again so nothing need stay prepared
+	s.source = global.CurrentSource()
+	log.Println("app.newServer() reading variables from", global.VariablesSource(), "and status from", global.StatusSource())
+	// Prior to setting up screen check that performance_schema is enabled.
+	// On MariaDB this is not the default setting so it will confuse people.
+	ensurePerformanceSchemaEnabled(variables)
+
+	s.cfg = config.NewConfig(status, variables, settings.Filter, true)
+	s.cfg.SetSmoothIntervals(settings.Smooth)
+	s.cfg.SetNameFilter(settings.NameFilter)
+	if settings.PrometheusListen != "" {
+		s.cfg.SetWantRelativeStats(false) // Prometheus calculates the rates from the absolute values
+	}
+	s.datadir = localDatadir(variables)
+	s.clockSkew = checkClockSkew(s.db)
+
+	s.setupInstruments = setupinstruments.NewSetupInstruments(s.db)
+	s.setupInstruments.EnableMonitoring()
+	s.reconnectBackoff = wait.Backoff{Min: minReconnectDelay, Max: maxReconnectDelay}
+
+	// setup to their initial types/values
+	log.Println("app.newServer() Setup models")
+	s.tablers = view.NewTablers(s.cfg, s.db)
+	log.Println("app.newServer() Finished initialising models")
+
+	return s
+}
+
+// detectServer detects the server version, unless given, and the current
+// schema which determine the tables to query
+func (s *server) detectServer() {
+	s.version = s.serverVersion
+	if s.version == nil {
+		if version, err := global.DetectServerVersion(s.db); err == nil {
+			s.version = &version
+		} else {
+			log.Println("app.detectServer() unable to detect the server version, probing the variables tables instead:", err)
+		}
+	}
+	s.chooseSource()
+	if schema, err := global.DetectCurrentSchema(s.db); err != nil {
+		log.Println("app.detectServer() unable to detect the current schema:", err)
+	} else {
+		s.schema = schema
+	}
+}
+
+// chooseSource chooses the tables to query from the server version, if
+// known, forgetting the fallbacks another server may have needed
+func (s *server) chooseSource() {
+	global.SetSource(global.Source{})
+	if s.version != nil {
+		global.SetServerVersion(*s.version)
+	}
+	s.source = global.CurrentSource()
+}
+
+// activate sets the tables to query, including any fallbacks found to be
+// needed, and the current schema, which are shared by all servers, to those
+// of this server
+func (s *server) activate() {
+	global.SetSource(s.source)
+	global.SetCurrentSchema(s.schema)
+}
+
+// deactivate saves the tables queried as the fallbacks needed may have
+// changed while the server was shown
+func (s *server) deactivate() {
+	s.source = global.CurrentSource()
+}
+
+// tabler returns the data of the given view collected from the server
+func (s *server) tabler(code view.Code) pstable.Tabler {
+	return s.tablers[code]
+}
+
+// useServer makes s the server collected and shown
+func (app *App) useServer(s *server) {
+	if app.server != nil && len(app.servers) > 1 {
+		app.server.deactivate()
+	}
+	app.server = s
+	if len(app.servers) > 1 {
+		s.activate()
+	}
+	if app.display != nil {
+		app.display.SetConfig(s.cfg)
+		app.display.SetDatadir(s.datadir)
+		app.display.SetClockSkew(s.clockSkew)
+		app.display.SetReconnecting(s.reconnecting)
+	}
+}
+
+// switchServer shows the server offset places after the current one,
+// collecting its data straight away
+func (app *App) switchServer(offset int) {
+	if len(app.servers) < 2 {
+		return
+	}
+	current := 0
+	for i, s := range app.servers {
+		if s == app.server {
+			current = i
+		}
+	}
+	next := app.servers[(current+offset+len(app.servers))%len(app.servers)]
+	log.Println("app.switchServer() showing", next.cfg.Hostname())
+
+	app.useServer(next)
+	_ = app.Collect() // any error is shown until the next collection
+	app.displayChanged()
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sjmudd/ps-top/global"
+)
+
+func TestUseServerSource(t *testing.T) {
+	defer global.SetSource(global.CurrentSource())
+
+	newTestServer := func(version string) *server {
+		v, err := global.ParseServerVersion(version)
+		if err != nil {
+			t.Fatalf("ParseServerVersion(%q) failed: %v", version, err)
+		}
+		s := &server{version: &v}
+		s.chooseSource()
+		return s
+	}
+	mariadb := newTestServer("10.11.6-MariaDB")
+	mysql := newTestServer("8.0.36")
+	app := &App{servers: []*server{mariadb, mysql}}
+
+	tests := []struct {
+		server   *server
+		expected string
+	}{
+		{mariadb, "INFORMATION_SCHEMA.GLOBAL_VARIABLES"},
+		{mysql, "performance_schema.global_variables"},
+		{mariadb, "INFORMATION_SCHEMA.GLOBAL_VARIABLES"},
+		{mysql, "performance_schema.global_variables"},
+	}
+	for i, test := range tests {
+		app.useServer(test.server)
+		if got := global.VariablesSource(); got != test.expected {
+			t.Errorf("useServer() step %d failed: expected the variables from %s, got %s", i, test.expected, got)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package baseobject
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/sjmudd/ps-top/config"
@@ -23,6 +24,11 @@ func (o *BaseObject) DatabaseFilter() *filter.DatabaseFilter {
 	return o.cfg.DatabaseFilter()
 }
 
+// NameFilter returns the config's NameFilter()
+func (o *BaseObject) NameFilter() *regexp.Regexp {
+	return o.cfg.NameFilter()
+}
+
 // SetConfig sets the config in this object which can be used later.
 // - it should always be defined (!= nil)
 func (o *BaseObject) SetConfig(cfg *config.Config) {
@@ -40,6 +46,14 @@ func (o BaseObject) Variables() *global.Variables {
 	return o.cfg.Variables()
 }
 
+// Status returns a pointer to the global status
+func (o BaseObject) Status() *global.Status {
+	if o.cfg == nil {
+		mylog.Fatal("BaseObject.Status() o.cfg should not be nil")
+	}
+	return o.cfg.Status()
+}
+
 // WantRelativeStats indicates whether we want relative stats or not
 // - FIXME and optmise me away
 func (o BaseObject) WantRelativeStats() bool {
@@ -49,3 +63,19 @@ func (o BaseObject) WantRelativeStats() bool {
 	}
 	return o.cfg.WantRelativeStats()
 }
+
+// SmoothIntervals returns the number of intervals rates should be averaged over
+func (o BaseObject) SmoothIntervals() int {
+	if o.cfg == nil {
+		mylog.Fatal("BaseObject.SmoothIntervals(): o.cfg should not be nil")
+	}
+	return o.cfg.SmoothIntervals()
+}
+
+// WantSmoothedRates indicates whether we want smoothed rather than raw rates
+func (o BaseObject) WantSmoothedRates() bool {
+	if o.cfg == nil {
+		mylog.Fatal("BaseObject.WantSmoothedRates(): o.cfg should not be nil")
+	}
+	return o.cfg.WantSmoothedRates()
+}
@@ -0,0 +1,96 @@
+// Package collector allows other programs to use the ps-top views
+// to collect data once without any terminal involvement.
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/global"
+	"github.com/sjmudd/ps-top/model/filter"
+	"github.com/sjmudd/ps-top/pstable"
+	"github.com/sjmudd/ps-top/view"
+)
+
+// ViewResult holds the rows of a view collected once.
+// Data.Columns describes the numeric values of each row.
+type ViewResult struct {
+	View string // the name of the view
+	pstable.Data
+}
+
+// collectWithin runs collect returning its error, or ctx.Err() if ctx is done
+// first, in which case collect is left to finish in the background. finished
+// is called once collect has finished, or straight away if it is not run, so
+// whatever collect uses is only released once it is no longer used.
+func collectWithin(ctx context.Context, collect func() error, finished func()) error {
+	if err := ctx.Err(); err != nil {
+		finished()
+		return err
+	}
+
+	done := make(chan error, 1) // buffered so collect finishes if we stop waiting
+	go func() {
+		defer finished()
+		done <- collect()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Views returns the names of the views which may be collected
+func Views() []string {
+	definitions := view.Definitions()
+	views := make([]string, 0, len(definitions))
+	for _, def := range definitions {
+		views = append(views, def.Name)
+	}
+	sort.Strings(views)
+
+	return views
+}
+
+// CollectView collects the named view once from dbh and returns its rows
+// with absolute values. ctx bounds the call: the connection checks and the
+// global variables use it but the views' own queries do not yet, so if ctx
+// is done first ctx.Err() is returned while the view's collection keeps
+// running its queries on dbh in the background until they finish, when its
+// result is discarded. dbh should not be closed before then or those queries
+// fail, which is only logged.
+func CollectView(ctx context.Context, dbh *sql.DB, viewName string) (ViewResult, error) {
+	def, ok := view.Lookup(viewName)
+	if !ok {
+		return ViewResult{}, fmt.Errorf("unknown view %q", viewName)
+	}
+	if dbh == nil {
+		return ViewResult{}, errors.New("no database connection given")
+	}
+	if err := dbh.PingContext(ctx); err != nil {
+		return ViewResult{}, err
+	}
+
+	variables, err := global.NewVariables(dbh).SelectAllContext(ctx)
+	if err != nil {
+		return ViewResult{}, err
+	}
+	cfg := config.NewConfig(global.NewStatus(dbh), variables, filter.NewDatabaseFilter(""), false)
+	tabler := def.New(cfg, dbh, nil)
+	// the views may collect the variables again so keep them until the collection finishes
+	if err := collectWithin(ctx, tabler.Collect, func() { _ = variables.Close() }); err != nil {
+		return ViewResult{}, err
+	}
+
+	return ViewResult{
+		View: viewName,
+		Data: tabler.Data(),
+	}, nil
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/view"
+)
+
+func TestCollectViewErrors(t *testing.T) {
+	tests := []struct {
+		view string
+	}{
+		{""},
+		{"no_such_view"},
+		{"table_io_latency"}, // known but there is no connection
+	}
+	for _, test := range tests {
+		if _, err := CollectView(context.Background(), nil, test.view); err == nil {
+			t.Errorf("CollectView(%q) failed: expected an error", test.view)
+		}
+	}
+}
+
+func TestCollectWithin(t *testing.T) {
+	finished := make(chan struct{}, 1)
+	done := func() { finished <- struct{}{} }
+
+	failed := errors.New("failed")
+	if err := collectWithin(context.Background(), func() error { return failed }, done); err != failed {
+		t.Errorf("collectWithin() failed: expected %v, got %v", failed, err)
+	}
+	<-finished
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	if err := collectWithin(ctx, func() error { <-release; return nil }, done); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("collectWithin() failed: expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case <-finished:
+		t.Errorf("collectWithin() failed: finished was called before the collection finished")
+	default:
+	}
+	close(release)
+	<-finished // called once the abandoned collection finishes
+
+	if err := collectWithin(ctx, func() error { return nil }, done); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("collectWithin() failed: expected %v when already done, got %v", context.DeadlineExceeded, err)
+	}
+	<-finished
+}
+
+func TestViews(t *testing.T) {
+	views := Views()
+	if len(views) != len(view.Definitions()) {
+		t.Errorf("Views() failed: expected %d views, got %d", len(view.Definitions()), len(views))
+	}
+	for i := 1; i < len(views); i++ {
+		if views[i-1] >= views[i] {
+			t.Errorf("Views() failed: not sorted: %v", views)
+		}
+	}
+}
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"log"
+	"regexp"
 	"strings"
 
 	"github.com/sjmudd/anonymiser"
@@ -14,9 +16,12 @@ import (
 // Config holds the common information
 type Config struct {
 	databaseFilter    *filter.DatabaseFilter
+	nameFilter        *regexp.Regexp // the rows' names must match this if set
 	status            *global.Status
 	variables         *global.Variables
 	wantRelativeStats bool
+	smoothIntervals   int  // number of intervals to average rates over, 0 to disable
+	wantSmoothedRates bool // show smoothed rather than raw rates
 }
 
 // NewConfig returns the pointer to a new (empty) config
@@ -34,6 +39,16 @@ func (c Config) DatabaseFilter() *filter.DatabaseFilter {
 	return c.databaseFilter
 }
 
+// SetNameFilter sets the regexp the names of the rows shown must match, nil showing all rows
+func (c *Config) SetNameFilter(re *regexp.Regexp) {
+	c.nameFilter = re
+}
+
+// NameFilter returns the regexp the names of the rows shown must match or nil if there is none
+func (c Config) NameFilter() *regexp.Regexp {
+	return c.nameFilter
+}
+
 // Hostname returns the current short hostname
 func (c Config) Hostname() string {
 	hostname := anonymiser.Anonymise("hostname", c.variables.Get("hostname"))
@@ -50,7 +65,20 @@ func (c Config) MySQLVersion() string {
 
 // Uptime returns the time that MySQL has been up (in seconds)
 func (c Config) Uptime() int {
-	return c.status.Get("Uptime")
+	if c.status == nil {
+		return 0 // not known, e.g. when replaying recorded data
+	}
+	uptime, err := c.status.Get("Uptime")
+	if err != nil {
+		log.Println("Config.Uptime():", err)
+		return 0 // not known
+	}
+	return uptime
+}
+
+// Status returns a pointer to global.Status
+func (c Config) Status() *global.Status {
+	return c.status
 }
 
 // Variables returns a pointer to global.Variables
@@ -58,6 +86,11 @@ func (c Config) Variables() *global.Variables {
 	return c.variables
 }
 
+// SetVariables replaces the global variables, e.g. after reconnecting to the server
+func (c *Config) SetVariables(variables *global.Variables) {
+	c.variables = variables
+}
+
 // SetWantRelativeStats tells what we want to see
 func (c *Config) SetWantRelativeStats(w bool) {
 	c.wantRelativeStats = w
@@ -67,3 +100,24 @@ func (c *Config) SetWantRelativeStats(w bool) {
 func (c Config) WantRelativeStats() bool {
 	return c.wantRelativeStats
 }
+
+// SetSmoothIntervals sets the number of intervals rates are averaged over, 0 disabling smoothing
+func (c *Config) SetSmoothIntervals(intervals int) {
+	c.smoothIntervals = intervals
+	c.wantSmoothedRates = intervals > 0
+}
+
+// SmoothIntervals returns the number of intervals rates are averaged over
+func (c Config) SmoothIntervals() int {
+	return c.smoothIntervals
+}
+
+// SetWantSmoothedRates tells us whether we want to see smoothed or raw rates
+func (c *Config) SetWantSmoothedRates(w bool) {
+	c.wantSmoothedRates = w && c.smoothIntervals > 0
+}
+
+// WantSmoothedRates tells us if we want to see smoothed rates
+func (c Config) WantSmoothedRates() bool {
+	return c.wantSmoothedRates
+}
@@ -5,7 +5,10 @@ package connector
 import (
 	"database/sql"
 	"log"
+	"net"
+	"os"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/sjmudd/mysql_defaults_file"
 	"github.com/sjmudd/ps-top/mylog"
 )
@@ -29,6 +32,7 @@ const (
 type Connector struct {
 	method ConnectMethod
 	config mysql_defaults_file.Config
+	tls    TLSOptions
 	DB     *sql.DB
 }
 
@@ -42,18 +46,23 @@ func (c *Connector) SetConnectBy(method ConnectMethod) {
 	c.method = method
 }
 
+// SetTLS records the TLS settings to connect with
+func (c *Connector) SetTLS(options TLSOptions) {
+	c.tls = options
+}
+
 // Connect makes a connection to the database using the previously defined settings
 func (c *Connector) Connect() {
-	var err error
+	var dsn string
 
 	switch {
 	case c.method == ConnectByConfig:
 		log.Println("ConnectByConfig() Connecting...")
-		c.DB, err = sql.Open(sqlDriver, mysql_defaults_file.BuildDSN(c.config, db))
+		dsn = mysql_defaults_file.BuildDSN(c.config, db)
 
 	case c.method == ConnectByDefaultsFile:
 		log.Println("ConnectByDefaults_file() Connecting...")
-		c.DB, err = mysql_defaults_file.Open(c.config.Filename, db)
+		dsn = mysql_defaults_file.BuildDSN(c.config, db)
 
 	case c.method == ConnectByEnvironment:
 		/*********************************************************************************
@@ -65,16 +74,21 @@ func (c *Connector) Connect() {
 		 *  2.12, “Environment Variables”.                                               *
 		 *********************************************************************************/
 		log.Println("ConnectByEnvironment() Connecting...")
-		c.DB, err = mysql_defaults_file.OpenUsingEnvironment(sqlDriver)
+		if dsn = os.Getenv("MYSQL_DSN"); dsn == "" {
+			mylog.Fatal("MYSQL_DSN not set or empty")
+		}
 
 	default:
 		mylog.Fatal("Connector.Connect() c.method not ConnectByDefaultsFile/ConnectByConfig/ConnectByEnvironment")
 	}
 
-	// we catch Open...() errors here
+	param, err := TLSParam(c.tls, c.tlsHost(dsn))
 	if err != nil {
 		mylog.Fatal(err)
 	}
+	if c.DB, err = sql.Open(sqlDriver, addTLSParam(dsn, param)); err != nil {
+		mylog.Fatal(err)
+	}
 
 	// without calling Ping() we don't actually connect.
 	if err = c.DB.Ping(); err != nil {
@@ -85,6 +99,22 @@ func (c *Connector) Connect() {
 	c.DB.SetMaxOpenConns(maxOpenConns)
 }
 
+// tlsHost returns the name of the host in the dsn, verified with VERIFY_IDENTITY
+func (c *Connector) tlsHost(dsn string) string {
+	if c.method != ConnectByEnvironment {
+		return c.config.Host
+	}
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil || config.Net != "tcp" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(config.Addr)
+	if err != nil {
+		return config.Addr
+	}
+	return host
+}
+
 // ConnectByConfig connects to MySQL using various configuration settings
 // needed to create the DSN.
 func (c *Connector) ConnectByConfig(config mysql_defaults_file.Config) {
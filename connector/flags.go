@@ -3,7 +3,10 @@ package connector
 import (
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/sjmudd/mysql_defaults_file"
 	"github.com/sjmudd/ps-top/lib"
@@ -17,60 +20,129 @@ type Config struct {
 	User           *string // the user to connect with
 	Password       *string // the password to use
 	DefaultsFile   *string // name of the defaults file to use
+	LoginPath      *string // name of the login path in ~/.mylogin.cnf to use
 	UseEnvironment *bool   // use the environment to set connection settings?
+	SSLMode        *string // the TLS mode, see TLSOptions
+	SSLCA          *string // file of the certificate authorities to trust
+	SSLCert        *string // file of the client certificate
+	SSLKey         *string // file of the client key
+}
+
+// tlsOptions returns the TLS settings given in the flags
+func (flags Config) tlsOptions() TLSOptions {
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return TLSOptions{
+		Mode: value(flags.SSLMode),
+		CA:   value(flags.SSLCA),
+		Cert: value(flags.SSLCert),
+		Key:  value(flags.SSLKey),
+	}
+}
+
+// Servers returns a Config for each of the comma-separated hosts given in
+// flags, each optionally followed by :port to use instead of flags.Port.
+func (flags Config) Servers() ([]Config, error) {
+	if flags.Host == nil || *flags.Host == "" {
+		return []Config{flags}, nil
+	}
+
+	var servers []Config
+	for _, host := range strings.Split(*flags.Host, ",") {
+		host := strings.TrimSpace(host)
+		port := 0
+		if flags.Port != nil {
+			port = *flags.Port
+		}
+		if name, value, err := net.SplitHostPort(host); err == nil {
+			if port, err = strconv.Atoi(value); err != nil || port <= 0 {
+				return nil, fmt.Errorf("invalid port in --host %q", host)
+			}
+			host = name
+		}
+		if host == "" {
+			return nil, fmt.Errorf("empty host name in --host %q", *flags.Host)
+		}
+		server := flags
+		server.Host = &host
+		server.Port = &port
+		servers = append(servers, server)
+	}
+
+	return servers, nil
 }
 
 // NewConnector returns a connected Connector given the provided flags
 func NewConnector(flags Config) *Connector {
 	var defaultsFile string
 	connector := new(Connector)
+	connector.SetTLS(flags.tlsOptions())
 
-	if *flags.UseEnvironment {
+	switch {
+	case *flags.UseEnvironment:
 		connector.ConnectByEnvironment()
-	} else {
-		if *flags.Host != "" || *flags.Socket != "" {
-			log.Println("--host= or --socket= defined")
-			var config mysql_defaults_file.Config
-			if *flags.Host != "" && *flags.Socket != "" {
-				fmt.Println(lib.ProgName + ": Do not specify --host and --socket together")
-				os.Exit(1)
-			}
-			if *flags.Host != "" {
-				config.Host = *flags.Host
-			}
-			if *flags.Port != 0 {
-				if *flags.Socket == "" {
-					config.Port = uint16(*flags.Port)
-				} else {
-					fmt.Println(lib.ProgName + ": Do not specify --socket and --port together")
-					os.Exit(1)
-				}
-			}
-			if *flags.Socket != "" {
-				config.Socket = *flags.Socket
-			}
-			if *flags.User != "" {
-				config.User = *flags.User
-			}
-			if *flags.Password != "" {
-				config.Password = *flags.Password
-			}
-			connector.ConnectByConfig(config)
+	case flags.LoginPath != nil && *flags.LoginPath != "":
+		log.Println("--login-path defined")
+		config, err := ReadLoginPath(LoginFilename(), *flags.LoginPath)
+		if err != nil {
+			fmt.Println(lib.ProgName + ": " + err.Error())
+			os.Exit(1)
+		}
+		connector.ConnectByConfig(applyFlags(config, flags))
+	case *flags.Host != "" || *flags.Socket != "":
+		log.Println("--host= or --socket= defined")
+		connector.ConnectByConfig(applyFlags(mysql_defaults_file.Config{}, flags))
+	default:
+		// no host or socket provided so assume connecting by a defaults file.
+		// - if an explicit defaults-file is provided use that.
+		// - if no explicit defaults-file is provided
+		//   we expect to IMPLICITLY use the default
+		//   defaults-file, e.g. ~/.my.cnf.
+		if flags.DefaultsFile != nil && *flags.DefaultsFile != "" {
+			log.Println("--defaults-file defined")
+			defaultsFile = *flags.DefaultsFile
 		} else {
-			// no host or socket provided so assume connecting by a defaults file.
-			// - if an explicit defaults-file is provided use that.
-			// - if no explicit defaults-file is provided
-			//   we expect to IMPLICITLY use the default
-			//   defaults-file, e.g. ~/.my.cnf.
-			if flags.DefaultsFile != nil && *flags.DefaultsFile != "" {
-				log.Println("--defaults-file defined")
-				defaultsFile = *flags.DefaultsFile
-			} else {
-				log.Println("connecting by implicit defaults file")
-			}
-			connector.ConnectByDefaultsFile(defaultsFile)
+			log.Println("connecting by implicit defaults file")
 		}
+		connector.ConnectByDefaultsFile(defaultsFile)
 	}
 
 	return connector
 }
+
+// applyFlags returns config with the connection settings given in the flags
+// taking precedence, e.g. over those of a login path
+func applyFlags(config mysql_defaults_file.Config, flags Config) mysql_defaults_file.Config {
+	if *flags.Host != "" && *flags.Socket != "" {
+		fmt.Println(lib.ProgName + ": Do not specify --host and --socket together")
+		os.Exit(1)
+	}
+	if *flags.Host != "" {
+		config.Host = *flags.Host
+		config.Socket = ""
+	}
+	if *flags.Port != 0 {
+		if *flags.Socket == "" {
+			config.Port = uint16(*flags.Port)
+		} else {
+			fmt.Println(lib.ProgName + ": Do not specify --socket and --port together")
+			os.Exit(1)
+		}
+	}
+	if *flags.Socket != "" {
+		config.Socket = *flags.Socket
+		config.Host = ""
+		config.Port = 0
+	}
+	if *flags.User != "" {
+		config.User = *flags.User
+	}
+	if *flags.Password != "" {
+		config.Password = *flags.Password
+	}
+	return config
+}
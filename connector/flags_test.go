@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestServers(t *testing.T) {
+	tests := []struct {
+		host  string
+		port  int
+		hosts []string
+		ports []int
+		err   bool
+	}{
+		{"", 0, []string{""}, []int{0}, false},
+		{"db1", 3307, []string{"db1"}, []int{3307}, false},
+		{"db1:3308", 0, []string{"db1"}, []int{3308}, false},
+		{"db1, db2:3308,db3", 3307, []string{"db1", "db2", "db3"}, []int{3307, 3308, 3307}, false},
+		{"[::1]:3308,db2", 0, []string{"::1", "db2"}, []int{3308, 0}, false},
+		{"db1,,db2", 0, nil, nil, true},
+		{"db1:port", 0, nil, nil, true},
+	}
+
+	for _, test := range tests {
+		flags := newConfig()
+		host, port := test.host, test.port
+		flags.Host, flags.Port = &host, &port
+
+		got, err := flags.Servers()
+		if (err != nil) != test.err {
+			t.Errorf("Servers(%q) failed: expected error: %v, got %v", test.host, test.err, err)
+			continue
+		}
+		if len(got) != len(test.hosts) {
+			t.Errorf("Servers(%q) failed: expected %d servers, got %d", test.host, len(test.hosts), len(got))
+			continue
+		}
+		for i := range got {
+			if *got[i].Host != test.hosts[i] || *got[i].Port != test.ports[i] {
+				t.Errorf("Servers(%q)[%d] failed: expected %q/%d, got %q/%d", test.host, i, test.hosts[i], test.ports[i], *got[i].Host, *got[i].Port)
+			}
+		}
+	}
+}
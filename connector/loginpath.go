@@ -0,0 +1,130 @@
+package connector
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sjmudd/mysql_defaults_file"
+	go_ini "github.com/vaughan0/go-ini"
+)
+
+const (
+	loginFileEnv   = "MYSQL_TEST_LOGIN_FILE" // overrides the login path file, as for the mysql client
+	loginSection   = "client"                // read before the named login path
+	loginKeyOffset = 4                       // the key follows 4 unused bytes
+	loginKeyLength = 20                      // length of the key stored in the file
+	loginLenLength = 4                       // length of the size prefixing each encrypted line
+)
+
+// LoginFilename returns the name of the login path file written by
+// mysql_config_editor, $MYSQL_TEST_LOGIN_FILE or ~/.mylogin.cnf
+func LoginFilename() string {
+	if filename := os.Getenv(loginFileEnv); filename != "" {
+		return filename
+	}
+	return filepath.Join(os.Getenv("HOME"), ".mylogin.cnf")
+}
+
+// ReadLoginPath returns the connection settings of the named login path in
+// filename. As with the mysql client the [client] section is read first and
+// the settings of the login path take precedence.
+func ReadLoginPath(filename, name string) (mysql_defaults_file.Config, error) {
+	var config mysql_defaults_file.Config
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return config, err
+	}
+	plain, err := decryptLoginFile(data)
+	if err != nil {
+		return config, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+	file, err := go_ini.Load(bytes.NewReader(plain))
+	if err != nil {
+		return config, fmt.Errorf("unable to parse %s: %w", filename, err)
+	}
+	if _, found := file[name]; !found {
+		return config, fmt.Errorf("login path %q not found in %s", name, filename)
+	}
+
+	config.Filename = filename
+	for _, section := range []string{loginSection, name} {
+		for key, value := range file[section] {
+			value = unquote(strings.TrimSpace(value))
+			switch key {
+			case "host":
+				config.Host = value
+			case "socket":
+				config.Socket = value
+			case "port":
+				port, err := strconv.ParseUint(value, 10, 16)
+				if err != nil {
+					return config, fmt.Errorf("invalid port %q in login path %q: %v", value, section, err)
+				}
+				config.Port = uint16(port)
+			case "user":
+				config.User = value
+			case "password":
+				config.Password = value
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// decryptLoginFile returns the plain text of a login path file. After 4 unused
+// bytes the file holds a 20 byte key, folded into an AES-128 key, followed by
+// each line encrypted in ECB mode and prefixed by its little-endian length.
+func decryptLoginFile(data []byte) ([]byte, error) {
+	if len(data) < loginKeyOffset+loginKeyLength {
+		return nil, errors.New("login path file is too short")
+	}
+	key := make([]byte, aes.BlockSize)
+	for i, b := range data[loginKeyOffset : loginKeyOffset+loginKeyLength] {
+		key[i%aes.BlockSize] ^= b
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain []byte
+	for data = data[loginKeyOffset+loginKeyLength:]; len(data) > 0; {
+		if len(data) < loginLenLength {
+			return nil, errors.New("login path file is truncated")
+		}
+		length := int(binary.LittleEndian.Uint32(data))
+		data = data[loginLenLength:]
+		if length == 0 || length%aes.BlockSize != 0 || length > len(data) {
+			return nil, errors.New("login path file is corrupt")
+		}
+		line := make([]byte, length)
+		for i := 0; i < length; i += aes.BlockSize {
+			block.Decrypt(line[i:], data[i:])
+		}
+		padding := int(line[length-1]) // PKCS#7
+		if padding == 0 || padding > aes.BlockSize {
+			return nil, errors.New("login path file is corrupt")
+		}
+		plain = append(plain, line[:length-padding]...)
+		data = data[length:]
+	}
+
+	return plain, nil
+}
+
+// unquote removes the quotes mysql_config_editor writes around values
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package connector
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// encryptLoginFile returns the lines encrypted as mysql_config_editor writes them
+func encryptLoginFile(t *testing.T, lines []string) []byte {
+	t.Helper()
+	key := []byte("0123456789abcdefghij")
+	folded := make([]byte, aes.BlockSize)
+	for i, b := range key {
+		folded[i%aes.BlockSize] ^= b
+	}
+	block, err := aes.NewCipher(folded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := append(make([]byte, loginKeyOffset), key...)
+	for _, line := range lines {
+		padding := aes.BlockSize - len(line)%aes.BlockSize
+		plain := append([]byte(line), bytes.Repeat([]byte{byte(padding)}, padding)...)
+		encrypted := make([]byte, len(plain))
+		for i := 0; i < len(plain); i += aes.BlockSize {
+			block.Encrypt(encrypted[i:], plain[i:])
+		}
+		length := make([]byte, loginLenLength)
+		binary.LittleEndian.PutUint32(length, uint32(len(encrypted)))
+		data = append(append(data, length...), encrypted...)
+	}
+	return data
+}
+
+func TestReadLoginPath(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".mylogin.cnf")
+	data := encryptLoginFile(t, []string{
+		"[client]\n",
+		"user = \"root\"\n",
+		"port = 3307\n",
+		"[monitor]\n",
+		"user = \"monitor\"\n",
+		"password = \"s3cr=t\"\n",
+		"host = \"db1.example.com\"\n",
+	})
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		host     string
+		port     uint16
+		err      bool
+	}{
+		{"client", "root", "", "", 3307, false},
+		{"monitor", "monitor", "s3cr=t", "db1.example.com", 3307, false},
+		{"missing", "", "", "", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := ReadLoginPath(filename, test.name)
+		if (err != nil) != test.err {
+			t.Errorf("ReadLoginPath(%q) failed: expected error: %v, got %v", test.name, test.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.User != test.user || got.Password != test.password || got.Host != test.host || got.Port != test.port {
+			t.Errorf("ReadLoginPath(%q) failed: expected: %q/%q/%q/%d, got %q/%q/%q/%d",
+				test.name, test.user, test.password, test.host, test.port, got.User, got.Password, got.Host, got.Port)
+		}
+	}
+}
+
+func TestDecryptLoginFileCorrupt(t *testing.T) {
+	good := encryptLoginFile(t, []string{"[client]\n"})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"too short", good[:10]},
+		{"truncated length", good[:loginKeyOffset+loginKeyLength+2]},
+		{"truncated line", good[:len(good)-1]},
+	}
+
+	for _, test := range tests {
+		if _, err := decryptLoginFile(test.data); err == nil {
+			t.Errorf("decryptLoginFile(%s) failed: expected an error", test.name)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package connector
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ApplyProfile returns a copy of flags where any setting not given on the
+// command line is taken from the profile. Passwords are not accepted in a
+// profile: use a defaults-file, --use-environment or --askpass instead.
+func ApplyProfile(flags Config, profile map[string]string) (Config, error) {
+	for key, value := range profile {
+		value := value // a new variable each time as we keep its address
+		switch key {
+		case "host":
+			if *flags.Host == "" {
+				flags.Host = &value
+			}
+		case "socket":
+			if *flags.Socket == "" {
+				flags.Socket = &value
+			}
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return flags, fmt.Errorf("invalid port %q in profile: %v", value, err)
+			}
+			if *flags.Port == 0 {
+				flags.Port = &port
+			}
+		case "user":
+			if *flags.User == "" {
+				flags.User = &value
+			}
+		case "defaults-file":
+			if *flags.DefaultsFile == "" {
+				flags.DefaultsFile = &value
+			}
+		case "use-environment":
+			useEnvironment, err := strconv.ParseBool(value)
+			if err != nil {
+				return flags, fmt.Errorf("invalid use-environment %q in profile: %v", value, err)
+			}
+			if !*flags.UseEnvironment {
+				flags.UseEnvironment = &useEnvironment
+			}
+		case "login-path":
+			setIfEmpty(&flags.LoginPath, &value)
+		case "ssl-mode":
+			setIfEmpty(&flags.SSLMode, &value)
+		case "ssl-ca":
+			setIfEmpty(&flags.SSLCA, &value)
+		case "ssl-cert":
+			setIfEmpty(&flags.SSLCert, &value)
+		case "ssl-key":
+			setIfEmpty(&flags.SSLKey, &value)
+		case "password":
+			return flags, fmt.Errorf("passwords must not be stored in a profile, use defaults-file, use-environment or --askpass instead")
+		default:
+			return flags, fmt.Errorf("unknown setting %q in profile", key)
+		}
+	}
+
+	return flags, nil
+}
+
+// setIfEmpty points flag at value if the flag was not given
+func setIfEmpty(flag **string, value *string) {
+	if *flag == nil || **flag == "" {
+		*flag = value
+	}
+}
@@ -0,0 +1,84 @@
+package connector
+
+import (
+	"testing"
+)
+
+// newConfig returns a Config as if no command line flags were given
+func newConfig() Config {
+	var host, socket, user, password, defaultsFile string
+	var port int
+	var useEnvironment bool
+
+	return Config{
+		Host:           &host,
+		Socket:         &socket,
+		Port:           &port,
+		User:           &user,
+		Password:       &password,
+		DefaultsFile:   &defaultsFile,
+		UseEnvironment: &useEnvironment,
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	flagHost := "flaghost"
+	withHost := newConfig()
+	withHost.Host = &flagHost
+
+	tests := []struct {
+		flags   Config
+		profile map[string]string
+		host    string
+		port    int
+		user    string
+		err     bool
+	}{
+		{newConfig(), nil, "", 0, "", false},
+		{newConfig(), map[string]string{"host": "prod-a", "port": "3307", "user": "monitor"}, "prod-a", 3307, "monitor", false},
+		{withHost, map[string]string{"host": "prod-a", "user": "monitor"}, "flaghost", 0, "monitor", false}, // flags take precedence
+		{newConfig(), map[string]string{"port": "notanumber"}, "", 0, "", true},
+		{newConfig(), map[string]string{"password": "secret"}, "", 0, "", true},
+		{newConfig(), map[string]string{"unknown": "value"}, "", 0, "", true},
+	}
+
+	for _, test := range tests {
+		got, err := ApplyProfile(test.flags, test.profile)
+		if (err != nil) != test.err {
+			t.Errorf("ApplyProfile(%v) failed: expected error: %v, got %v", test.profile, test.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if *got.Host != test.host || *got.Port != test.port || *got.User != test.user {
+			t.Errorf("ApplyProfile(%v) failed: expected: %q/%d/%q, got %q/%d/%q",
+				test.profile, test.host, test.port, test.user, *got.Host, *got.Port, *got.User)
+		}
+	}
+}
+
+func TestApplyProfileTLS(t *testing.T) {
+	flagMode := TLSVerifyIdentity
+	withMode := newConfig()
+	withMode.SSLMode = &flagMode
+
+	tests := []struct {
+		flags Config
+		mode  string
+	}{
+		{newConfig(), TLSRequired}, // the ssl flags may be unset
+		{withMode, TLSVerifyIdentity},
+	}
+
+	for _, test := range tests {
+		got, err := ApplyProfile(test.flags, map[string]string{"ssl-mode": TLSRequired, "ssl-ca": "/etc/ca.pem"})
+		if err != nil {
+			t.Errorf("ApplyProfile() failed: %v", err)
+			continue
+		}
+		if options := got.tlsOptions(); options.Mode != test.mode || options.CA != "/etc/ca.pem" {
+			t.Errorf("ApplyProfile() failed: expected mode %q and CA /etc/ca.pem, got %+v", test.mode, options)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package connector
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// TLS modes, named as by the mysql client's --ssl-mode
+const (
+	TLSDisabled       = "DISABLED"        // do not use TLS
+	TLSPreferred      = "PREFERRED"       // use TLS if the server supports it
+	TLSRequired       = "REQUIRED"        // fail if the server does not support TLS
+	TLSVerifyCA       = "VERIFY_CA"       // as REQUIRED, also verifying the server certificate
+	TLSVerifyIdentity = "VERIFY_IDENTITY" // as VERIFY_CA, also verifying the host name
+)
+
+// tlsConfigName is the name the TLS configuration is registered with the driver as
+const tlsConfigName = "ps-top"
+
+// TLSOptions holds the TLS settings for connecting to the server
+type TLSOptions struct {
+	Mode string // one of the TLS* modes, empty for the driver's default of no TLS
+	CA   string // file of the PEM certificates of the certificate authorities to trust
+	Cert string // file of the PEM client certificate
+	Key  string // file of the PEM client key
+}
+
+// mode returns the upper-cased mode. As with the mysql client a
+// CA without a mode means VERIFY_CA, and REQUIRED means VERIFY_CA if a CA is given.
+func (o TLSOptions) mode() string {
+	mode := strings.ToUpper(o.Mode)
+	if o.CA != "" && (mode == "" || mode == TLSRequired) {
+		return TLSVerifyCA
+	}
+	return mode
+}
+
+// TLSParam returns the value of the tls DSN parameter for the options, registering
+// a TLS configuration with the driver if needed. host is the name of the server
+// verified with VERIFY_IDENTITY. An empty value means no tls parameter is needed.
+func TLSParam(options TLSOptions, host string) (string, error) {
+	switch options.mode() {
+	case "":
+		if options.Cert != "" || options.Key != "" {
+			return "", errors.New("--ssl-cert and --ssl-key need --ssl-mode=REQUIRED or stronger")
+		}
+		return "", nil
+	case TLSDisabled:
+		return "false", nil
+	case TLSPreferred:
+		if options.Cert != "" || options.Key != "" {
+			return "", errors.New("--ssl-cert and --ssl-key need --ssl-mode=REQUIRED or stronger")
+		}
+		return "preferred", nil
+	}
+
+	config, err := buildTLSConfig(options, host)
+	if err != nil {
+		return "", err
+	}
+	if err := mysql.RegisterTLSConfig(tlsConfigName, config); err != nil {
+		return "", err
+	}
+	return tlsConfigName, nil
+}
+
+// buildTLSConfig returns the TLS configuration for the REQUIRED, VERIFY_CA and VERIFY_IDENTITY modes.
+// The driver fails to connect with it if the server does not support TLS.
+func buildTLSConfig(options TLSOptions, host string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	var roots *x509.CertPool // the system's pool if no CA is given
+	if options.CA != "" {
+		pem, err := os.ReadFile(options.CA)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read --ssl-ca: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in --ssl-ca %s", options.CA)
+		}
+	}
+
+	if (options.Cert == "") != (options.Key == "") {
+		return nil, errors.New("--ssl-cert and --ssl-key must be given together")
+	}
+	if options.Cert != "" {
+		certificate, err := tls.LoadX509KeyPair(options.Cert, options.Key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load --ssl-cert and --ssl-key: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	switch mode := options.mode(); mode {
+	case TLSRequired:
+		config.InsecureSkipVerify = true // encrypted but not verified, as the mysql client does
+	case TLSVerifyCA:
+		// tls only verifies the chain together with the host name, so do it ourselves
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = verifyChain(roots)
+	case TLSVerifyIdentity:
+		if host == "" {
+			return nil, errors.New("--ssl-mode=VERIFY_IDENTITY needs a --host to verify")
+		}
+		config.RootCAs = roots
+		config.ServerName = host
+	default:
+		return nil, fmt.Errorf("unknown --ssl-mode %q, expected one of %s, %s, %s, %s or %s", options.Mode, TLSDisabled, TLSPreferred, TLSRequired, TLSVerifyCA, TLSVerifyIdentity)
+	}
+
+	return config, nil
+}
+
+// verifyChain returns a function checking the server's certificate is signed by one of roots
+// without checking the host name
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("the server sent no certificate")
+		}
+		intermediates := x509.NewCertPool()
+		var leaf *x509.Certificate
+		for i, raw := range rawCerts {
+			certificate, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			if i == 0 {
+				leaf = certificate
+			} else {
+				intermediates.AddCert(certificate)
+			}
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+}
+
+// addTLSParam returns the dsn with the tls parameter added if value is not empty
+func addTLSParam(dsn, value string) string {
+	if value == "" {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&tls=" + value
+	}
+	return dsn + "?tls=" + value
+}
@@ -0,0 +1,217 @@
+package connector
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCertificate is a generated certificate and its key
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCertificate returns a certificate for name signed by parent, or self-signed if parent is nil
+func newTestCertificate(t *testing.T, name string, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCertificate{cert: cert, key: key, der: der}
+}
+
+// writePEM writes the certificate, and its key if key is given, returning the file names
+func (c *testCertificate) writePEM(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	certFile := filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSParam(t *testing.T) {
+	dir := t.TempDir()
+	caFile, _ := newTestCertificate(t, "ca", nil).writePEM(t, dir, "ca")
+
+	tests := []struct {
+		options TLSOptions
+		host    string
+		param   string
+		err     bool
+	}{
+		{TLSOptions{}, "", "", false},
+		{TLSOptions{Mode: TLSDisabled}, "", "false", false},
+		{TLSOptions{Mode: "preferred"}, "", "preferred", false},
+		{TLSOptions{Mode: TLSRequired}, "", tlsConfigName, false},
+		{TLSOptions{CA: caFile}, "", tlsConfigName, false},
+		{TLSOptions{Mode: TLSVerifyIdentity, CA: caFile}, "db.example.com", tlsConfigName, false},
+		{TLSOptions{Mode: TLSPreferred, Cert: "client.pem", Key: "client-key.pem"}, "", "", true},
+		{TLSOptions{Cert: "client.pem"}, "", "", true},
+		{TLSOptions{Mode: "sometimes"}, "", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := TLSParam(test.options, test.host)
+		if (err != nil) != test.err {
+			t.Errorf("TLSParam(%+v) failed: expected error: %v, got %v", test.options, test.err, err)
+			continue
+		}
+		if got != test.param {
+			t.Errorf("TLSParam(%+v) failed: expected %q, got %q", test.options, test.param, got)
+		}
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, "ca", nil)
+	caFile, _ := ca.writePEM(t, dir, "ca")
+	clientFile, clientKeyFile := newTestCertificate(t, "client", ca).writePEM(t, dir, "client")
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		options      TLSOptions
+		host         string
+		skipVerify   bool
+		verifyChain  bool
+		serverName   string
+		certificates int
+		err          bool
+	}{
+		{TLSOptions{Mode: TLSRequired}, "", true, false, "", 0, false},
+		{TLSOptions{Mode: TLSRequired, CA: caFile}, "", true, true, "", 0, false}, // a CA means VERIFY_CA
+		{TLSOptions{Mode: TLSVerifyCA, CA: caFile, Cert: clientFile, Key: clientKeyFile}, "", true, true, "", 1, false},
+		{TLSOptions{Mode: TLSVerifyIdentity, CA: caFile}, "db.example.com", false, false, "db.example.com", 0, false},
+		{TLSOptions{Mode: TLSVerifyIdentity}, "", false, false, "", 0, true}, // no host to verify
+		{TLSOptions{Mode: TLSVerifyCA, CA: filepath.Join(dir, "missing.pem")}, "", false, false, "", 0, true},
+		{TLSOptions{Mode: TLSVerifyCA, CA: notPEM}, "", false, false, "", 0, true},
+		{TLSOptions{Mode: TLSRequired, Key: clientKeyFile}, "", false, false, "", 0, true},
+		{TLSOptions{Mode: TLSRequired, Cert: clientFile, Key: caFile}, "", false, false, "", 0, true}, // mismatched key
+	}
+
+	for _, test := range tests {
+		got, err := buildTLSConfig(test.options, test.host)
+		if (err != nil) != test.err {
+			t.Errorf("buildTLSConfig(%+v) failed: expected error: %v, got %v", test.options, test.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.InsecureSkipVerify != test.skipVerify || (got.VerifyPeerCertificate != nil) != test.verifyChain ||
+			got.ServerName != test.serverName || len(got.Certificates) != test.certificates {
+			t.Errorf("buildTLSConfig(%+v) failed: expected skip verify: %v, verify chain: %v, server name: %q, %d certificates, got %v, %v, %q, %d",
+				test.options, test.skipVerify, test.verifyChain, test.serverName, test.certificates,
+				got.InsecureSkipVerify, got.VerifyPeerCertificate != nil, got.ServerName, len(got.Certificates))
+		}
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	ca := newTestCertificate(t, "ca", nil)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	verify := verifyChain(roots)
+
+	tests := []struct {
+		name  string
+		certs [][]byte
+		err   bool
+	}{
+		{"signed by the CA", [][]byte{newTestCertificate(t, "some-other-host", ca).der}, false}, // the host name is not checked
+		{"signed by another CA", [][]byte{newTestCertificate(t, "db", newTestCertificate(t, "other", nil)).der}, true},
+		{"no certificate", nil, true},
+		{"not a certificate", [][]byte{[]byte("garbage")}, true},
+	}
+
+	for _, test := range tests {
+		if err := verify(test.certs, nil); (err != nil) != test.err {
+			t.Errorf("verifyChain() %s failed: expected error: %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestAddTLSParam(t *testing.T) {
+	tests := []struct {
+		dsn   string
+		value string
+		want  string
+	}{
+		{"user@tcp(db:3306)/performance_schema", "", "user@tcp(db:3306)/performance_schema"},
+		{"user@tcp(db:3306)/performance_schema", "ps-top", "user@tcp(db:3306)/performance_schema?tls=ps-top"},
+		{"user@tcp(db:3306)/performance_schema?allowNativePasswords=true", "false", "user@tcp(db:3306)/performance_schema?allowNativePasswords=true&tls=false"},
+	}
+
+	for _, test := range tests {
+		if got := addTLSParam(test.dsn, test.value); got != test.want {
+			t.Errorf("addTLSParam(%q, %q) failed: expected %q, got %q", test.dsn, test.value, test.want, got)
+		}
+	}
+}
+
+func TestTLSHost(t *testing.T) {
+	tests := []struct {
+		connector Connector
+		dsn       string
+		want      string
+	}{
+		{Connector{method: ConnectByConfig}, "", ""},
+		{Connector{method: ConnectByEnvironment}, "user:pass@tcp(db.example.com:3306)/performance_schema", "db.example.com"},
+		{Connector{method: ConnectByEnvironment}, "user:pass@unix(/tmp/mysql.sock)/performance_schema", ""},
+	}
+	tests[0].connector.config.Host = "db.example.com"
+	tests[0].want = "db.example.com"
+
+	for _, test := range tests {
+		if got := test.connector.tlsHost(test.dsn); got != test.want {
+			t.Errorf("tlsHost(%q) failed: expected %q, got %q", test.dsn, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package diskspace provides the free space of the filesystem holding a local path.
+package diskspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sjmudd/ps-top/lib"
+)
+
+// barWidth is the width of the percentage used bar
+const barWidth = 10
+
+// Usage holds the size and free space of a filesystem in bytes
+type Usage struct {
+	Total uint64
+	Free  uint64 // space available to unprivileged users
+}
+
+// UsedPct returns the fraction of the filesystem used
+func (u Usage) UsedPct() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return 1 - lib.Divide(u.Free, u.Total)
+}
+
+// String returns the usage as a percentage used bar, e.g. "[#####     ]  52% used, 120.30 G free"
+func (u Usage) String() string {
+	free := strings.TrimSpace(lib.FormatAmount(u.Free))
+	if free == "" {
+		free = "0"
+	}
+	used := int(u.UsedPct()*barWidth + 0.5)
+	if used > barWidth {
+		used = barWidth
+	}
+	return fmt.Sprintf("[%s%s] %3.0f%% used, %s free",
+		strings.Repeat("#", used),
+		strings.Repeat(" ", barWidth-used),
+		u.UsedPct()*100,
+		free)
+}
+
+// Get returns the usage of the filesystem holding path
+func Get(path string) (Usage, error) {
+	return get(path)
+}
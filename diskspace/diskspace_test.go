@@ -0,0 +1,33 @@
+package diskspace
+
+import (
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		usage    Usage
+		expected string
+	}{
+		{Usage{}, "[          ]   0% used, 0 free"},
+		{Usage{Total: 1000, Free: 1000}, "[          ]   0% used, 1000 free"},
+		{Usage{Total: 1000, Free: 480}, "[#####     ]  52% used, 480 free"},
+		{Usage{Total: 1000, Free: 0}, "[##########] 100% used, 0 free"},
+		{Usage{Total: 4 * 1024 * 1024, Free: 1024 * 1024 * 3 / 2}, "[######    ]  62% used, 1.50 M free"},
+	}
+	for _, test := range tests {
+		if got := test.usage.String(); got != test.expected {
+			t.Errorf("Usage%+v.String() failed: expected: %q, got %q", test.usage, test.expected, got)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	usage, err := Get(".")
+	if err != nil {
+		t.Skipf("Get(\".\") not supported: %v", err)
+	}
+	if usage.Total == 0 || usage.Free > usage.Total {
+		t.Errorf("Get(\".\") returned unexpected usage: %+v", usage)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package diskspace
+
+import (
+	"syscall"
+)
+
+// get returns the usage of the filesystem holding path using statfs(2)
+func get(path string) (Usage, error) {
+	var fs syscall.Statfs_t
+
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return Usage{}, err
+	}
+
+	return Usage{
+		Total: uint64(fs.Blocks) * uint64(fs.Bsize),
+		Free:  uint64(fs.Bavail) * uint64(fs.Bsize),
+	}, nil
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package diskspace
+
+import (
+	"errors"
+)
+
+// get is not supported on windows
+func get(path string) (Usage, error) {
+	return Usage{}, errors.New("disk space is not supported on windows")
+}
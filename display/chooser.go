@@ -0,0 +1,146 @@
+package display
+
+import (
+	"github.com/gdamore/tcell/termbox"
+
+	"github.com/sjmudd/ps-top/event"
+)
+
+// columnChooser holds the state of the screen choosing the columns of a view
+type columnChooser struct {
+	shown    bool
+	view     string // the view whose columns are chosen
+	columns  layout // the columns of the view being chosen
+	original string // the layout when the chooser was shown
+	selected int    // the column selected
+}
+
+// handleChooserKey converts the keys pressed while choosing the columns to app
+// events, returning whether the chooser is still active
+func handleChooserKey(ch rune, key termbox.Key) (event.Event, bool) {
+	switch ch {
+	case 'j':
+		return event.Event{Type: event.EventSelectDown}, true
+	case 'k':
+		return event.Event{Type: event.EventSelectUp}, true
+	case 'J':
+		return event.Event{Type: event.EventColumnMoveDown}, true
+	case 'K':
+		return event.Event{Type: event.EventColumnMoveUp}, true
+	case 'd':
+		return event.Event{Type: event.EventColumnDefault}, true
+	case 'c':
+		return event.Event{Type: event.EventColumnChooser}, false
+	}
+	switch key {
+	case termbox.KeyArrowDown:
+		return event.Event{Type: event.EventSelectDown}, true
+	case termbox.KeyArrowUp:
+		return event.Event{Type: event.EventSelectUp}, true
+	case termbox.KeySpace:
+		return event.Event{Type: event.EventColumnToggle}, true
+	case termbox.KeyEnter, termbox.KeyEsc, termbox.KeyCtrlC:
+		return event.Event{Type: event.EventColumnChooser}, false
+	}
+	return event.Event{Type: event.EventUnknown}, true
+}
+
+// SetLayouts sets the saved column layouts of the views, by view name
+func (display *Display) SetLayouts(saved map[string]string) {
+	display.layouts = make(map[string]layout)
+	for view, columns := range saved {
+		display.layouts[view] = parseLayout(columns)
+	}
+}
+
+// SetView sets the name of the view shown, whose column layout is used
+func (display *Display) SetView(name string) {
+	display.view = name
+}
+
+// ColumnChooserShown returns whether the column chooser is shown
+func (display *Display) ColumnChooserShown() bool {
+	return display.chooser.shown
+}
+
+// ShowColumnChooser shows the columns of the current view, which has the given headings, to be chosen
+func (display *Display) ShowColumnChooser(headings string) {
+	columns := display.layouts[display.view].resolve(columnNames(headings))
+	display.chooser = columnChooser{
+		shown:    true,
+		view:     display.view,
+		columns:  columns,
+		original: columns.String(),
+	}
+}
+
+// HideColumnChooser stops showing the column chooser, using the columns
+// chosen. It returns the view and its layout to save, empty for the default
+// layout, and whether the layout was changed.
+func (display *Display) HideColumnChooser() (string, string, bool) {
+	chooser := display.chooser
+	display.chooser = columnChooser{}
+	if chooser.columns.String() == chooser.original {
+		return chooser.view, chooser.columns.String(), false
+	}
+
+	display.ResetColumnWidths()
+	if chooser.columns.isDefault() {
+		delete(display.layouts, chooser.view)
+		return chooser.view, "", true
+	}
+	if display.layouts == nil {
+		display.layouts = make(map[string]layout)
+	}
+	display.layouts[chooser.view] = chooser.columns
+	return chooser.view, chooser.columns.String(), true
+}
+
+// ToggleColumn shows or hides the selected column of the column chooser
+func (display *Display) ToggleColumn() {
+	if i := display.chooser.selected; i < len(display.chooser.columns) {
+		display.chooser.columns[i].hidden = !display.chooser.columns[i].hidden
+	}
+}
+
+// MoveColumn moves the selected column of the column chooser offset places, keeping it selected
+func (display *Display) MoveColumn(offset int) {
+	columns := display.chooser.columns
+	i, j := display.chooser.selected, display.chooser.selected+offset
+	if i >= len(columns) || j < 0 || j >= len(columns) {
+		return
+	}
+	columns[i], columns[j] = columns[j], columns[i]
+	display.chooser.selected = j
+}
+
+// DefaultColumns shows all the columns of the column chooser in the view's own order
+func (display *Display) DefaultColumns() {
+	names := make([]string, len(display.chooser.columns))
+	for _, column := range display.chooser.columns {
+		names[column.index] = column.name
+	}
+	display.chooser.columns = layout(nil).resolve(names)
+}
+
+// DisplayColumnChooser shows the columns of the view to be chosen
+func (display *Display) DisplayColumnChooser() {
+	title := "Columns of " + display.chooser.view + " (the name is always shown last)"
+	display.screen.InvertedPrintAt(0, 0, title)
+	display.screen.ClearLine(len(title), 0)
+	display.screen.PrintAt(0, 1, "j/k or <down>/<up> - select  <space> - show / hide  J/K - move down / up")
+	display.screen.PrintAt(0, 2, "d - show all in the default order  c or <enter> - use and save the columns")
+
+	for i, column := range display.chooser.columns {
+		line := "[x] " + column.name
+		if column.hidden {
+			line = "[ ] " + column.name
+		}
+		if i == display.chooser.selected {
+			display.screen.HighlightPrintAt(0, 4+i, line)
+		} else {
+			display.screen.PrintAt(0, 4+i, line)
+		}
+		display.screen.ClearLine(len(line), 4+i)
+	}
+}
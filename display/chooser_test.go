@@ -0,0 +1,49 @@
+package display
+
+import (
+	"testing"
+)
+
+func TestColumnChooser(t *testing.T) {
+	display := new(Display)
+	display.SetLayouts(map[string]string{"commands": "Ops, -%"})
+	display.SetView("table_io_latency")
+	headings := "Latency|Fetch|Insert|Table Name"
+
+	display.ShowColumnChooser(headings)
+	if _, _, changed := display.HideColumnChooser(); changed {
+		t.Errorf("HideColumnChooser() failed: expected no change")
+	}
+
+	display.ShowColumnChooser(headings)
+	display.SelectDown()
+	display.MoveColumn(1) // Fetch after Insert
+	display.ToggleColumn()
+	display.MoveColumn(1) // already last
+	display.SelectUp()
+	display.SelectUp()
+	display.SelectUp() // already first
+	display.ToggleColumn()
+	if !display.ColumnChooserShown() {
+		t.Errorf("ColumnChooserShown() failed: expected the chooser to be shown")
+	}
+	view, saved, changed := display.HideColumnChooser()
+	if view != "table_io_latency" || saved != "-Latency, Insert, -Fetch" || !changed {
+		t.Errorf("HideColumnChooser() failed: expected table_io_latency, %q, true, got %s, %q, %v", "-Latency, Insert, -Fetch", view, saved, changed)
+	}
+	if display.ColumnChooserShown() {
+		t.Errorf("ColumnChooserShown() failed: expected the chooser to be hidden")
+	}
+
+	display.ShowColumnChooser(headings)
+	display.DefaultColumns()
+	if _, saved, changed := display.HideColumnChooser(); saved != "" || !changed {
+		t.Errorf("HideColumnChooser() failed: expected the default layout to be saved as empty, got %q, %v", saved, changed)
+	}
+	if _, found := display.layouts["table_io_latency"]; found {
+		t.Errorf("HideColumnChooser() failed: expected the default layout to be forgotten")
+	}
+	if got := display.layouts["commands"].String(); got != "Ops, -%" {
+		t.Errorf("SetLayouts() failed: expected %q, got %q", "Ops, -%", got)
+	}
+}
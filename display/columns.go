@@ -0,0 +1,41 @@
+package display
+
+import (
+	"strings"
+)
+
+// columnSeparator separates the columns of the rows shown
+const columnSeparator = "|"
+
+// columnWidths holds the widths of each column of the rows shown, excluding the last
+// column which is the name of the row. Widths only grow so the layout stays stable.
+type columnWidths []int
+
+// update grows the widths to fit the first count columns of the given lines.
+// The rest of a line is the name, which may itself hold the separator.
+func (widths *columnWidths) update(count int, lines ...string) {
+	for _, line := range lines {
+		columns := strings.SplitN(line, columnSeparator, count+1)
+		for i := 0; i < len(columns)-1; i++ {
+			if i >= len(*widths) {
+				*widths = append(*widths, 0)
+			}
+			if len(columns[i]) > (*widths)[i] {
+				(*widths)[i] = len(columns[i])
+			}
+		}
+	}
+}
+
+// apply pads each of the first count columns of line to the frozen width.
+// Padding is added on the left as most columns are right aligned numbers.
+func (widths columnWidths) apply(line string, count int) string {
+	columns := strings.SplitN(line, columnSeparator, count+1)
+	for i := 0; i < len(columns)-1 && i < len(widths); i++ {
+		if len(columns[i]) < widths[i] {
+			columns[i] = strings.Repeat(" ", widths[i]-len(columns[i])) + columns[i]
+		}
+	}
+
+	return strings.Join(columns, columnSeparator)
+}
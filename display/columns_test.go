@@ -0,0 +1,37 @@
+package display
+
+import (
+	"testing"
+)
+
+func TestColumnWidths(t *testing.T) {
+	var widths columnWidths
+
+	widths.update(2, "  1|   2|name", "1000000|2|longer name", "", "1|2|SELECT a | b FROM t")
+	expected := []int{7, 4}
+	if len(widths) != len(expected) {
+		t.Fatalf("update() failed: expected: %v, got %v", expected, widths)
+	}
+	for i := range expected {
+		if widths[i] != expected[i] {
+			t.Errorf("update() failed: expected: %v, got %v", expected, widths)
+		}
+	}
+
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"", ""},
+		{"no columns", "no columns"},
+		{"  1|   2|name", "      1|   2|name"},
+		{"12345678|12345|name", "12345678|12345|name"},
+		{"1|2|3|name", "      1|   2|3|name"},
+		{"1|2|SELECT a | b FROM t", "      1|   2|SELECT a | b FROM t"},
+	}
+	for _, test := range tests {
+		if got := widths.apply(test.line, 2); got != test.expected {
+			t.Errorf("apply(%q) failed: expected: %q, got %q", test.line, test.expected, got)
+		}
+	}
+}
@@ -3,12 +3,15 @@ package display
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/termbox"
 
 	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/diskspace"
 	"github.com/sjmudd/ps-top/event"
+	"github.com/sjmudd/ps-top/global"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/screen"
 	"github.com/sjmudd/ps-top/version"
@@ -19,6 +22,28 @@ type Display struct {
 	cfg         *config.Config
 	screen      *screen.Screen
 	termboxChan chan termbox.Event
+	selected    int               // the selected row
+	rows        int               // the rows of data shown, not counting a row summarising the others
+	datadir     string            // the server's data directory if local, otherwise empty
+	freeze      bool              // keep the column widths stable
+	widths      columnWidths      // the frozen column widths
+	clockSkew   string            // description of the clock skew between the server and this host
+	gotoView    prompt            // state of the go to view prompt, only used by the event poller
+	promptShown bool              // is the go to view prompt shown?
+	promptText  string            // the text shown in the go to view prompt
+	filter      filterPrompt      // state of the filter prompt, only used by the event poller
+	filterShown bool              // is the filter prompt shown?
+	filterText  string            // the text shown in the filter prompt
+	errorText   string            // the last collection error shown instead of the menu, if any
+	reconnect   bool              // are we reconnecting to the server?
+	view        string            // the name of the view shown
+	layouts     map[string]layout // the column layouts chosen for the views, by view name
+	shown       []int             // the columns of the view last shown, to notice when they change
+	chooser     columnChooser     // state of the column chooser
+	choosing    bool              // is the column chooser active? Only used by the event poller
+	paused      bool              // is collecting the data paused?
+	pane        pane              // lines of text shown instead of the view, e.g. a query plan
+	paneActive  bool              // is the pane shown? Only used by the event poller
 }
 
 // NewDisplay returns a Display
@@ -32,6 +57,150 @@ func NewDisplay(cfg *config.Config) *Display {
 	return display
 }
 
+// SetConfig sets the config of the server shown, e.g. after switching servers
+func (display *Display) SetConfig(cfg *config.Config) {
+	display.cfg = cfg
+}
+
+// SetHighlight sets how the selected row is shown and whether to use colours
+func (display *Display) SetHighlight(highlight screen.Highlight, noColor bool) {
+	display.screen.SetHighlight(highlight)
+	display.screen.SetNoColor(noColor)
+}
+
+// SetDatadir sets the local path of the server's data directory. If empty its free space is shown as n/a.
+func (display *Display) SetDatadir(datadir string) {
+	display.datadir = datadir
+}
+
+// datadirSpace returns the usage of the filesystem holding the data directory or "n/a" if not known
+func (display *Display) datadirSpace() string {
+	if display.datadir == "" {
+		return "n/a"
+	}
+	usage, err := diskspace.Get(display.datadir)
+	if err != nil {
+		return "n/a"
+	}
+	return usage.String()
+}
+
+// SetClockSkew sets the clock skew between the server and this host shown on the help screen
+func (display *Display) SetClockSkew(skew string) {
+	display.clockSkew = skew
+}
+
+// SetFreezeColumns sets whether the column widths are kept stable across intervals
+func (display *Display) SetFreezeColumns(freeze bool) {
+	display.freeze = freeze
+	display.widths = nil
+}
+
+// ToggleFreezeColumns toggles keeping the column widths stable, starting from the current widths
+func (display *Display) ToggleFreezeColumns() {
+	display.SetFreezeColumns(!display.freeze)
+}
+
+// ResetColumnWidths forgets the frozen column widths, e.g. after changing view.
+func (display *Display) ResetColumnWidths() {
+	display.widths = nil
+}
+
+// SetViewNames sets the names of the views which may be chosen with the go to view prompt
+func (display *Display) SetViewNames(names []string) {
+	display.gotoView.names = names
+}
+
+// ShowPrompt shows the go to view prompt with the given text instead of the menu
+func (display *Display) ShowPrompt(text string) {
+	display.promptShown = true
+	display.promptText = text
+}
+
+// HidePrompt hides the go to view prompt, showing the menu again
+func (display *Display) HidePrompt() {
+	display.promptShown = false
+	display.promptText = ""
+}
+
+// SetFilter sets the filter the filter prompt starts editing.
+// It must be called before the events are polled.
+func (display *Display) SetFilter(filter string) {
+	display.filter.current = filter
+}
+
+// ShowFilterPrompt shows the filter prompt with the given text instead of the menu
+func (display *Display) ShowFilterPrompt(text string) {
+	display.filterShown = true
+	display.filterText = text
+}
+
+// HideFilterPrompt hides the filter prompt, showing the menu again
+func (display *Display) HideFilterPrompt() {
+	display.filterShown = false
+	display.filterText = ""
+}
+
+// SetError sets the collection error shown instead of the menu until the
+// next successful collection. An empty text removes it.
+func (display *Display) SetError(text string) {
+	display.errorText = text
+}
+
+// SetReconnecting sets whether we are reconnecting to the server after
+// losing the connection, shown with the error set by SetError
+func (display *Display) SetReconnecting(reconnecting bool) {
+	display.reconnect = reconnecting
+}
+
+// SetPaused sets whether collecting the data is paused, which is shown instead of the menu
+func (display *Display) SetPaused(paused bool) {
+	display.paused = paused
+}
+
+// SelectUp moves the selected row up
+func (display *Display) SelectUp() {
+	if display.pane.shown {
+		display.pane.scroll(-1)
+		return
+	}
+	if display.chooser.shown {
+		if display.chooser.selected > 0 {
+			display.chooser.selected--
+		}
+		return
+	}
+	if display.selected > 0 {
+		display.selected--
+	}
+}
+
+// SelectDown moves the selected row down. It is limited to the rows shown by Display.
+func (display *Display) SelectDown() {
+	if display.pane.shown {
+		display.pane.scroll(1)
+		return
+	}
+	if display.chooser.shown {
+		if display.chooser.selected < len(display.chooser.columns)-1 {
+			display.chooser.selected++
+		}
+		return
+	}
+	display.selected++
+}
+
+// ResetSelection selects the first row, e.g. after changing view.
+func (display *Display) ResetSelection() {
+	display.selected = 0
+}
+
+// Selected returns the index of the selected row and false if it is not a
+// row of data, e.g. the row summarising the rows which do not fit on the screen
+func (display *Display) Selected() (int, bool) {
+	return display.selected, display.selected < display.rows
+}
+
 // uptime returns cfg.uptime() protecting against nil pointers
 func (display *Display) uptime() int {
 	if display == nil || display.cfg == nil {
@@ -44,8 +213,60 @@ func (display *Display) uptime() int {
 func (display *Display) Display(t GenericData) {
 	heading := display.HeadingLine(t.HaveRelativeStats(), display.cfg.WantRelativeStats(), t.FirstCollectTime(), t.LastCollectTime())
 	description := t.Description()
+	if sorted, ok := t.(SortedData); ok && sorted.SortColumn() != "" {
+		description += " sorted by " + sorted.SortColumn()
+	}
 	headings := t.Headings()
 
+	maxRows := display.screen.Height() - 4
+	lastRow := display.screen.Height() - 2
+	bottomRow := display.screen.Height() - 1
+	content := t.RowContent()
+	total := t.TotalRowContent()
+	empty := t.EmptyRowContent()
+
+	// if there are too many rows to show summarise those which don't fit
+	// on the last available row so the rows shown add up to the totals.
+	display.rows = len(content)
+	if others, ok := t.(OthersData); ok {
+		available := lastRow - 3
+		if available > 0 && len(content) > available {
+			content = append(content[:available-1], others.OthersRowContent(available-1))
+			display.rows = available - 1
+		}
+	}
+
+	// show the columns chosen for the view, dropping those which do not fit on the screen
+	if names := columnNames(headings); len(names) > 0 {
+		width, _ := display.screen.Size()
+		shown := display.layouts[display.view].resolve(names).shown()
+		fitted := fit(strings.Split(headings, columnSeparator), shown, width)
+		if dropped := len(shown) - len(fitted); dropped > 0 {
+			description += fmt.Sprintf(" (%d columns hidden to fit)", dropped)
+		}
+		if !equalInts(fitted, display.shown) {
+			display.shown = fitted
+			display.widths = nil // the frozen widths are those of other columns
+		}
+		headings = arrange(headings, len(names), fitted)
+		total = arrange(total, len(names), fitted)
+		empty = arrange(empty, len(names), fitted)
+		for i := range content {
+			content[i] = arrange(content[i], len(names), fitted)
+		}
+	}
+
+	if display.freeze {
+		count := strings.Count(headings, columnSeparator) // the headings have no separator in the name
+		display.widths.update(count, append([]string{headings, total}, content...)...)
+		headings = display.widths.apply(headings, count)
+		total = display.widths.apply(total, count)
+		empty = display.widths.apply(empty, count)
+		for i := range content {
+			content[i] = display.widths.apply(content[i], count)
+		}
+	}
+
 	display.screen.PrintAt(0, 0, heading)
 	display.screen.ClearLine(len(heading), 0)
 
@@ -55,31 +276,58 @@ func (display *Display) Display(t GenericData) {
 	display.screen.BoldPrintAt(0, 2, headings)
 	display.screen.ClearLine(len(headings), 2)
 
-	maxRows := display.screen.Height() - 4
-	lastRow := display.screen.Height() - 2
-	bottomRow := display.screen.Height() - 1
-	content := t.RowContent()
+	// keep the selection within the rows we can show
+	if display.selected > len(content)-1 {
+		display.selected = len(content) - 1
+	}
+	if display.selected > lastRow-4 {
+		display.selected = lastRow - 4
+	}
+	if display.selected < 0 {
+		display.selected = 0
+	}
 
 	for k := 0; k < maxRows; k++ {
 		y := 3 + k
 		if k <= len(content)-1 && k < maxRows {
 			// print out rows
-			display.screen.PrintAt(0, y, content[k])
+			if k == display.selected {
+				display.screen.HighlightPrintAt(0, y, content[k])
+			} else {
+				display.screen.PrintAt(0, y, content[k])
+			}
 			display.screen.ClearLine(len(content[k]), y)
 		} else {
 			// print out empty rows
 			if y < lastRow {
-				display.screen.PrintAt(0, y, t.EmptyRowContent())
+				display.screen.PrintAt(0, y, empty)
 			}
 		}
 	}
 
 	// print out the totals at the bottom
-	total := t.TotalRowContent()
 	display.screen.BoldPrintAt(0, lastRow, total)
 	display.screen.ClearLine(len(total), lastRow)
 
-	menu := "[+-] Delay  [<] Prev  [>] Next  [h]elp  [r] Abs/Rel  [q]uit  [z] Reset stats"
+	menu := "[+-] Delay  [<] Prev  [>] Next  [:] Go to  [h]elp  [r] Abs/Rel  [q]uit  [z] Reset stats"
+	if display.errorText != "" {
+		menu = "Collection failed, retrying: " + display.errorText
+		if display.reconnect {
+			menu = "Lost the connection, reconnecting…: " + display.errorText
+		}
+	}
+	if display.paused {
+		menu = "PAUSED  [p] Resume  [n] Refresh  " + menu
+	}
+	if display.promptShown {
+		menu = "Go to view: " + display.promptText + "_  " + strings.Join(matches(display.gotoView.names, display.promptText), " ")
+	}
+	if display.filterShown {
+		menu = "Filter rows on regexp: " + display.filterText + "_"
+		if !validFilter(display.filterText) {
+			menu += "  (invalid)"
+		}
+	}
 	display.screen.PrintAt(0, bottomRow, menu)
 	display.screen.ClearLine(len(menu), bottomRow)
 }
@@ -99,15 +347,21 @@ func (display *Display) DisplayHelp() {
 
 	display.screen.PrintAt(0, 5, "Keys:")
 	display.screen.PrintAt(0, 6, "- - reduce the poll interval by 1 second (minimum 1 second)")
-	display.screen.PrintAt(0, 7, "+ - increase the poll interval by 1 second")
-	display.screen.PrintAt(0, 8, "h/? - this help screen")
-	display.screen.PrintAt(0, 9, "q - quit")
-	display.screen.PrintAt(0, 10, "s - sort differently (where enabled) - sorts on a different column")
+	display.screen.PrintAt(0, 7, "+ - increase the poll interval by 1 second  f - freeze / unfreeze the column widths")
+	display.screen.PrintAt(0, 8, "h/? - this help screen  j/k or <down>/<up> arrow - select the next / previous row")
+	display.screen.PrintAt(0, 9, "m - toggle between raw and smoothed rates (with --smooth)  q - quit")
+	display.screen.PrintAt(0, 10, "s/S - sort on the next / previous column (where enabled), going back to the view's own order")
 	display.screen.PrintAt(0, 11, "t - toggle between showing time since resetting statistics or since P_S data was collected")
-	display.screen.PrintAt(0, 12, "z - reset statistics")
+	display.screen.PrintAt(0, 12, "z - reset statistics  : - go to a view by name, <tab> completes the name  / - filter the rows on a regexp")
 	display.screen.PrintAt(0, 13, "<tab> or <right arrow> - change display modes between: latency, ops, file I/O, lock and user modes")
-	display.screen.PrintAt(0, 14, "<left arrow> - change display modes to the previous screen (see above)")
-	display.screen.PrintAt(0, 16, "Press h to return to main screen")
+	display.screen.PrintAt(0, 14, "<left arrow> - change display modes to the previous screen (see above)  p - pause / resume  n - refresh now")
+	display.screen.PrintAt(0, 15, "[ / ] - show the previous / next server given with --host  c - choose the columns shown")
+	display.screen.PrintAt(0, 16, "u - group user_latency by user or by account (user@host)  e - EXPLAIN the statement selected in statements")
+	if display.clockSkew != "" {
+		display.screen.PrintAt(0, 17, "Clock skew: "+display.clockSkew)
+	}
+	display.screen.PrintAt(0, 18, "Global variables from: "+global.VariablesSource()+", status from: "+global.StatusSource())
+	display.screen.PrintAt(0, 19, "Press h to return to main screen")
 }
 
 // Resize records the new size of the screen and resizes it
@@ -126,7 +380,31 @@ func (display *Display) pollEvent() event.Event {
 	tbEvent := <-display.termboxChan
 	switch tbEvent.Type {
 	case termbox.EventKey:
+		if display.gotoView.active {
+			return display.gotoView.handle(tbEvent.Ch, tbEvent.Key)
+		}
+		if display.filter.active {
+			return display.filter.handle(tbEvent.Ch, tbEvent.Key)
+		}
+		if display.choosing {
+			e, display.choosing = handleChooserKey(tbEvent.Ch, tbEvent.Key)
+			return e
+		}
+		if display.paneActive {
+			e, display.paneActive = handlePaneKey(tbEvent.Ch, tbEvent.Key)
+			return e
+		}
 		switch tbEvent.Ch {
+		case 'c':
+			display.choosing = true
+			e = event.Event{Type: event.EventColumnChooser}
+		case 'e':
+			display.paneActive = true
+			e = event.Event{Type: event.EventExplain}
+		case ':':
+			e = display.gotoView.start()
+		case '/':
+			e = display.filter.start()
 		case '-':
 			e = event.Event{Type: event.EventDecreasePollTime}
 		case '+':
@@ -135,14 +413,40 @@ func (display *Display) pollEvent() event.Event {
 			e = event.Event{Type: event.EventHelp}
 		case 'q':
 			e = event.Event{Type: event.EventFinished}
+		case 'j':
+			e = event.Event{Type: event.EventSelectDown}
+		case 'k':
+			e = event.Event{Type: event.EventSelectUp}
+		case 'f':
+			e = event.Event{Type: event.EventToggleFreezeColumns}
+		case 'm':
+			e = event.Event{Type: event.EventToggleSmoothing}
+		case 'n':
+			e = event.Event{Type: event.EventCollectNow}
+		case 'p':
+			e = event.Event{Type: event.EventTogglePause}
+		case 's':
+			e = event.Event{Type: event.EventSortNext}
+		case 'S':
+			e = event.Event{Type: event.EventSortPrev}
 		case 't':
 			e = event.Event{Type: event.EventToggleWantRelative}
+		case 'u':
+			e = event.Event{Type: event.EventToggleByAccount}
 		case 'z':
 			e = event.Event{Type: event.EventResetStatistics}
+		case '[':
+			e = event.Event{Type: event.EventServerPrev}
+		case ']':
+			e = event.Event{Type: event.EventServerNext}
 		}
 		switch tbEvent.Key {
 		case termbox.KeyCtrlZ, termbox.KeyCtrlC, termbox.KeyEsc:
 			e = event.Event{Type: event.EventFinished}
+		case termbox.KeyArrowUp:
+			e = event.Event{Type: event.EventSelectUp}
+		case termbox.KeyArrowDown:
+			e = event.Event{Type: event.EventSelectDown}
 		case termbox.KeyArrowLeft:
 			e = event.Event{Type: event.EventViewPrev}
 		case termbox.KeyTab, termbox.KeyArrowRight:
@@ -206,6 +510,11 @@ func (display *Display) HeadingLine(haveRelativeStats, wantRelativeStats bool, i
 			heading += " [ABS]             "
 		}
 	}
+	heading += " datadir " + display.datadirSpace()
+	if re := display.cfg.NameFilter(); re != nil {
+		heading += " filter /" + re.String() + "/"
+	}
+
 	return heading
 }
 
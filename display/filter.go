@@ -0,0 +1,55 @@
+package display
+
+import (
+	"regexp"
+
+	"github.com/gdamore/tcell/termbox"
+
+	"github.com/sjmudd/ps-top/event"
+)
+
+// filterPrompt holds the state of the filter prompt. It is only used by the event poller.
+type filterPrompt struct {
+	active  bool   // is the prompt being shown?
+	text    string // the text typed so far
+	current string // the filter in use, kept if the prompt is closed with <esc>
+}
+
+// validFilter returns true if the text can be used as a filter
+func validFilter(text string) bool {
+	_, err := regexp.Compile(text)
+	return err == nil
+}
+
+// start shows the prompt, starting with the filter in use
+func (p *filterPrompt) start() event.Event {
+	p.active = true
+	p.text = p.current
+
+	return event.Event{Type: event.EventFilterPrompt, Text: p.text}
+}
+
+// handle processes a key while the prompt is active and returns the event to send
+func (p *filterPrompt) handle(ch rune, key termbox.Key) event.Event {
+	switch key {
+	case termbox.KeyEsc, termbox.KeyCtrlC:
+		p.active = false
+		return event.Event{Type: event.EventFilter, Text: p.current}
+	case termbox.KeyEnter:
+		if validFilter(p.text) {
+			p.active = false
+			p.current = p.text
+			return event.Event{Type: event.EventFilter, Text: p.current}
+		}
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		if runes := []rune(p.text); len(runes) > 0 {
+			p.text = string(runes[:len(runes)-1])
+		}
+	default:
+		if ch != 0 {
+			p.text += string(ch)
+		}
+	}
+
+	return event.Event{Type: event.EventFilterPrompt, Text: p.text}
+}
@@ -0,0 +1,44 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/termbox"
+
+	"github.com/sjmudd/ps-top/event"
+)
+
+func TestFilterPrompt(t *testing.T) {
+	type key struct {
+		ch  rune
+		key termbox.Key
+	}
+	tests := []struct {
+		current  string
+		keys     []key
+		expected event.Event
+	}{
+		{"", nil, event.Event{Type: event.EventFilterPrompt}},
+		{"db1", nil, event.Event{Type: event.EventFilterPrompt, Text: "db1"}},
+		{"", []key{{'d', 0}, {'b', 0}}, event.Event{Type: event.EventFilterPrompt, Text: "db"}},
+		{"", []key{{'d', 0}, {'b', 0}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventFilter, Text: "db"}},
+		{"db1", []key{{0, termbox.KeyBackspace2}, {'2', 0}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventFilter, Text: "db2"}},
+		{"db1", []key{{'x', 0}, {0, termbox.KeyEsc}}, event.Event{Type: event.EventFilter, Text: "db1"}},
+		{"db1", []key{{0, termbox.KeyBackspace2}, {0, termbox.KeyBackspace2}, {0, termbox.KeyBackspace2}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventFilter}},
+		{"", []key{{'(', 0}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventFilterPrompt, Text: "("}}, // invalid regexp
+	}
+
+	for _, test := range tests {
+		p := filterPrompt{current: test.current}
+		got := p.start()
+		for _, k := range test.keys {
+			got = p.handle(k.ch, k.key)
+		}
+		if got != test.expected {
+			t.Errorf("filterPrompt(%q, %v) failed: expected: %+v, got %+v", test.current, test.keys, test.expected, got)
+		}
+		if p.active != (got.Type == event.EventFilterPrompt) {
+			t.Errorf("filterPrompt(%q, %v) failed: unexpected active state %v", test.current, test.keys, p.active)
+		}
+	}
+}
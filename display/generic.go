@@ -16,3 +16,14 @@ type GenericData interface {
 	EmptyRowContent() string     // a string containing the details of an empty row
 	HaveRelativeStats() bool     // does this data type have relative statistics
 }
+
+// SortedData is implemented by data which can be sorted on different columns
+type SortedData interface {
+	SortColumn() string // the column sorted on, empty for the default order
+}
+
+// OthersData is implemented by data which can summarise the rows which
+// do not fit on the screen
+type OthersData interface {
+	OthersRowContent(shown int) string // a row summarising the rows after the first shown rows
+}
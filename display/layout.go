@@ -0,0 +1,150 @@
+package display
+
+import (
+	"strings"
+)
+
+const (
+	minNameWidth = 20  // the width left for the name, the last column, when columns are dropped to fit the screen
+	hiddenPrefix = "-" // marks the hidden columns of a saved layout
+	layoutSep    = "," // separates the columns of a saved layout
+)
+
+// layoutColumn is one of the columns of a view's layout
+type layoutColumn struct {
+	name   string // the column's heading with its spaces collapsed
+	index  int    // the position of the column in the rows, set by resolve
+	hidden bool   // is the column hidden?
+}
+
+// layout holds the order of the columns of a view and which are hidden.
+// The name of the row, the last column, is not part of the layout and is
+// always shown last.
+type layout []layoutColumn
+
+// parseLayout returns the layout saved by String
+func parseLayout(saved string) layout {
+	var l layout
+
+	for _, name := range strings.Split(saved, layoutSep) {
+		name = strings.TrimSpace(name)
+		hidden := strings.HasPrefix(name, hiddenPrefix)
+		if name = strings.TrimSpace(strings.TrimPrefix(name, hiddenPrefix)); name != "" {
+			l = append(l, layoutColumn{name: name, hidden: hidden})
+		}
+	}
+	return l
+}
+
+// String returns the layout as a list of the column names in the order
+// shown, the hidden columns being prefixed by -
+func (l layout) String() string {
+	names := make([]string, 0, len(l))
+	for _, column := range l {
+		if column.hidden {
+			names = append(names, hiddenPrefix+column.name)
+		} else {
+			names = append(names, column.name)
+		}
+	}
+	return strings.Join(names, layoutSep+" ")
+}
+
+// columnNames returns the names of the columns of the given headings, excluding the last.
+// Unlike the rows the headings never have the separator in the name.
+func columnNames(headings string) []string {
+	columns := strings.Split(headings, columnSeparator)
+	names := make([]string, 0, len(columns)-1)
+	for _, column := range columns[:len(columns)-1] {
+		names = append(names, strings.Join(strings.Fields(column), " "))
+	}
+	return names
+}
+
+// resolve returns the layout of the columns with the given names: those in l
+// in its order, ignoring any which no longer exist, then the others as they come.
+func (l layout) resolve(names []string) layout {
+	resolved := make(layout, 0, len(names))
+	used := make([]bool, len(names))
+
+	for _, column := range l {
+		for i, name := range names {
+			if !used[i] && name == column.name {
+				used[i] = true
+				resolved = append(resolved, layoutColumn{name: name, index: i, hidden: column.hidden})
+				break
+			}
+		}
+	}
+	for i, name := range names {
+		if !used[i] {
+			resolved = append(resolved, layoutColumn{name: name, index: i})
+		}
+	}
+	return resolved
+}
+
+// shown returns the positions of the columns shown in the order they are shown
+func (l layout) shown() []int {
+	var shown []int
+	for _, column := range l {
+		if !column.hidden {
+			shown = append(shown, column.index)
+		}
+	}
+	return shown
+}
+
+// fit returns the columns of shown which fit within width, dropping them
+// from the right so the name still gets minNameWidth, keeping at least one.
+// columns holds the columns of the headings to give the width of each.
+func fit(columns []string, shown []int, width int) []int {
+	used := 0
+	for _, i := range shown {
+		used += len(columns[i]) + len(columnSeparator)
+	}
+	for len(shown) > 1 && used+minNameWidth > width {
+		used -= len(columns[shown[len(shown)-1]]) + len(columnSeparator)
+		shown = shown[:len(shown)-1]
+	}
+	return shown
+}
+
+// arrange returns line with only the columns in shown in that order, followed
+// by the name, which may itself hold the separator. Lines with fewer columns,
+// e.g. those which do not have any, are returned as they are.
+func arrange(line string, count int, shown []int) string {
+	columns := strings.SplitN(line, columnSeparator, count+1)
+	if len(columns) != count+1 {
+		return line
+	}
+
+	arranged := make([]string, 0, len(shown)+1)
+	for _, i := range shown {
+		arranged = append(arranged, columns[i])
+	}
+	return strings.Join(append(arranged, columns[count]), columnSeparator)
+}
+
+// isDefault returns whether the layout shows all the columns in the view's own order
+func (l layout) isDefault() bool {
+	for i, column := range l {
+		if column.hidden || column.index != i {
+			return false
+		}
+	}
+	return true
+}
+
+// equalInts returns whether a and b hold the same values
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
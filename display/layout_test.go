@@ -0,0 +1,67 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	headings := "   Latency      %| Fetch|Insert|Table Name"
+	names := columnNames(headings)
+	if strings.Join(names, ",") != "Latency %,Fetch,Insert" {
+		t.Fatalf("columnNames(%q) failed: got %q", headings, names)
+	}
+
+	tests := []struct {
+		saved    string
+		expected string // the layout once resolved
+		shown    string // the headings shown
+	}{
+		{"", "Latency %, Fetch, Insert", headings},
+		{"Insert, -Latency %", "Insert, -Latency %, Fetch", "Insert| Fetch|Table Name"},
+		{"Fetch, No Longer, -Insert, Latency %", "Fetch, -Insert, Latency %", " Fetch|   Latency      %|Table Name"},
+		{"-Latency %, -Fetch, -Insert", "-Latency %, -Fetch, -Insert", "Table Name"},
+	}
+	for _, test := range tests {
+		l := parseLayout(test.saved).resolve(names)
+		if got := l.String(); got != test.expected {
+			t.Errorf("parseLayout(%q).resolve() failed: expected %q, got %q", test.saved, test.expected, got)
+		}
+		if got := arrange(headings, len(names), l.shown()); got != test.shown {
+			t.Errorf("arrange(%q) failed: expected %q, got %q", test.saved, test.shown, got)
+		}
+		if l.isDefault() != (test.saved == "") {
+			t.Errorf("isDefault(%q) failed: expected %v", test.saved, test.saved == "")
+		}
+	}
+
+	// the name may hold the separator
+	row := "  1.2 s  10.0%|    10|     2|SELECT a | b FROM t"
+	if got, expected := arrange(row, len(names), []int{2, 0}), "     2|  1.2 s  10.0%|SELECT a | b FROM t"; got != expected {
+		t.Errorf("arrange(%q) failed: expected %q, got %q", row, expected, got)
+	}
+
+	// lines without the same columns are shown as they are
+	if got := arrange("no columns", len(names), []int{2}); got != "no columns" {
+		t.Errorf("arrange() failed: expected the line unchanged, got %q", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	columns := strings.Split("   Latency      %| Fetch|Insert|Table Name", columnSeparator)
+
+	tests := []struct {
+		width    int
+		expected []int
+	}{
+		{80, []int{0, 1, 2}},
+		{52, []int{0, 1, 2}},
+		{51, []int{0, 1}},
+		{10, []int{0}},
+	}
+	for _, test := range tests {
+		if got := fit(columns, []int{0, 1, 2}, test.width); !equalInts(got, test.expected) {
+			t.Errorf("fit(%d) failed: expected %v, got %v", test.width, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package display
+
+import (
+	"github.com/gdamore/tcell/termbox"
+
+	"github.com/sjmudd/ps-top/event"
+)
+
+// pane holds the lines of text shown instead of the view, e.g. a query plan,
+// which may be scrolled if they do not fit on the screen
+type pane struct {
+	shown  bool
+	title  string
+	lines  []string
+	offset int // the first line shown
+}
+
+// scroll moves the first line shown by the given number of lines
+func (p *pane) scroll(lines int) {
+	p.offset += lines
+	if p.offset > len(p.lines)-1 {
+		p.offset = len(p.lines) - 1
+	}
+	if p.offset < 0 {
+		p.offset = 0
+	}
+}
+
+// handlePaneKey converts the keys pressed while the pane is shown to app
+// events, returning whether the pane is still shown
+func handlePaneKey(ch rune, key termbox.Key) (event.Event, bool) {
+	switch ch {
+	case 'j':
+		return event.Event{Type: event.EventSelectDown}, true
+	case 'k':
+		return event.Event{Type: event.EventSelectUp}, true
+	case 'e', 'q':
+		return event.Event{Type: event.EventExplain}, false
+	}
+	switch key {
+	case termbox.KeyArrowDown:
+		return event.Event{Type: event.EventSelectDown}, true
+	case termbox.KeyArrowUp:
+		return event.Event{Type: event.EventSelectUp}, true
+	case termbox.KeyEnter, termbox.KeyEsc, termbox.KeyCtrlC:
+		return event.Event{Type: event.EventExplain}, false
+	}
+	return event.Event{Type: event.EventUnknown}, true
+}
+
+// PaneShown returns whether the pane is shown instead of the view
+func (display *Display) PaneShown() bool {
+	return display.pane.shown
+}
+
+// ShowPane shows the lines with the given title instead of the view
+func (display *Display) ShowPane(title string, lines []string) {
+	display.pane = pane{
+		shown: true,
+		title: title,
+		lines: lines,
+	}
+}
+
+// HidePane stops showing the pane, showing the view again
+func (display *Display) HidePane() {
+	display.pane = pane{}
+}
+
+// DisplayPane shows the lines of the pane from the first line scrolled to
+func (display *Display) DisplayPane() {
+	display.screen.InvertedPrintAt(0, 0, display.pane.title)
+	display.screen.ClearLine(len(display.pane.title), 0)
+	help := "j/k or <down>/<up> - scroll  e, q or <enter> - return to the view"
+	display.screen.PrintAt(0, 1, help)
+	display.screen.ClearLine(len(help), 1)
+
+	lines := display.pane.lines[display.pane.offset:]
+	for y := 3; y < display.screen.Height(); y++ {
+		line := ""
+		if y-3 < len(lines) {
+			line = lines[y-3]
+		}
+		display.screen.PrintAt(0, y, line)
+		display.screen.ClearLine(len(line), y)
+	}
+}
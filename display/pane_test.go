@@ -0,0 +1,42 @@
+package display
+
+import (
+	"testing"
+)
+
+func TestPane(t *testing.T) {
+	display := new(Display)
+	display.ShowPane("EXPLAIN", []string{"{", `  "query_block": {}`, "}"})
+	if !display.PaneShown() {
+		t.Errorf("PaneShown() failed: expected the pane to be shown")
+	}
+
+	tests := []struct {
+		down     bool
+		expected int
+	}{
+		{false, 0}, // already at the top
+		{true, 1},
+		{true, 2},
+		{true, 2}, // the last line stays shown
+		{false, 1},
+	}
+	for _, test := range tests {
+		if test.down {
+			display.SelectDown()
+		} else {
+			display.SelectUp()
+		}
+		if display.pane.offset != test.expected {
+			t.Errorf("scrolling the pane (down: %v) failed: expected offset %d, got %d", test.down, test.expected, display.pane.offset)
+		}
+	}
+	if display.selected != 0 {
+		t.Errorf("scrolling the pane failed: expected the selected row to be kept, got %d", display.selected)
+	}
+
+	display.HidePane()
+	if display.PaneShown() {
+		t.Errorf("PaneShown() failed: expected the pane to be hidden")
+	}
+}
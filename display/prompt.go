@@ -0,0 +1,92 @@
+package display
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/termbox"
+
+	"github.com/sjmudd/ps-top/event"
+)
+
+// prompt holds the state of the go to view prompt. It is only used by the event poller.
+type prompt struct {
+	active bool     // is the prompt being shown?
+	text   string   // the text typed so far
+	names  []string // the view names which may be chosen
+}
+
+// start shows an empty prompt
+func (p *prompt) start() event.Event {
+	p.active = true
+	p.text = ""
+
+	return event.Event{Type: event.EventGotoViewPrompt}
+}
+
+// handle processes a key while the prompt is active and returns the event to send
+func (p *prompt) handle(ch rune, key termbox.Key) event.Event {
+	switch key {
+	case termbox.KeyEsc, termbox.KeyCtrlC:
+		p.active = false
+		return event.Event{Type: event.EventGotoView}
+	case termbox.KeyEnter:
+		if name, ok := p.choice(); ok {
+			p.active = false
+			return event.Event{Type: event.EventGotoView, Text: name}
+		}
+	case termbox.KeyTab:
+		p.complete()
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		if runes := []rune(p.text); len(runes) > 0 {
+			p.text = string(runes[:len(runes)-1])
+		}
+	default:
+		if ch != 0 {
+			p.text += string(ch)
+		}
+	}
+
+	return event.Event{Type: event.EventGotoViewPrompt, Text: p.text}
+}
+
+// matches returns the names starting with prefix
+func matches(names []string, prefix string) []string {
+	var found []string
+
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// complete extends the text to the longest prefix shared by the matching names
+func (p *prompt) complete() {
+	found := matches(p.names, p.text)
+	if len(found) == 0 {
+		return
+	}
+
+	common := found[0]
+	for _, name := range found[1:] {
+		for !strings.HasPrefix(name, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	p.text = common
+}
+
+// choice returns the chosen name: the one matching the text exactly or the only one with it as a prefix
+func (p prompt) choice() (string, bool) {
+	found := matches(p.names, p.text)
+	for _, name := range found {
+		if name == p.text {
+			return name, true
+		}
+	}
+	if len(found) == 1 {
+		return found[0], true
+	}
+	return "", false
+}
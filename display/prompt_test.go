@@ -0,0 +1,45 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/termbox"
+
+	"github.com/sjmudd/ps-top/event"
+)
+
+func TestPrompt(t *testing.T) {
+	type key struct {
+		ch  rune
+		key termbox.Key
+	}
+	names := []string{"table_io_latency", "table_io_ops", "table_lock_latency", "user_latency"}
+	tests := []struct {
+		keys     []key
+		expected event.Event
+	}{
+		{[]key{{'u', 0}}, event.Event{Type: event.EventGotoViewPrompt, Text: "u"}},
+		{[]key{{'u', 0}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventGotoView, Text: "user_latency"}},
+		{[]key{{'t', 0}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventGotoViewPrompt, Text: "t"}},
+		{[]key{{'t', 0}, {0, termbox.KeyTab}}, event.Event{Type: event.EventGotoViewPrompt, Text: "table_"}},
+		{[]key{{'t', 0}, {0, termbox.KeyTab}, {'i', 0}, {0, termbox.KeyTab}}, event.Event{Type: event.EventGotoViewPrompt, Text: "table_io_"}},
+		{[]key{{'t', 0}, {0, termbox.KeyTab}, {'l', 0}, {0, termbox.KeyEnter}}, event.Event{Type: event.EventGotoView, Text: "table_lock_latency"}},
+		{[]key{{'x', 0}, {0, termbox.KeyTab}}, event.Event{Type: event.EventGotoViewPrompt, Text: "x"}},
+		{[]key{{'u', 0}, {'x', 0}, {0, termbox.KeyBackspace2}}, event.Event{Type: event.EventGotoViewPrompt, Text: "u"}},
+		{[]key{{'u', 0}, {0, termbox.KeyEsc}}, event.Event{Type: event.EventGotoView}},
+	}
+
+	for _, test := range tests {
+		p := prompt{names: names}
+		got := p.start()
+		for _, k := range test.keys {
+			got = p.handle(k.ch, k.key)
+		}
+		if got != test.expected {
+			t.Errorf("prompt(%v) failed: expected: %+v, got %+v", test.keys, test.expected, got)
+		}
+		if p.active != (got.Type == event.EventGotoViewPrompt) {
+			t.Errorf("prompt(%v) failed: unexpected active state %v", test.keys, p.active)
+		}
+	}
+}
@@ -6,26 +6,49 @@ type Type uint8
 
 // Event* hold the different event types as integer values
 const (
-	EventNone               Type = iota // no event was given
-	EventAnonymise                      // toggle anonymising data.
-	EventFinished                       // please exit the program
-	EventViewNext                       // show me the next view
-	EventViewPrev                       // show me the previous view
-	EventDecreasePollTime               // reduce the poll time (if possible)
-	EventIncreasePollTime               // increase the poll time
-	EventHelp                           // provide me with help
-	EventToggleWantRelative             // toggle between wanting absolute or relative stats
-	EventResetStatistics                // reset the current stats back to zero
-	EventResizeScreen                   // not really a event but a state change
-	EventUnknown                        // something weird has happened
-	EventError                          // some error
+	EventNone                Type = iota // no event was given
+	EventAnonymise                       // toggle anonymising data.
+	EventFinished                        // please exit the program
+	EventViewNext                        // show me the next view
+	EventViewPrev                        // show me the previous view
+	EventDecreasePollTime                // reduce the poll time (if possible)
+	EventIncreasePollTime                // increase the poll time
+	EventHelp                            // provide me with help
+	EventToggleWantRelative              // toggle between wanting absolute or relative stats
+	EventResetStatistics                 // reset the current stats back to zero
+	EventResizeScreen                    // not really a event but a state change
+	EventSelectUp                        // move the selected row up
+	EventSelectDown                      // move the selected row down
+	EventToggleSmoothing                 // toggle between raw and smoothed rates
+	EventToggleFreezeColumns             // toggle keeping the column widths stable
+	EventGotoViewPrompt                  // show the go to view prompt with the text typed so far
+	EventGotoView                        // go to the view given in Text, or close the prompt if empty
+	EventSortNext                        // sort the view on the next column
+	EventSortPrev                        // sort the view on the previous column
+	EventFilterPrompt                    // show the filter prompt with the text typed so far
+	EventFilter                          // filter the rows on the regexp given in Text, showing all rows if empty
+	EventServerNext                      // show the next server
+	EventServerPrev                      // show the previous server
+	EventColumnChooser                   // show or hide the column chooser
+	EventColumnToggle                    // show or hide the selected column
+	EventColumnMoveUp                    // move the selected column up
+	EventColumnMoveDown                  // move the selected column down
+	EventColumnDefault                   // show all the columns in the default order
+	EventTogglePause                     // pause or resume collecting data
+	EventCollectNow                      // collect the data now, even if paused
+	EventToggleByAccount                 // toggle grouping users by account (user@host)
+	EventExplain                         // explain the selected statement or stop showing its plan
+	EventUnknown                         // something weird has happened
+	EventError                           // some error
 )
 
 // Event is one of the earlier list of Event constants and also contains a position
+// or some text
 type Event struct {
 	Type   Type
 	Width  int
 	Height int
+	Text   string
 }
 
 const eventChanSize = 100 // arbitrary size. Maybe should be 0?
@@ -0,0 +1,118 @@
+// Package explain collects the query plan of a statement so it can be shown to the user.
+package explain
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sjmudd/ps-top/lib"
+)
+
+// statements which MySQL is able to EXPLAIN
+var explainable = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE", "TABLE", "WITH"}
+
+// check returns an error if the query can not be explained
+func check(query string) error {
+	query = strings.TrimSpace(query)
+	query = strings.TrimSuffix(query, ";")
+
+	if query == "" {
+		return errors.New("no query to explain")
+	}
+	if strings.HasSuffix(query, "...") {
+		return errors.New("the query has been truncated so can not be explained")
+	}
+	if strings.Contains(query, ";") {
+		return errors.New("multi-statement queries can not be explained")
+	}
+	words := strings.Fields(query)
+	keyword := strings.ToUpper(strings.TrimLeft(words[0], "("))
+	for _, statement := range explainable {
+		if keyword == statement {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s statements can not be explained", keyword)
+}
+
+// maxIdentifierLength is the longest schema name MySQL allows, in characters
+const maxIdentifierLength = 64
+
+// validIdentifier returns true if name can be used as a schema name: it must be
+// valid UTF-8 of at most 64 characters, not end with a space and not contain any
+// control characters such as NUL. Backticks are fine as lib.QuoteIdentifier doubles them.
+func validIdentifier(name string) bool {
+	if name == "" || !utf8.ValidString(name) || utf8.RuneCountInString(name) > maxIdentifierLength || strings.HasSuffix(name, " ") {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// prettyPrint converts the JSON plan into indented lines
+func prettyPrint(plan string) ([]string, error) {
+	var out bytes.Buffer
+
+	if err := json.Indent(&out, []byte(plan), "", "  "); err != nil {
+		return nil, err
+	}
+
+	return strings.Split(out.String(), "\n"), nil
+}
+
+// Explain runs EXPLAIN FORMAT=JSON for the query using schema as the default
+// database (if not empty) and returns the plan as lines for displaying.
+// EXPLAIN does not modify data so this is safe on a read-only server.
+func Explain(db *sql.DB, schema, query string) ([]string, error) {
+	if err := check(query); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if schema != "" && !validIdentifier(schema) {
+		return nil, fmt.Errorf("invalid schema name %q", schema)
+	}
+	if schema != "" {
+		// restore the original default database as the connection is returned to the pool
+		var original sql.NullString
+		if err := conn.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&original); err != nil {
+			return nil, err
+		}
+		if _, err := conn.ExecContext(ctx, "USE "+lib.QuoteIdentifier(schema)); err != nil {
+			return nil, err
+		}
+		if original.Valid {
+			defer func() {
+				if _, err := conn.ExecContext(ctx, "USE "+lib.QuoteIdentifier(original.String)); err != nil {
+					log.Println("Explain() failed to restore default database:", err)
+				}
+			}()
+		}
+	}
+
+	var plan string
+	if err := conn.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+strings.TrimSuffix(strings.TrimSpace(query), ";")).Scan(&plan); err != nil {
+		return nil, err
+	}
+
+	return prettyPrint(plan)
+}
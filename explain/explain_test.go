@@ -0,0 +1,75 @@
+package explain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool // true if the query can be explained
+	}{
+		{"", false},
+		{"SELECT 1", true},
+		{"  select * from t1 where id = 1;", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"UPDATE t1 SET a = 1", true},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", true},
+		{"SELECT 1; SELECT 2", false},
+		{"SELECT * FROM t1 WHERE a IN (1, 2, ...", false},
+		{"SHOW TABLES", false},
+		{"COMMIT", false},
+	}
+	for _, test := range tests {
+		got := check(test.query) == nil
+		if got != test.expected {
+			t.Errorf("check(%q) failed: expected: %v, got %v", test.query, test.expected, got)
+		}
+	}
+}
+
+func TestValidIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"", false},
+		{"db", true},
+		{"my-db", true},
+		{"select", true},
+		{"my`db", true},
+		{"`; DROP DATABASE db; --", true}, // harmless once quoted
+		{"db\x00", false},
+		{"db\nUSE mysql", false},
+		{"db ", false},
+		{"\xff", false},
+		{strings.Repeat("d", 64), true},
+		{strings.Repeat("d", 65), false},
+		{strings.Repeat("é", 64), true},
+	}
+	for _, test := range tests {
+		if got := validIdentifier(test.name); got != test.expected {
+			t.Errorf("validIdentifier(%q) failed: expected: %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestPrettyPrint(t *testing.T) {
+	got, err := prettyPrint(`{"query_block":{"select_id":1}}`)
+	if err != nil {
+		t.Fatalf("prettyPrint() failed: %v", err)
+	}
+	expected := []string{"{", `  "query_block": {`, `    "select_id": 1`, "  }", "}"}
+	if len(got) != len(expected) {
+		t.Fatalf("prettyPrint() failed: expected: %q, got %q", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("prettyPrint() line %d failed: expected: %q, got %q", i, expected[i], got[i])
+		}
+	}
+	if _, err := prettyPrint("not json"); err == nil {
+		t.Errorf("prettyPrint() failed: expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,31 @@
+package global
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// ClockSkew returns how far the server's clock is ahead of the local clock.
+// The server time is compared with the middle of the time taken by the query.
+func ClockSkew(dbh *sql.DB) (time.Duration, error) {
+	var serverTime float64
+
+	before := time.Now()
+	if err := dbh.QueryRow("SELECT UNIX_TIMESTAMP(NOW(6))").Scan(&serverTime); err != nil {
+		return 0, err
+	}
+	after := time.Now()
+
+	return skew(serverTime, before, after), nil
+}
+
+// skew returns the difference between the server time, given in seconds since the epoch,
+// and the local time at the middle of before and after
+func skew(serverTime float64, before, after time.Time) time.Duration {
+	local := before.Add(after.Sub(before) / 2)
+	seconds, fraction := math.Modf(serverTime)
+	server := time.Unix(int64(seconds), int64(math.Round(fraction*1e6))*1000)
+
+	return server.Sub(local)
+}
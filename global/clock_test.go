@@ -0,0 +1,25 @@
+package global
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSkew(t *testing.T) {
+	before := time.Unix(1700000000, 0)
+	tests := []struct {
+		serverTime float64
+		after      time.Time
+		expected   time.Duration
+	}{
+		{1700000000, before, 0},
+		{1700000000.5, before.Add(time.Second), 0},
+		{1700000002.25, before, 2250 * time.Millisecond},
+		{1699999990, before.Add(2 * time.Second), -11 * time.Second},
+	}
+	for _, test := range tests {
+		if got := skew(test.serverTime, before, test.after); got != test.expected {
+			t.Errorf("skew(%v) failed: expected: %v, got %v", test.serverTime, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package global
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"syscall"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// server errors sent when it closes the connection
+const (
+	serverShutdownErrorNum   = 1053 // Error 1053: Server shutdown in progress
+	connectionKilledErrorNum = 1927 // Error 1927: Connection was killed (MariaDB)
+	clientTimeoutErrorNum    = 4031 // Error 4031: The client was disconnected by the server because of inactivity
+)
+
+// IsConnectionLost returns true if the error is caused by a lost or refused
+// connection so the query may work if tried again, e.g. after the server restarts
+func IsConnectionLost(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case serverShutdownErrorNum, connectionKilledErrorNum, clientTimeoutErrorNum:
+			return true
+		}
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,34 @@
+package global
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsConnectionLost(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("whatever"), false},
+		{driver.ErrBadConn, true},
+		{mysql.ErrInvalidConn, true},
+		{fmt.Errorf("commands: %w", mysql.ErrInvalidConn), true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{&mysql.MySQLError{Number: 1053, Message: "Server shutdown in progress"}, true},
+		{&mysql.MySQLError{Number: 1146, Message: "Table 'performance_schema.x' doesn't exist"}, false},
+	}
+	for _, test := range tests {
+		if got := IsConnectionLost(test.err); got != test.expected {
+			t.Errorf("IsConnectionLost(%v) failed: expected %v, got %v", test.err, test.expected, got)
+		}
+	}
+}
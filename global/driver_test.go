@@ -0,0 +1,181 @@
+package global
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// fakeResult holds the columns and rows, or the error, returned for a query
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+	fails   int // if set err is only returned by the first fails queries
+}
+
+// fakeConnector is a database/sql connector returning fixed results for each query
+type fakeConnector struct {
+	mu       sync.Mutex
+	results  map[string]fakeResult
+	queries  []string         // the queries run, in order
+	args     [][]driver.Value // the arguments of each query
+	prepared []string         // the queries prepared, in order
+
+	parseDelay time.Duration // time taken to parse each query which is not prepared, and to prepare one
+}
+
+// newFakeDB returns a *sql.DB returning the given results
+func newFakeDB(results map[string]fakeResult) (*sql.DB, *fakeConnector) {
+	connector := &fakeConnector{results: results}
+	return sql.OpenDB(connector), connector
+}
+
+// Connect returns a connection using the results of the connector
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{connector: c}, nil
+}
+
+// Driver is needed to satisfy driver.Connector
+func (c *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// Queries returns the queries run so far
+func (c *fakeConnector) Queries() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.queries...)
+}
+
+// Prepared returns the queries prepared so far
+func (c *fakeConnector) Prepared() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.prepared...)
+}
+
+// Args returns the arguments of the queries run so far
+func (c *fakeConnector) Args() [][]driver.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([][]driver.Value(nil), c.args...)
+}
+
+// result returns the result of the query, recording that it was run with args
+func (c *fakeConnector) result(query string, args []driver.NamedValue) (fakeResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, values)
+	result, ok := c.results[query]
+	if ok && result.fails > 0 {
+		failed := result
+		if result.fails--; result.fails == 0 {
+			result.err = nil // succeed from now on
+		}
+		c.results[query] = result
+		return failed, true
+	}
+	return result, ok
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDriver: use the connector")
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+// Prepare returns a statement which runs query through the connection
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	time.Sleep(c.connector.parseDelay)
+	c.connector.mu.Lock()
+	c.connector.prepared = append(c.connector.prepared, query)
+	c.connector.mu.Unlock()
+
+	return fakeStmt{conn: c, query: query}, nil
+}
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fakeConn: transactions are not supported")
+}
+
+// QueryContext parses the query and returns the rows configured for it whatever the arguments
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	time.Sleep(c.connector.parseDelay)
+	return c.query(ctx, query, args)
+}
+
+// query returns the rows configured for the already parsed query
+func (c fakeConn) query(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result, ok := c.connector.result(query, args)
+	if !ok {
+		return nil, errors.New("fakeConn: unexpected query: " + query)
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &fakeRows{columns: result.columns, rows: result.rows}, nil
+}
+
+// fakeStmt is a prepared query run with the results of its connection
+type fakeStmt struct {
+	conn  fakeConn
+	query string
+}
+
+func (fakeStmt) Close() error { return nil }
+
+func (fakeStmt) NumInput() int { return -1 }
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("fakeStmt: Exec is not supported")
+}
+
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("fakeStmt: use QueryContext")
+}
+
+// QueryContext runs the prepared query through the connection
+func (s fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.query(ctx, s.query, args)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
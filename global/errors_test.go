@@ -2,7 +2,10 @@ package global
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestError(t *testing.T) {
@@ -16,9 +19,18 @@ func TestError(t *testing.T) {
 		{0, "Error 0000 (?????):", true},
 		{3167, "Error 3167 (?????): The 'INFORMATION_SCHEMA.GLOBAL_VARIABLES' feature is disabled; see the documentation for 'show_compatibility_56", true},
 		{1109, "Error 1109 (42S02): Unknown table 'GLOBAL_VARIABLES' in information_schema", true},
+		{1109, "Error 1146 (42S02): Table 'performance_schema.x' doesn't exist", false},
+		{145, "Error 145 (HY000): Table './db/t1' is marked as crashed and should be repaired", true},
+		{13118, "Error 13118 (HY000): some five digit error", true},
+		{1109, "Error 1109: Unknown table 'GLOBAL_VARIABLES' in information_schema", true}, // before v1.7.0
+		{1109, "error 1109 (42S02): lower case prefix", false},
+		{1109, "Error 1109 (42S02) missing colon", false},
 	}
 	for _, test := range tests {
 		err := errors.New(test.errstr)
+		if test.errstr == "" {
+			err = nil
+		}
 		got := IsMysqlError(err, test.errnum)
 		if got != test.expected {
 			t.Errorf("IsMysqlError(%v,%v) failed: expected: %v, got %v",
@@ -29,3 +41,38 @@ func TestError(t *testing.T) {
 		}
 	}
 }
+
+func TestMySQLError(t *testing.T) {
+	tests := []struct {
+		err      error
+		errnum   int
+		expected bool
+	}{
+		{&mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"}, 1146, true},
+		{&mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"}, 1109, false},
+		{fmt.Errorf("collecting: %w", &mysql.MySQLError{Number: 3167}), 3167, true},
+		{fmt.Errorf("collecting: %w", errors.New("Error 3167 (HY000): disabled")), 3167, false},
+	}
+	for _, test := range tests {
+		if got := IsMysqlError(test.err, test.errnum); got != test.expected {
+			t.Errorf("IsMysqlError(%v,%v) failed: expected: %v, got %v", test.err, test.errnum, test.expected, got)
+		}
+	}
+}
+
+func TestIsAccessDenied(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{&mysql.MySQLError{Number: 1142, Message: "SELECT command denied to user 'ro'@'%' for table 'table_io_waits_summary_by_table'"}, true},
+		{errors.New("Error 1044 (42000): Access denied for user 'ro'@'%' to database 'performance_schema'"), true},
+		{&mysql.MySQLError{Number: 1146, Message: "Table doesn't exist"}, false},
+	}
+	for _, test := range tests {
+		if got := IsAccessDenied(test.err); got != test.expected {
+			t.Errorf("IsAccessDenied(%v) failed: expected: %v, got %v", test.err, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package global
+
+import "sync"
+
+// DefaultHistorySize is the number of snapshots kept by a History if no size is given
+const DefaultHistorySize = 60
+
+// History keeps the last snapshots of the variables in a ring buffer
+type History struct {
+	mu        sync.RWMutex
+	snapshots []*Variables // ring buffer of the snapshots
+	next      int          // where the next snapshot goes
+	count     int          // number of snapshots held
+}
+
+// NewHistory returns a History keeping size snapshots, DefaultHistorySize if size is not positive
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{snapshots: make([]*Variables, size)}
+}
+
+// Push adds a snapshot of the variables currently in v, replacing the oldest if
+// the history is full. The snapshot is kept so later collections into v don't change it.
+func (h *History) Push(v *Variables) {
+	snapshot := &Variables{}
+	if v != nil {
+		v.mu.RLock()
+		snapshot.variables, snapshot.source = v.variables, v.source // the map is replaced, never modified
+		v.mu.RUnlock()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.snapshots[h.next] = snapshot
+	h.next = (h.next + 1) % len(h.snapshots)
+	if h.count < len(h.snapshots) {
+		h.count++
+	}
+}
+
+// Len returns the number of snapshots held
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.count
+}
+
+// At returns the snapshot pushed ageBack pushes ago, 0 being the latest,
+// or nil if there is no such snapshot
+func (h *History) At(ageBack int) *Variables {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if ageBack < 0 || ageBack >= h.count {
+		return nil
+	}
+	return h.snapshots[(h.next-1-ageBack+2*len(h.snapshots))%len(h.snapshots)]
+}
+
+// DiffOldest returns the changes from the oldest to the latest snapshot as Variables.Diff
+// does. No changes are returned if there are fewer than two snapshots.
+func (h *History) DiffOldest() map[string][2]string {
+	count := h.Len()
+	if count < 2 {
+		return make(map[string][2]string)
+	}
+	return h.At(0).Diff(h.At(count - 1))
+}
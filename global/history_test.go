@@ -0,0 +1,41 @@
+package global
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	if got := len(NewHistory(0).snapshots); got != DefaultHistorySize {
+		t.Errorf("NewHistory(0) failed: expected size %d, got %d", DefaultHistorySize, got)
+	}
+
+	h := NewHistory(3)
+	if h.At(0) != nil || h.Len() != 0 || len(h.DiffOldest()) != 0 {
+		t.Errorf("NewHistory(3) failed: expected an empty history")
+	}
+
+	v := &Variables{}
+	for i := 1; i <= 5; i++ {
+		v.set(map[string]string{"max_connections": strconv.Itoa(i * 100), "port": "3306"}, informationSchemaGlobalVariables)
+		h.Push(v) // the same *Variables is collected into each time
+	}
+
+	if h.Len() != 3 {
+		t.Errorf("Len() failed: expected 3, got %d", h.Len())
+	}
+	for ageBack, expected := range []string{"500", "400", "300"} {
+		if got := h.At(ageBack).Get("max_connections"); got != expected {
+			t.Errorf("At(%d) failed: expected max_connections %s, got %q", ageBack, expected, got)
+		}
+	}
+	if h.At(-1) != nil || h.At(3) != nil {
+		t.Errorf("At() failed: expected nil outside the history")
+	}
+
+	expected := map[string][2]string{"max_connections": {"300", "500"}}
+	if got := h.DiffOldest(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("DiffOldest() failed: expected: %v, got %v", expected, got)
+	}
+}
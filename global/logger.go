@@ -0,0 +1,57 @@
+package global
+
+import (
+	"log"
+	"sync"
+
+	"github.com/sjmudd/ps-top/mylog"
+)
+
+// Logger is used by the package for its logging so an application embedding it
+// can send the messages to its own logging. Fatalf is expected not to return.
+type Logger interface {
+	Printf(format string, v ...any)
+	Fatalf(format string, v ...any)
+}
+
+// stdLogger logs with the log package and mylog.Fatalf as ps-top does
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...any) { log.Printf(format, v...) }
+
+func (stdLogger) Fatalf(format string, v ...any) { mylog.Fatalf(format, v...) }
+
+var (
+	loggerMu      sync.RWMutex
+	packageLogger Logger = stdLogger{}
+)
+
+// SetLogger sets the logger used by the package and by any Variables or Status
+// created without their own. nil restores the default of logging as ps-top does.
+func SetLogger(logger Logger) {
+	if logger == nil {
+		logger = stdLogger{}
+	}
+
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+
+	packageLogger = logger
+}
+
+// getLogger returns the logger set by SetLogger
+func getLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+
+	return packageLogger
+}
+
+// firstLogger returns the first of the optional loggers given to a constructor,
+// or nil to use the package logger
+func firstLogger(loggers []Logger) Logger {
+	if len(loggers) > 0 {
+		return loggers[0]
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package global
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingLogger keeps the messages logged through it
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Fatalf(format string, v ...any) {
+	l.Printf("FATAL: "+format, v...)
+}
+
+// contains returns true if a message containing text was logged
+func (l *recordingLogger) contains(text string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, message := range l.messages {
+		if strings.Contains(message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVariablesLogger(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+	db, _ := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: variableRows(
+			[]driver.Value{"max_connections", "151"},
+		),
+	})
+	defer db.Close()
+
+	own := &recordingLogger{}
+	if _, err := NewVariables(db, own).SelectAll(); err != nil {
+		t.Fatalf("SelectAll() failed: %v", err)
+	}
+	if !own.contains("selectAll() result has 1 rows") {
+		t.Errorf("NewVariables() with a logger failed: expected the result to be logged, got %q", own.messages)
+	}
+
+	shared := &recordingLogger{}
+	SetLogger(shared)
+	defer SetLogger(nil)
+	if _, err := NewVariables(db).SelectAll(); err != nil {
+		t.Fatalf("SelectAll() failed: %v", err)
+	}
+	if !shared.contains("selectAll() query succeeded") {
+		t.Errorf("SetLogger() failed: expected the query to be logged, got %q", shared.messages)
+	}
+	NewStatus(nil)
+	if !shared.contains("FATAL: NewStatus() dbh is nil") {
+		t.Errorf("SetLogger() failed: expected Fatalf to be called, got %q", shared.messages)
+	}
+}
@@ -0,0 +1,58 @@
+package global
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prometheus metric names used for the variables and status
+const (
+	prometheusVariableMetric = "mysql_global_variable"
+	prometheusStatusMetric   = "mysql_global_status"
+)
+
+// prometheusLabelEscaper escapes label values as required by the Prometheus text format
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the numeric variables to w in the Prometheus text
+// exposition format, e.g. mysql_global_variable{name="max_connections"} 151.
+// Variables whose values are not numbers are skipped.
+func (v *Variables) WritePrometheus(w io.Writer) error {
+	return writePrometheus(w, prometheusVariableMetric, v.current())
+}
+
+// WritePrometheus queries the global status and writes the numeric values to w
+// in the Prometheus text exposition format as Variables.WritePrometheus does.
+func (status *Status) WritePrometheus(w io.Writer) error {
+	values, err := status.selectAll()
+	if err != nil {
+		return err
+	}
+	return writePrometheus(w, prometheusStatusMetric, values)
+}
+
+// writePrometheus writes the values which parse as floats as samples of metric
+// labelled with their name, sorted by name
+func writePrometheus(w io.Writer, metric string, values map[string]string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# TYPE %s untyped\n", metric)
+	for _, name := range names {
+		f, err := strconv.ParseFloat(strings.TrimSpace(values[name]), 64)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s{name=\"%s\"} %s\n", metric, prometheusLabelEscaper.Replace(name), strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
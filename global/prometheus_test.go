@@ -0,0 +1,62 @@
+package global
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestVariablesWritePrometheus(t *testing.T) {
+	v := Variables{variables: map[string]string{
+		"max_connections": "151",
+		"long_query_time": "10.000000",
+		"version":         "8.0.35",
+		"read_only":       "OFF",
+		"init_connect":    "",
+		`odd"name\`:       "1",
+		"offset":          "-1",
+	}}
+
+	var b strings.Builder
+	if err := v.WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	expected := `# TYPE mysql_global_variable untyped
+mysql_global_variable{name="long_query_time"} 10
+mysql_global_variable{name="max_connections"} 151
+mysql_global_variable{name="odd\"name\\"} 1
+mysql_global_variable{name="offset"} -1
+`
+	if got := b.String(); got != expected {
+		t.Errorf("WritePrometheus() failed: expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestStatusWritePrometheus(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	globalStatusTable = performanceSchemaGlobalStatus
+
+	db, _ := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + performanceSchemaGlobalStatus: variableRows(
+			[]driver.Value{"Uptime", "251107"},
+			[]driver.Value{"Threads_running", "2"},
+			[]driver.Value{"Rpl_semi_sync_master_status", "OFF"},
+			[]driver.Value{"Ssl_cipher", nil},
+		),
+	})
+	defer db.Close()
+
+	var b strings.Builder
+	if err := NewStatus(db).WritePrometheus(&b); err != nil {
+		t.Fatalf("WritePrometheus() failed: %v", err)
+	}
+	expected := `# TYPE mysql_global_status untyped
+mysql_global_status{name="threads_running"} 2
+mysql_global_status{name="uptime"} 251107
+`
+	if got := b.String(); got != expected {
+		t.Errorf("WritePrometheus() failed: expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
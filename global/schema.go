@@ -0,0 +1,43 @@
+package global
+
+import (
+	"database/sql"
+	"sync"
+)
+
+var (
+	currentSchemaMu sync.RWMutex
+	currentSchema   string // the default schema of the connection, usually none
+)
+
+// DetectCurrentSchema returns the default schema of the connection using
+// SELECT DATABASE() and remembers it for CurrentSchema. An empty string is
+// returned if there is no default schema.
+func DetectCurrentSchema(dbh *sql.DB) (string, error) {
+	var schema sql.NullString
+
+	if err := dbh.QueryRow("SELECT DATABASE()").Scan(&schema); err != nil {
+		return "", err
+	}
+	SetCurrentSchema(schema.String)
+	return schema.String, nil
+}
+
+// SetCurrentSchema sets the schema returned by CurrentSchema, e.g. to one configured by the user
+func SetCurrentSchema(schema string) {
+	getLogger().Printf("global.SetCurrentSchema(%s)", schema)
+
+	currentSchemaMu.Lock()
+	defer currentSchemaMu.Unlock()
+
+	currentSchema = schema
+}
+
+// CurrentSchema returns the schema set by DetectCurrentSchema or SetCurrentSchema,
+// for use with lib.QualifiedTableNameRel
+func CurrentSchema() string {
+	currentSchemaMu.RLock()
+	defer currentSchemaMu.RUnlock()
+
+	return currentSchema
+}
@@ -0,0 +1,37 @@
+package global
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestDetectCurrentSchema(t *testing.T) {
+	defer SetCurrentSchema(CurrentSchema())
+
+	tests := []struct {
+		value    driver.Value
+		expected string
+	}{
+		{nil, ""},
+		{"sakila", "sakila"},
+	}
+	for _, test := range tests {
+		db, _ := newFakeDB(map[string]fakeResult{
+			"SELECT DATABASE()": {columns: []string{"DATABASE()"}, rows: [][]driver.Value{{test.value}}},
+		})
+		got, err := DetectCurrentSchema(db)
+		db.Close()
+
+		if err != nil || got != test.expected {
+			t.Errorf("DetectCurrentSchema(%v) failed: expected: %q, got %q (err: %v)", test.value, test.expected, got, err)
+		}
+		if CurrentSchema() != test.expected {
+			t.Errorf("CurrentSchema() failed: expected: %q, got %q", test.expected, CurrentSchema())
+		}
+	}
+
+	SetCurrentSchema("configured")
+	if got := CurrentSchema(); got != "configured" {
+		t.Errorf("SetCurrentSchema() failed: expected: %q, got %q", "configured", got)
+	}
+}
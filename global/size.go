@@ -0,0 +1,42 @@
+package global
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// multipliers of the size suffixes, 1024 based as used by MySQL
+var sizeMultipliers = map[string]uint64{
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// ParseSize parses a size in bytes with an optional K, M, G or T suffix
+// (in either case), e.g. 512K, 256M or 1G. A bare integer is a number of bytes.
+func ParseSize(s string) (uint64, error) {
+	number, multiplier := s, uint64(1)
+	if len(s) > 0 {
+		last := s[len(s)-1:]
+		if last < "0" || last > "9" {
+			m, ok := sizeMultipliers[strings.ToUpper(last)]
+			if !ok {
+				return 0, fmt.Errorf("invalid size %q: unknown suffix %q", s, last)
+			}
+			number, multiplier = s[:len(s)-1], m
+		}
+	}
+
+	value, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if value > math.MaxUint64/multiplier {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+
+	return value * multiplier, nil
+}
@@ -0,0 +1,34 @@
+package global
+
+import (
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		expected uint64
+		ok       bool
+	}{
+		{"0", 0, true},
+		{"134217728", 134217728, true},
+		{"512K", 512 * 1024, true},
+		{"512k", 512 * 1024, true},
+		{"256M", 256 * 1024 * 1024, true},
+		{"1G", 1024 * 1024 * 1024, true},
+		{"2T", 2 * 1024 * 1024 * 1024 * 1024, true},
+		{"1X", 0, false},
+		{"1.5G", 0, false},
+		{"-1M", 0, false},
+		{"G", 0, false},
+		{"", 0, false},
+		{"99999999999T", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := ParseSize(test.size)
+		if (err == nil) != test.ok || got != test.expected {
+			t.Errorf("ParseSize(%q) failed: expected: %v (ok: %v), got %v (err: %v)", test.size, test.expected, test.ok, got, err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package global
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// snapshot is the JSON representation of the variables
+type snapshot struct {
+	Source    string            `json:"source"`
+	Variables map[string]string `json:"variables"`
+}
+
+// WriteJSON writes the variables to w as a JSON object with the source they were
+// read from. The variables are sorted, one per line, so snapshots can be compared
+// with diff.
+func (v *Variables) WriteJSON(w io.Writer) error {
+	v.mu.RLock()
+	s := snapshot{Source: v.source, Variables: v.variables}
+	v.mu.RUnlock()
+	if s.Variables == nil {
+		s.Variables = make(map[string]string)
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ") // map keys are sorted by encoding/json
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// LoadJSON loads the variables from a snapshot written by WriteJSON
+func (v *Variables) LoadJSON(r io.Reader) error {
+	var s snapshot
+
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return fmt.Errorf("LoadJSON() failed with: %w", err)
+	}
+	if s.Variables == nil {
+		s.Variables = make(map[string]string)
+	}
+	v.set(s.Variables, s.Source)
+
+	return nil
+}
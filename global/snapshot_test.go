@@ -0,0 +1,41 @@
+package global
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	v := Variables{
+		variables: map[string]string{"version": "8.0.35", "max_connections": "151"},
+		source:    performanceSchemaGlobalVariables,
+	}
+	expected := `{
+  "source": "performance_schema.global_variables",
+  "variables": {
+    "max_connections": "151",
+    "version": "8.0.35"
+  }
+}
+`
+
+	var b strings.Builder
+	if err := v.WriteJSON(&b); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	if b.String() != expected {
+		t.Errorf("WriteJSON() failed: expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	var loaded Variables
+	if err := loaded.LoadJSON(strings.NewReader(b.String())); err != nil {
+		t.Fatalf("LoadJSON() failed: %v", err)
+	}
+	if !loaded.Equal(&v) || loaded.source != v.source {
+		t.Errorf("LoadJSON() failed: expected: %v from %s, got %v from %s", v.AsMap(), v.source, loaded.AsMap(), loaded.source)
+	}
+
+	if err := loaded.LoadJSON(strings.NewReader("not json")); err == nil {
+		t.Errorf("LoadJSON() failed: expected an error for invalid input")
+	}
+}
@@ -3,9 +3,10 @@ package global
 
 import (
 	"database/sql"
-	"log"
-
-	"github.com/sjmudd/ps-top/mylog"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 const (
@@ -13,22 +14,55 @@ const (
 	performanceSchemaGlobalStatus = "performance_schema.global_status"
 )
 
-// may be modified by usePerformanceSchema()
-var globalStatusTable = informationSchemaGlobalVariables
+// may be modified by usePerformanceSchema(), protected by sourceMu
+var globalStatusTable = informationSchemaGlobalStatus
+
+// likeEscaper escapes the characters which are special in a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLikePattern escapes s so it only matches itself in a LIKE pattern,
+// e.g. innodb_ matches innodb_ but not innodbX. The default \ escape
+// character is assumed.
+func escapeLikePattern(s string) string {
+	return likeEscaper.Replace(s)
+}
 
 // Status holds a handle to the database where the status can be queried
+// and the status last collected by SelectAll
 type Status struct {
-	dbh *sql.DB
+	dbh    *sql.DB
+	logger Logger            // nil to use the package logger
+	mu     sync.RWMutex      // protects values
+	values map[string]string // replaced, never modified, by SelectAll
+}
+
+// StatusSource returns the table the global status is read from
+func StatusSource() string {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+
+	return globalStatusTable
 }
 
-// NewStatus returns a *Status structure to the user
-func NewStatus(dbh *sql.DB) *Status {
+// NewStatus returns a *Status structure to the user. An optional logger may
+// be given, otherwise the package logger is used.
+func NewStatus(dbh *sql.DB, logger ...Logger) *Status {
+	status := &Status{
+		dbh:    dbh,
+		logger: firstLogger(logger),
+	}
 	if dbh == nil {
-		mylog.Fatal("NewStatus() dbh is nil")
+		status.log().Fatalf("NewStatus() dbh is nil")
 	}
-	return &Status{
-		dbh: dbh,
+	return status
+}
+
+// log returns the logger to use
+func (status *Status) log() Logger {
+	if status.logger != nil {
+		return status.logger
 	}
+	return getLogger()
 }
 
 /*
@@ -43,24 +77,135 @@ func NewStatus(dbh *sql.DB) *Status {
 
 // Get returns the value of the variable name requested (if found), or if not an error
 // - note: we assume we have checked a variable first as there's no logic here to switch between I_S and P_S
-func (status *Status) Get(name string) int {
+func (status *Status) Get(name string) (int, error) {
 	var value int
 
-	query := "SELECT VARIABLE_VALUE FROM information_schema.global_status WHERE VARIABLE_NAME = ?"
+	query := "SELECT VARIABLE_VALUE FROM " + StatusSource() + " WHERE VARIABLE_NAME = ?"
 
 	err := status.dbh.QueryRow(query, name).Scan(&value)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Println("Status.Get(" + name + "): no status with this name")
+		return 0, fmt.Errorf("Status.Get(%s): no status with this name", name)
 	case err != nil:
-		mylog.Fatal(err)
-	default:
-		// fmt.Println("value for", name, "is", value)
+		return 0, fmt.Errorf("unable to retrieve status for '%s': %w", name, err)
 	}
 
+	return value, nil
+}
+
+// ValuesWithPrefix returns the values of the status names starting with prefix,
+// e.g. "Com_", with the names lower-cased.
+func (status *Status) ValuesWithPrefix(prefix string) (map[string]int, error) {
+	pattern := escapeLikePattern(prefix) + "%"
+
+	return status.queryValues("SELECT VARIABLE_NAME, VARIABLE_VALUE FROM "+StatusSource()+" WHERE VARIABLE_NAME LIKE ?", pattern)
+}
+
+// Values returns the values of the given status names with the names lower-cased.
+// Names which are not found are not returned.
+func (status *Status) Values(names ...string) (map[string]int, error) {
+	if len(names) == 0 {
+		return make(map[string]int), nil
+	}
+
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		args = append(args, name)
+	}
+	query := "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + StatusSource() + " WHERE VARIABLE_NAME IN (?" + strings.Repeat(", ?", len(names)-1) + ")"
+
+	return status.queryValues(query, args...)
+}
+
+// queryValues returns the name/value pairs returned by query with the names lower-cased
+func (status *Status) queryValues(query string, args ...interface{}) (map[string]int, error) {
+	rows, err := status.dbh.Query(query, args...)
 	if err != nil {
-		mylog.Fatal("Unable to retrieve status for '"+name+"':", err)
+		return nil, fmt.Errorf("unable to retrieve status values: %w", err)
 	}
+	defer rows.Close()
 
-	return value
+	values := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var value int
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("unable to retrieve status values: %w", err)
+		}
+		values[strings.ToLower(name)] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve status values: %w", err)
+	}
+
+	return values, nil
+}
+
+// selectAll returns all the global status with the names lower-cased
+func (status *Status) selectAll() (map[string]string, error) {
+	rows, err := status.dbh.Query("SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + StatusSource())
+	if err != nil {
+		return nil, fmt.Errorf("Status.selectAll() query failed with: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("Status.selectAll() scan failed with: %w", err)
+		}
+		values[strings.ToLower(name)] = value.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Status.selectAll() failed with: %w", err)
+	}
+	return values, nil
+}
+
+// SelectAll collects all the global status and stores it for later use, e.g. by
+// Subtract. If collecting fails the error is returned and the previous status is kept.
+func (status *Status) SelectAll() error {
+	values, err := status.selectAll()
+	if err != nil {
+		return err
+	}
+
+	status.mu.Lock()
+	defer status.mu.Unlock()
+
+	status.values = values
+	return nil
+}
+
+// current returns the status last collected by SelectAll
+func (status *Status) current() map[string]string {
+	status.mu.RLock()
+	defer status.mu.RUnlock()
+
+	return status.values
+}
+
+// Subtract returns the change of each numeric status since previous was collected.
+// If a counter went backwards, e.g. after FLUSH STATUS, or is not in previous its
+// current value is used. Values which are not unsigned integers are skipped.
+func (status *Status) Subtract(previous *Status) map[string]uint64 {
+	var before map[string]string
+	if previous != nil {
+		before = previous.current()
+	}
+
+	deltas := make(map[string]uint64)
+	for name, value := range status.current() {
+		current, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		if earlier, err := strconv.ParseUint(before[name], 10, 64); err == nil && earlier <= current {
+			current -= earlier
+		}
+		deltas[name] = current
+	}
+	return deltas
 }
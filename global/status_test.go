@@ -0,0 +1,85 @@
+package global
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestStatusSubtract(t *testing.T) {
+	previous := &Status{values: map[string]string{
+		"questions":       "1000",
+		"com_select":      "500",
+		"uptime":          "3600",
+		"threads_running": "4",
+		"ssl_cipher":      "",
+	}}
+	current := &Status{values: map[string]string{
+		"questions":       "1250",
+		"com_select":      "20", // reset by FLUSH STATUS
+		"uptime":          "3610",
+		"threads_running": "4",
+		"com_insert":      "7", // not seen before
+		"ssl_cipher":      "TLS_AES_256_GCM_SHA384",
+		"rpl_status":      "OFF",
+	}}
+
+	expected := map[string]uint64{
+		"questions":       250,
+		"com_select":      20,
+		"uptime":          10,
+		"threads_running": 0,
+		"com_insert":      7,
+	}
+	if got := current.Subtract(previous); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Subtract() failed: expected: %v, got %v", expected, got)
+	}
+
+	expected = map[string]uint64{"questions": 1250, "com_select": 20, "uptime": 3610, "threads_running": 4, "com_insert": 7}
+	if got := current.Subtract(nil); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Subtract(nil) failed: expected: %v, got %v", expected, got)
+	}
+}
+
+func TestStatusSelectAll(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	globalStatusTable = informationSchemaGlobalStatus
+
+	db, _ := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalStatus: variableRows(
+			[]driver.Value{"Questions", "1000"},
+			[]driver.Value{"Ssl_cipher", nil},
+		),
+	})
+	defer db.Close()
+
+	status := NewStatus(db)
+	if err := status.SelectAll(); err != nil {
+		t.Fatalf("SelectAll() failed: %v", err)
+	}
+	if expected := map[string]string{"questions": "1000", "ssl_cipher": ""}; !reflect.DeepEqual(status.current(), expected) {
+		t.Errorf("SelectAll() failed: expected: %v, got %v", expected, status.current())
+	}
+}
+
+func TestEscapeLikePattern(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected string
+	}{
+		{"", ""},
+		{"Com_", `Com\_`},
+		{"100%", `100\%`},
+		{`a\b`, `a\\b`},
+		{`%_\`, `\%\_\\`},
+		{"odd`name", "odd`name"},
+		{"x' OR '1'='1", "x' OR '1'='1"}, // passed as an argument so quotes need no escaping
+	}
+	for _, test := range tests {
+		if got := escapeLikePattern(test.s); got != test.expected {
+			t.Errorf("escapeLikePattern(%q) failed: expected: %q, got %q", test.s, test.expected, got)
+		}
+	}
+}
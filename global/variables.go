@@ -2,17 +2,29 @@
 package global
 
 import (
+	"bufio"
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/sjmudd/ps-top/mylog"
+	"github.com/go-sql-driver/mysql"
 )
 
 const (
 	showCompatibility56ErrorNum    = 3167 // Error 3167: The 'INFORMATION_SCHEMA.GLOBAL_VARIABLES' feature is disabled; see the documentation for 'show_compatibility_56'
 	globalVariablesNotInISErrorNum = 1109 // Error 1109: Unknown table 'GLOBAL_VARIABLES' in information_schema
+	tableDoesNotExistErrorNum      = 1146 // Error 1146: Table 'performance_schema.global_variables' doesn't exist
+
+	showGlobalVariables = "SHOW GLOBAL VARIABLES" // used if neither table can be queried
 
 	informationSchemaGlobalVariables = "INFORMATION_SCHEMA.GLOBAL_VARIABLES"
 	performanceSchemaGlobalVariables = "performance_schema.global_variables"
@@ -21,114 +33,704 @@ const (
 // We expect to use I_S to query Global Variables. 5.7+ now wants us to use P_S,
 // so this variable will be changed if we see the show_compatibility_56 error message
 
+// ErrPerformanceSchemaDisabled is returned if performance_schema is needed but is disabled
+var ErrPerformanceSchemaDisabled = errors.New("performance_schema is disabled, configure performance_schema = 1 in /etc/my.cnf (or equivalent) and restart mysqld")
+
+// sourceMu protects the choice of where the global variables and status are
+// read from as it may be changed while other goroutines are querying them
+var sourceMu sync.RWMutex
+
 // globally used by Status and Variables
 var seenCompatibilityError bool
 
 // may be modified by usePerformanceSchema()
 var globalVariablesTable = informationSchemaGlobalVariables // default
 
+// set if neither the I_S nor the P_S table could be queried
+var useShowGlobalVariables bool
+
+// Querier is the part of *sql.DB used to collect the variables so that
+// tests may provide their own implementation
+type Querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
 // Variables holds the handle and variables collected from the database
 type Variables struct {
-	dbh       *sql.DB
-	variables map[string]string
+	dbh       Querier
+	logger    Logger            // nil to use the package logger
+	mu        sync.RWMutex      // protects variables and source
+	variables map[string]string // replaced, never modified, by SelectAll
+	source    string            // where the variables were read from
+	retries   int               // the retries made by the last SelectAllRetry
+	collected time.Time         // when SelectAll last succeeded
+	psEnabled bool              // performance_schema was seen to be enabled so need not be checked again
+
+	stmtMu    sync.Mutex // protects stmt and stmtQuery
+	stmt      *sql.Stmt  // prepared after SelectAll succeeds a second time to be used by later calls
+	stmtQuery string     // the query stmt was prepared for
 }
 
 // shared by Status and Variables
-// - no locking. Not sure if absolutely necessary.
 func usePerformanceSchema() {
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+
 	seenCompatibilityError = true
 	globalStatusTable = performanceSchemaGlobalStatus
 	globalVariablesTable = performanceSchemaGlobalVariables
 }
 
-// IsMysqlError returns true if the given error matches the expected number
-//   - format of MySQL error messages changed in database-sql-driver/mysql v1.7.0
-//     so adjusting code to handle the expected format
-//
+// Source holds where the global variables and status are read from and the
+// fallbacks tried, so they may be saved and restored when switching between
+// servers which need different tables
+type Source struct {
+	statusTable    string
+	variablesTable string
+	seenError      bool // the fallback to P_S has been made
+	useShow        bool // the fallback to SHOW GLOBAL VARIABLES has been made
+}
+
+// CurrentSource returns where the global variables and status are read from
+func CurrentSource() Source {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+
+	return Source{
+		statusTable:    globalStatusTable,
+		variablesTable: globalVariablesTable,
+		seenError:      seenCompatibilityError,
+		useShow:        useShowGlobalVariables,
+	}
+}
+
+// SetSource reads the global variables and status from the source returned
+// by CurrentSource. The zero Source starts again from information_schema
+// without any fallbacks, as before probing a new server.
+func SetSource(source Source) {
+	if source.statusTable == "" || source.variablesTable == "" {
+		source.statusTable, source.variablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+	}
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+
+	globalStatusTable = source.statusTable
+	globalVariablesTable = source.variablesTable
+	seenCompatibilityError = source.seenError
+	useShowGlobalVariables = source.useShow
+}
+
+// mysqlErrorRE matches the number of a MySQL error message, with the SQLSTATE
+// included since database-sql-driver/mysql v1.7.0, e.g.
 // Error 1109 (42S02): Unknown table 'GLOBAL_VARIABLES' in information_schema
+var mysqlErrorRE = regexp.MustCompile(`^Error (\d+)(?: \([^)]*\))?:`)
+
+// IsMysqlError returns true if the given error matches the expected number.
+// The driver's *mysql.MySQLError is checked if it can be unwrapped, otherwise the
+// number is taken from the error message.
 func IsMysqlError(err error, wantedErrNum int) bool {
-	s := err.Error()
-	if len(s) < 19 {
+	if err == nil {
 		return false
 	}
-	if !strings.HasPrefix(s, "Error ") {
-		return false
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return int(mysqlErr.Number) == wantedErrNum
 	}
-	if s[10] != ' ' {
+
+	matches := mysqlErrorRE.FindStringSubmatch(err.Error())
+	if matches == nil {
 		return false
 	}
-	if s[18] != ':' {
+	num, convErr := strconv.Atoi(matches[1])
+	if convErr != nil {
 		return false
 	}
-	errNumStr := s[6:10]
-	num, err := strconv.Atoi(errNumStr)
+	return num == wantedErrNum
+}
+
+// Errors returned when the user may not read a table or a whole schema
+const (
+	tableAccessDeniedErrorNum    = 1142
+	databaseAccessDeniedErrorNum = 1044
+)
+
+// IsAccessDenied returns true if the error says the user lacks the privileges
+// to read a table or schema, e.g. performance_schema on a managed server.
+func IsAccessDenied(err error) bool {
+	return IsMysqlError(err, tableAccessDeniedErrorNum) ||
+		IsMysqlError(err, databaseAccessDeniedErrorNum)
+}
+
+// NewVariables returns a pointer to an initialised Variables structure. An optional
+// logger may be given, otherwise the package logger is used.
+func NewVariables(dbh Querier, logger ...Logger) *Variables {
+	v := &Variables{
+		dbh:    dbh,
+		logger: firstLogger(logger),
+	}
+	if db, ok := dbh.(*sql.DB); dbh == nil || (ok && db == nil) {
+		v.log().Fatalf("NewVariables(): dbh == nil")
+	}
+	return v
+}
+
+// NewRecordedVariables returns Variables holding the given values instead of
+// ones collected from a server, e.g. when replaying recorded data. The keys
+// are lower-cased as in SelectAll.
+func NewRecordedVariables(variables map[string]string) *Variables {
+	v := new(Variables)
+	hashref := make(map[string]string, len(variables))
+	for key, value := range variables {
+		hashref[strings.ToLower(key)] = value
+	}
+	v.set(hashref, "recorded")
+
+	return v
+}
+
+// log returns the logger to use
+func (v *Variables) log() Logger {
+	if v.logger != nil {
+		return v.logger
+	}
+	return getLogger()
+}
+
+// current returns the variables last collected
+func (v *Variables) current() map[string]string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.variables
+}
+
+// set replaces the variables and records where they came from
+func (v *Variables) set(variables map[string]string, source string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.variables = variables
+	v.source = source
+}
+
+// AsMap returns a copy of the variables last collected, an empty map if none have been
+func (v *Variables) AsMap() map[string]string {
+	variables := v.current()
+
+	copied := make(map[string]string, len(variables))
+	for key, value := range variables {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Get returns the value of the given variable if found or an empty string if not.
+// The name is looked up lower-cased and trimmed as the variables are stored.
+func (v *Variables) Get(key string) string {
+	result, _ := v.lookup(key)
+	return result
+}
+
+// lookup returns the value of the given variable and whether it was found
+// after lower-casing and trimming the name
+func (v *Variables) lookup(key string) (string, bool) {
+	value, ok := v.current()[strings.ToLower(strings.TrimSpace(key))]
+	return value, ok
+}
+
+// PerformanceSchemaEnabled returns true if the performance_schema variable is ON
+func (v *Variables) PerformanceSchemaEnabled() bool {
+	enabled, ok := v.GetBool("performance_schema")
+	return ok && enabled
+}
+
+// ServerVersion returns the parsed version of the server from the version variable
+func (v *Variables) ServerVersion() (ServerVersion, error) {
+	version, ok := v.current()["version"]
+	if !ok {
+		return ServerVersion{}, errors.New("the version variable has not been collected")
+	}
+	return ParseServerVersion(version)
+}
+
+// GetInt returns the value of the given variable as an int64 and true,
+// or false if the variable is not found or is not an integer.
+func (v *Variables) GetInt(key string) (int64, bool) {
+	value, ok := v.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	return i, err == nil
+}
+
+// GetUint returns the value of the given variable as a uint64 and true,
+// or false if the variable is not found or is not an unsigned integer.
+// Sizes with a K, M, G or T suffix, e.g. 256M, are expanded to bytes.
+func (v *Variables) GetUint(key string) (uint64, bool) {
+	value, ok := v.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	u, err := strconv.ParseUint(value, 10, 64)
 	if err != nil {
+		u, err = ParseSize(value)
+	}
+	return u, err == nil
+}
+
+// GetFloat returns the value of the given variable as a float64 and true,
+// or false if the variable is not found or is not a number.
+func (v *Variables) GetFloat(key string) (float64, bool) {
+	value, ok := v.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
+
+// NumericVariable is a variable with a numeric value
+type NumericVariable struct {
+	Name  string
+	Value float64
+}
+
+// NumericSorted returns the variables with numeric values, parsed as by GetFloat,
+// sorted by value descending and then by name. NaN and infinite values are dropped.
+func (v *Variables) NumericSorted() []NumericVariable {
+	var numeric []NumericVariable
+	for name, value := range v.AsMap() {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			continue
+		}
+		numeric = append(numeric, NumericVariable{Name: name, Value: f})
+	}
+
+	sort.Slice(numeric, func(i, j int) bool {
+		if numeric[i].Value != numeric[j].Value {
+			return numeric[i].Value > numeric[j].Value
+		}
+		return numeric[i].Name < numeric[j].Name
+	})
+	return numeric
+}
+
+// GetBool returns the value of the given variable as a bool and true, or
+// false if the variable is not found or is not a boolean. MySQL reports
+// booleans as ON/OFF, YES/NO, 1/0 or TRUE/FALSE in any case.
+func (v *Variables) GetBool(key string) (bool, bool) {
+	value, ok := v.lookup(key)
+	if !ok {
+		return false, false
+	}
+	switch strings.ToUpper(value) {
+	case "ON", "YES", "1", "TRUE":
+		return true, true
+	case "OFF", "NO", "0", "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// VariablesSource returns where the global variables are read from:
+// the table name or SHOW GLOBAL VARIABLES.
+func VariablesSource() string {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+
+	if useShowGlobalVariables {
+		return showGlobalVariables
+	}
+	return globalVariablesTable
+}
+
+// Diff returns the variables which differ from the previous snapshot as {old, new} pairs.
+// Added variables have an empty old value and removed ones an empty new value.
+func (v *Variables) Diff(previous *Variables) map[string][2]string {
+	changes := make(map[string][2]string)
+
+	var old map[string]string
+	if previous != nil {
+		old = previous.current()
+	}
+	variables := v.current()
+	for key, value := range variables {
+		if oldValue, ok := old[key]; !ok || oldValue != value {
+			changes[key] = [2]string{oldValue, value}
+		}
+	}
+	for key, oldValue := range old {
+		if _, ok := variables[key]; !ok {
+			changes[key] = [2]string{oldValue, ""}
+		}
+	}
+
+	return changes
+}
+
+// Equal returns true if both have the same variables with the same values. A nil
+// *Variables is treated as having no variables. A pointer receiver is used as
+// Variables holds a mutex which must not be copied.
+func (v *Variables) Equal(other *Variables) bool {
+	var variables, others map[string]string
+	if v != nil {
+		variables = v.current()
+	}
+	if other != nil {
+		others = other.current()
+	}
+	if len(variables) != len(others) {
 		return false
 	}
-	return num == wantedErrNum
+	for key, value := range variables {
+		if otherValue, ok := others[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
 }
 
-// NewVariables returns a pointer to an initialised Variables structure
-func NewVariables(dbh *sql.DB) *Variables {
-	if dbh == nil {
-		mylog.Fatal("NewVariables(): dbh == nil")
+// LoadFromReader loads the variables from the output of SHOW GLOBAL VARIABLES
+// saved by the mysql client, either tab separated (batch mode) or as a table with
+// | separated columns. Lines without exactly two fields, such as the table borders,
+// are skipped as is the heading. The keys are lower-cased as in SelectAll.
+func (v *Variables) LoadFromReader(r io.Reader) error {
+	hashref := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // some values, e.g. optimizer_switch, are long
+	for scanner.Scan() {
+		variable, value, ok := splitVariableLine(scanner.Text())
+		if !ok || variable == "" || strings.EqualFold(variable, "Variable_name") {
+			continue
+		}
+		hashref[strings.ToLower(variable)] = value
 	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("LoadFromReader() failed with: %w", err)
+	}
+	v.log().Printf("LoadFromReader() loaded %d variables", len(hashref))
+	v.set(hashref, showGlobalVariables)
+
+	return nil
+}
 
-	return &Variables{
-		dbh: dbh,
+// splitVariableLine returns the name and value of a tab or | separated line
+func splitVariableLine(line string) (string, string, bool) {
+	line = strings.TrimRight(line, "\r")
+
+	var fields []string
+	if strings.Contains(line, "\t") {
+		fields = strings.Split(line, "\t")
+	} else {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "|") || !strings.HasSuffix(trimmed, "|") || len(trimmed) < 2 {
+			return "", "", false
+		}
+		fields = strings.Split(trimmed[1:len(trimmed)-1], "|")
+	}
+	if len(fields) != 2 {
+		return "", "", false
 	}
+
+	return strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), true
 }
 
-// Get returns the value of the given variable if found or an empty string if not.
-func (v Variables) Get(key string) string {
-	var result string
-	var ok bool
+// variablesQuery returns the query used to collect the global variables.
+// SHOW GLOBAL VARIABLES returns the same columns as the tables.
+func variablesQuery() string {
+	if source := VariablesSource(); source != showGlobalVariables {
+		return "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + source
+	}
+	return showGlobalVariables
+}
+
+// fallbacksTried returns whether the fallbacks to P_S and to SHOW GLOBAL VARIABLES have been made
+func fallbacksTried() (performanceSchema, show bool) {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+
+	return seenCompatibilityError, useShowGlobalVariables
+}
+
+// useShow makes SHOW GLOBAL VARIABLES be used to collect the variables
+func useShow() {
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
 
-	if result, ok = v.variables[key]; !ok {
-		result = ""
+	useShowGlobalVariables = true
+}
+
+// isTableUnavailable returns true if the error shows the global variables table can't be used
+func isTableUnavailable(err error) bool {
+	return IsMysqlError(err, showCompatibility56ErrorNum) ||
+		IsMysqlError(err, globalVariablesNotInISErrorNum) ||
+		IsMysqlError(err, tableDoesNotExistErrorNum)
+}
+
+// queryVariables runs the query collecting the variables from the current source,
+// returning ErrPerformanceSchemaDisabled rather than querying performance_schema if
+// it is disabled. This is only checked until it is seen to be enabled as it can't
+// change without restarting the server. If prefix is given the tables are only
+// asked for the variables starting with it.
+func (v *Variables) queryVariables(ctx context.Context, prefix string) (*sql.Rows, string, error) {
+	query := variablesQuery()
+	var args []any
+	if prefix != "" && query != showGlobalVariables {
+		query += " WHERE VARIABLE_NAME LIKE ?"
+		args = append(args, escapeLikePattern(prefix)+"%")
 	}
+	v.log().Printf("query: %s", query)
 
-	return result
+	if VariablesSource() == performanceSchemaGlobalVariables && !v.performanceSchemaSeen() {
+		var enabled int
+		err := v.dbh.QueryRowContext(ctx, "SELECT @@performance_schema").Scan(&enabled)
+		if err == nil && enabled == 0 {
+			return nil, query, ErrPerformanceSchemaDisabled
+		}
+		if err == nil {
+			v.mu.Lock()
+			v.psEnabled = true
+			v.mu.Unlock()
+		}
+	}
+
+	if prefix == "" {
+		if stmt := v.preparedFor(query); stmt != nil {
+			rows, err := stmt.QueryContext(ctx)
+			return rows, query, err
+		}
+	}
+	rows, err := v.dbh.QueryContext(ctx, query, args...)
+	return rows, query, err
+}
+
+// performanceSchemaSeen returns true if performance_schema was seen to be enabled
+func (v *Variables) performanceSchemaSeen() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.psEnabled
+}
+
+// preparedFor returns the prepared statement if it was prepared for query, otherwise nil
+func (v *Variables) preparedFor(query string) *sql.Stmt {
+	v.stmtMu.Lock()
+	defer v.stmtMu.Unlock()
+
+	if v.stmtQuery != query {
+		return nil
+	}
+	return v.stmt
+}
+
+// prepare prepares query for later calls to SelectAll if it isn't already, closing
+// any statement prepared for a previous source. Failing to prepare is not an error
+// as the query is then run as before.
+func (v *Variables) prepare(ctx context.Context, query string) {
+	v.stmtMu.Lock()
+	defer v.stmtMu.Unlock()
+
+	if v.stmt != nil && v.stmtQuery == query {
+		return
+	}
+	if v.stmt != nil {
+		_ = v.stmt.Close()
+		v.stmt, v.stmtQuery = nil, ""
+	}
+	stmt, err := v.dbh.PrepareContext(ctx, query)
+	if err != nil {
+		v.log().Printf("Variables.prepare() unable to prepare %s: %v", query, err)
+		return
+	}
+	v.stmt, v.stmtQuery = stmt, query
+}
+
+// Close closes the statement prepared by SelectAll, if any. It should be called
+// once a Variables collected repeatedly is no longer needed.
+func (v *Variables) Close() error {
+	v.stmtMu.Lock()
+	defer v.stmtMu.Unlock()
+
+	if v.stmt == nil {
+		return nil
+	}
+	err := v.stmt.Close()
+	v.stmt, v.stmtQuery = nil, ""
+	return err
 }
 
 // SelectAll collects all variables from the database and stores for later use.
 // - all returned keys are lower-cased.
-func (v *Variables) SelectAll() *Variables {
+// - I_S is tried first, then P_S and finally SHOW GLOBAL VARIABLES.
+// - if collecting fails the error is returned and any previously collected variables are kept.
+func (v *Variables) SelectAll() (*Variables, error) {
+	return v.SelectAllContext(context.Background())
+}
+
+// SelectAllContext collects all variables like SelectAll using ctx for all the queries
+// so they may be cancelled or given a deadline.
+func (v *Variables) SelectAllContext(ctx context.Context) (*Variables, error) {
+	hashref, err := v.selectVariables(ctx, "")
+	if err != nil {
+		return v, err
+	}
+	v.set(hashref, VariablesSource())
+
+	v.mu.Lock()
+	again := !v.collected.IsZero()
+	v.collected = time.Now()
+	v.mu.Unlock()
+
+	// only prepare the query once it is collected repeatedly, so Variables
+	// collected once, e.g. at startup, don't keep a statement on the server
+	if again {
+		v.prepare(ctx, variablesQuery())
+	}
+
+	return v, nil
+}
+
+// SelectByPrefix collects the variables whose names start with prefix like
+// SelectAll, replacing those collected before and keeping the others.
+func (v *Variables) SelectByPrefix(prefix string) (*Variables, error) {
+	selected, err := v.selectVariables(context.Background(), prefix)
+	if err != nil {
+		return v, err
+	}
+
+	hashref := v.AsMap()
+	for name := range hashref {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) {
+			delete(hashref, name)
+		}
+	}
+	for name, value := range selected {
+		hashref[name] = value
+	}
+	v.set(hashref, VariablesSource())
+
+	return v, nil
+}
+
+// selectVariables returns the variables starting with prefix, all of them if it
+// is empty, with lower-cased names. The fallbacks are made as described in SelectAll.
+func (v *Variables) selectVariables(ctx context.Context, prefix string) (map[string]string, error) {
 	hashref := make(map[string]string)
 
-	query := "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + globalVariablesTable
-	log.Println("query:", query)
+	err := v.forEach(ctx, prefix, func(name, value string) error {
+		hashref[name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.log().Printf("selectAll() result has %d rows", len(hashref))
 
-	rows, err := v.dbh.Query(query)
+	return hashref, nil
+}
+
+// ForEach collects the variables like SelectAllContext calling fn with each lower-cased
+// name and value instead of storing them. If fn returns an error no further rows are
+// read and the error is returned.
+func (v *Variables) ForEach(ctx context.Context, fn func(name, value string) error) error {
+	return v.forEach(ctx, "", fn)
+}
+
+// forEach calls fn for each variable starting with prefix as described in ForEach
+func (v *Variables) forEach(ctx context.Context, prefix string, fn func(name, value string) error) error {
+	rows, query, err := v.queryVariables(ctx, prefix)
 	if err != nil {
-		if !seenCompatibilityError && (IsMysqlError(err, showCompatibility56ErrorNum) || IsMysqlError(err, globalVariablesNotInISErrorNum)) {
-			log.Println("selectAll() I_S query failed, trying with P_S")
+		if seen, _ := fallbacksTried(); !seen && (IsMysqlError(err, showCompatibility56ErrorNum) || IsMysqlError(err, globalVariablesNotInISErrorNum)) {
+			v.log().Printf("selectAll() I_S query failed, trying with P_S")
 			usePerformanceSchema()
-			query = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + globalVariablesTable
-			log.Println("query:", query)
-
-			rows, err = v.dbh.Query(query)
+			rows, query, err = v.queryVariables(ctx, prefix)
+		}
+		if seen, show := fallbacksTried(); err != nil && seen && !show && isTableUnavailable(err) {
+			v.log().Printf("selectAll() P_S query failed, trying with %s", showGlobalVariables)
+			useShow()
+			rows, query, err = v.queryVariables(ctx, prefix)
 		}
 		if err != nil {
-			mylog.Fatal("selectAll() query", query, "failed with:", err)
+			return fmt.Errorf("selectAll() query %s failed with: %w", query, err)
 		}
 	}
-	log.Println("selectAll() query succeeded")
+	v.log().Printf("selectAll() query succeeded")
 	defer rows.Close()
 
+	prefix = strings.ToLower(prefix)
 	for rows.Next() {
-		var variable, value string
+		var variable string
+		var value sql.NullString // a few variables may be NULL, pass these as ""
 		if err := rows.Scan(&variable, &value); err != nil {
-			mylog.Fatal(err)
+			return fmt.Errorf("selectAll() scan failed with: %w", err)
+		}
+		variable = strings.ToLower(variable)
+		if !strings.HasPrefix(variable, prefix) { // SHOW GLOBAL VARIABLES returns them all
+			continue
+		}
+		if err := fn(variable, value.String); err != nil {
+			return err
 		}
-		hashref[strings.ToLower(variable)] = value
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return fmt.Errorf("selectAll() failed with: %w", err)
+	}
+	return nil
+}
+
+// SelectAllCached collects all variables like SelectAll unless they were
+// last collected successfully less than ttl ago, when they are kept.
+func (v *Variables) SelectAllCached(ttl time.Duration) (*Variables, error) {
+	if collected := v.Collected(); !collected.IsZero() && time.Since(collected) < ttl {
+		return v, nil
 	}
-	log.Println("selectAll() result has", len(hashref), "rows")
+	return v.SelectAll()
+}
 
-	v.variables = hashref
+// Collected returns when the variables were last collected successfully
+// from the database, the zero time if they have not been
+func (v *Variables) Collected() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 
-	return v
+	return v.collected
+}
+
+// SelectAllRetry collects all variables like SelectAllContext making up to attempts
+// tries if the connection fails, waiting backoff before the first retry and doubling
+// it for each later one. Other errors are returned at once.
+func (v *Variables) SelectAllRetry(ctx context.Context, attempts int, backoff time.Duration) (*Variables, error) {
+	var err error
+	for retries := 0; ; retries++ {
+		v.mu.Lock()
+		v.retries = retries
+		v.mu.Unlock()
+
+		if _, err = v.SelectAllContext(ctx); err == nil || !IsConnectionLost(err) || retries+1 >= attempts {
+			return v, err
+		}
+		v.log().Printf("SelectAllRetry() retrying in %v after: %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return v, fmt.Errorf("SelectAllRetry() cancelled after %v: %w", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Retries returns the number of retries made by the current or last SelectAllRetry
+func (v *Variables) Retries() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.retries
 }
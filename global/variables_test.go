@@ -0,0 +1,857 @@
+package global
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestVariablesTypedGet(t *testing.T) {
+	v := Variables{
+		variables: map[string]string{
+			"max_connections":         "151",
+			"auto_increment_offset":   "-1",
+			"long_query_time":         "10.000000",
+			"performance_schema":      "ON",
+			"have_ssl":                "YES",
+			"autocommit":              "1",
+			"sql_safe_updates":        "false",
+			"read_only":               "off",
+			"version":                 "8.0.35",
+			"innodb_buffer_pool_size": "1G",
+			"sort_buffer_size":        "256x",
+		},
+	}
+
+	intTests := []struct {
+		key      string
+		expected int64
+		ok       bool
+	}{
+		{"max_connections", 151, true},
+		{"auto_increment_offset", -1, true},
+		{"version", 0, false},
+		{"missing", 0, false},
+	}
+	for _, test := range intTests {
+		if got, ok := v.GetInt(test.key); got != test.expected || ok != test.ok {
+			t.Errorf("GetInt(%q) failed: expected: %v, %v, got %v, %v", test.key, test.expected, test.ok, got, ok)
+		}
+	}
+
+	uintTests := []struct {
+		key      string
+		expected uint64
+		ok       bool
+	}{
+		{"max_connections", 151, true},
+		{"auto_increment_offset", 0, false},
+		{"innodb_buffer_pool_size", 1 << 30, true},
+		{"sort_buffer_size", 0, false},
+		{"missing", 0, false},
+	}
+	for _, test := range uintTests {
+		if got, ok := v.GetUint(test.key); got != test.expected || ok != test.ok {
+			t.Errorf("GetUint(%q) failed: expected: %v, %v, got %v, %v", test.key, test.expected, test.ok, got, ok)
+		}
+	}
+
+	floatTests := []struct {
+		key      string
+		expected float64
+		ok       bool
+	}{
+		{"long_query_time", 10, true},
+		{"max_connections", 151, true},
+		{"performance_schema", 0, false},
+		{"missing", 0, false},
+	}
+	for _, test := range floatTests {
+		if got, ok := v.GetFloat(test.key); got != test.expected || ok != test.ok {
+			t.Errorf("GetFloat(%q) failed: expected: %v, %v, got %v, %v", test.key, test.expected, test.ok, got, ok)
+		}
+	}
+
+	boolTests := []struct {
+		key      string
+		expected bool
+		ok       bool
+	}{
+		{"performance_schema", true, true},
+		{"have_ssl", true, true},
+		{"autocommit", true, true},
+		{"sql_safe_updates", false, true},
+		{"read_only", false, true},
+		{"max_connections", false, false},
+		{"missing", false, false},
+	}
+	for _, test := range boolTests {
+		if got, ok := v.GetBool(test.key); got != test.expected || ok != test.ok {
+			t.Errorf("GetBool(%q) failed: expected: %v, %v, got %v, %v", test.key, test.expected, test.ok, got, ok)
+		}
+	}
+}
+
+func TestVariablesGet(t *testing.T) {
+	v := Variables{variables: map[string]string{"max_connections": "151", "init_connect": ""}}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"max_connections", "151"},
+		{"Max_Connections", "151"},
+		{"MAX_CONNECTIONS", "151"},
+		{" max_connections\t", "151"},
+		{"init_connect", ""},
+		{"missing", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := v.Get(test.key); got != test.expected {
+			t.Errorf("Get(%q) failed: expected: %q, got %q", test.key, test.expected, got)
+		}
+	}
+	if got, ok := v.GetInt("Max_Connections"); got != 151 || !ok {
+		t.Errorf("GetInt(%q) failed: expected: 151, true, got %v, %v", "Max_Connections", got, ok)
+	}
+}
+
+func TestVariablesDiff(t *testing.T) {
+	previous := &Variables{variables: map[string]string{
+		"max_connections": "151",
+		"read_only":       "OFF",
+		"old_variable":    "1",
+	}}
+	current := &Variables{variables: map[string]string{
+		"max_connections": "500",
+		"read_only":       "OFF",
+		"new_variable":    "2",
+	}}
+
+	expected := map[string][2]string{
+		"max_connections": {"151", "500"},
+		"new_variable":    {"", "2"},
+		"old_variable":    {"1", ""},
+	}
+	if got := current.Diff(previous); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Diff() failed: expected: %v, got %v", expected, got)
+	}
+	if got := current.Diff(current); len(got) != 0 {
+		t.Errorf("Diff() failed: expected no changes, got %v", got)
+	}
+	if got := current.Diff(nil); len(got) != 3 {
+		t.Errorf("Diff(nil) failed: expected all variables as added, got %v", got)
+	}
+}
+
+// variableRows returns the rows of a global variables query
+func variableRows(rows ...[]driver.Value) fakeResult {
+	return fakeResult{columns: []string{"VARIABLE_NAME", "VARIABLE_VALUE"}, rows: rows}
+}
+
+func TestSelectAllConcurrentGet(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	SetServerVersion(ServerVersion{Version{8, 0, 35}, FlavorMySQL})
+
+	db, _ := newFakeDB(map[string]fakeResult{
+		"SELECT @@performance_schema": {columns: []string{"@@performance_schema"}, rows: [][]driver.Value{{int64(1)}}},
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + performanceSchemaGlobalVariables: variableRows(
+			[]driver.Value{"MAX_CONNECTIONS", "151"},
+			[]driver.Value{"version", "8.0.35"},
+		),
+	})
+	defer db.Close()
+
+	v := NewVariables(db)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := v.SelectAll(); err != nil {
+				t.Errorf("SelectAll() failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = v.Get("max_connections")
+			_ = VariablesSource()
+		}()
+	}
+	wg.Wait()
+
+	if got := v.Get("max_connections"); got != "151" {
+		t.Errorf("Get(max_connections) failed: expected: 151, got %q", got)
+	}
+}
+
+func TestSelectAllNullValue(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	SetServerVersion(ServerVersion{Version{8, 0, 35}, FlavorMySQL})
+
+	db, _ := newFakeDB(map[string]fakeResult{
+		"SELECT @@performance_schema": {columns: []string{"@@performance_schema"}, rows: [][]driver.Value{{int64(1)}}},
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + performanceSchemaGlobalVariables: variableRows(
+			[]driver.Value{"MAX_CONNECTIONS", "151"},
+			[]driver.Value{"some_optional_variable", nil},
+			[]driver.Value{"version", "8.0.35"},
+		),
+	})
+	defer db.Close()
+
+	v, err := NewVariables(db).SelectAll()
+	if err != nil {
+		t.Fatalf("SelectAll() failed: %v", err)
+	}
+	expected := map[string]string{
+		"max_connections":        "151",
+		"some_optional_variable": "",
+		"version":                "8.0.35",
+	}
+	if got := v.AsMap(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("SelectAll() failed: expected: %v, got %v", expected, got)
+	}
+}
+
+func TestVariablesEqual(t *testing.T) {
+	base := &Variables{variables: map[string]string{"max_connections": "151", "init_connect": ""}}
+
+	tests := []struct {
+		name     string
+		v        *Variables
+		other    *Variables
+		expected bool
+	}{
+		{"same", base, &Variables{variables: map[string]string{"max_connections": "151", "init_connect": ""}}, true},
+		{"itself", base, base, true},
+		{"changed value", base, &Variables{variables: map[string]string{"max_connections": "200", "init_connect": ""}}, false},
+		{"missing key", base, &Variables{variables: map[string]string{"max_connections": "151"}}, false},
+		{"other key", base, &Variables{variables: map[string]string{"max_connections": "151", "port": ""}}, false},
+		{"nil other", base, nil, false},
+		{"both nil", nil, nil, true},
+		{"nil and empty", nil, &Variables{}, true},
+		{"empty and nil", &Variables{}, nil, true},
+	}
+	for _, test := range tests {
+		if got := test.v.Equal(test.other); got != test.expected {
+			t.Errorf("Equal(%s) failed: expected: %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestLoadFromReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			"batch",
+			"Variable_name\tValue\n" +
+				"max_connections\t151\n" +
+				"version_comment\tMySQL Community Server - GPL\n" +
+				"init_connect\t\n" +
+				"bad line\n" +
+				"a\tb\tc\n",
+		},
+		{
+			"table",
+			"+-----------------+------------------------------+\n" +
+				"| Variable_name   | Value                        |\n" +
+				"+-----------------+------------------------------+\n" +
+				"| max_connections | 151                          |\n" +
+				"| version_comment | MySQL Community Server - GPL |\n" +
+				"| init_connect    |                              |\n" +
+				"| a | b | c |\n" +
+				"+-----------------+------------------------------+\n" +
+				"3 rows in set (0.00 sec)\n",
+		},
+	}
+	expected := map[string]string{
+		"max_connections": "151",
+		"version_comment": "MySQL Community Server - GPL",
+		"init_connect":    "",
+	}
+
+	for _, test := range tests {
+		var v Variables
+		if err := v.LoadFromReader(strings.NewReader(test.input)); err != nil {
+			t.Errorf("LoadFromReader(%s) failed: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(v.current(), expected) {
+			t.Errorf("LoadFromReader(%s) failed: expected: %v, got %v", test.name, expected, v.current())
+		}
+	}
+}
+
+func TestAsMap(t *testing.T) {
+	var empty Variables
+	if got := empty.AsMap(); got == nil || len(got) != 0 {
+		t.Errorf("AsMap() failed: expected an empty map, got %#v", got)
+	}
+
+	v := Variables{variables: map[string]string{"max_connections": "151"}}
+	copied := v.AsMap()
+	copied["max_connections"] = "1"
+	if got := v.Get("max_connections"); got != "151" {
+		t.Errorf("AsMap() failed: changing the copy changed the variables to %q", got)
+	}
+}
+
+func TestNumericSorted(t *testing.T) {
+	v := Variables{variables: map[string]string{
+		"max_connections":   "151",
+		"long_query_time":   "10.000000",
+		"version":           "8.0.36",
+		"sql_mode":          "STRICT_TRANS_TABLES",
+		"port":              "3306",
+		"back_log":          "151",
+		"innodb_page_size":  "16384",
+		"offset":            "-1",
+		"binlog_cache_size": "32K", // not a plain number
+		"not_a_number":      "NaN",
+		"empty":             "",
+	}}
+	want := []NumericVariable{
+		{"innodb_page_size", 16384},
+		{"port", 3306},
+		{"back_log", 151}, // ties sorted by name
+		{"max_connections", 151},
+		{"long_query_time", 10},
+		{"offset", -1},
+	}
+
+	for i := 0; i < 10; i++ { // map ordering varies so repeat to check the order is stable
+		if got := v.NumericSorted(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("NumericSorted() failed: expected %v, got %v", want, got)
+		}
+	}
+
+	var empty Variables
+	if got := empty.NumericSorted(); len(got) != 0 {
+		t.Errorf("NumericSorted() failed: expected no variables, got %v", got)
+	}
+}
+
+func TestSelectAllPerformanceSchemaDisabled(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+	db, connector := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: {
+			err: errors.New("Error 3167 (HY000): The 'INFORMATION_SCHEMA.GLOBAL_VARIABLES' feature is disabled"),
+		},
+		"SELECT @@performance_schema": {columns: []string{"@@performance_schema"}, rows: [][]driver.Value{{int64(0)}}},
+	})
+	defer db.Close()
+
+	_, err := NewVariables(db).SelectAll()
+	if !errors.Is(err, ErrPerformanceSchemaDisabled) {
+		t.Errorf("SelectAll() failed: expected ErrPerformanceSchemaDisabled, got %v", err)
+	}
+	for _, query := range connector.Queries() {
+		if strings.Contains(query, performanceSchemaGlobalVariables) {
+			t.Errorf("SelectAll() failed: unexpected query %q with performance_schema disabled", query)
+		}
+	}
+}
+
+// recordingQuerier records the queries run through it before running them with db
+type recordingQuerier struct {
+	db      *sql.DB
+	mu      sync.Mutex
+	queries []string
+}
+
+func (q *recordingQuerier) record(query string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queries = append(q.queries, query)
+}
+
+func (q *recordingQuerier) Query(query string, args ...any) (*sql.Rows, error) {
+	q.record(query)
+	return q.db.Query(query, args...)
+}
+
+func (q *recordingQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	q.record(query)
+	return q.db.QueryContext(ctx, query, args...)
+}
+
+func (q *recordingQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	q.record(query)
+	return q.db.QueryRowContext(ctx, query, args...)
+}
+
+// PrepareContext is not recorded as the queries of the statement are not run through q
+func (q *recordingQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return q.db.PrepareContext(ctx, query)
+}
+
+func TestSelectAllFallbacks(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+
+	const (
+		isQuery = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables
+		psCheck = "SELECT @@performance_schema"
+		psQuery = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + performanceSchemaGlobalVariables
+	)
+	psEnabled := fakeResult{columns: []string{"@@performance_schema"}, rows: [][]driver.Value{{int64(1)}}}
+	tableMissing := fakeResult{err: errors.New("Error 1146 (42S02): Table 'performance_schema.global_variables' doesn't exist")}
+	rows := variableRows([]driver.Value{"max_connections", "151"})
+
+	tests := []struct {
+		name     string
+		results  map[string]fakeResult
+		expected []string
+		source   string
+	}{
+		{
+			"information_schema",
+			map[string]fakeResult{isQuery: rows},
+			[]string{isQuery},
+			informationSchemaGlobalVariables,
+		},
+		{
+			"3167 then performance_schema",
+			map[string]fakeResult{
+				isQuery: {err: errors.New("Error 3167 (HY000): The 'INFORMATION_SCHEMA.GLOBAL_VARIABLES' feature is disabled")},
+				psCheck: psEnabled,
+				psQuery: rows,
+			},
+			[]string{isQuery, psCheck, psQuery},
+			performanceSchemaGlobalVariables,
+		},
+		{
+			"1109 then performance_schema then SHOW",
+			map[string]fakeResult{
+				isQuery:             {err: errors.New("Error 1109 (42S02): Unknown table 'GLOBAL_VARIABLES' in information_schema")},
+				psCheck:             psEnabled,
+				psQuery:             tableMissing,
+				showGlobalVariables: rows,
+			},
+			[]string{isQuery, psCheck, psQuery, showGlobalVariables},
+			showGlobalVariables,
+		},
+	}
+
+	for _, test := range tests {
+		seenCompatibilityError, useShowGlobalVariables = false, false
+		globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+		db, _ := newFakeDB(test.results)
+		querier := &recordingQuerier{db: db}
+		v, err := NewVariables(querier).SelectAll()
+		db.Close()
+
+		if err != nil {
+			t.Errorf("SelectAll(%s) failed: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(querier.queries, test.expected) {
+			t.Errorf("SelectAll(%s) failed: expected queries: %q, got %q", test.name, test.expected, querier.queries)
+		}
+		if got := VariablesSource(); got != test.source {
+			t.Errorf("SelectAll(%s) failed: expected source: %q, got %q", test.name, test.source, got)
+		}
+		if got := v.Get("max_connections"); got != "151" {
+			t.Errorf("SelectAll(%s) failed: expected max_connections 151, got %q", test.name, got)
+		}
+	}
+}
+
+func TestSelectAllRetry(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+
+	const query = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	accessDenied := errors.New("Error 1045 (28000): Access denied for user 'ps-top'@'localhost'")
+
+	tests := []struct {
+		name     string
+		result   fakeResult
+		attempts int
+		queries  int
+		retries  int
+		wantErr  bool
+	}{
+		{"no failures", fakeResult{columns: []string{"VARIABLE_NAME", "VARIABLE_VALUE"}, rows: [][]driver.Value{{"max_connections", "151"}}}, 3, 1, 0, false},
+		{"refused twice", fakeResult{columns: []string{"VARIABLE_NAME", "VARIABLE_VALUE"}, rows: [][]driver.Value{{"max_connections", "151"}}, err: refused, fails: 2}, 3, 3, 2, false},
+		{"refused too often", fakeResult{columns: []string{"VARIABLE_NAME", "VARIABLE_VALUE"}, rows: [][]driver.Value{{"max_connections", "151"}}, err: refused, fails: 3}, 3, 3, 2, true},
+		{"not transient", fakeResult{err: accessDenied}, 3, 1, 0, true},
+	}
+
+	for _, test := range tests {
+		seenCompatibilityError, useShowGlobalVariables = false, false
+		globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+		db, connector := newFakeDB(map[string]fakeResult{query: test.result})
+		v, err := NewVariables(db).SelectAllRetry(context.Background(), test.attempts, time.Millisecond)
+		db.Close()
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("SelectAllRetry(%s) failed: expected error: %v, got %v", test.name, test.wantErr, err)
+		}
+		if got := len(connector.Queries()); got != test.queries {
+			t.Errorf("SelectAllRetry(%s) failed: expected %d queries, got %d", test.name, test.queries, got)
+		}
+		if got := v.Retries(); got != test.retries {
+			t.Errorf("SelectAllRetry(%s) failed: expected %d retries, got %d", test.name, test.retries, got)
+		}
+		if !test.wantErr && v.Get("max_connections") != "151" {
+			t.Errorf("SelectAllRetry(%s) failed: expected max_connections 151, got %q", test.name, v.Get("max_connections"))
+		}
+	}
+}
+
+func TestSelectAllRetryCancelled(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+	db, connector := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: {err: syscall.EPIPE},
+	})
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := NewVariables(db).SelectAllRetry(ctx, 10, time.Hour)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SelectAllRetry() failed: expected context.DeadlineExceeded, got %v", err)
+	}
+	if got := len(connector.Queries()); got != 1 {
+		t.Errorf("SelectAllRetry() failed: expected 1 query, got %d", got)
+	}
+}
+
+func TestSelectAllCached(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+	db, connector := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: variableRows(
+			[]driver.Value{"max_connections", "151"},
+		),
+	})
+	defer db.Close()
+
+	v := NewVariables(db)
+	if !v.Collected().IsZero() {
+		t.Errorf("Collected() failed: expected the zero time before collecting, got %v", v.Collected())
+	}
+	for i, ttl := range []time.Duration{time.Hour, time.Hour, 0, time.Hour} {
+		if _, err := v.SelectAllCached(ttl); err != nil {
+			t.Fatalf("SelectAllCached(%v) failed: %v", ttl, err)
+		}
+		// only the first collection and the one with no ttl query the database
+		expected := 1
+		if i >= 2 {
+			expected = 2
+		}
+		if got := len(connector.Queries()); got != expected {
+			t.Errorf("SelectAllCached(%v) call %d failed: expected %d queries, got %d", ttl, i, expected, got)
+		}
+	}
+	if v.Collected().IsZero() || v.Get("max_connections") != "151" {
+		t.Errorf("SelectAllCached() failed: collected at %v with max_connections %q", v.Collected(), v.Get("max_connections"))
+	}
+}
+
+func TestSelectByPrefix(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+
+	const isQuery = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables + " WHERE VARIABLE_NAME LIKE ?"
+	tests := []struct {
+		name    string
+		show    bool
+		results map[string]fakeResult
+		args    [][]driver.Value
+	}{
+		{
+			"information_schema",
+			false,
+			map[string]fakeResult{isQuery: variableRows(
+				[]driver.Value{"INNODB_BUFFER_POOL_SIZE", "134217728"},
+				[]driver.Value{"innodb_log_file_size", "50331648"},
+			)},
+			[][]driver.Value{{`innodb\_%`}},
+		},
+		{
+			"SHOW GLOBAL VARIABLES",
+			true,
+			map[string]fakeResult{showGlobalVariables: variableRows(
+				[]driver.Value{"innodb_buffer_pool_size", "134217728"},
+				[]driver.Value{"innodb_log_file_size", "50331648"},
+				[]driver.Value{"innodbXlog", "1"},
+				[]driver.Value{"max_connections", "200"},
+			)},
+			[][]driver.Value{{}},
+		},
+	}
+
+	for _, test := range tests {
+		seenCompatibilityError, useShowGlobalVariables = test.show, test.show
+		globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+		db, connector := newFakeDB(test.results)
+		v := &Variables{dbh: db, variables: map[string]string{
+			"innodb_old_variable": "1",
+			"max_connections":     "151",
+		}}
+		_, err := v.SelectByPrefix("innodb_")
+		db.Close()
+
+		if err != nil {
+			t.Errorf("SelectByPrefix(%s) failed: %v", test.name, err)
+			continue
+		}
+		if got := connector.Args(); !reflect.DeepEqual(got, test.args) {
+			t.Errorf("SelectByPrefix(%s) failed: expected args: %q, got %q", test.name, test.args, got)
+		}
+		expected := map[string]string{
+			"innodb_buffer_pool_size": "134217728",
+			"innodb_log_file_size":    "50331648",
+			"max_connections":         "151",
+		}
+		if got := v.AsMap(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("SelectByPrefix(%s) failed: expected: %v, got %v", test.name, expected, got)
+		}
+	}
+}
+
+func TestForEach(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+	db, _ := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: variableRows(
+			[]driver.Value{"MAX_CONNECTIONS", "151"},
+			[]driver.Value{"port", "3306"},
+			[]driver.Value{"version", "8.0.35"},
+		),
+	})
+	defer db.Close()
+	v := NewVariables(db)
+
+	var names []string
+	if err := v.ForEach(context.Background(), func(name, value string) error {
+		names = append(names, name+"="+value)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEach() failed: %v", err)
+	}
+	if expected := []string{"max_connections=151", "port=3306", "version=8.0.35"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("ForEach() failed: expected: %q, got %q", expected, names)
+	}
+	if got := v.AsMap(); len(got) != 0 {
+		t.Errorf("ForEach() failed: expected no variables to be stored, got %v", got)
+	}
+
+	stop := errors.New("found it")
+	names = nil
+	err := v.ForEach(context.Background(), func(name, value string) error {
+		names = append(names, name)
+		if name == "port" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("ForEach() failed: expected the callback's error, got %v", err)
+	}
+	if expected := []string{"max_connections", "port"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("ForEach() failed: expected to stop after: %q, got %q", expected, names)
+	}
+}
+
+func TestSelectAllPrepared(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+
+	const (
+		isQuery = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables
+		psQuery = "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + performanceSchemaGlobalVariables
+	)
+	rows := variableRows([]driver.Value{"max_connections", "151"})
+	db, connector := newFakeDB(map[string]fakeResult{
+		isQuery:                       rows,
+		psQuery:                       rows,
+		"SELECT @@performance_schema": {columns: []string{"@@performance_schema"}, rows: [][]driver.Value{{int64(1)}}},
+	})
+	defer db.Close()
+	querier := &recordingQuerier{db: db}
+	v := NewVariables(querier)
+
+	steps := []struct {
+		name     string
+		queries  []string // run directly, not through the prepared statement
+		prepared []string
+	}{
+		{"first", []string{isQuery}, nil},
+		{"second", []string{isQuery, isQuery}, []string{isQuery}},
+		{"third", []string{isQuery, isQuery}, []string{isQuery}},
+		{"after the fallback", []string{isQuery, isQuery, "SELECT @@performance_schema", psQuery}, []string{isQuery, psQuery}},
+		{"after the fallback again", []string{isQuery, isQuery, "SELECT @@performance_schema", psQuery}, []string{isQuery, psQuery}},
+	}
+	for _, step := range steps {
+		if step.name == "after the fallback" {
+			usePerformanceSchema()
+		}
+		if _, err := v.SelectAll(); err != nil {
+			t.Fatalf("SelectAll(%s) failed: %v", step.name, err)
+		}
+		if !reflect.DeepEqual(querier.queries, step.queries) {
+			t.Errorf("SelectAll(%s) failed: expected queries: %q, got %q", step.name, step.queries, querier.queries)
+		}
+		if got := connector.Prepared(); !reflect.DeepEqual(got, step.prepared) {
+			t.Errorf("SelectAll(%s) failed: expected prepared: %q, got %q", step.name, step.prepared, got)
+		}
+		if got := v.Get("max_connections"); got != "151" {
+			t.Errorf("SelectAll(%s) failed: expected max_connections 151, got %q", step.name, got)
+		}
+	}
+
+	if err := v.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	if v.preparedFor(psQuery) != nil {
+		t.Errorf("Close() failed: the statement is still prepared")
+	}
+	if err := v.Close(); err != nil {
+		t.Errorf("Close() failed when already closed: %v", err)
+	}
+}
+
+// unpreparedQuerier is a Querier which can't prepare statements
+type unpreparedQuerier struct {
+	*sql.DB
+}
+
+func (unpreparedQuerier) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.New("unpreparedQuerier: prepared statements are not supported")
+}
+
+// benchmarkSelectAll runs SelectAll against dbh returning a typical number of variables,
+// the server taking parseDelay to parse each query which is not prepared
+func benchmarkSelectAll(b *testing.B, wrap func(*sql.DB) Querier) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	seenCompatibilityError, useShowGlobalVariables = false, false
+	globalStatusTable, globalVariablesTable = informationSchemaGlobalStatus, informationSchemaGlobalVariables
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var rows [][]driver.Value
+	for i := 0; i < 600; i++ {
+		rows = append(rows, []driver.Value{"variable_" + strconv.Itoa(i), strconv.Itoa(i)})
+	}
+	db, connector := newFakeDB(map[string]fakeResult{
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: variableRows(rows...),
+	})
+	defer db.Close()
+	connector.parseDelay = parseDelay
+	v := NewVariables(wrap(db))
+	defer v.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := v.SelectAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// parseDelay is roughly the time a server takes to parse the variables query
+const parseDelay = 100 * time.Microsecond
+
+// The prepared statement is only parsed once so it avoids parseDelay on later calls.
+func BenchmarkSelectAllPrepared(b *testing.B) {
+	benchmarkSelectAll(b, func(db *sql.DB) Querier { return db })
+}
+
+func BenchmarkSelectAllUnprepared(b *testing.B) {
+	benchmarkSelectAll(b, func(db *sql.DB) Querier { return unpreparedQuerier{db} })
+}
+
+func TestPerformanceSchemaEnabled(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"ON", true},
+		{"OFF", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		v := Variables{variables: map[string]string{"performance_schema": test.value}}
+		if got := v.PerformanceSchemaEnabled(); got != test.expected {
+			t.Errorf("PerformanceSchemaEnabled() with %q failed: expected: %v, got %v", test.value, test.expected, got)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	defer SetSource(CurrentSource())
+
+	SetSource(Source{})
+	if got := VariablesSource(); got != informationSchemaGlobalVariables {
+		t.Errorf("SetSource(Source{}) failed: expected the variables from %s, got %s", informationSchemaGlobalVariables, got)
+	}
+
+	usePerformanceSchema()
+	useShow()
+	saved := CurrentSource()
+	SetSource(Source{})
+	if seen, show := fallbacksTried(); seen || show {
+		t.Errorf("SetSource(Source{}) failed: expected no fallbacks, got %v, %v", seen, show)
+	}
+
+	SetSource(saved)
+	if got := VariablesSource(); got != showGlobalVariables {
+		t.Errorf("SetSource() failed: expected the variables from %s, got %s", showGlobalVariables, got)
+	}
+	if got := StatusSource(); got != performanceSchemaGlobalStatus {
+		t.Errorf("SetSource() failed: expected the status from %s, got %s", performanceSchemaGlobalStatus, got)
+	}
+}
@@ -0,0 +1,121 @@
+package global
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version holds the major, minor and patch numbers of a MySQL server version
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a version like 8.0, 8.0.35 or 5.7.44-log. At least the major and
+// minor numbers must be given and anything after the numbers (a suffix starting with '-')
+// is ignored.
+func ParseVersion(version string) (Version, error) {
+	numbers := version
+	if i := strings.IndexByte(numbers, '-'); i >= 0 {
+		numbers = numbers[:i]
+	}
+
+	parts := strings.Split(numbers, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q, expected <major>.<minor>[.<patch>]", version)
+	}
+	values := make([]int, 3)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return Version{}, fmt.Errorf("invalid version %q, expected <major>.<minor>[.<patch>]", version)
+		}
+		values[i] = value
+	}
+
+	return Version{Major: values[0], Minor: values[1], Patch: values[2]}, nil
+}
+
+// AtLeast returns true if the version is major.minor or later
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// String returns the version as <major>.<minor>.<patch>
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// the flavors of MySQL server
+const (
+	FlavorMySQL   = "mysql"
+	FlavorMariaDB = "mariadb"
+	FlavorPercona = "percona"
+)
+
+// mariaDBReplicationPrefix is prefixed to old MariaDB versions for the benefit of MySQL replicas
+const mariaDBReplicationPrefix = "5.5.5-"
+
+// perconaSuffixRE matches the release suffix of Percona Server versions, e.g. -27 in 8.0.35-27
+var perconaSuffixRE = regexp.MustCompile(`^-\d+(\.\d+)?$`)
+
+// ServerVersion holds the version and flavor of a MySQL server
+type ServerVersion struct {
+	Version
+	Flavor string // one of the Flavor* constants
+}
+
+// ParseServerVersion parses the version variable of a server, e.g. 8.0.36, 5.7.44-log,
+// 10.11.6-MariaDB or 8.0.35-0ubuntu0.22.04.1, determining the flavor from the suffix.
+func ParseServerVersion(version string) (ServerVersion, error) {
+	flavor := FlavorMySQL
+	numbers := version
+	if strings.Contains(version, "MariaDB") {
+		flavor = FlavorMariaDB
+		numbers = strings.TrimPrefix(numbers, mariaDBReplicationPrefix)
+	}
+	if i := strings.IndexByte(numbers, '-'); i >= 0 {
+		if flavor == FlavorMySQL && perconaSuffixRE.MatchString(numbers[i:]) {
+			flavor = FlavorPercona
+		}
+	}
+
+	v, err := ParseVersion(numbers)
+	if err != nil {
+		return ServerVersion{}, err
+	}
+	return ServerVersion{Version: v, Flavor: flavor}, nil
+}
+
+// DetectServerVersion returns the version of the server using SELECT VERSION(),
+// which works whichever tables hold the global variables.
+func DetectServerVersion(dbh *sql.DB) (ServerVersion, error) {
+	var version string
+
+	if err := dbh.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return ServerVersion{}, err
+	}
+	return ParseServerVersion(version)
+}
+
+// SetServerVersion chooses the tables used for the global variables and status
+// based on the server version, so no query is needed to find out which to use.
+// MySQL 5.7 and later use performance_schema. Earlier versions and MariaDB,
+// whose performance_schema has no global_variables table, use information_schema.
+func SetServerVersion(v ServerVersion) {
+	getLogger().Printf("global.SetServerVersion(%s %s)", v.String(), v.Flavor)
+	if v.Flavor != FlavorMariaDB && v.AtLeast(5, 7) {
+		usePerformanceSchema()
+		return
+	}
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+
+	seenCompatibilityError = true // don't try performance_schema if information_schema fails
+	globalStatusTable = informationSchemaGlobalStatus
+	globalVariablesTable = informationSchemaGlobalVariables
+}
@@ -0,0 +1,136 @@
+package global
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected Version
+		ok       bool
+	}{
+		{"8.0", Version{8, 0, 0}, true},
+		{"8.0.35", Version{8, 0, 35}, true},
+		{"5.7.44-log", Version{5, 7, 44}, true},
+		{"8.0.35-27", Version{8, 0, 35}, true},
+		{"8", Version{}, false},
+		{"8.x", Version{}, false},
+		{"8.0.1.2", Version{}, false},
+		{"", Version{}, false},
+	}
+
+	for _, test := range tests {
+		got, err := ParseVersion(test.version)
+		if (err == nil) != test.ok || got != test.expected {
+			t.Errorf("ParseVersion(%q) failed: expected: %v (ok: %v), got %v (err: %v)", test.version, test.expected, test.ok, got, err)
+		}
+	}
+}
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected ServerVersion
+		ok       bool
+	}{
+		{"8.0.36", ServerVersion{Version{8, 0, 36}, FlavorMySQL}, true},
+		{"5.7.44-log", ServerVersion{Version{5, 7, 44}, FlavorMySQL}, true},
+		{"8.0.35-0ubuntu0.22.04.1", ServerVersion{Version{8, 0, 35}, FlavorMySQL}, true},
+		{"10.11.6-MariaDB", ServerVersion{Version{10, 11, 6}, FlavorMariaDB}, true},
+		{"5.5.5-10.4.32-MariaDB-log", ServerVersion{Version{10, 4, 32}, FlavorMariaDB}, true},
+		{"8.0.35-27", ServerVersion{Version{8, 0, 35}, FlavorPercona}, true},
+		{"5.7.44-48.1", ServerVersion{Version{5, 7, 44}, FlavorPercona}, true},
+		{"MariaDB", ServerVersion{}, false},
+	}
+
+	for _, test := range tests {
+		got, err := ParseServerVersion(test.version)
+		if (err == nil) != test.ok || got != test.expected {
+			t.Errorf("ParseServerVersion(%q) failed: expected: %+v (ok: %v), got %+v (err: %v)", test.version, test.expected, test.ok, got, err)
+		}
+	}
+
+	v := Variables{variables: map[string]string{"version": "10.11.6-MariaDB"}}
+	if got, err := v.ServerVersion(); err != nil || !got.AtLeast(10, 5) || got.AtLeast(11, 0) {
+		t.Errorf("Variables.ServerVersion() failed: got %+v (err: %v)", got, err)
+	}
+	var empty Variables
+	if _, err := empty.ServerVersion(); err == nil {
+		t.Errorf("Variables.ServerVersion() failed: expected an error without a version")
+	}
+}
+
+func TestSetServerVersion(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	useShowGlobalVariables = false
+
+	tests := []struct {
+		version   ServerVersion
+		status    string
+		variables string
+	}{
+		{ServerVersion{Version{8, 0, 35}, FlavorMySQL}, performanceSchemaGlobalStatus, performanceSchemaGlobalVariables},
+		{ServerVersion{Version{5, 7, 0}, FlavorPercona}, performanceSchemaGlobalStatus, performanceSchemaGlobalVariables},
+		{ServerVersion{Version{5, 6, 51}, FlavorMySQL}, informationSchemaGlobalStatus, informationSchemaGlobalVariables},
+		{ServerVersion{Version{10, 11, 6}, FlavorMariaDB}, informationSchemaGlobalStatus, informationSchemaGlobalVariables},
+	}
+
+	for _, test := range tests {
+		SetServerVersion(test.version)
+		if StatusSource() != test.status || VariablesSource() != test.variables || !seenCompatibilityError {
+			t.Errorf("SetServerVersion(%v) failed: expected: %s, %s, got %s, %s (seen: %v)",
+				test.version, test.status, test.variables, StatusSource(), VariablesSource(), seenCompatibilityError)
+		}
+	}
+}
+
+func TestVariablesSource(t *testing.T) {
+	defer func(show bool) { useShowGlobalVariables = show }(useShowGlobalVariables)
+
+	useShowGlobalVariables = true
+	if got := VariablesSource(); got != showGlobalVariables {
+		t.Errorf("VariablesSource() failed: expected: %q, got %q", showGlobalVariables, got)
+	}
+}
+
+func TestDetectServerVersion(t *testing.T) {
+	defer func(status, variables string, seen, show bool) {
+		globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables = status, variables, seen, show
+	}(globalStatusTable, globalVariablesTable, seenCompatibilityError, useShowGlobalVariables)
+	usePerformanceSchema() // as if a MySQL server had been seen before
+
+	db, connector := newFakeDB(map[string]fakeResult{
+		"SELECT VERSION()": {columns: []string{"VERSION()"}, rows: [][]driver.Value{{"10.11.6-MariaDB-log"}}},
+		"SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables: {
+			columns: []string{"VARIABLE_NAME", "VARIABLE_VALUE"},
+			rows:    [][]driver.Value{{"VERSION", "10.11.6-MariaDB-log"}},
+		},
+	})
+	defer db.Close()
+
+	version, err := DetectServerVersion(db)
+	if err != nil || version.Flavor != FlavorMariaDB {
+		t.Fatalf("DetectServerVersion() failed: expected a MariaDB version, got %+v (err: %v)", version, err)
+	}
+	SetServerVersion(version)
+	if VariablesSource() != informationSchemaGlobalVariables || StatusSource() != informationSchemaGlobalStatus {
+		t.Errorf("SetServerVersion(%+v) failed: expected I_S, got %s and %s", version, VariablesSource(), StatusSource())
+	}
+
+	v, err := NewVariables(db).SelectAll()
+	if err != nil {
+		t.Fatalf("SelectAll() failed: %v", err)
+	}
+	expected := []string{"SELECT VERSION()", "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM " + informationSchemaGlobalVariables}
+	if got := connector.Queries(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("SelectAll() failed: expected queries: %q, got %q", expected, got)
+	}
+	if got := v.Get("version"); got != "10.11.6-MariaDB-log" {
+		t.Errorf("SelectAll() failed: expected the version to be collected, got %q", got)
+	}
+}
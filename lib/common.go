@@ -4,9 +4,12 @@ package lib
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sjmudd/anonymiser"
 )
@@ -25,19 +28,121 @@ const (
 var ProgName string
 
 func init() {
-	ProgName = regexp.MustCompile(`.*/`).ReplaceAllLiteralString(os.Args[0], "")
+	ProgName = progName(os.Args[0])
 }
 
+// progName returns the program name from path removing any leading
+// directories using either / or \ and any trailing .exe
+func progName(path string) string {
+	name := regexp.MustCompile(`.*[/\\]`).ReplaceAllLiteralString(path, "")
+	if len(name) > len(".exe") && strings.EqualFold(name[len(name)-len(".exe"):], ".exe") {
+		name = name[:len(name)-len(".exe")]
+	}
+	return name
+}
+
+// RoundMode determines how myroundMode rounds a value to the wanted decimals
+type RoundMode int
+
+// Round* constants are the supported rounding modes
+const (
+	RoundDefault  RoundMode = iota // the rounding done by fmt
+	RoundFloor                     // round towards negative infinity
+	RoundCeil                      // round towards positive infinity
+	RoundHalfEven                  // round to nearest, ties to the even digit
+	RoundHalfUp                    // round to nearest, ties away from zero
+)
+
 // myround converts this floating value to the right width etc.
-// There must be a function in Go to do this. Find it.
+// Ties are rounded half up, as written, so 1.005 becomes 1.01 with 2 decimals.
+// All the Format* functions built on myround therefore round the same way.
 func myround(f float64, width, decimals int) string {
-	format := "%" + fmt.Sprintf("%d", width) + "." + fmt.Sprintf("%d", decimals) + "f"
-	return fmt.Sprintf(format, f)
+	return myroundMode(f, width, decimals, RoundHalfUp)
+}
+
+// myroundMode converts this floating value to the given width and decimals
+// using the given rounding mode. Values are rounded based on their shortest
+// decimal representation so 0.29 is treated as 0.29 and not 0.28999...
+func myroundMode(value float64, width, decimals int, mode RoundMode) string {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if mode == RoundDefault || !ok {
+		format := "%" + fmt.Sprintf("%d", width) + "." + fmt.Sprintf("%d", decimals) + "f"
+		return fmt.Sprintf(format, value)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := roundRat(r.Mul(r, new(big.Rat).SetInt(scale)), mode)
+
+	return fmt.Sprintf("%*s", width, new(big.Rat).SetFrac(scaled, scale).FloatString(decimals))
+}
+
+// myroundSep converts this floating value to the given width and decimals
+// as myround does but with commas separating the thousands. Values too
+// wide for width are returned in full, as fmt does.
+func myroundSep(value float64, width, decimals int) string {
+	formatted := strings.TrimLeft(myround(value, 0, decimals), " ")
+
+	var sign string
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+	integer, fraction := formatted, ""
+	if i := strings.IndexByte(formatted, '.'); i >= 0 {
+		integer, fraction = formatted[:i], formatted[i:]
+	}
+	if len(integer) > 3 && integer[0] >= '0' && integer[0] <= '9' { // leave Inf and NaN alone
+		var b strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				b.WriteByte(',')
+			}
+			b.WriteRune(digit)
+		}
+		integer = b.String()
+	}
+	return fmt.Sprintf("%*s", width, sign+integer+fraction)
+}
+
+// roundRat rounds r to an integer using the given rounding mode
+func roundRat(r *big.Rat, mode RoundMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int)) // truncated towards zero
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	away := big.NewInt(int64(r.Sign())) // one step away from zero
+
+	switch mode {
+	case RoundFloor:
+		if r.Sign() < 0 {
+			return quotient.Add(quotient, away)
+		}
+	case RoundCeil:
+		if r.Sign() > 0 {
+			return quotient.Add(quotient, away)
+		}
+	case RoundHalfEven:
+		half := new(big.Int).Abs(remainder)
+		switch half.Lsh(half, 1).Cmp(r.Denom()) {
+		case 1:
+			return quotient.Add(quotient, away)
+		case 0:
+			if quotient.Bit(0) == 1 {
+				return quotient.Add(quotient, away)
+			}
+		}
+	case RoundHalfUp:
+		half := new(big.Int).Abs(remainder)
+		if half.Lsh(half, 1).Cmp(r.Denom()) >= 0 {
+			return quotient.Add(quotient, away)
+		}
+	}
+
+	return quotient
 }
 
-// secToTime() converts a number of hours, minutes and seconds into hh:mm:ss format.
+// SecToTime() converts a number of hours, minutes and seconds into hh:mm:ss format.
 // e.g. 7384 = 2h 3m 4s, 7200 + 180 + 4
-func secToTime(totalSeconds uint64) string {
+func SecToTime(totalSeconds uint64) string {
 	hours := totalSeconds / 3600                // integer value
 	minutes := (totalSeconds - hours*3600) / 60 // integer value
 	seconds := totalSeconds - hours*3600 - minutes*60
@@ -45,35 +150,86 @@ func secToTime(totalSeconds uint64) string {
 	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
 }
 
+// SecToTimeDays converts a number of seconds into "Nd hh:mm:ss" format,
+// leaving out the days if there are none.
+func SecToTimeDays(totalSeconds uint64) string {
+	days := totalSeconds / 86400
+	if days == 0 {
+		return SecToTime(totalSeconds)
+	}
+	return fmt.Sprintf("%dd %s", days, SecToTime(totalSeconds-days*86400))
+}
+
+// timeUnits are the units used by FormatTimeWidth, largest first.
+var timeUnits = []struct {
+	picoseconds uint64
+	suffix      string
+}{
+	{604800000000000000, "w"},
+	{86400000000000000, "d"},
+	{3600000000000000, "h"},
+	{60000000000000, "m"},
+	{1000000000000, "s"},
+	{1000000000, "ms"},
+	{1000000, "us"},
+	{1000, "ns"},
+}
+
 // FormatTime is based on sys.format_time. It
 // formats to 10 characters including space and suffix.
 // All values have 2 decimal places. Zero is returned as
 // an empty string.
+// Note: a uint64 holds at most about 30 weeks of picoseconds
+// so there is no need for a year suffix.
 func FormatTime(picoseconds uint64) string {
+	return FormatTimeWidth(picoseconds, 7, 2)
+}
+
+// FormatTimeWidth formats like FormatTime but with the number using
+// the given width and decimal places. Single character suffixes get
+// one extra character of width so all units line up.
+func FormatTimeWidth(picoseconds uint64, width, decimals int) string {
 	if picoseconds == 0 {
 		return ""
 	}
-	if picoseconds >= 3600000000000000 {
-		return myround(float64(picoseconds)/3600000000000000, 8, 2) + " h"
-	}
-	if picoseconds >= 60000000000000 {
-		return myround(float64(picoseconds)/60000000000000, 8, 2) + " m"
-	}
-	if picoseconds >= 1000000000000 {
-		return myround(float64(picoseconds)/1000000000000, 8, 2) + " s"
-	}
-	if picoseconds >= 1000000000 {
-		return myround(float64(picoseconds)/1000000000, 7, 2) + " ms"
-	}
-	if picoseconds >= 1000000 {
-		return myround(float64(picoseconds)/1000000, 7, 2) + " us"
-	}
-	if picoseconds >= 1000 {
-		return myround(float64(picoseconds)/1000, 7, 2) + " ns"
+	for _, unit := range timeUnits {
+		if picoseconds >= unit.picoseconds {
+			return myround(float64(picoseconds)/float64(unit.picoseconds), width+2-len(unit.suffix), decimals) + " " + unit.suffix
+		}
 	}
 	return strconv.Itoa(int(picoseconds)) + " ps"
 }
 
+// FormatDuration formats a time.Duration in the same way as FormatTime
+// to 10 characters. Negative durations have a leading minus sign.
+// Zero is returned as 10 spaces.
+func FormatDuration(d time.Duration) string {
+	return FormatDurationWidth(d, 10)
+}
+
+// FormatDurationWidth formats a time.Duration as FormatDuration does
+// right aligned to the given width.
+func FormatDurationWidth(d time.Duration, width int) string {
+	var formatted string
+
+	if d != 0 {
+		nanoseconds := uint64(d)
+		if d < 0 {
+			nanoseconds = -nanoseconds // two's complement, so also right for math.MinInt64
+		}
+		picoseconds := uint64(math.MaxUint64)
+		if nanoseconds <= math.MaxUint64/1000 {
+			picoseconds = nanoseconds * 1000
+		}
+		formatted = strings.TrimLeft(FormatTime(picoseconds), " ")
+		if d < 0 {
+			formatted = "-" + formatted
+		}
+	}
+
+	return fmt.Sprintf("%*s", width, formatted)
+}
+
 // FormatPct formats a floating point number as a percentage
 // including the trailing % sign. Print the value as a %5.1f with
 // a % suffix if there's a value.
@@ -130,6 +286,50 @@ func FormatAmount(amount uint64) string {
 	return formatted
 }
 
+// byteUnits are the binary units used by FormatBytesWidth, largest first.
+var byteUnits = []struct {
+	bytes  uint64
+	suffix string
+}{
+	{1 << 60, "EiB"},
+	{1 << 50, "PiB"},
+	{1 << 40, "TiB"},
+	{1 << 30, "GiB"},
+	{1 << 20, "MiB"},
+	{1 << 10, "KiB"},
+}
+
+// FormatBytes formats a byte count with binary suffixes to 10 characters
+// including space and suffix, e.g. "  1.00 KiB" or " 256.0 MiB".
+// Values of 100 or more of a unit have 1 decimal place, others 2.
+// Zero is returned as an empty string.
+func FormatBytes(bytes uint64) string {
+	for _, unit := range byteUnits {
+		if bytes >= unit.bytes {
+			if bytes/unit.bytes >= 100 {
+				return FormatBytesWidth(bytes, 6, 1)
+			}
+			break
+		}
+	}
+	return FormatBytesWidth(bytes, 6, 2)
+}
+
+// FormatBytesWidth formats like FormatBytes but with the number using the
+// given width and decimal places. Values below 1 KiB are shown as whole
+// bytes right aligned to the same overall width.
+func FormatBytesWidth(bytes uint64, width, decimals int) string {
+	if bytes == 0 {
+		return ""
+	}
+	for _, unit := range byteUnits {
+		if bytes >= unit.bytes {
+			return myround(float64(bytes)/float64(unit.bytes), width, decimals) + " " + unit.suffix
+		}
+	}
+	return fmt.Sprintf("%*d B", width+2, bytes)
+}
+
 // SignedFormatAmount formats a signed integer as per FormatAmount()
 func SignedFormatAmount(amount int64) string {
 	var suffix string
@@ -165,14 +365,78 @@ func SignedFormatAmount(amount int64) string {
 	return formatted
 }
 
-// FormatCounter formats a counter like an Amount but is tighter in space
-func FormatCounter(counter int, width int) string {
+// counterUnits are the decimal suffixes used by FormatCounter, smallest first.
+var counterUnits = []struct {
+	size   uint64
+	suffix string
+}{
+	{1000, "K"},
+	{1000000, "M"},
+	{1000000000, "G"},
+	{1000000000000, "T"},
+}
+
+// FormatCounter formats a counter right aligned to the given width.
+// Values from 1000 are shown with a K, M, G or T suffix and up to 2
+// decimal places, dropping decimals until the value fits the width. The
+// unit is chosen after rounding so 999999 is shown as 1.00M, not
+// 1000.00K. Zero is shown as spaces.
+func FormatCounter(counter uint64, width int) string {
 	if counter == 0 {
-		pattern := "%" + fmt.Sprintf("%d", width) + "s"
-		return fmt.Sprintf(pattern, " ")
+		return fmt.Sprintf("%*s", width, " ")
 	}
-	pattern := "%" + fmt.Sprintf("%d", width) + "d"
-	return fmt.Sprintf(pattern, counter)
+	if counter < counterUnits[0].size {
+		return fmt.Sprintf("%*d", width, counter)
+	}
+
+	unit := 0
+	for unit+1 < len(counterUnits) && counter >= counterUnits[unit+1].size {
+		unit++
+	}
+
+	var formatted string
+	for decimals := 2; decimals >= 0; decimals-- {
+		value := float64(counter) / float64(counterUnits[unit].size)
+		rounded := myround(value, 0, decimals)
+		if f, err := strconv.ParseFloat(rounded, 64); err == nil && f >= 1000 && unit+1 < len(counterUnits) {
+			unit++       // rounding reached the next unit
+			decimals = 3 // so start again from 2 decimal places
+			continue
+		}
+		formatted = rounded + counterUnits[unit].suffix
+		if len(formatted) <= width {
+			break
+		}
+	}
+	return fmt.Sprintf("%*s", width, formatted)
+}
+
+// FormatID formats an identifier such as a thread id right aligned to
+// the given width. Unlike FormatCounter the full value is always shown.
+// Zero is shown as spaces.
+func FormatID(id uint64, width int) string {
+	if id == 0 {
+		return fmt.Sprintf("%*s", width, " ")
+	}
+	return fmt.Sprintf("%*d", width, id)
+}
+
+// ProgressBar returns a bar of the given width inside brackets, with the
+// fraction done, between 0 and 1, filled with #
+func ProgressBar(done float64, width int) string {
+	filled := int(done * float64(width))
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+// OthersName returns the name to show for a row summarising count other rows
+func OthersName(count int) string {
+	return fmt.Sprintf("<others: %d rows>", count)
 }
 
 // Divide divides a by b except if b is 0 in which case we return 0.
@@ -183,6 +447,53 @@ func Divide(a uint64, b uint64) float64 {
 	return float64(a) / float64(b)
 }
 
+// DivideOrNaN divides a by b except if b is 0 in which case we return NaN
+// so the caller can tell a zero result from a missing one.
+func DivideOrNaN(a uint64, b uint64) float64 {
+	if b == 0 {
+		return math.NaN()
+	}
+	return float64(a) / float64(b)
+}
+
+// DivideRound divides a by b rounding half up to the given number of
+// decimals. If b is 0 we return 0 as Divide does.
+func DivideRound(a uint64, b uint64, decimals int) float64 {
+	if b == 0 {
+		return float64(0)
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	// floor((2 * a * scale + b) / (2 * b)) rounds a * scale / b half up exactly
+	numerator := new(big.Int).Mul(new(big.Int).SetUint64(a), scale)
+	numerator.Lsh(numerator, 1).Add(numerator, new(big.Int).SetUint64(b))
+	denominator := new(big.Int).Lsh(new(big.Int).SetUint64(b), 1)
+	scaled := numerator.Quo(numerator, denominator)
+
+	f, _ := new(big.Rat).SetFrac(scaled, scale).Float64()
+	return f
+}
+
+// PerSecond returns the rate per second of a change of delta during interval
+func PerSecond(delta uint64, interval time.Duration) float64 {
+	if interval <= 0 {
+		return 0
+	}
+	return float64(delta) / interval.Seconds()
+}
+
+// Rate returns the rate per second of a counter going from previous to
+// current during interval. If the counter went backwards, e.g. after
+// FLUSH STATUS or a restart, the rate is 0.
+func Rate(current, previous uint64, interval time.Duration) float64 {
+	if current < previous {
+		return 0
+	}
+	return PerSecond(current-previous, interval)
+}
+
 // SignedDivide divides a by b except if b is 0 in which case we return 0.
 func SignedDivide(a int64, b int64) float64 {
 	if b == 0 {
@@ -193,9 +504,41 @@ func SignedDivide(a int64, b int64) float64 {
 
 // QualifiedTableName returns the anonymised qualified table name from the columns as '<schema>.<table>'
 func QualifiedTableName(schema, table string) string {
+	return qualifiedName(anonymiser.Anonymise("schema", schema), anonymiser.Anonymise("table", table))
+}
+
+// QualifiedTableNameRel returns the anonymised table name as QualifiedTableName
+// but leaves out the schema if it is currentSchema
+func QualifiedTableNameRel(schema, table, currentSchema string) string {
+	if schema == currentSchema {
+		schema = ""
+	}
+	return QualifiedTableName(schema, table)
+}
+
+// QualifiedTableNameQuoted returns the anonymised qualified table name
+// with each part backtick quoted as '`<schema>`.`<table>`'
+func QualifiedTableNameQuoted(schema, table string) string {
 	schema = anonymiser.Anonymise("schema", schema)
 	table = anonymiser.Anonymise("table", table)
 
+	if len(schema) > 0 {
+		schema = QuoteIdentifier(schema)
+	}
+	if len(table) > 0 {
+		table = QuoteIdentifier(table)
+	}
+	return qualifiedName(schema, table)
+}
+
+// QuoteIdentifier returns name quoted with backticks as MySQL expects,
+// doubling any embedded backtick
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// qualifiedName joins schema and table with a dot, leaving out empty parts
+func qualifiedName(schema, table string) string {
 	var name string
 	if len(schema) > 0 {
 		name += schema
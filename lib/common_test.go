@@ -1,7 +1,9 @@
 package lib
 
 import (
+	"math"
 	"testing"
+	"time"
 )
 
 func TestProgName(t *testing.T) {
@@ -11,6 +13,54 @@ func TestProgName(t *testing.T) {
 	}
 }
 
+func TestMyroundSep(t *testing.T) {
+	tests := []struct {
+		input    float64
+		width    int
+		decimals int
+		expected string
+	}{
+		{0, 5, 0, "    0"},
+		{999, 5, 0, "  999"},
+		{1000, 6, 0, " 1,000"},
+		{1234567, 12, 0, "   1,234,567"},
+		{1234567, 9, 0, "1,234,567"},
+		{1234567, 5, 0, "1,234,567"}, // too narrow so shown in full
+		{1234567, 12, 2, "1,234,567.00"},
+		{1234567.891, 14, 1, "   1,234,567.9"},
+		{-1234567, 12, 0, "  -1,234,567"},
+		{-123, 6, 0, "  -123"},
+		{-999.99, 8, 1, "-1,000.0"},
+		{math.Inf(1), 6, 0, "  +Inf"},
+	}
+	for _, test := range tests {
+		got := myroundSep(test.input, test.width, test.decimals)
+		if got != test.expected {
+			t.Errorf("myroundSep(%f,%d,%d) failed: expected: %q, got %q", test.input, test.width, test.decimals, test.expected, got)
+		}
+	}
+}
+
+func TestProgNameFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"ps-top", "ps-top"},
+		{"/usr/local/bin/ps-top", "ps-top"},
+		{"./ps-top", "ps-top"},
+		{`C:\tools\ps-top.exe`, "ps-top"},
+		{`C:\tools\PS-TOP.EXE`, "PS-TOP"},
+		{"C:/tools/ps-top.exe", "ps-top"},
+		{".exe", ".exe"},
+	}
+	for _, test := range tests {
+		if got := progName(test.path); got != test.expected {
+			t.Errorf("progName(%q) failed: expected: %q, got %q", test.path, test.expected, got)
+		}
+	}
+}
+
 func TestMyround(t *testing.T) {
 	tests := []struct {
 		input    float64
@@ -29,6 +79,11 @@ func TestMyround(t *testing.T) {
 		{123, 8, 3, " 123.000"},
 		{123, 9, 3, "  123.000"},
 		{123, 10, 3, "   123.000"},
+		{1.005, 6, 2, "  1.01"}, // half up although 1.005 is stored as 1.00499...
+		{1.015, 6, 2, "  1.02"},
+		{2.5, 6, 0, "     3"},
+		{-2.5, 6, 0, "    -3"},
+		{0.125, 6, 2, "  0.13"},
 	}
 	for _, test := range tests {
 		got := myround(test.input, test.width, test.decimals)
@@ -38,6 +93,41 @@ func TestMyround(t *testing.T) {
 	}
 }
 
+func TestMyroundMode(t *testing.T) {
+	tests := []struct {
+		input    float64
+		decimals int
+		mode     RoundMode
+		expected string
+	}{
+		{2.5, 0, RoundDefault, "     2"},
+		{2.5, 0, RoundFloor, "     2"},
+		{2.5, 0, RoundCeil, "     3"},
+		{2.5, 0, RoundHalfEven, "     2"},
+		{3.5, 0, RoundHalfEven, "     4"},
+		{2.5, 0, RoundHalfUp, "     3"},
+		{-2.5, 0, RoundHalfUp, "    -3"},
+		{2.49, 0, RoundHalfUp, "     2"},
+		{-2.5, 0, RoundFloor, "    -3"},
+		{-2.5, 0, RoundCeil, "    -2"},
+		{-2.5, 0, RoundHalfEven, "    -2"},
+		{0.125, 2, RoundDefault, "  0.12"},
+		{0.125, 2, RoundFloor, "  0.12"},
+		{0.125, 2, RoundCeil, "  0.13"},
+		{0.125, 2, RoundHalfEven, "  0.12"},
+		{0.135, 2, RoundHalfEven, "  0.14"},
+		{0.29, 2, RoundFloor, "  0.29"},
+		{0.126, 2, RoundHalfEven, "  0.13"},
+		{12, 1, RoundCeil, "  12.0"},
+	}
+	for _, test := range tests {
+		got := myroundMode(test.input, 6, test.decimals, test.mode)
+		if got != test.expected {
+			t.Errorf("myroundMode(%v,6,%v,%v) failed: expected: %q, got %q", test.input, test.decimals, test.mode, test.expected, got)
+		}
+	}
+}
+
 func TestFormatTime(t *testing.T) {
 	tests := []struct {
 		picoseconds uint64
@@ -51,6 +141,13 @@ func TestFormatTime(t *testing.T) {
 		{1000000000000, "    1.00 s"},
 		{60000000000000, "    1.00 m"},
 		{3600000000000000, "    1.00 h"},
+		{86400000000000000 - 1, "   24.00 h"},
+		{86400000000000000, "    1.00 d"},
+		{604800000000000000, "    1.00 w"},
+		{math.MaxUint64, "   30.50 w"}, // the longest time we can hold, so no years
+		{1005000000000, "    1.01 s"},
+		{1015000000, "   1.02 ms"},
+		{2500000000000, "    2.50 s"},
 	}
 	for _, test := range tests {
 		got := FormatTime(test.picoseconds)
@@ -60,6 +157,183 @@ func TestFormatTime(t *testing.T) {
 	}
 }
 
+func TestFormatTimeWidth(t *testing.T) {
+	tests := []struct {
+		picoseconds uint64
+		width       int
+		decimals    int
+		expected    string
+	}{
+		{0, 5, 1, ""},
+		{1, 5, 1, "1 ps"},
+		{1500, 5, 1, "  1.5 ns"},
+		{1500000000000, 5, 1, "   1.5 s"},
+		{1500000000000, 7, 2, "    1.50 s"},
+		{1234567000000, 9, 4, "    1.2346 s"},
+		{86400000000000000, 3, 0, "   1 d"},
+	}
+	for _, test := range tests {
+		got := FormatTimeWidth(test.picoseconds, test.width, test.decimals)
+		if got != test.expected {
+			t.Errorf("FormatTimeWidth(%v, %v, %v) failed: expected: %q, got %q", test.picoseconds, test.width, test.decimals, test.expected, got)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    uint64
+		expected string
+	}{
+		{0, ""},
+		{1, "       1 B"},
+		{1023, "    1023 B"},
+		{1 << 10, "  1.00 KiB"},
+		{1536, "  1.50 KiB"},
+		{1<<20 - 1, "1024.0 KiB"},
+		{1 << 20, "  1.00 MiB"},
+		{256 << 20, " 256.0 MiB"},
+		{1 << 30, "  1.00 GiB"},
+		{3 << 29, "  1.50 GiB"},
+		{1 << 40, "  1.00 TiB"},
+		{1 << 50, "  1.00 PiB"},
+		{1 << 60, "  1.00 EiB"},
+		{math.MaxUint64, " 16.00 EiB"},
+	}
+	for _, test := range tests {
+		got := FormatBytes(test.bytes)
+		if got != test.expected {
+			t.Errorf("FormatBytes(%v) failed: expected: %q, got %q", test.bytes, test.expected, got)
+		}
+	}
+}
+
+func TestFormatBytesWidth(t *testing.T) {
+	tests := []struct {
+		bytes    uint64
+		width    int
+		decimals int
+		expected string
+	}{
+		{0, 4, 0, ""},
+		{512, 4, 0, "   512 B"},
+		{1536, 4, 0, "   2 KiB"},
+		{1536, 8, 3, "   1.500 KiB"},
+	}
+	for _, test := range tests {
+		got := FormatBytesWidth(test.bytes, test.width, test.decimals)
+		if got != test.expected {
+			t.Errorf("FormatBytesWidth(%v, %v, %v) failed: expected: %q, got %q", test.bytes, test.width, test.decimals, test.expected, got)
+		}
+	}
+}
+
+func TestFormatCounter(t *testing.T) {
+	tests := []struct {
+		counter  uint64
+		width    int
+		expected string
+	}{
+		{0, 6, "      "},
+		{1, 6, "     1"},
+		{999, 6, "   999"},
+		{1000, 6, " 1.00K"},
+		{1500, 6, " 1.50K"},
+		{123456, 6, "123.5K"},
+		{999994, 6, " 1.00M"},
+		{999999, 6, " 1.00M"},
+		{999999, 4, "1.0M"},
+		{1000, 4, "1.0K"},
+		{1000, 3, " 1K"},
+		{12345, 3, "12K"},
+		{999, 3, "999"},
+		{1000000, 6, " 1.00M"},
+		{2300000, 6, " 2.30M"},
+		{1000000000, 6, " 1.00G"},
+		{4100000000, 6, " 4.10G"},
+		{1000000000000, 6, " 1.00T"},
+		{1500, 10, "     1.50K"},
+	}
+	for _, test := range tests {
+		got := FormatCounter(test.counter, test.width)
+		if got != test.expected {
+			t.Errorf("FormatCounter(%v, %v) failed: expected: %q, got %q", test.counter, test.width, test.expected, got)
+		}
+	}
+}
+
+func TestFormatID(t *testing.T) {
+	tests := []struct {
+		id       uint64
+		width    int
+		expected string
+	}{
+		{0, 6, "      "},
+		{42, 6, "    42"},
+		{1234567, 6, "1234567"},
+	}
+	for _, test := range tests {
+		got := FormatID(test.id, test.width)
+		if got != test.expected {
+			t.Errorf("FormatID(%v, %v) failed: expected: %q, got %q", test.id, test.width, test.expected, got)
+		}
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		done     float64
+		width    int
+		expected string
+	}{
+		{0, 4, "[....]"},
+		{0.5, 4, "[##..]"},
+		{0.99, 4, "[###.]"},
+		{1, 4, "[####]"},
+		{1.5, 4, "[####]"},
+		{-1, 4, "[....]"},
+	}
+	for _, test := range tests {
+		got := ProgressBar(test.done, test.width)
+		if got != test.expected {
+			t.Errorf("ProgressBar(%v, %v) failed: expected: %q, got %q", test.done, test.width, test.expected, got)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		width    int
+		expected string
+	}{
+		{0, 10, "          "},
+		{-time.Second, 10, "   -1.00 s"},
+		{-1500 * time.Microsecond, 12, "    -1.50 ms"},
+		{-time.Nanosecond, 10, "  -1.00 ns"},
+		{time.Duration(math.MinInt64), 10, "  -30.50 w"}, // limited as FormatTime can hold no more
+		{time.Nanosecond, 10, "   1.00 ns"},
+		{999 * time.Nanosecond, 10, " 999.00 ns"},
+		{1500 * time.Nanosecond, 10, "   1.50 us"},
+		{25 * time.Millisecond, 10, "  25.00 ms"},
+		{25 * time.Millisecond, 12, "    25.00 ms"},
+		{90 * time.Second, 10, "    1.50 m"},
+		{5 * time.Second, 10, "    5.00 s"},
+		{2 * time.Hour, 10, "    2.00 h"},
+		{26*time.Hour + 30*time.Minute, 10, "    1.10 d"},
+		{-26 * time.Hour, 10, "   -1.08 d"},
+	}
+	for _, test := range tests {
+		got := FormatDurationWidth(test.duration, test.width)
+		if got != test.expected {
+			t.Errorf("FormatDurationWidth(%v,%v) failed: expected: %q, got %q", test.duration, test.width, test.expected, got)
+		}
+		if test.width == 10 && FormatDuration(test.duration) != got {
+			t.Errorf("FormatDuration(%v) failed: expected: %q, got %q", test.duration, got, FormatDuration(test.duration))
+		}
+	}
+}
+
 func TestSecToTime(t *testing.T) {
 	tests := []struct {
 		seconds  uint64
@@ -71,11 +345,71 @@ func TestSecToTime(t *testing.T) {
 		{61, "00:01:01"},
 		{3600, "01:00:00"},
 		{3601, "01:00:01"},
+		{86400, "24:00:00"},
+		{90000, "25:00:00"},
+		{90061, "25:01:01"},
+		{10*86400 + 3661, "241:01:01"},
 	}
 	for _, test := range tests {
-		got := secToTime(test.seconds)
+		got := SecToTime(test.seconds)
 		if got != test.expected {
-			t.Errorf("secToTime(%v) failed: expected: %q, got %q", test.seconds, test.expected, got)
+			t.Errorf("SecToTime(%v) failed: expected: %q, got %q", test.seconds, test.expected, got)
+		}
+	}
+}
+
+func TestSecToTimeDays(t *testing.T) {
+	tests := []struct {
+		seconds  uint64
+		expected string
+	}{
+		{0, "00:00:00"},
+		{86399, "23:59:59"},
+		{86400, "1d 00:00:00"},
+		{90061, "1d 01:01:01"},
+		{10*86400 + 3661, "10d 01:01:01"},
+	}
+	for _, test := range tests {
+		got := SecToTimeDays(test.seconds)
+		if got != test.expected {
+			t.Errorf("SecToTimeDays(%v) failed: expected: %q, got %q", test.seconds, test.expected, got)
+		}
+	}
+}
+
+func TestDivideOrNaN(t *testing.T) {
+	if got := DivideOrNaN(1, 0); !math.IsNaN(got) {
+		t.Errorf("DivideOrNaN(1,0) failed: expected: NaN, got %v", got)
+	}
+	if got := DivideOrNaN(0, 1); got != 0 {
+		t.Errorf("DivideOrNaN(0,1) failed: expected: 0, got %v", got)
+	}
+	if got := DivideOrNaN(1, 2); got != 0.5 {
+		t.Errorf("DivideOrNaN(1,2) failed: expected: 0.5, got %v", got)
+	}
+}
+
+func TestDivideRound(t *testing.T) {
+	tests := []struct {
+		a        uint64
+		b        uint64
+		decimals int
+		expected float64
+	}{
+		{1, 0, 2, 0},
+		{2, 3, 2, 0.67},
+		{1, 3, 2, 0.33},
+		{1, 8, 2, 0.13}, // 0.125 rounds half up
+		{29, 100, 2, 0.29},
+		{2, 3, 0, 1},
+		{1, 2, 0, 1},
+		{2, 3, -1, 1},
+		{math.MaxUint64, 1, 2, math.MaxUint64},
+	}
+	for _, test := range tests {
+		got := DivideRound(test.a, test.b, test.decimals)
+		if got != test.expected {
+			t.Errorf("DivideRound(%v,%v,%v) failed: expected: %v, got %v", test.a, test.b, test.decimals, test.expected, got)
 		}
 	}
 }
@@ -102,6 +436,48 @@ func TestDivide(t *testing.T) {
 	}
 }
 
+func TestPerSecond(t *testing.T) {
+	tests := []struct {
+		delta    uint64
+		interval time.Duration
+		expected float64
+	}{
+		{10, 0, 0},
+		{10, -time.Second, 0},
+		{0, time.Second, 0},
+		{10, time.Second, 10},
+		{10, 4 * time.Second, 2.5},
+		{3, 500 * time.Millisecond, 6},
+	}
+	for _, test := range tests {
+		if got := PerSecond(test.delta, test.interval); got != test.expected {
+			t.Errorf("PerSecond(%v,%v) failed: expected: %v, got %v", test.delta, test.interval, test.expected, got)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		current  uint64
+		previous uint64
+		interval time.Duration
+		expected float64
+	}{
+		{110, 100, time.Second, 10},
+		{110, 100, 4 * time.Second, 2.5},
+		{100, 100, time.Second, 0},
+		{5, 100, time.Second, 0}, // reset
+		{110, 100, 0, 0},
+		{103, 100, 500 * time.Millisecond, 6},
+		{101, 100, 250 * time.Millisecond, 4},
+	}
+	for _, test := range tests {
+		if got := Rate(test.current, test.previous, test.interval); got != test.expected {
+			t.Errorf("Rate(%v,%v,%v) failed: expected: %v, got %v", test.current, test.previous, test.interval, test.expected, got)
+		}
+	}
+}
+
 func TestQualifiedTableName(t *testing.T) {
 	tests := []struct {
 		schema   string
@@ -122,3 +498,66 @@ func TestQualifiedTableName(t *testing.T) {
 		}
 	}
 }
+
+func TestQualifiedTableNameQuoted(t *testing.T) {
+	tests := []struct {
+		schema   string
+		table    string
+		expected string
+	}{
+		{"", "", ""},
+		{"schema", "table", "`schema1`.`table1`"},
+		{"", "table", "`table1`"},
+	}
+
+	for _, test := range tests {
+		got := QualifiedTableNameQuoted(test.schema, test.table)
+		if got != test.expected {
+			t.Errorf("QualifiedTableNameQuoted(%q,%q) failed: expected: %q, got %q", test.schema, test.table, test.expected, got)
+		}
+	}
+}
+
+func TestQualifiedTableNameRel(t *testing.T) {
+	tests := []struct {
+		schema        string
+		table         string
+		currentSchema string
+		expected      string
+	}{
+		{"", "", "", ""},
+		{"", "table", "", "table1"},
+		{"", "table", "schema", "table1"},
+		{"schema", "table", "schema", "table1"},
+		{"schema", "table", "some_schema", "schema1.table1"},
+		{"schema", "table", "", "schema1.table1"},
+	}
+
+	for _, test := range tests {
+		got := QualifiedTableNameRel(test.schema, test.table, test.currentSchema)
+		if got != test.expected {
+			t.Errorf("QualifiedTableNameRel(%q,%q,%q) failed: expected: %q, got %q", test.schema, test.table, test.currentSchema, test.expected, got)
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", "``"},
+		{"table", "`table`"},
+		{"my-db", "`my-db`"},
+		{"select", "`select`"},
+		{"a.b", "`a.b`"},
+		{"odd`name", "`odd``name`"},
+	}
+
+	for _, test := range tests {
+		got := QuoteIdentifier(test.name)
+		if got != test.expected {
+			t.Errorf("QuoteIdentifier(%q) failed: expected: %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package lib
+
+// Severity is the classification of a value against its thresholds
+type Severity int
+
+// Severity* constants are the possible classifications, in increasing order of concern
+const (
+	SeverityOK Severity = iota
+	SeverityWarn
+	SeverityCrit
+)
+
+// ColorReset is the ANSI escape sequence to return to the default colour after Color()
+const ColorReset = "\x1b[0m"
+
+// Classify returns the severity of value where higher values are worse.
+// Reaching a threshold is enough to raise the severity.
+func Classify(value, warn, crit float64) Severity {
+	switch {
+	case value >= crit:
+		return SeverityCrit
+	case value >= warn:
+		return SeverityWarn
+	}
+	return SeverityOK
+}
+
+// ClassifyInverted returns the severity of value where lower values are
+// worse, e.g. a hit ratio, so crit is expected to be below warn.
+func ClassifyInverted(value, warn, crit float64) Severity {
+	switch {
+	case value <= crit:
+		return SeverityCrit
+	case value <= warn:
+		return SeverityWarn
+	}
+	return SeverityOK
+}
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityOK:
+		return "ok"
+	case SeverityWarn:
+		return "warn"
+	case SeverityCrit:
+		return "crit"
+	}
+	return "unknown"
+}
+
+// Color returns the ANSI escape sequence for the severity's colour:
+// green, yellow or red
+func (s Severity) Color() string {
+	switch s {
+	case SeverityOK:
+		return "\x1b[32m"
+	case SeverityWarn:
+		return "\x1b[33m"
+	case SeverityCrit:
+		return "\x1b[31m"
+	}
+	return ColorReset
+}
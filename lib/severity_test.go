@@ -0,0 +1,62 @@
+package lib
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected Severity
+	}{
+		{0, SeverityOK},
+		{0.79, SeverityOK},
+		{0.8, SeverityWarn},
+		{0.89, SeverityWarn},
+		{0.9, SeverityCrit},
+		{1.5, SeverityCrit},
+	}
+	for _, test := range tests {
+		if got := Classify(test.value, 0.8, 0.9); got != test.expected {
+			t.Errorf("Classify(%v, 0.8, 0.9) failed: expected: %v, got %v", test.value, test.expected, got)
+		}
+	}
+}
+
+func TestClassifyInverted(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected Severity
+	}{
+		{1, SeverityOK},
+		{0.991, SeverityOK},
+		{0.99, SeverityWarn},
+		{0.951, SeverityWarn},
+		{0.95, SeverityCrit},
+		{0, SeverityCrit},
+	}
+	for _, test := range tests {
+		if got := ClassifyInverted(test.value, 0.99, 0.95); got != test.expected {
+			t.Errorf("ClassifyInverted(%v, 0.99, 0.95) failed: expected: %v, got %v", test.value, test.expected, got)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		name     string
+		color    string
+	}{
+		{SeverityOK, "ok", "\x1b[32m"},
+		{SeverityWarn, "warn", "\x1b[33m"},
+		{SeverityCrit, "crit", "\x1b[31m"},
+		{Severity(99), "unknown", ColorReset},
+	}
+	for _, test := range tests {
+		if got := test.severity.String(); got != test.name {
+			t.Errorf("Severity(%d).String() failed: expected: %q, got %q", int(test.severity), test.name, got)
+		}
+		if got := test.severity.Color(); got != test.color {
+			t.Errorf("Severity(%d).Color() failed: expected: %q, got %q", int(test.severity), test.color, got)
+		}
+	}
+}
@@ -7,16 +7,25 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"runtime/pprof"
+	"strconv"
+	"time"
 
 	"github.com/howeyc/gopass"
 
+	"github.com/sjmudd/ps-top/alert"
 	"github.com/sjmudd/ps-top/app"
 	"github.com/sjmudd/ps-top/connector"
+	"github.com/sjmudd/ps-top/global"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/filter"
 	"github.com/sjmudd/ps-top/mylog"
+	"github.com/sjmudd/ps-top/output"
+	"github.com/sjmudd/ps-top/rc"
+	"github.com/sjmudd/ps-top/screen"
 	"github.com/sjmudd/ps-top/version"
+	"github.com/sjmudd/ps-top/wait"
 )
 
 var (
@@ -24,12 +33,34 @@ var (
 
 	// command line flags
 	cpuprofile         = flag.String("cpuprofile", "", "write cpu profile to file")
+	flagAdaptive       = flag.Bool("adaptive-interval", false, "Adjust the interval to the time taken to collect data and the server's load")
+	flagAlertThreshold = flag.String("alert-threshold", "", "Optional comma-separated list of <status_variable>:<value> thresholds to alert on")
+	flagAlertWebhook   = flag.String("alert-webhook", "", "Optional url to send alerts to as JSON")
 	flagAnonymise      = flag.Bool("anonymise", false, "Anonymise hostname, user, db and table names (default: false)")
 	flagAskpass        = flag.Bool("askpass", false, "Ask for password interactively")
+	flagCSVQuote       = flag.String("csv-quote", "minimal", "When to quote csv fields: always, minimal or never")
+	flagCSVSeparator   = flag.String("csv-separator", ",", "The csv field separator, a single character")
 	flagDatabaseFilter = flag.String("database-filter", "", "Optional comma-separated filter of database names")
 	flagDebug          = flag.Bool("debug", false, "Enabling debug logging")
+	flagFilter         = flag.String("filter", "", "Only show the rows whose name (e.g. schema.table, file name or user) matches the given regexp")
+	flagFormat         = flag.String("format", "", "Write the collected data in the given format instead of showing it on the screen: csv, influx or json")
+	flagFreezeColumns  = flag.Bool("freeze-columns", false, "Keep the column widths stable across intervals, toggled with 'f'")
 	flagHelp           = flag.Bool("help", false, "Provide some help for "+lib.ProgName)
-	flagInterval       = flag.Int("interval", 1, "Set the initial poll interval (default 1 second)")
+	flagHighlight      = flag.String("highlight", "reverse", "How to show the selected row: reverse, bold, underline or color")
+	flagInfluxURL      = flag.String("influx-url", "", "Send influx output to the given http(s):// or udp:// url instead of stdout")
+	flagInterval       = newIntervalFlag("interval", time.Second, "Set the initial poll interval, e.g. 5 (seconds), 5s or 500ms")
+	flagMaxInterval    = newIntervalFlag("max-interval", time.Minute, "The longest interval used with --adaptive-interval, e.g. 60 (seconds) or 1m")
+	flagMinInterval    = newIntervalFlag("min-interval", time.Second, "The shortest interval used with --adaptive-interval, e.g. 1 (second) or 500ms")
+	flagOutput         = flag.String("output", "", "Append the batch output to the given file instead of writing it to stdout (implies --format=csv)")
+	flagNoColor        = flag.Bool("no-color", false, "Do not use colours, using the terminal's default colours instead")
+	flagProfile        = flag.String("profile", "", "Use the named connection profile from ~/.pstoprc")
+	flagPrometheus     = flag.String("prometheus-listen", "", "Serve the collected data as Prometheus metrics on the given address, e.g. :9104")
+	flagRecord         = flag.String("record", "", "Record the data of every view each interval to the given file")
+	flagReplay         = flag.String("replay", "", "Show the data recorded with --record in the given file instead of connecting to a server")
+	flagReplayBucket   = newIntervalFlag("replay-bucket", 0, "Average the replayed data over buckets of the given size, e.g. 1m, showing counters as rates")
+	flagServerVersion  = flag.String("server-version", "", "The MySQL server version, e.g. 8.0 or 10.11-MariaDB, to avoid probing where to find the global variables")
+	flagSmooth         = flag.Int("smooth", 0, "Show rates as a moving average over the given number of intervals")
+	flagStatusLine     = flag.Bool("status-line", false, "Write a single line summary of the server's activity each interval")
 	flagVersion        = flag.Bool("version", false, "Show the version of "+lib.ProgName)
 	flagView           = flag.String("view", "", "Provide view to show when starting "+lib.ProgName+" (default: table_io_latency)")
 )
@@ -43,21 +74,50 @@ func usage() {
 	fmt.Println("Usage: " + lib.ProgName + " <options>")
 	fmt.Println("")
 	fmt.Println("Options:")
+	fmt.Println("--adaptive-interval                      Lengthen the interval if collecting is expensive and shorten it if cheap and the server is busy")
+	fmt.Println("--alert-threshold=<name>:<value>[,...]   Alert if any of the given global status values exceed the threshold")
+	fmt.Println("--alert-webhook=<url>                    Send alerts to the given url (Slack compatible JSON)")
 	fmt.Println("--anonymise=<true|false>                 Anonymise hostname, user, db and table names")
 	fmt.Println("--askpass                                Request password to be provided interactively")
+	fmt.Println("--csv-quote=<always|minimal|never>       When to quote csv fields (default: minimal, only when needed)")
+	fmt.Println("--csv-separator=<char>                   The csv field separator (default: ,)")
 	fmt.Println("--database-filter=db1[,db2,db3,...]      Optional database names to filter on, default ''")
 	fmt.Println("--defaults-file=/path/to/defaults.file   Connect to MySQL using given defaults-file, default ~/.my.cnf")
+	fmt.Println("--filter=<regexp>                        Only show the rows whose name, e.g. schema.table, file name or user, matches the regexp, changed with '/'")
+	fmt.Println("--format=<format>                        Write the data of the view each interval instead of showing it on the screen")
+	fmt.Println("                                         Possible values: csv (comma separated values), influx (InfluxDB line protocol) or json (a JSON document per line)")
+	fmt.Println("--freeze-columns                         Keep the column widths stable across intervals, toggled with 'f'")
 	fmt.Println("--help                                   Show this help message")
-	fmt.Println("--host=<hostname>                        MySQL host to connect to")
-	fmt.Println("--interval=<seconds>                     Set the default poll interval (in seconds)")
+	fmt.Println("--highlight=<style>                      How to show the selected row: reverse (default), bold, underline or color")
+	fmt.Println("--host=<hostname>                        MySQL host to connect to, or a comma-separated list of host[:port] to switch between with [ and ]")
+	fmt.Println("--influx-url=<url>                       Send influx output to an http(s):// write url or udp://host:port, implies --format=influx")
+	fmt.Println("--interval=<interval>                    Set the default poll interval in seconds or as a duration, e.g. 5 or 500ms (default: 1s)")
+	fmt.Println("--login-path=<name>                      Connect to MySQL using the login path written by mysql_config_editor to ~/.mylogin.cnf")
+	fmt.Println("--max-interval=<interval>                The longest interval used with --adaptive-interval, in seconds or as a duration (default: 1m)")
+	fmt.Println("--min-interval=<interval>                The shortest interval used with --adaptive-interval, in seconds or as a duration (default: 1s)")
+	fmt.Println("--no-color                               Do not use colours, using the terminal's default colours instead")
+	fmt.Println("--output=<file>                          Append the batch output to the file instead of stdout, implies --format=csv")
 	fmt.Println("--password=<password>                    Password to use when connecting")
 	fmt.Println("--port=<port>                            MySQL port to connect to")
+	fmt.Println("--profile=<name>                         Use the connection settings of [profile <name>] in ~/.pstoprc")
+	fmt.Println("--prometheus-listen=<address>            Serve the data of all views as Prometheus metrics on http://<address>/metrics, e.g. :9104")
+	fmt.Println("--record=<file>                          Record the data of every view each interval to the file, one JSON document per line")
+	fmt.Println("--replay=<file>                          Show the data recorded in the file, a record each interval, instead of connecting to a server")
+	fmt.Println("--replay-bucket=<interval>               Average the replayed data over buckets of the given size, e.g. 1m, showing counters as rates per second")
+	fmt.Println("--server-version=<version>               The server version, e.g. 8.0 or 10.11-MariaDB, so the global variables and status tables needn't be probed")
+	fmt.Println("--smooth=<intervals>                     Show rates as a moving average over the given number of intervals, toggled with 'm'")
 	fmt.Println("--socket=<path>                          MySQL path of the socket to connect to")
+	fmt.Println("--ssl-ca=<file>                          File of the PEM certificate authorities to verify the server with, implies --ssl-mode=VERIFY_CA")
+	fmt.Println("--ssl-cert=<file>                        File of the PEM client certificate, used with --ssl-key")
+	fmt.Println("--ssl-key=<file>                         File of the PEM client key, used with --ssl-cert")
+	fmt.Println("--ssl-mode=<mode>                        TLS mode: DISABLED, PREFERRED, REQUIRED, VERIFY_CA or VERIFY_IDENTITY (default: no TLS)")
+	fmt.Println("                                         REQUIRED and stronger fail if the server does not support TLS")
+	fmt.Println("--status-line                            Write a single line summary (qps, connections and replication lag) each interval")
 	fmt.Println("--user=<user>                            User to connect with")
 	fmt.Println("--use-environment                        Connect to MySQL using a go dsn collected from MYSQL_DSN e.g. MYSQL_DSN='test_user:test_pass@tcp(127.0.0.1:3306)/performance_schema'")
 	fmt.Println("--version                                Show the version")
 	fmt.Println("--view=<view>                            Determine the view you want to see when " + lib.ProgName + " starts (default: table_io_latency)")
-	fmt.Println("                                         Possible values: table_io_latency table_io_ops file_io_latency table_lock_latency user_latency mutex_latency stages_latency")
+	fmt.Println("                                         Possible values: table_io_latency table_io_ops index_io file_io_latency table_lock_latency user_latency processlist mutex_latency stages_latency thread_activity response_time lock_errors transactions active_transactions metadata_locks applier_workers table_cache innodb diagnostics commands statements replication")
 }
 
 // askPass asks for a password interactively from the user and returns it.
@@ -71,13 +131,80 @@ func askPass() (string, error) {
 	return stringPassword, nil
 }
 
+// intervalFlag is a flag holding the poll interval
+type intervalFlag time.Duration
+
+// newIntervalFlag defines an interval flag with the given name, default value and usage
+func newIntervalFlag(name string, value time.Duration, usage string) *intervalFlag {
+	interval := intervalFlag(value)
+	flag.Var(&interval, name, usage)
+	return &interval
+}
+
+// String returns the interval as a duration
+func (interval *intervalFlag) String() string {
+	return time.Duration(*interval).String()
+}
+
+// Set sets the interval from a duration or a number of seconds
+func (interval *intervalFlag) Set(value string) error {
+	parsed, err := parseInterval(value)
+	if err != nil {
+		return err
+	}
+	*interval = intervalFlag(parsed)
+	return nil
+}
+
+// parseInterval converts a duration such as "5s" or "500ms" into a time.Duration.
+// A plain number is treated as a number of seconds.
+func parseInterval(value string) (time.Duration, error) {
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid interval %q: %v", value, err)
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid interval %q: must be positive", value)
+	}
+	return interval, nil
+}
+
+// getFormat returns the batch output format, or an empty string if running interactively
+func getFormat() (string, error) {
+	format := *flagFormat
+	if format == "" && *flagInfluxURL != "" {
+		format = "influx"
+	}
+	if format == "" && *flagOutput != "" {
+		format = "csv"
+	}
+	if *flagOutput != "" && *flagInfluxURL != "" {
+		return "", fmt.Errorf("--output and --influx-url can not be used together")
+	}
+
+	switch format {
+	case "", "csv", "influx", "json":
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q", format)
+}
+
 // getConfig collects the configuration from the command line arguments
 func getConnectorConfig() connector.Config {
 	defaultsFile := flag.String("defaults-file", "", "Define the defaults file to read")
-	host := flag.String("host", "", "Provide the hostname of the MySQL to connect to")
+	loginPath := flag.String("login-path", "", "Use the named login path written by mysql_config_editor to ~/.mylogin.cnf")
+	host := flag.String("host", "", "Provide the hostname of the MySQL to connect to, or a comma-separated list of host[:port]")
 	password := flag.String("password", "", "Provide the password when connecting to the MySQL server")
 	port := flag.Int("port", 0, "Provide the port number of the MySQL to connect to (default: 3306)") /* Port is deliberately 0 here, defaults to 3306 elsewhere */
 	socket := flag.String("socket", "", "Provide the path to the local MySQL server to connect to")
+	sslCA := flag.String("ssl-ca", "", "File of the PEM certificate authorities to verify the server with")
+	sslCert := flag.String("ssl-cert", "", "File of the PEM client certificate")
+	sslKey := flag.String("ssl-key", "", "File of the PEM client key")
+	sslMode := flag.String("ssl-mode", "", "TLS mode: DISABLED, PREFERRED, REQUIRED, VERIFY_CA or VERIFY_IDENTITY")
 	user := flag.String("user", "", "Provide the username to connect with to MySQL (default: $USER)")
 	useEnvironment := flag.Bool("use-environment", false, "Use the environment variable MYSQL_DSN (go dsn) to connect with to MySQL")
 
@@ -86,9 +213,14 @@ func getConnectorConfig() connector.Config {
 	return connector.Config{
 		DefaultsFile:   defaultsFile,
 		Host:           host,
+		LoginPath:      loginPath,
 		Password:       password,
 		Port:           port,
 		Socket:         socket,
+		SSLCA:          sslCA,
+		SSLCert:        sslCert,
+		SSLKey:         sslKey,
+		SSLMode:        sslMode,
 		User:           user,
 		UseEnvironment: useEnvironment,
 	}
@@ -97,6 +229,34 @@ func getConnectorConfig() connector.Config {
 func main() {
 	connectorFlags = getConnectorConfig()
 
+	// settings not given on the command line may come from the environment, a profile or ~/.pstoprc
+	defaults, err := rc.Defaults()
+	if err == nil {
+		err = rc.ApplyEnvironment(flag.CommandLine, os.Getenv)
+	}
+	if err != nil {
+		fmt.Printf("Failed to read the default settings: %v\n", err)
+		return
+	}
+	if *flagProfile == "" {
+		*flagProfile = defaults["profile"]
+	}
+
+	if *flagProfile != "" {
+		profile, err := rc.Profile(*flagProfile)
+		if err == nil {
+			connectorFlags, err = connector.ApplyProfile(connectorFlags, profile)
+		}
+		if err != nil {
+			fmt.Printf("Failed to use profile %q: %v\n", *flagProfile, err)
+			return
+		}
+	}
+	if err := rc.ApplyDefaults(flag.CommandLine, defaults); err != nil {
+		fmt.Printf("Failed to use the default settings: %v\n", err)
+		return
+	}
+
 	// Enable logging if requested or PSTOP_DEBUG=1
 	mylog.SetupLogging(*flagDebug || os.Getenv("PSTOP_DEBUG") == "1", lib.ProgName+".log")
 
@@ -131,13 +291,118 @@ func main() {
 		return
 	}
 
+	interval := time.Duration(*flagInterval)
+
+	thresholds, err := alert.ParseThresholds(*flagAlertThreshold)
+	if err != nil {
+		fmt.Printf("Failed to parse --alert-threshold: %v\n", err)
+		return
+	}
+
+	highlight, err := screen.ParseHighlight(*flagHighlight)
+	if err != nil {
+		fmt.Printf("Failed to parse --highlight: %v\n", err)
+		return
+	}
+
+	if *flagReplay != "" && (*flagRecord != "" || *flagStatusLine || *flagPrometheus != "") {
+		fmt.Println("Failed to parse --replay: can not be used with --record, --status-line or --prometheus-listen")
+		return
+	}
+	if *flagReplayBucket != 0 && (*flagReplay == "" || *flagReplayBucket < 0) {
+		fmt.Println("Failed to parse --replay-bucket: must be positive and used with --replay")
+		return
+	}
+
+	var nameFilter *regexp.Regexp
+	if *flagFilter != "" {
+		if nameFilter, err = regexp.Compile(*flagFilter); err != nil {
+			fmt.Printf("Failed to parse --filter: %v\n", err)
+			return
+		}
+	}
+
+	smooth := *flagSmooth
+	if smooth < 0 {
+		fmt.Printf("Failed to parse --smooth: invalid number of intervals %d\n", smooth)
+		return
+	}
+
+	format, err := getFormat()
+	if err != nil {
+		fmt.Printf("Failed to parse --format: %v\n", err)
+		return
+	}
+
+	separator, err := output.ParseSeparator(*flagCSVSeparator)
+	if err != nil {
+		fmt.Printf("Failed to parse --csv-separator: %v\n", err)
+		return
+	}
+
+	quote, err := output.ParseQuoting(*flagCSVQuote)
+	if err != nil {
+		fmt.Printf("Failed to parse --csv-quote: %v\n", err)
+		return
+	}
+
+	var serverVersion *global.ServerVersion
+	if *flagServerVersion != "" {
+		v, err := global.ParseServerVersion(*flagServerVersion)
+		if err != nil {
+			fmt.Printf("Failed to parse --server-version: %v\n", err)
+			return
+		}
+		serverVersion = &v
+	}
+
+	var adaptive *wait.Adaptive
+	if *flagAdaptive {
+		if *flagMinInterval <= 0 || *flagMaxInterval < *flagMinInterval {
+			fmt.Printf("Failed to parse --min-interval / --max-interval: invalid bounds %s, %s\n", flagMinInterval, flagMaxInterval)
+			return
+		}
+		adaptive = &wait.Adaptive{
+			Min: time.Duration(*flagMinInterval),
+			Max: time.Duration(*flagMaxInterval),
+		}
+	}
+
+	servers, err := connectorFlags.Servers()
+	if err != nil {
+		fmt.Printf("Failed to parse --host: %v\n", err)
+		return
+	}
+	if len(servers) > 1 && (format != "" || *flagStatusLine || *flagPrometheus != "" || *flagRecord != "") {
+		fmt.Println("Failed to parse --host: several servers can only be shown on the screen, not with --format, --status-line, --prometheus-listen or --record")
+		return
+	}
+
 	app := app.NewApp(
 		connectorFlags,
 		app.Settings{
-			Anonymise: *flagAnonymise,
-			Filter:    filter.NewDatabaseFilter(*flagDatabaseFilter),
-			Interval:  *flagInterval,
-			ViewName:  *flagView,
+			AdaptiveInterval: adaptive,
+			AlertThresholds:  thresholds,
+			AlertWebhook:     *flagAlertWebhook,
+			Anonymise:        *flagAnonymise,
+			CSV:              output.CSVOptions{Separator: separator, Quote: quote},
+			Filter:           filter.NewDatabaseFilter(*flagDatabaseFilter),
+			Format:           format,
+			FreezeColumns:    *flagFreezeColumns,
+			Highlight:        highlight,
+			InfluxURL:        *flagInfluxURL,
+			Interval:         interval,
+			NameFilter:       nameFilter,
+			NoColor:          *flagNoColor,
+			Output:           *flagOutput,
+			PrometheusListen: *flagPrometheus,
+			Record:           *flagRecord,
+			Replay:           *flagReplay,
+			ReplayBucket:     time.Duration(*flagReplayBucket),
+			ServerVersion:    serverVersion,
+			Smooth:           smooth,
+			StatusLine:       *flagStatusLine,
+			ViewName:         *flagView,
 		})
 	defer app.Cleanup()
 	app.Run()
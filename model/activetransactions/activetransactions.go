@@ -0,0 +1,87 @@
+// Package activetransactions provides library routines for ps-top
+// for showing the active transactions and their isolation level.
+package activetransactions
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// ActiveTransactions holds a table of rows and the summary of the completed transactions
+type ActiveTransactions struct {
+	baseobject.BaseObject         // embedded
+	Results               Rows    // the transactions currently active
+	Totals                Row     // totals of results
+	first                 Summary // initial summary for relative values
+	last                  Summary // last loaded summary
+	Summary               Summary // summary of the completed transactions (maybe with subtraction)
+	db                    *sql.DB
+}
+
+// NewActiveTransactions returns an active transactions object using given config and db
+func NewActiveTransactions(cfg *config.Config, db *sql.DB) *ActiveTransactions {
+	log.Println("NewActiveTransactions()")
+	at := &ActiveTransactions{
+		db: db,
+	}
+	at.SetConfig(cfg)
+
+	return at
+}
+
+// Collect collects the active transactions and the summary of the completed
+// transactions from the db. Only the summary has relative values as each
+// collection of the active transactions is a snapshot.
+func (at *ActiveTransactions) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(at.db)
+	if err != nil {
+		return err
+	}
+	summary, err := collectSummary(at.db)
+	if err != nil {
+		return err
+	}
+
+	at.Results = collected
+	at.last = summary
+	at.LastCollected = time.Now()
+
+	// check if no first data or we need to reload initial characteristics
+	if at.FirstCollected.IsZero() || at.last.Count < at.first.Count {
+		at.first = at.last
+		at.FirstCollected = at.LastCollected
+	}
+
+	at.calculate()
+
+	log.Println("ActiveTransactions.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+func (at *ActiveTransactions) calculate() {
+	at.Totals = totals(at.Results)
+	at.Summary = at.last
+	if at.WantRelativeStats() {
+		at.Summary = at.last.subtract(at.first)
+	}
+}
+
+// ResetStatistics resets the summary of the completed transactions to the last values
+func (at *ActiveTransactions) ResetStatistics() {
+	at.first = at.last
+	at.FirstCollected = at.LastCollected
+
+	at.calculate()
+}
+
+// HaveRelativeStats returns if we have relative information
+func (at ActiveTransactions) HaveRelativeStats() bool {
+	return true
+}
@@ -0,0 +1,57 @@
+package activetransactions
+
+/* events_transactions_current is joined to threads to find the owner of each transaction.
+
+mysql> select THREAD_ID, STATE, TIMER_WAIT, ACCESS_MODE, ISOLATION_LEVEL, AUTOCOMMIT from events_transactions_current where STATE = 'ACTIVE';
++-----------+--------+--------------+-------------+-----------------+------------+
+| THREAD_ID | STATE  | TIMER_WAIT   | ACCESS_MODE | ISOLATION_LEVEL | AUTOCOMMIT |
++-----------+--------+--------------+-------------+-----------------+------------+
+|        52 | ACTIVE | 842318000000 | READ WRITE  | REPEATABLE READ | NO         |
++-----------+--------+--------------+-------------+-----------------+------------+
+
+The TIMER_WAIT of a transaction which has not finished is the time since it started.
+
+*/
+
+// Row contains the information of a single active transaction
+type Row struct {
+	ThreadID       uint64 // performance_schema thread id
+	ProcesslistID  uint64 // connection id as shown in the processlist
+	User           string
+	Host           string
+	Duration       uint64 // picoseconds since the transaction started
+	IsolationLevel string // e.g. REPEATABLE READ
+	AccessMode     string // READ WRITE or READ ONLY
+	Autocommit     string // YES or NO
+}
+
+// Name returns user@host of the owner of the transaction, or just the user if the host is unknown
+func (row Row) Name() string {
+	if row.Host == "" {
+		return row.User
+	}
+	return row.User + "@" + row.Host
+}
+
+// Summary holds the totals of the completed transactions from
+// events_transactions_summary_global_by_event_name
+type Summary struct {
+	Count        uint64 // number of completed transactions
+	SumTimerWait uint64 // their total duration in picoseconds
+	ReadWrite    uint64 // number of read write transactions
+	ReadOnly     uint64 // number of read only transactions
+}
+
+// subtract returns the summary less the initial values, or the summary
+// itself if the counters were reset since
+func (s Summary) subtract(initial Summary) Summary {
+	if s.Count < initial.Count || s.SumTimerWait < initial.SumTimerWait {
+		return s
+	}
+	return Summary{
+		Count:        s.Count - initial.Count,
+		SumTimerWait: s.SumTimerWait - initial.SumTimerWait,
+		ReadWrite:    s.ReadWrite - initial.ReadWrite,
+		ReadOnly:     s.ReadOnly - initial.ReadOnly,
+	}
+}
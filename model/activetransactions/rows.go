@@ -0,0 +1,92 @@
+// Package activetransactions contains the library routines for managing the
+// active transactions from performance_schema.events_transactions_current.
+package activetransactions
+
+import (
+	"database/sql"
+
+	"github.com/sjmudd/anonymiser"
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// totals returns the totals of all rows, the duration being that of the longest transaction
+func totals(rows Rows) Row {
+	total := Row{User: "Totals"}
+
+	for _, row := range rows {
+		if row.Duration > total.Duration {
+			total.Duration = row.Duration
+		}
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	query := `SELECT etc.THREAD_ID,
+	IFNULL(t.PROCESSLIST_ID, 0),
+	t.PROCESSLIST_USER,
+	t.PROCESSLIST_HOST,
+	IFNULL(etc.TIMER_WAIT, 0),
+	IFNULL(etc.ISOLATION_LEVEL, ''),
+	IFNULL(etc.ACCESS_MODE, ''),
+	IFNULL(etc.AUTOCOMMIT, '')
+FROM performance_schema.events_transactions_current etc
+LEFT JOIN performance_schema.threads t ON t.THREAD_ID = etc.THREAD_ID
+WHERE etc.STATE = 'ACTIVE'`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		var user, host sql.NullString
+		if err := rows.Scan(
+			&r.ThreadID,
+			&r.ProcesslistID,
+			&user,
+			&host,
+			&r.Duration,
+			&r.IsolationLevel,
+			&r.AccessMode,
+			&r.Autocommit); err != nil {
+			return nil, err
+		}
+		r.User = anonymiser.Anonymise("user", user.String)
+		r.Host = host.String
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// collectSummary returns the totals of the completed transactions
+func collectSummary(dbh *sql.DB) (Summary, error) {
+	var s Summary
+
+	query := `SELECT COUNT_STAR, SUM_TIMER_WAIT, COUNT_READ_WRITE, COUNT_READ_ONLY
+FROM performance_schema.events_transactions_summary_global_by_event_name
+WHERE EVENT_NAME = 'transaction'`
+
+	err := dbh.QueryRow(query).Scan(&s.Count, &s.SumTimerWait, &s.ReadWrite, &s.ReadOnly)
+	if err == sql.ErrNoRows {
+		return s, nil // the transaction instrument is not known
+	}
+
+	return s, err
+}
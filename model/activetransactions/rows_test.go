@@ -0,0 +1,35 @@
+package activetransactions
+
+import (
+	"testing"
+)
+
+func TestTotals(t *testing.T) {
+	rows := Rows{
+		{ThreadID: 50, User: "app", Duration: 2000},
+		{ThreadID: 51, User: "app", Duration: 9000},
+		{ThreadID: 52, User: "batch", Duration: 4000},
+	}
+
+	if total := rows.Totals(); total.Duration != 9000 || total.Name() != "Totals" {
+		t.Errorf("Totals() failed: expected the longest duration 9000 of Totals, got %+v", total)
+	}
+}
+
+func TestSummarySubtract(t *testing.T) {
+	tests := []struct {
+		summary  Summary
+		initial  Summary
+		expected Summary
+	}{
+		{Summary{10, 5000, 7, 3}, Summary{4, 2000, 3, 1}, Summary{6, 3000, 4, 2}},
+		{Summary{10, 5000, 7, 3}, Summary{}, Summary{10, 5000, 7, 3}},
+		{Summary{2, 100, 1, 1}, Summary{4, 2000, 3, 1}, Summary{2, 100, 1, 1}}, // reset since
+	}
+
+	for _, test := range tests {
+		if got := test.summary.subtract(test.initial); got != test.expected {
+			t.Errorf("%+v.subtract(%+v) failed: expected: %+v, got %+v", test.summary, test.initial, test.expected, got)
+		}
+	}
+}
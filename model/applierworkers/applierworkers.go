@@ -0,0 +1,63 @@
+// Package applierworkers provides library routines for ps-top
+// for showing the progress of the replication applier workers.
+package applierworkers
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// ApplierWorkers holds a table of rows
+type ApplierWorkers struct {
+	baseobject.BaseObject      // embedded
+	Results               Rows // the current state of each worker
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewApplierWorkers returns an applier workers object using given config and db
+func NewApplierWorkers(cfg *config.Config, db *sql.DB) *ApplierWorkers {
+	log.Println("NewApplierWorkers()")
+	aw := &ApplierWorkers{
+		db: db,
+	}
+	aw.SetConfig(cfg)
+
+	return aw
+}
+
+// Collect collects the current state of the applier workers from the db and stores the totals.
+// There are no relative values as each collection is a snapshot.
+func (aw *ApplierWorkers) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(aw.db)
+	if err != nil {
+		return err
+	}
+
+	aw.Results = collected
+	aw.LastCollected = time.Now()
+	if aw.FirstCollected.IsZero() {
+		aw.FirstCollected = aw.LastCollected
+	}
+	aw.Totals = totals(aw.Results)
+
+	log.Println("ApplierWorkers.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (aw *ApplierWorkers) ResetStatistics() {
+	log.Println("applierworkers.ApplierWorkers.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (aw ApplierWorkers) HaveRelativeStats() bool {
+	return false
+}
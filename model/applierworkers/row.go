@@ -0,0 +1,52 @@
+package applierworkers
+
+import (
+	"strconv"
+)
+
+/* This table exists in MySQL 5.7 but the transaction columns need MySQL 8.0
+
+CREATE TABLE `replication_applier_status_by_worker` (
+  `CHANNEL_NAME` char(64) NOT NULL,
+  `WORKER_ID` bigint unsigned NOT NULL,
+  `THREAD_ID` bigint unsigned DEFAULT NULL,
+  `SERVICE_STATE` enum('ON','OFF') NOT NULL,
+  `LAST_ERROR_NUMBER` int NOT NULL,
+  `LAST_ERROR_MESSAGE` varchar(1024) NOT NULL,
+  `LAST_ERROR_TIMESTAMP` timestamp(6) NOT NULL,
+  `LAST_APPLIED_TRANSACTION` char(57) DEFAULT NULL,
+  `LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP` timestamp(6) NOT NULL,
+  `LAST_APPLIED_TRANSACTION_IMMEDIATE_COMMIT_TIMESTAMP` timestamp(6) NOT NULL,
+  `LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP` timestamp(6) NOT NULL,
+  `LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP` timestamp(6) NOT NULL,
+  `APPLYING_TRANSACTION` char(57) DEFAULT NULL,
+  `APPLYING_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP` timestamp(6) NOT NULL,
+  `APPLYING_TRANSACTION_IMMEDIATE_COMMIT_TIMESTAMP` timestamp(6) NOT NULL,
+  `APPLYING_TRANSACTION_START_APPLY_TIMESTAMP` timestamp(6) NOT NULL,
+  ...
+  PRIMARY KEY (`CHANNEL_NAME`,`WORKER_ID`),
+  KEY `THREAD_ID` (`THREAD_ID`)
+) ENGINE=PERFORMANCE_SCHEMA
+
+*/
+
+// Row contains the state of a single applier worker
+type Row struct {
+	Channel      string
+	WorkerID     uint64
+	ThreadID     uint64
+	State        string // ON or OFF
+	LastApplied  string // the last applied transaction (GTID)
+	ApplyTime    uint64 // picoseconds taken to apply the last transaction
+	Applying     string // the transaction being applied, if any
+	Lag          uint64 // picoseconds since the original commit of the transaction being or last applied
+	ErrorNumber  int
+	ErrorMessage string
+	Workers      uint64 // number of workers (1 unless this is a totals row)
+	Errors       uint64 // number of workers with an error (0 or 1 unless this is a totals row)
+}
+
+// Name returns the channel and worker id, e.g. "channel_1:3"
+func (row Row) Name() string {
+	return row.Channel + ":" + strconv.FormatUint(row.WorkerID, 10)
+}
@@ -0,0 +1,101 @@
+// Package applierworkers contains the library routines for managing the
+// replication_applier_status_by_worker table.
+package applierworkers
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/ps-top/global"
+)
+
+const (
+	tableDoesNotExistErrorNum = 1146 // replication_applier_status_by_worker needs MySQL 5.7
+	unknownColumnErrorNum     = 1054 // the transaction columns need MySQL 8.0
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// totals returns the totals of all rows, the lag and apply time being the largest seen
+func totals(rows Rows) Row {
+	total := Row{Channel: "Totals"}
+
+	for _, row := range rows {
+		if row.Lag > total.Lag {
+			total.Lag = row.Lag
+		}
+		if row.ApplyTime > total.ApplyTime {
+			total.ApplyTime = row.ApplyTime
+		}
+		total.Workers += row.Workers
+		total.Errors += row.Errors
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	// timestamps which have never been set are 0000-00-00 and give NULL
+	query := `SELECT CHANNEL_NAME,
+	WORKER_ID,
+	IFNULL(THREAD_ID, 0),
+	SERVICE_STATE,
+	LAST_ERROR_NUMBER,
+	LAST_ERROR_MESSAGE,
+	IFNULL(LAST_APPLIED_TRANSACTION, ''),
+	IFNULL(GREATEST(0, TIMESTAMPDIFF(MICROSECOND, LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP, LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP)), 0),
+	IFNULL(APPLYING_TRANSACTION, ''),
+	IFNULL(GREATEST(0, IF(IFNULL(APPLYING_TRANSACTION, '') <> '',
+		TIMESTAMPDIFF(MICROSECOND, APPLYING_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP, NOW(6)),
+		TIMESTAMPDIFF(MICROSECOND, LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP, LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP))), 0)
+FROM performance_schema.replication_applier_status_by_worker`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		// the view will not be available but we are called by the initial collection of all views
+		if global.IsMysqlError(err, tableDoesNotExistErrorNum) || global.IsMysqlError(err, unknownColumnErrorNum) {
+			log.Println("applierworkers.collect() replication_applier_status_by_worker not available, ignoring:", err)
+			return t, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		var applyTime, lag uint64 // in microseconds
+		if err := rows.Scan(
+			&r.Channel,
+			&r.WorkerID,
+			&r.ThreadID,
+			&r.State,
+			&r.ErrorNumber,
+			&r.ErrorMessage,
+			&r.LastApplied,
+			&applyTime,
+			&r.Applying,
+			&lag); err != nil {
+			return nil, err
+		}
+		r.ApplyTime = applyTime * 1000000
+		r.Lag = lag * 1000000
+		r.Workers = 1
+		if r.ErrorNumber != 0 {
+			r.Errors = 1
+		}
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
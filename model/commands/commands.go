@@ -0,0 +1,113 @@
+// Package commands provides library routines for ps-top
+// for showing the Com_* global status counters.
+package commands
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+// prefix of the status counters we show
+const prefix = "com_"
+
+// Commands holds a table of rows
+type Commands struct {
+	baseobject.BaseObject                // embedded
+	first                 map[string]int // initial counters for relative values
+	previous              map[string]int // counters of the previous collection, for rates
+	previousCollected     time.Time      // time of the previous collection
+	last                  map[string]int // last loaded counters
+	Results               Rows           // results (maybe with subtraction)
+	Totals                Row            // totals of results
+	smoothed              *smooth.Rates  // recent changes by counter
+	db                    *sql.DB
+}
+
+// NewCommands returns a commands object using given config and db
+func NewCommands(cfg *config.Config, db *sql.DB) *Commands {
+	log.Println("NewCommands()")
+	c := &Commands{
+		db: db,
+	}
+	c.SetConfig(cfg)
+
+	return c
+}
+
+// Collect collects the Com_* counters from the db, updating first
+// values if needed, and then calculates the rates and totals.
+func (c *Commands) Collect() error {
+	start := time.Now()
+
+	collected, err := c.Status().ValuesWithPrefix(prefix)
+	if err != nil {
+		return err
+	}
+
+	c.previous = c.last
+	c.previousCollected = c.LastCollected
+	c.last = collected
+	c.LastCollected = time.Now()
+	c.updateSmoothedRates()
+
+	// check if no first data or we need to reload initial characteristics
+	if c.first == nil || needsRefresh(c.first, c.last) {
+		c.first = c.last
+		c.FirstCollected = c.LastCollected
+	}
+
+	c.calculate()
+
+	log.Println("Commands.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+func (c *Commands) calculate() {
+	var first map[string]int
+	if c.WantRelativeStats() {
+		first = c.first
+	}
+	interval := time.Duration(0)
+	if !c.previousCollected.IsZero() {
+		interval = c.LastCollected.Sub(c.previousCollected)
+	}
+
+	c.Results = newRows(c.last, first, c.previous, interval)
+	if c.smoothed != nil {
+		for i := range c.Results {
+			c.Results[i].SmoothedRate, _ = c.smoothed.PerSecond(prefix + c.Results[i].Name)
+		}
+	}
+	c.Totals = totals(c.Results)
+}
+
+// updateSmoothedRates records the change of each counter since the previous collection
+func (c *Commands) updateSmoothedRates() {
+	intervals := c.SmoothIntervals()
+	if intervals < 1 || c.previousCollected.IsZero() {
+		return
+	}
+	if c.smoothed == nil {
+		c.smoothed = smooth.NewRates(intervals)
+	}
+	c.smoothed.Add(c.last, c.previous, c.LastCollected.Sub(c.previousCollected))
+}
+
+// ResetStatistics resets the statistics to current values
+func (c *Commands) ResetStatistics() {
+	c.first = c.last
+	c.FirstCollected = c.LastCollected
+
+	c.calculate()
+}
+
+// HaveRelativeStats is true for this object
+func (c Commands) HaveRelativeStats() bool {
+	return true
+}
@@ -0,0 +1,17 @@
+package commands
+
+// Row contains a single Com_* counter
+type Row struct {
+	Name  string  // the command, i.e. the counter name without the Com_ prefix
+	Count uint64  // the number of times the command was executed
+	Rate  float64 // executions per second since the previous collection
+
+	SmoothedRate float64 // executions per second averaged over the last few collections
+}
+
+// add adds other to row
+func (row *Row) add(other Row) {
+	row.Count += other.Count
+	row.Rate += other.Rate
+	row.SmoothedRate += other.SmoothedRate
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/lib"
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// newRows returns a row for each counter which has been executed, subtracting the
+// first values if given. The rates are calculated from the values collected interval
+// ago, if known.
+func newRows(last, first, previous map[string]int, interval time.Duration) Rows {
+	var rows Rows
+
+	for name, value := range last {
+		count := uint64(value)
+		if old, ok := first[name]; ok && value >= old {
+			count -= uint64(old)
+		}
+		if count == 0 {
+			continue
+		}
+		row := Row{
+			Name:  strings.TrimPrefix(name, prefix),
+			Count: count,
+		}
+		if old, ok := previous[name]; ok && value >= old {
+			row.Rate = lib.PerSecond(uint64(value-old), interval)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// totals returns the totals of all rows
+func totals(rows Rows) Row {
+	total := Row{Name: "Totals"}
+
+	for _, row := range rows {
+		total.add(row)
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+// needsRefresh returns true if any counter has gone backwards, e.g. after FLUSH STATUS
+func needsRefresh(first, last map[string]int) bool {
+	for name, value := range last {
+		if value < first[name] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewRows(t *testing.T) {
+	first := map[string]int{"com_select": 100, "com_insert": 10, "com_delete": 5}
+	previous := map[string]int{"com_select": 150, "com_insert": 12, "com_delete": 5}
+	last := map[string]int{"com_select": 250, "com_insert": 22, "com_delete": 5, "com_commit": 4}
+
+	rows := newRows(last, first, previous, 10*time.Second)
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	expected := Rows{
+		{Name: "commit", Count: 4},
+		{Name: "insert", Count: 12, Rate: 1},
+		{Name: "select", Count: 150, Rate: 10},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("newRows() failed: expected: %+v, got %+v", expected, rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("newRows() failed: expected: %+v, got %+v", expected[i], rows[i])
+		}
+	}
+
+	if total := totals(rows); total.Count != 166 || total.Rate != 11 {
+		t.Errorf("totals() failed: expected count 166 and rate 11, got %+v", total)
+	}
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	first := map[string]int{"com_select": 100}
+	if needsRefresh(first, map[string]int{"com_select": 101, "com_insert": 1}) {
+		t.Errorf("needsRefresh() failed: expected false when the counters increase")
+	}
+	if !needsRefresh(first, map[string]int{"com_select": 1}) {
+		t.Errorf("needsRefresh() failed: expected true after FLUSH STATUS")
+	}
+}
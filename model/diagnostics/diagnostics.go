@@ -0,0 +1,64 @@
+// Package diagnostics provides library routines for ps-top
+// for showing ps-top's own resource usage.
+package diagnostics
+
+import (
+	"database/sql"
+	"log"
+	"runtime"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// Diagnostics holds a table of rows
+type Diagnostics struct {
+	baseobject.BaseObject      // embedded
+	last                  Rows // the rows of the previous collection, for rates
+	Results               Rows // results
+	Totals                Row  // totals of results, not really meaningful here
+}
+
+// NewDiagnostics returns a diagnostics object using the given config.
+// The database is not used as the information comes from the Go runtime.
+func NewDiagnostics(cfg *config.Config, _ *sql.DB) *Diagnostics {
+	log.Println("NewDiagnostics()")
+	d := &Diagnostics{}
+	d.SetConfig(cfg)
+
+	return d
+}
+
+// Collect collects ps-top's own resource usage from the Go runtime
+func (d *Diagnostics) Collect() error {
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+	previousCollected := d.LastCollected
+	d.LastCollected = time.Now()
+	if d.FirstCollected.IsZero() {
+		d.FirstCollected = d.LastCollected
+	}
+
+	interval := time.Duration(0)
+	if !previousCollected.IsZero() {
+		interval = d.LastCollected.Sub(previousCollected)
+	}
+	d.last = d.Results
+	d.Results = newRows(runtime.NumGoroutine(), &stats)
+	d.Results.setRates(d.last, interval)
+	d.Totals = Row{Name: "Totals"}
+
+	return nil
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (d *Diagnostics) ResetStatistics() {
+	log.Println("diagnostics.Diagnostics.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (d Diagnostics) HaveRelativeStats() bool {
+	return false
+}
@@ -0,0 +1,20 @@
+package diagnostics
+
+// Kind describes how a value should be shown
+type Kind int
+
+// the different kinds of values
+const (
+	KindCount    Kind = iota // a number of things
+	KindBytes                // an amount of memory
+	KindDuration             // a duration in nanoseconds
+)
+
+// Row contains a single resource usage value
+type Row struct {
+	Name    string  // description of the value, e.g. "Goroutines"
+	Kind    Kind    // how the value should be shown
+	Value   uint64  // the current value
+	Counter bool    // is the value a counter, only ever increasing?
+	Rate    float64 // change per second since the previous collection if the value is a counter
+}
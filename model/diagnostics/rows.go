@@ -0,0 +1,45 @@
+package diagnostics
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/sjmudd/ps-top/lib"
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// newRows returns the rows describing the given number of goroutines and memory statistics
+func newRows(goroutines int, stats *runtime.MemStats) Rows {
+	lastPause := uint64(0)
+	if stats.NumGC > 0 {
+		lastPause = stats.PauseNs[(stats.NumGC+255)%256]
+	}
+
+	return Rows{
+		{Name: "Goroutines", Kind: KindCount, Value: uint64(goroutines)},
+		{Name: "Heap allocated", Kind: KindBytes, Value: stats.HeapAlloc},
+		{Name: "Heap in use", Kind: KindBytes, Value: stats.HeapInuse},
+		{Name: "Heap objects", Kind: KindCount, Value: stats.HeapObjects},
+		{Name: "Memory obtained from the OS", Kind: KindBytes, Value: stats.Sys},
+		{Name: "Next GC target", Kind: KindBytes, Value: stats.NextGC},
+		{Name: "Total allocated", Kind: KindBytes, Value: stats.TotalAlloc, Counter: true},
+		{Name: "Allocations", Kind: KindCount, Value: stats.Mallocs, Counter: true},
+		{Name: "GC cycles", Kind: KindCount, Value: uint64(stats.NumGC), Counter: true},
+		{Name: "GC pause total", Kind: KindDuration, Value: stats.PauseTotalNs, Counter: true},
+		{Name: "Last GC pause", Kind: KindDuration, Value: lastPause},
+	}
+}
+
+// setRates sets the rates of the counters given the rows collected interval ago
+func (rows Rows) setRates(previous Rows, interval time.Duration) {
+	for i := range rows {
+		if !rows[i].Counter || i >= len(previous) || previous[i].Name != rows[i].Name {
+			continue
+		}
+		if rows[i].Value >= previous[i].Value {
+			rows[i].Rate = lib.PerSecond(rows[i].Value-previous[i].Value, interval)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package diagnostics
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNewRows(t *testing.T) {
+	stats := runtime.MemStats{HeapAlloc: 1024, TotalAlloc: 4096, NumGC: 2}
+	stats.PauseNs[1] = 500
+
+	rows := newRows(7, &stats)
+	expected := map[string]uint64{
+		"Goroutines":      7,
+		"Heap allocated":  1024,
+		"Total allocated": 4096,
+		"GC cycles":       2,
+		"Last GC pause":   500,
+	}
+	for _, row := range rows {
+		if value, ok := expected[row.Name]; ok && row.Value != value {
+			t.Errorf("newRows() failed: expected %s: %d, got %d", row.Name, value, row.Value)
+		}
+	}
+
+	stats.TotalAlloc = 14336
+	stats.NumGC = 4
+	next := newRows(8, &stats)
+	next.setRates(rows, 2*time.Second)
+	for _, row := range next {
+		var rate float64
+		switch row.Name {
+		case "Total allocated":
+			rate = 5120
+		case "GC cycles":
+			rate = 1
+		}
+		if row.Rate != rate {
+			t.Errorf("setRates() failed: expected %s rate: %v, got %v", row.Name, rate, row.Rate)
+		}
+	}
+}
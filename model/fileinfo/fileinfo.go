@@ -8,6 +8,7 @@ import (
 
 	"github.com/sjmudd/ps-top/baseobject"
 	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/global"
 )
 
 // FileIoLatency represents the contents of the data collected from file_summary_by_instance
@@ -18,6 +19,7 @@ type FileIoLatency struct {
 	Results               Rows
 	Totals                Row
 	db                    *sql.DB
+	useSys                bool // collect from the sys schema as performance_schema may not be read
 }
 
 // NewFileSummaryByInstance creates a new structure and include various variable values:
@@ -41,9 +43,19 @@ func (fiol *FileIoLatency) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (fiol *FileIoLatency) Collect() {
+func (fiol *FileIoLatency) Collect() error {
 	start := time.Now()
-	fiol.last = FileInfo2MySQLNames(fiol.Variables(), collect(fiol.db))
+	collected, err := collect(fiol.db, fiol.useSys)
+	if err != nil && !fiol.useSys && global.IsAccessDenied(err) {
+		log.Println("FileIoLatency.Collect() performance_schema is not accessible, using sys.x$io_global_by_file_by_latency:", err)
+		fiol.useSys = true
+		collected, err = collect(fiol.db, fiol.useSys)
+	}
+	if err != nil {
+		return err
+	}
+
+	fiol.last = FileInfo2MySQLNames(fiol.Variables(), collected)
 	fiol.LastCollected = time.Now()
 
 	// copy in first data if it was not there
@@ -58,6 +70,8 @@ func (fiol *FileIoLatency) Collect() {
 	log.Println("fiol.first.totals():", totals(fiol.first))
 	log.Println("fiol.last.totals():", totals(fiol.last))
 	log.Println("FileIoLatency.Collect() took:", time.Duration(time.Since(start)).String())
+
+	return nil
 }
 
 func (fiol *FileIoLatency) calculate() {
@@ -6,8 +6,6 @@ import (
 	"database/sql"
 	"log"
 	"time"
-
-	"github.com/sjmudd/ps-top/mylog"
 )
 
 // Config provides an interface for getting a configuration value from a key/value store
@@ -46,9 +44,29 @@ func (rows Rows) Valid() bool {
 	return valid
 }
 
+// sysQuery returns the same columns as file_summary_by_instance from the sys
+// schema, for users who may not read performance_schema. The bytes are only in
+// sys.x$io_global_by_file_by_bytes.
+const sysQuery = `
+SELECT	l.file,
+	l.total_latency,
+	l.read_latency,
+	l.write_latency,
+	b.total_read,
+	b.total_written,
+	l.misc_latency,
+	l.total,
+	l.count_read,
+	l.count_write,
+	l.count_misc
+FROM	sys.x$io_global_by_file_by_latency AS l
+JOIN	sys.x$io_global_by_file_by_bytes AS b USING (file)
+WHERE	l.total_latency > 0
+`
+
 // Select the raw data from the database into Rows
-func collect(dbh *sql.DB) Rows {
-	log.Println("collect() starts")
+func collect(dbh *sql.DB, useSys bool) (Rows, error) {
+	log.Println("collect() starts, useSys:", useSys)
 	var t Rows
 	start := time.Now()
 
@@ -67,10 +85,13 @@ SELECT	FILE_NAME,
 FROM	file_summary_by_instance
 WHERE	SUM_TIMER_WAIT > 0
 `
+	if useSys {
+		sql = sysQuery
+	}
 
 	rows, err := dbh.Query(sql)
 	if err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -89,19 +110,19 @@ WHERE	SUM_TIMER_WAIT > 0
 			&r.CountRead,
 			&r.CountWrite,
 			&r.CountMisc); err != nil {
-			mylog.Fatal(err)
+			return nil, err
 		}
 		t = append(t, r)
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	if !t.Valid() {
 		log.Println("WARNING: collect(): t is invalid")
 	}
 	log.Println("collect() took:", time.Duration(time.Since(start)).String(), "and returned", len(t), "rows")
 
-	return t
+	return t, nil
 }
 
 // subtract compares 2 slices of rows by name and removes the initial values
@@ -155,3 +176,8 @@ func (rows *Rows) subtract(initial Rows) {
 func (rows Rows) needsRefresh(otherRows Rows) bool {
 	return totals(rows).SumTimerWait > totals(otherRows).SumTimerWait
 }
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
@@ -0,0 +1,81 @@
+// Package indexio contains the routines for managing table_io_waits_summary_by_index_usage.
+package indexio
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// IndexIo contains performance_schema.table_io_waits_summary_by_index_usage data
+type IndexIo struct {
+	baseobject.BaseObject
+	first   Rows // initial data for relative values
+	last    Rows // last loaded values
+	Results Rows // results (maybe with subtraction)
+	Totals  Row  // totals of results
+	db      *sql.DB
+}
+
+// NewIndexIo returns an index i/o object with config and db handle
+func NewIndexIo(cfg *config.Config, db *sql.DB) *IndexIo {
+	iio := &IndexIo{
+		db: db,
+	}
+	iio.SetConfig(cfg)
+
+	return iio
+}
+
+// ResetStatistics resets the statistics to current values
+func (iio *IndexIo) ResetStatistics() {
+	iio.first = duplicateSlice(iio.last)
+	iio.FirstCollected = iio.LastCollected
+
+	iio.calculate()
+}
+
+// Collect collects data from the db, updating initial values
+// if needed, and then subtracting initial values if we want relative
+// values, after which it stores totals.
+func (iio *IndexIo) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(iio.db, iio.DatabaseFilter())
+	if err != nil {
+		return err
+	}
+
+	iio.last = collected
+	iio.LastCollected = time.Now()
+
+	// check for no first data or need to reload initial characteristics
+	if (len(iio.first) == 0 && len(iio.last) > 0) || iio.first.needsRefresh(iio.last) {
+		iio.first = duplicateSlice(iio.last)
+		iio.FirstCollected = iio.LastCollected
+	}
+
+	iio.calculate()
+
+	log.Println("IndexIo.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+func (iio *IndexIo) calculate() {
+	iio.Results = duplicateSlice(iio.last)
+
+	if iio.WantRelativeStats() {
+		iio.Results.subtract(iio.first)
+	}
+
+	iio.Totals = totals(iio.Results)
+}
+
+// HaveRelativeStats is true for this object
+func (iio IndexIo) HaveRelativeStats() bool {
+	return true
+}
@@ -0,0 +1,48 @@
+// Package indexio contains the routines for managing
+// performance_schema.table_io_waits_summary_by_index_usage.
+package indexio
+
+// noIndex is shown instead of the index name for the i/o which did not use an index
+const noIndex = "(no index)"
+
+// Row contains a row from table_io_waits_summary_by_index_usage
+type Row struct {
+	Table  string // the generated table name
+	Index  string // the index name, empty if no index was used, e.g. for full table scans
+	Unused bool   // has the index not been used since the server started?
+
+	SumTimerWait  uint64
+	SumTimerRead  uint64
+	SumTimerWrite uint64
+
+	CountStar  uint64
+	CountRead  uint64
+	CountWrite uint64
+}
+
+// Name returns the table and index name
+func (row Row) Name() string {
+	if row.Index == "" {
+		if row.Table == "" || row.Table == "Totals" {
+			return row.Table
+		}
+		return row.Table + " " + noIndex
+	}
+	return row.Table + "." + row.Index
+}
+
+// duplicateSlice copies the full slice
+func duplicateSlice(slice []Row) []Row {
+	return append(make([]Row, len(slice)), slice...)
+}
+
+// subtract the countable values in one row from another
+func (row *Row) subtract(other Row) {
+	row.SumTimerWait -= other.SumTimerWait
+	row.SumTimerRead -= other.SumTimerRead
+	row.SumTimerWrite -= other.SumTimerWrite
+
+	row.CountStar -= other.CountStar
+	row.CountRead -= other.CountRead
+	row.CountWrite -= other.CountWrite
+}
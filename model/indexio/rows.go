@@ -0,0 +1,119 @@
+package indexio
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/filter"
+)
+
+// Rows contains a set of rows
+type Rows []Row
+
+func totals(rows Rows) Row {
+	total := Row{Table: "Totals"}
+
+	for _, row := range rows {
+		total.SumTimerWait += row.SumTimerWait
+		total.SumTimerRead += row.SumTimerRead
+		total.SumTimerWrite += row.SumTimerWrite
+
+		total.CountStar += row.CountStar
+		total.CountRead += row.CountRead
+		total.CountWrite += row.CountWrite
+	}
+
+	return total
+}
+
+func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) (Rows, error) {
+	var t Rows
+
+	log.Printf("collect(?,%q)\n", databaseFilter)
+
+	// indexes are collected even if they have not been used so unused indexes can be seen.
+	// The system schemas' indexes are skipped as they would only hide the others.
+	sql := `SELECT OBJECT_SCHEMA, OBJECT_NAME, IFNULL(INDEX_NAME, ''), COUNT_STAR, SUM_TIMER_WAIT, COUNT_READ, SUM_TIMER_READ, COUNT_WRITE, SUM_TIMER_WRITE FROM table_io_waits_summary_by_index_usage WHERE (SUM_TIMER_WAIT > 0 OR INDEX_NAME IS NOT NULL) AND OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema', 'sys')`
+	args := []interface{}{}
+
+	// Apply the filter if provided and seems good.
+	if len(databaseFilter.Args()) > 0 {
+		sql = sql + databaseFilter.ExtraSQL()
+		for _, v := range databaseFilter.Args() {
+			args = append(args, v)
+		}
+		log.Printf("apply databaseFilter: sql: %q, args: %+v\n", sql, args)
+	}
+
+	rows, err := dbh.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, table string
+		var r Row
+		if err := rows.Scan(
+			&schema,
+			&table,
+			&r.Index,
+			&r.CountStar,
+			&r.SumTimerWait,
+			&r.CountRead,
+			&r.SumTimerRead,
+			&r.CountWrite,
+			&r.SumTimerWrite); err != nil {
+			return nil, err
+		}
+		r.Table = lib.QualifiedTableName(schema, table)
+		r.Unused = r.Index != "" && r.CountStar == 0
+
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// remove the initial values from those rows where there's a match
+// - if we find a row we can't match ignore it
+func (rows *Rows) subtract(initial Rows) {
+	initialByName := make(map[string]int)
+
+	// iterate over rows by name
+	for i := range initial {
+		initialByName[initial[i].Name()] = i
+	}
+
+	for i := range *rows {
+		if initialIndex, ok := initialByName[(*rows)[i].Name()]; ok {
+			(*rows)[i].subtract(initial[initialIndex])
+		}
+	}
+}
+
+// if the data in t2 is "newer", "has more values" than t then it needs refreshing.
+// check this by comparing totals.
+func (rows Rows) needsRefresh(otherRows Rows) bool {
+	return totals(rows).SumTimerWait > totals(otherRows).SumTimerWait
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+// Unused returns the number of indexes which have not been used since the server started
+func (rows Rows) Unused() int {
+	var unused int
+	for i := range rows {
+		if rows[i].Unused {
+			unused++
+		}
+	}
+	return unused
+}
@@ -0,0 +1,50 @@
+package indexio
+
+import (
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		row      Row
+		expected string
+	}{
+		{Row{Table: "db.t1", Index: "PRIMARY"}, "db.t1.PRIMARY"},
+		{Row{Table: "db.t1"}, "db.t1 (no index)"},
+		{Row{Table: "Totals"}, "Totals"},
+		{Row{}, ""},
+	}
+
+	for _, test := range tests {
+		if got := test.row.Name(); got != test.expected {
+			t.Errorf("%+v.Name() failed: expected: %q, got %q", test.row, test.expected, got)
+		}
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	initial := Rows{
+		{Table: "db.t1", Index: "PRIMARY", SumTimerWait: 100, CountStar: 10, CountRead: 10},
+		{Table: "db.t1", SumTimerWait: 500, CountStar: 2, CountRead: 2},
+	}
+	rows := Rows{
+		{Table: "db.t1", SumTimerWait: 900, CountStar: 5, CountRead: 5},
+		{Table: "db.t1", Index: "PRIMARY", SumTimerWait: 150, CountStar: 12, CountRead: 11, CountWrite: 1},
+		{Table: "db.t1", Index: "idx_unused", Unused: true},
+	}
+	expected := Rows{
+		{Table: "db.t1", SumTimerWait: 400, CountStar: 3, CountRead: 3},
+		{Table: "db.t1", Index: "PRIMARY", SumTimerWait: 50, CountStar: 2, CountRead: 1, CountWrite: 1},
+		{Table: "db.t1", Index: "idx_unused", Unused: true},
+	}
+
+	rows.subtract(initial)
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("subtract() failed for row %d: expected: %+v, got %+v", i, expected[i], rows[i])
+		}
+	}
+	if unused := rows.Unused(); unused != 1 {
+		t.Errorf("Unused() failed: expected 1, got %d", unused)
+	}
+}
@@ -0,0 +1,105 @@
+// Package innodb provides library routines for ps-top
+// for showing a summary of the InnoDB buffer pool and engine status.
+package innodb
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+// InnoDB holds a table of rows
+type InnoDB struct {
+	baseobject.BaseObject                // embedded
+	previous              map[string]int // status values of the previous collection, for rates
+	previousCollected     time.Time      // time of the previous collection
+	last                  map[string]int // last loaded status values
+	Results               Rows           // the derived metrics
+	Totals                Row            // totals of results, not really meaningful here
+	smoothed              *smooth.Rates  // recent changes by status name
+	db                    *sql.DB
+}
+
+// NewInnoDB returns an InnoDB object using given config and db
+func NewInnoDB(cfg *config.Config, db *sql.DB) *InnoDB {
+	log.Println("NewInnoDB()")
+	i := &InnoDB{
+		db: db,
+	}
+	i.SetConfig(cfg)
+
+	return i
+}
+
+// Collect collects the InnoDB status values and metrics from the db and
+// derives the metrics shown, rates being since the previous collection.
+func (i *InnoDB) Collect() error {
+	start := time.Now()
+
+	collected, err := i.Status().Values(statusNames...)
+	if err != nil {
+		return err
+	}
+	metrics, err := collectMetrics(i.db, metricNames)
+	if err != nil {
+		return err
+	}
+
+	i.previous = i.last
+	i.previousCollected = i.LastCollected
+	i.last = collected
+	i.LastCollected = time.Now()
+	if i.FirstCollected.IsZero() {
+		i.FirstCollected = i.LastCollected
+	}
+
+	i.updateSmoothedRates()
+
+	s := snapshot{
+		current:  i.last,
+		previous: i.previous,
+		metrics:  metrics,
+		smoothed: i.smoothed,
+	}
+	if !i.previousCollected.IsZero() {
+		s.interval = i.LastCollected.Sub(i.previousCollected)
+	}
+	i.Results = newRows(s)
+	i.Totals = Row{Name: "Totals"}
+
+	log.Println("InnoDB.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// updateSmoothedRates records the change of each status value since the previous collection
+func (i *InnoDB) updateSmoothedRates() {
+	intervals := i.SmoothIntervals()
+	if intervals < 1 || i.previousCollected.IsZero() {
+		return
+	}
+	if i.smoothed == nil {
+		i.smoothed = smooth.NewRates(intervals)
+	}
+	i.smoothed.Add(i.last, i.previous, i.LastCollected.Sub(i.previousCollected))
+}
+
+// HitRatio returns the buffer pool hit ratio and whether it is known
+func (i InnoDB) HitRatio() (float64, bool) {
+	row, ok := i.Results.find("Buffer pool hit ratio")
+	return row.Value, ok
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (i *InnoDB) ResetStatistics() {
+	log.Println("innodb.InnoDB.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (i InnoDB) HaveRelativeStats() bool {
+	return false
+}
@@ -0,0 +1,21 @@
+package innodb
+
+// Kind says how the value of a row is shown
+type Kind int
+
+// the kinds of value
+const (
+	KindCount Kind = iota // a number of things, e.g. pages or transactions
+	KindBytes             // a size in bytes
+	KindRatio             // a fraction shown as a percentage
+	KindRate              // a change per second
+)
+
+// Row contains a single metric derived from the InnoDB status values
+type Row struct {
+	Name  string  // description of the metric, e.g. "Buffer pool hit ratio"
+	Value float64 // the value of the metric
+	Kind  Kind    // how the value is shown
+
+	SmoothedValue float64 // a KindRate value averaged over the last few collections
+}
@@ -0,0 +1,198 @@
+package innodb
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/global"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+const accessDeniedErrorNum = 1227 // INNODB_METRICS needs the PROCESS privilege
+
+// statusNames are the global status values the metrics are derived from
+var statusNames = []string{
+	"innodb_buffer_pool_read_requests",
+	"innodb_buffer_pool_reads",
+	"innodb_buffer_pool_pages_total",
+	"innodb_buffer_pool_pages_data",
+	"innodb_buffer_pool_pages_dirty",
+	"innodb_buffer_pool_pages_free",
+	"innodb_buffer_pool_wait_free",
+	"innodb_log_waits",
+	"innodb_redo_log_current_lsn",
+	"innodb_redo_log_checkpoint_lsn",
+	"innodb_rows_read",
+	"innodb_rows_inserted",
+	"innodb_rows_updated",
+	"innodb_rows_deleted",
+}
+
+// metricNames are the INNODB_METRICS counters the metrics are derived from.
+// Only enabled counters are returned, log_lsn_checkpoint_age is disabled by default.
+var metricNames = []string{
+	"trx_rseg_history_len",
+	"log_lsn_checkpoint_age",
+}
+
+// snapshot holds the values the metrics are derived from
+type snapshot struct {
+	current  map[string]int // global status values
+	previous map[string]int // global status values of the previous collection, nil if none
+	interval time.Duration  // time between the previous and current collection
+	metrics  map[string]int // enabled INNODB_METRICS counters
+	smoothed *smooth.Rates  // the smoothed rates of the status counters, nil if none
+	average  bool           // rate returns the smoothed rates
+}
+
+// rate returns the change per second of the status counter since the previous
+// collection, or averaged over the last few collections if s.average is set
+func (s snapshot) rate(name string) (float64, bool) {
+	if s.average {
+		return s.smoothed.PerSecond(name)
+	}
+	current, ok := s.current[name]
+	if !ok {
+		return 0, false
+	}
+	previous, ok := s.previous[name]
+	if !ok || current < previous || s.interval <= 0 {
+		return 0, false
+	}
+	return lib.PerSecond(uint64(current-previous), s.interval), true
+}
+
+// delta returns the change of the status counter since the previous collection,
+// or its value since the server started if there is no previous collection
+func (s snapshot) delta(name string) uint64 {
+	current := s.current[name]
+	if previous, ok := s.previous[name]; ok && current >= previous {
+		return uint64(current - previous)
+	}
+	return uint64(current)
+}
+
+// pagesRatio returns the fraction of the buffer pool pages given by the status name
+func (s snapshot) pagesRatio(name string) (float64, bool) {
+	pages, ok := s.current[name]
+	total := s.current["innodb_buffer_pool_pages_total"]
+	if !ok || total == 0 {
+		return 0, false
+	}
+	return lib.Divide(uint64(pages), uint64(total)), true
+}
+
+// derived describes each metric and how it is derived from a snapshot.
+// The metrics which can not be derived, e.g. as the server is too old, are skipped.
+var derived = []struct {
+	name  string
+	kind  Kind
+	value func(s snapshot) (float64, bool)
+}{
+	{"Buffer pool hit ratio", KindRatio, func(s snapshot) (float64, bool) {
+		requests := s.delta("innodb_buffer_pool_read_requests")
+		if _, ok := s.current["innodb_buffer_pool_read_requests"]; !ok || requests == 0 {
+			return 0, false
+		}
+		reads := s.delta("innodb_buffer_pool_reads")
+		if reads > requests {
+			return 0, true
+		}
+		return 1 - lib.Divide(reads, requests), true
+	}},
+	{"Buffer pool pages used", KindRatio, func(s snapshot) (float64, bool) { return s.pagesRatio("innodb_buffer_pool_pages_data") }},
+	{"Buffer pool pages dirty", KindRatio, func(s snapshot) (float64, bool) { return s.pagesRatio("innodb_buffer_pool_pages_dirty") }},
+	{"Buffer pool pages free", KindRatio, func(s snapshot) (float64, bool) { return s.pagesRatio("innodb_buffer_pool_pages_free") }},
+	{"Buffer pool waits for free pages", KindRate, func(s snapshot) (float64, bool) { return s.rate("innodb_buffer_pool_wait_free") }},
+	{"Checkpoint age", KindBytes, func(s snapshot) (float64, bool) {
+		if age, ok := s.metrics["log_lsn_checkpoint_age"]; ok {
+			return float64(age), true
+		}
+		current, ok := s.current["innodb_redo_log_current_lsn"] // MySQL 8.0.30+
+		checkpoint, ok2 := s.current["innodb_redo_log_checkpoint_lsn"]
+		if !ok || !ok2 || current < checkpoint {
+			return 0, false
+		}
+		return float64(current - checkpoint), true
+	}},
+	{"Log waits", KindRate, func(s snapshot) (float64, bool) { return s.rate("innodb_log_waits") }},
+	{"History list length", KindCount, func(s snapshot) (float64, bool) {
+		length, ok := s.metrics["trx_rseg_history_len"]
+		return float64(length), ok
+	}},
+	{"Rows read", KindRate, func(s snapshot) (float64, bool) { return s.rate("innodb_rows_read") }},
+	{"Rows inserted", KindRate, func(s snapshot) (float64, bool) { return s.rate("innodb_rows_inserted") }},
+	{"Rows updated", KindRate, func(s snapshot) (float64, bool) { return s.rate("innodb_rows_updated") }},
+	{"Rows deleted", KindRate, func(s snapshot) (float64, bool) { return s.rate("innodb_rows_deleted") }},
+}
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// newRows returns the metrics which can be derived from the snapshot
+func newRows(s snapshot) Rows {
+	var rows Rows
+
+	averaged := s
+	averaged.average = s.smoothed != nil
+	for _, d := range derived {
+		if value, ok := d.value(s); ok {
+			row := Row{Name: d.name, Value: value, Kind: d.kind}
+			if d.kind == KindRate && averaged.average {
+				row.SmoothedValue, _ = d.value(averaged)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// find returns the row with the given name
+func (rows Rows) find(name string) (Row, bool) {
+	for _, row := range rows {
+		if row.Name == name {
+			return row, true
+		}
+	}
+	return Row{}, false
+}
+
+// collectMetrics returns the enabled INNODB_METRICS counters with the given names.
+// If the table may not be read no counters are returned.
+func collectMetrics(dbh *sql.DB, names []string) (map[string]int, error) {
+	metrics := make(map[string]int)
+
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		args = append(args, name)
+	}
+	query := "SELECT NAME, COUNT FROM information_schema.INNODB_METRICS WHERE STATUS = 'enabled' AND NAME IN (?" + strings.Repeat(", ?", len(names)-1) + ")"
+
+	rows, err := dbh.Query(query, args...)
+	if err != nil {
+		if global.IsMysqlError(err, accessDeniedErrorNum) {
+			log.Println("innodb.collectMetrics() INNODB_METRICS not available, ignoring:", err)
+			return metrics, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var count int
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, err
+		}
+		metrics[strings.ToLower(name)] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,71 @@
+package innodb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+func TestNewRows(t *testing.T) {
+	s := snapshot{
+		previous: map[string]int{
+			"innodb_buffer_pool_read_requests": 1000,
+			"innodb_buffer_pool_reads":         100,
+			"innodb_rows_read":                 5000,
+		},
+		current: map[string]int{
+			"innodb_buffer_pool_read_requests": 2000,
+			"innodb_buffer_pool_reads":         150,
+			"innodb_buffer_pool_pages_total":   1000,
+			"innodb_buffer_pool_pages_dirty":   250,
+			"innodb_redo_log_current_lsn":      9000,
+			"innodb_redo_log_checkpoint_lsn":   8000,
+			"innodb_rows_read":                 6000,
+		},
+		interval: 10 * time.Second,
+		metrics:  map[string]int{"trx_rseg_history_len": 42},
+		smoothed: smooth.NewRates(2),
+	}
+	s.smoothed.Add(map[string]int{"innodb_rows_read": 5000}, map[string]int{"innodb_rows_read": 2000}, 10*time.Second)
+	s.smoothed.Add(s.current, s.previous, 10*time.Second)
+	expected := Rows{
+		{Name: "Buffer pool hit ratio", Value: 0.95, Kind: KindRatio},
+		{Name: "Buffer pool pages dirty", Value: 0.25, Kind: KindRatio},
+		{Name: "Checkpoint age", Value: 1000, Kind: KindBytes},
+		{Name: "History list length", Value: 42, Kind: KindCount},
+		{Name: "Rows read", Value: 100, Kind: KindRate, SmoothedValue: 200},
+	}
+
+	rows := newRows(s)
+	if len(rows) != len(expected) {
+		t.Fatalf("newRows() failed: expected: %+v, got %+v", expected, rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("newRows() failed: expected: %+v, got %+v", expected[i], rows[i])
+		}
+	}
+}
+
+func TestNewRowsFirstCollection(t *testing.T) {
+	s := snapshot{
+		current: map[string]int{
+			"innodb_buffer_pool_read_requests": 1000,
+			"innodb_buffer_pool_reads":         10,
+			"innodb_rows_read":                 6000,
+		},
+		metrics: map[string]int{"log_lsn_checkpoint_age": 512},
+	}
+
+	rows := newRows(s)
+	if row, ok := rows.find("Buffer pool hit ratio"); !ok || row.Value != 0.99 {
+		t.Errorf("newRows() failed: expected a hit ratio since startup of 0.99, got %+v", row)
+	}
+	if row, ok := rows.find("Checkpoint age"); !ok || row.Value != 512 {
+		t.Errorf("newRows() failed: expected the checkpoint age from INNODB_METRICS of 512, got %+v", row)
+	}
+	if row, ok := rows.find("Rows read"); ok {
+		t.Errorf("newRows() failed: expected no rate without a previous collection, got %+v", row)
+	}
+}
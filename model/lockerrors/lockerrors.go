@@ -0,0 +1,123 @@
+// Package lockerrors provides library routines for ps-top
+// for showing deadlocks and lock wait timeouts seen by the server.
+package lockerrors
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+// LockErrors holds a table of rows
+type LockErrors struct {
+	baseobject.BaseObject                      // embedded
+	first                 Rows                 // initial data for relative values
+	previous              Rows                 // values of the previous collection, for rates
+	previousCollected     time.Time            // time of the previous collection
+	last                  Rows                 // last loaded values
+	Results               Rows                 // results (maybe with subtraction)
+	Totals                Row                  // totals of results
+	smoothed              map[int]*smooth.Rate // recent changes by error number
+	db                    *sql.DB
+}
+
+// NewLockErrors returns a lock errors object using given config and db
+func NewLockErrors(cfg *config.Config, db *sql.DB) *LockErrors {
+	log.Println("NewLockErrors()")
+	le := &LockErrors{
+		db: db,
+	}
+	le.SetConfig(cfg)
+
+	return le
+}
+
+// Collect collects data from the db, updating first
+// values if needed, and then subtracting first values if we want
+// relative values, after which it stores totals.
+func (le *LockErrors) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(le.db)
+	if err != nil {
+		return err
+	}
+
+	le.previous = le.last
+	le.previousCollected = le.LastCollected
+	le.last = collected
+	le.LastCollected = time.Now()
+	le.updateSmoothedRates()
+
+	// check if no first data or we need to reload initial characteristics
+	if (len(le.first) == 0 && len(le.last) > 0) || le.first.needsRefresh(le.last) {
+		le.first = duplicateSlice(le.last)
+		le.FirstCollected = le.LastCollected
+	}
+
+	le.calculate()
+
+	log.Println("LockErrors.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+func (le *LockErrors) calculate() {
+	le.Results = duplicateSlice(le.last)
+	le.Results.setRates(le.previous, le.LastCollected.Sub(le.previousCollected))
+	for i := range le.Results {
+		if rate, ok := le.smoothed[le.Results[i].Number]; ok {
+			le.Results[i].SmoothedRate = rate.PerSecond()
+		}
+	}
+	if le.WantRelativeStats() {
+		le.Results.subtract(le.first)
+	}
+
+	le.Totals = totals(le.Results)
+}
+
+// updateSmoothedRates records the change of each error since the previous collection
+func (le *LockErrors) updateSmoothedRates() {
+	intervals := le.SmoothIntervals()
+	if intervals < 1 || le.previousCollected.IsZero() {
+		return
+	}
+	if le.smoothed == nil {
+		le.smoothed = make(map[int]*smooth.Rate)
+	}
+
+	previousByNumber := make(map[int]uint64)
+	for i := range le.previous {
+		previousByNumber[le.previous[i].Number] = le.previous[i].Raised
+	}
+
+	interval := le.LastCollected.Sub(le.previousCollected)
+	for i := range le.last {
+		previous, ok := previousByNumber[le.last[i].Number]
+		if !ok || le.last[i].Raised < previous {
+			continue
+		}
+		if _, ok := le.smoothed[le.last[i].Number]; !ok {
+			le.smoothed[le.last[i].Number] = smooth.NewRate(intervals)
+		}
+		le.smoothed[le.last[i].Number].Add(float64(le.last[i].Raised-previous), interval)
+	}
+}
+
+// ResetStatistics resets the statistics to current values
+func (le *LockErrors) ResetStatistics() {
+	le.first = duplicateSlice(le.last)
+	le.FirstCollected = le.LastCollected
+
+	le.calculate()
+}
+
+// HaveRelativeStats is true for this object
+func (le LockErrors) HaveRelativeStats() bool {
+	return true
+}
@@ -0,0 +1,33 @@
+// Package lockerrors contains the library routines for managing the
+// events_errors_summary_global_by_error table.
+package lockerrors
+
+import (
+	"log"
+)
+
+// Row contains a row from performance_schema.events_errors_summary_global_by_error
+type Row struct {
+	Number       int
+	Name         string
+	Raised       uint64  // number of times the error was raised
+	Rate         float64 // errors per second since the previous collection
+	SmoothedRate float64 // errors per second averaged over the last few collections
+	LastSeen     string  // when the error was last seen, if at all
+}
+
+// duplicateSlice copies the full slice
+func duplicateSlice(slice []Row) []Row {
+	return append(make([]Row, len(slice)), slice...)
+}
+
+// subtract the countable values in one row from another
+func (row *Row) subtract(other Row) {
+	if row.Raised >= other.Raised {
+		row.Raised -= other.Raised
+	} else {
+		log.Println("WARNING: Row.subtract() - subtraction problem! (not subtracting)")
+		log.Println("row=", row)
+		log.Println("other=", other)
+	}
+}
@@ -0,0 +1,107 @@
+// Package lockerrors contains the library routines for managing the
+// events_errors_summary_global_by_error table.
+package lockerrors
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/global"
+	"github.com/sjmudd/ps-top/lib"
+)
+
+const (
+	lockWaitTimeoutErrorNum   = 1205 // ER_LOCK_WAIT_TIMEOUT
+	deadlockErrorNum          = 1213 // ER_LOCK_DEADLOCK
+	tableDoesNotExistErrorNum = 1146 // events_errors_summary_global_by_error needs MySQL 8.0
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+func totals(rows Rows) Row {
+	total := Row{Name: "Totals"}
+
+	for _, row := range rows {
+		total.Raised += row.Raised
+		total.Rate += row.Rate
+		total.SmoothedRate += row.SmoothedRate
+	}
+
+	return total
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	query := "SELECT ERROR_NUMBER, ERROR_NAME, SUM_ERROR_RAISED, LAST_SEEN FROM events_errors_summary_global_by_error WHERE ERROR_NUMBER IN (?, ?)"
+
+	rows, err := dbh.Query(query, lockWaitTimeoutErrorNum, deadlockErrorNum)
+	if err != nil {
+		// the view will not be available but we are called by the initial collection of all views
+		if global.IsMysqlError(err, tableDoesNotExistErrorNum) {
+			log.Println("lockerrors.collect() errors summary table not available, ignoring:", err)
+			return t, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		var lastSeen sql.NullString
+		if err := rows.Scan(
+			&r.Number,
+			&r.Name,
+			&r.Raised,
+			&lastSeen); err != nil {
+			return nil, err
+		}
+		r.LastSeen = lastSeen.String
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// setRates sets the rate of each error given the values of the previous collection
+func (rows Rows) setRates(previous Rows, interval time.Duration) {
+	previousByNumber := make(map[int]int)
+
+	for i := range previous {
+		previousByNumber[previous[i].Number] = i
+	}
+
+	for i := range rows {
+		rows[i].Rate = 0
+		if previousIndex, ok := previousByNumber[rows[i].Number]; ok && interval > 0 && rows[i].Raised >= previous[previousIndex].Raised {
+			rows[i].Rate = lib.PerSecond(rows[i].Raised-previous[previousIndex].Raised, interval)
+		}
+	}
+}
+
+// remove the initial values from those rows where there's a match
+// - if we find a row we can't match ignore it
+func (rows *Rows) subtract(initial Rows) {
+	initialByNumber := make(map[int]int)
+
+	for i := range initial {
+		initialByNumber[initial[i].Number] = i
+	}
+
+	for i := range *rows {
+		if initialIndex, ok := initialByNumber[(*rows)[i].Number]; ok {
+			(*rows)[i].subtract(initial[initialIndex])
+		}
+	}
+}
+
+// if the data in t2 is "newer", "has more values" than t then it needs refreshing.
+// check this by comparing totals.
+func (rows Rows) needsRefresh(otherRows Rows) bool {
+	return totals(rows).Raised > totals(otherRows).Raised
+}
@@ -15,6 +15,7 @@ import (
 // MemoryUsage represents a table of rows
 type MemoryUsage struct {
 	baseobject.BaseObject      // embedded
+	previous              Rows // values of the previous collection, for the changes
 	last                  Rows // last loaded values
 	Results               Rows // results (maybe with subtraction)
 	Totals                Row  // totals of results
@@ -31,12 +32,21 @@ func NewMemoryUsage(cfg *config.Config, db *sql.DB) *MemoryUsage {
 	return mu
 }
 
-// Collect data from the db, no merging needed
-func (mu *MemoryUsage) Collect() {
-	mu.last = collect(mu.db)
+// Collect data from the db, no merging needed other than
+// calculating the changes since the previous collection
+func (mu *MemoryUsage) Collect() error {
+	collected, err := collect(mu.db)
+	if err != nil {
+		return err
+	}
+
+	mu.previous = mu.last
+	mu.last = collected
 	mu.LastCollected = time.Now()
 
 	mu.calculate()
+
+	return nil
 }
 
 // ResetStatistics resets the statistics to current values
@@ -64,5 +74,6 @@ func (mu MemoryUsage) HaveRelativeStats() bool {
 func (mu *MemoryUsage) calculate() {
 	mu.Results = make(Rows, len(mu.last))
 	copy(mu.Results, mu.last)
+	mu.Results.setChanges(mu.previous)
 	mu.Totals = totals(mu.Results)
 }
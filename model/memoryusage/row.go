@@ -32,6 +32,7 @@ type Row struct {
 	TotalMemoryOps    int64
 	CurrentBytesUsed  int64
 	HighBytesUsed     int64
+	ChangeBytesUsed   int64 // change in CurrentBytesUsed since the previous collection
 	TotalBytesManaged uint64
 }
 
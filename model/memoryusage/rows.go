@@ -6,10 +6,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql" // keep glint happy
-
-	"github.com/sjmudd/ps-top/mylog"
 )
 
 // Rows contains multiple rows
@@ -21,6 +20,7 @@ func totals(rows Rows) Row {
 
 	for _, row := range rows {
 		total.CurrentBytesUsed += row.CurrentBytesUsed
+		total.ChangeBytesUsed += row.ChangeBytesUsed
 		total.TotalMemoryOps += row.TotalMemoryOps
 		total.CurrentCountUsed += row.CurrentCountUsed
 	}
@@ -28,25 +28,20 @@ func totals(rows Rows) Row {
 	return total
 }
 
-// catch a SELECT error - specifically this one.
+// catch a SELECT error - specifically this one, returning true if it can be ignored.
 // Error 1146: Table 'performance_schema.memory_summary_global_by_event_name' doesn't exist
 func sqlErrorHandler(err error) bool {
-	var ignore bool
-
 	log.Println("- SELECT gave an error:", err.Error())
-	if err.Error()[0:11] != "Error 1146:" {
-		fmt.Printf("XXX'%s'XXX\n", err.Error()[0:11])
-		mylog.Fatal("Unexpected error", fmt.Sprintf("XXX'%s'XXX", err.Error()[0:11]))
-	} else {
-		log.Println("- expected error, so ignoring")
-		ignore = true
+	if !strings.HasPrefix(err.Error(), "Error 1146:") {
+		return false
 	}
+	log.Println("- expected error, so ignoring")
 
-	return ignore
+	return true
 }
 
 // Select the raw data from the database
-func collect(dbh *sql.DB) Rows {
+func collect(dbh *sql.DB) (Rows, error) {
 	var t Rows
 	var skip bool
 
@@ -68,7 +63,10 @@ WHERE	HIGH_COUNT_USED > 0`
 		// FIXME   table collection. I'm waiting to clean up by splitting views and models but
 		// FIXME   that has not been done yet so for now work aruond the initial app.CollectAll()
 		// FIXME   by simply ignoring a request if the table does not exist.
-		skip = sqlErrorHandler(err) // temporarily catch a SELECT error. // should not be necessary now
+		if !sqlErrorHandler(err) { // temporarily catch a SELECT error. // should not be necessary now
+			return nil, err
+		}
+		skip = true
 	}
 
 	if !skip {
@@ -84,14 +82,36 @@ WHERE	HIGH_COUNT_USED > 0`
 				&r.HighBytesUsed,
 				&r.TotalMemoryOps,
 				&r.TotalBytesManaged); err != nil {
-				mylog.Fatalf("collect: rows.Scan() failed: %+v", err)
+				return nil, fmt.Errorf("collect: rows.Scan() failed: %w", err)
 			}
 			t = append(t, r)
 		}
 		if err := rows.Err(); err != nil {
-			mylog.Fatalf("collect: rows.Err() returned: %v", err)
+			return nil, fmt.Errorf("collect: rows.Err() returned: %w", err)
 		}
 	}
 
-	return t
+	return t, nil
+}
+
+// setChanges sets the change in bytes used of each row since the previous
+// collection. Events not seen before have changed by all their bytes used,
+// and nothing has changed if there is no previous collection.
+func (rows Rows) setChanges(previous Rows) {
+	if len(previous) == 0 {
+		return
+	}
+	previousBytes := make(map[string]int64, len(previous))
+	for _, row := range previous {
+		previousBytes[row.Name] = row.CurrentBytesUsed
+	}
+
+	for i := range rows {
+		rows[i].ChangeBytesUsed = rows[i].CurrentBytesUsed - previousBytes[rows[i].Name]
+	}
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
 }
@@ -0,0 +1,36 @@
+package memoryusage
+
+import (
+	"testing"
+)
+
+func TestSetChanges(t *testing.T) {
+	previous := Rows{
+		{Name: "memory/sql/THD::main_mem_root", CurrentBytesUsed: 1000},
+		{Name: "memory/innodb/buf_buf_pool", CurrentBytesUsed: 5000},
+		{Name: "memory/sql/TABLE", CurrentBytesUsed: 300},
+	}
+	rows := Rows{
+		{Name: "memory/sql/THD::main_mem_root", CurrentBytesUsed: 1500},
+		{Name: "memory/innodb/buf_buf_pool", CurrentBytesUsed: 5000},
+		{Name: "memory/sql/TABLE", CurrentBytesUsed: 100},
+		{Name: "memory/sql/Filesort_buffer::sort_keys", CurrentBytesUsed: 64}, // not seen before
+	}
+	expected := []int64{500, 0, -200, 64}
+
+	rows.setChanges(previous)
+	for i := range expected {
+		if rows[i].ChangeBytesUsed != expected[i] {
+			t.Errorf("setChanges() failed for %s: expected: %d, got %d", rows[i].Name, expected[i], rows[i].ChangeBytesUsed)
+		}
+	}
+	if total := totals(rows); total.ChangeBytesUsed != 364 {
+		t.Errorf("totals() failed: expected a change of 364, got %d", total.ChangeBytesUsed)
+	}
+
+	first := Rows{{Name: "memory/sql/TABLE", CurrentBytesUsed: 100}}
+	first.setChanges(nil)
+	if first[0].ChangeBytesUsed != 0 {
+		t.Errorf("setChanges() failed: expected no change without a previous collection, got %d", first[0].ChangeBytesUsed)
+	}
+}
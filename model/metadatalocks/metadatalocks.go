@@ -0,0 +1,65 @@
+// Package metadatalocks provides library routines for ps-top
+// for showing the granted and pending metadata locks.
+package metadatalocks
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// MetadataLocks holds a table of rows
+type MetadataLocks struct {
+	baseobject.BaseObject      // embedded
+	Results               Rows // the current metadata locks
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewMetadataLocks returns a metadata locks object using given config and db
+func NewMetadataLocks(cfg *config.Config, db *sql.DB) *MetadataLocks {
+	log.Println("NewMetadataLocks()")
+	ml := &MetadataLocks{
+		db: db,
+	}
+	ml.SetConfig(cfg)
+
+	return ml
+}
+
+// Collect collects the current metadata locks from the db, finds which
+// thread is blocking each pending lock and stores the totals.
+// There are no relative values as each collection is a snapshot.
+func (ml *MetadataLocks) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(ml.db)
+	if err != nil {
+		return err
+	}
+
+	ml.Results = collected
+	ml.Results.setBlockers()
+	ml.LastCollected = time.Now()
+	if ml.FirstCollected.IsZero() {
+		ml.FirstCollected = ml.LastCollected
+	}
+	ml.Totals = totals(ml.Results)
+
+	log.Println("MetadataLocks.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (ml *MetadataLocks) ResetStatistics() {
+	log.Println("metadatalocks.MetadataLocks.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (ml MetadataLocks) HaveRelativeStats() bool {
+	return false
+}
@@ -0,0 +1,65 @@
+package metadatalocks
+
+import (
+	"github.com/sjmudd/ps-top/lib"
+)
+
+/* This table exists in MySQL 5.7+
+
+CREATE TABLE `metadata_locks` (
+  `OBJECT_TYPE` varchar(64) NOT NULL,
+  `OBJECT_SCHEMA` varchar(64) DEFAULT NULL,
+  `OBJECT_NAME` varchar(64) DEFAULT NULL,
+  `COLUMN_NAME` varchar(64) DEFAULT NULL,
+  `OBJECT_INSTANCE_BEGIN` bigint unsigned NOT NULL,
+  `LOCK_TYPE` varchar(32) NOT NULL,
+  `LOCK_DURATION` varchar(32) NOT NULL,
+  `LOCK_STATUS` varchar(32) NOT NULL,
+  `SOURCE` varchar(64) DEFAULT NULL,
+  `OWNER_THREAD_ID` bigint unsigned DEFAULT NULL,
+  `OWNER_EVENT_ID` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`OBJECT_INSTANCE_BEGIN`),
+  KEY `OBJECT_TYPE` (`OBJECT_TYPE`,`OBJECT_SCHEMA`,`OBJECT_NAME`,`COLUMN_NAME`),
+  KEY `OWNER_THREAD_ID` (`OWNER_THREAD_ID`,`OWNER_EVENT_ID`)
+) ENGINE=PERFORMANCE_SCHEMA
+
+*/
+
+// lock statuses we are interested in
+const (
+	statusGranted = "GRANTED"
+	statusPending = "PENDING"
+)
+
+// Row contains a single metadata lock
+type Row struct {
+	ObjectType    string // e.g. TABLE, SCHEMA or GLOBAL
+	Schema        string
+	Name          string
+	LockType      string // e.g. SHARED_READ or EXCLUSIVE
+	Duration      string // STATEMENT, TRANSACTION or EXPLICIT
+	Status        string // GRANTED or PENDING
+	ThreadID      uint64 // the owning performance_schema thread
+	ProcesslistID uint64 // the owning connection id, if any
+	BlockedBy     uint64 // the thread holding a lock which blocks a pending lock
+	Granted       uint64 // number of granted locks (1 unless this is a totals row)
+	Pending       uint64 // number of pending locks (1 unless this is a totals row)
+}
+
+// Object returns the name of the locked object, e.g. <schema>.<table>
+func (row Row) Object() string {
+	if object := lib.QualifiedTableName(row.Schema, row.Name); object != "" {
+		return object
+	}
+	return row.ObjectType
+}
+
+// IsPending returns true if the lock is waiting to be granted
+func (row Row) IsPending() bool {
+	return row.Status == statusPending
+}
+
+// sameObject returns true if both rows lock the same object
+func (row Row) sameObject(other Row) bool {
+	return row.ObjectType == other.ObjectType && row.Schema == other.Schema && row.Name == other.Name
+}
@@ -0,0 +1,96 @@
+// Package metadatalocks contains the library routines for managing the
+// metadata_locks table.
+package metadatalocks
+
+import (
+	"database/sql"
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// totals returns the number of granted and pending locks
+func totals(rows Rows) Row {
+	total := Row{ObjectType: "Totals"}
+
+	for _, row := range rows {
+		total.Granted += row.Granted
+		total.Pending += row.Pending
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	// ignore the locks taken by our own connection
+	query := `SELECT ml.OBJECT_TYPE,
+	IFNULL(ml.OBJECT_SCHEMA, ''),
+	IFNULL(ml.OBJECT_NAME, ''),
+	ml.LOCK_TYPE,
+	ml.LOCK_DURATION,
+	ml.LOCK_STATUS,
+	IFNULL(ml.OWNER_THREAD_ID, 0),
+	IFNULL(t.PROCESSLIST_ID, 0)
+FROM performance_schema.metadata_locks ml
+LEFT JOIN performance_schema.threads t ON t.THREAD_ID = ml.OWNER_THREAD_ID
+WHERE t.PROCESSLIST_ID IS NULL OR t.PROCESSLIST_ID <> CONNECTION_ID()`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(
+			&r.ObjectType,
+			&r.Schema,
+			&r.Name,
+			&r.LockType,
+			&r.Duration,
+			&r.Status,
+			&r.ThreadID,
+			&r.ProcesslistID); err != nil {
+			return nil, err
+		}
+		switch r.Status {
+		case statusGranted:
+			r.Granted = 1
+		case statusPending:
+			r.Pending = 1
+		}
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// setBlockers sets BlockedBy of each pending lock to the lowest thread id
+// of another thread holding a granted lock on the same object
+func (rows Rows) setBlockers() {
+	for i := range rows {
+		rows[i].BlockedBy = 0
+		if !rows[i].IsPending() {
+			continue
+		}
+		for j := range rows {
+			if rows[j].Status == statusGranted &&
+				rows[j].ThreadID != rows[i].ThreadID &&
+				rows[j].sameObject(rows[i]) &&
+				(rows[i].BlockedBy == 0 || rows[j].ThreadID < rows[i].BlockedBy) {
+				rows[i].BlockedBy = rows[j].ThreadID
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package metadatalocks
+
+import (
+	"testing"
+)
+
+func TestSetBlockers(t *testing.T) {
+	rows := Rows{
+		{ObjectType: "TABLE", Schema: "db", Name: "t1", LockType: "SHARED_READ", Status: statusGranted, ThreadID: 50},
+		{ObjectType: "TABLE", Schema: "db", Name: "t1", LockType: "SHARED_WRITE", Status: statusGranted, ThreadID: 40},
+		{ObjectType: "TABLE", Schema: "db", Name: "t1", LockType: "EXCLUSIVE", Status: statusPending, ThreadID: 60},
+		{ObjectType: "TABLE", Schema: "db", Name: "t2", LockType: "EXCLUSIVE", Status: statusPending, ThreadID: 70},
+		{ObjectType: "TABLE", Schema: "db", Name: "t3", LockType: "SHARED_UPGRADABLE", Status: statusGranted, ThreadID: 80},
+		{ObjectType: "TABLE", Schema: "db", Name: "t3", LockType: "EXCLUSIVE", Status: statusPending, ThreadID: 80},
+	}
+	expected := []uint64{0, 0, 40, 0, 0, 0}
+
+	rows.setBlockers()
+	for i := range rows {
+		if rows[i].BlockedBy != expected[i] {
+			t.Errorf("setBlockers() failed for row %d (%+v): expected: %v, got %v", i, rows[i], expected[i], rows[i].BlockedBy)
+		}
+	}
+}
@@ -38,10 +38,15 @@ func NewMutexLatency(cfg *config.Config, db *sql.DB) *MutexLatency {
 // Collect collects data from the db, updating first
 // values if needed, and then subtracting first values if we want
 // relative values, after which it stores totals.
-func (ml *MutexLatency) Collect() {
+func (ml *MutexLatency) Collect() error {
 	start := time.Now()
 
-	ml.last = collect(ml.db)
+	collected, err := collect(ml.db)
+	if err != nil {
+		return err
+	}
+
+	ml.last = collected
 	ml.LastCollected = time.Now()
 
 	// check if no first data or we need to reload initial characteristics
@@ -55,6 +60,8 @@ func (ml *MutexLatency) Collect() {
 	log.Println("t.initial.totals():", totals(ml.first))
 	log.Println("t.current.totals():", totals(ml.last))
 	log.Println("MutexLatency.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
 }
 
 func (ml *MutexLatency) calculate() {
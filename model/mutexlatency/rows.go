@@ -4,8 +4,6 @@ package mutexlatency
 
 import (
 	"database/sql"
-
-	"github.com/sjmudd/ps-top/mylog"
 )
 
 // Rows contains a slice of Row
@@ -22,7 +20,7 @@ func totals(rows Rows) Row {
 	return total
 }
 
-func collect(dbh *sql.DB) Rows {
+func collect(dbh *sql.DB) (Rows, error) {
 	var t Rows
 
 	// we collect all information even if it's mainly empty as we may reference it later
@@ -30,7 +28,7 @@ func collect(dbh *sql.DB) Rows {
 
 	rows, err := dbh.Query(sql)
 	if err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -40,7 +38,7 @@ func collect(dbh *sql.DB) Rows {
 			&r.Name,
 			&r.SumTimerWait,
 			&r.CountStar); err != nil {
-			mylog.Fatal(err)
+			return nil, err
 		}
 
 		// trim off the leading 'wait/synch/mutex/innodb/'
@@ -52,10 +50,10 @@ func collect(dbh *sql.DB) Rows {
 		t = append(t, r)
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 
-	return t
+	return t, nil
 }
 
 // remove the initial values from those rows where there's a match
@@ -82,3 +80,8 @@ func (rows *Rows) subtract(initial Rows) {
 func (rows Rows) needsRefresh(otherRows Rows) bool {
 	return totals(rows).SumTimerWait > totals(otherRows).SumTimerWait
 }
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
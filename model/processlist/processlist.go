@@ -0,0 +1,63 @@
+// Package processlist provides library routines for ps-top
+// for showing what each thread is doing, like SHOW PROCESSLIST.
+package processlist
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// Processlist holds a table of rows
+type Processlist struct {
+	baseobject.BaseObject      // embedded
+	Results               Rows // the threads which are doing something
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewProcesslist returns a processlist object using given config and db
+func NewProcesslist(cfg *config.Config, db *sql.DB) *Processlist {
+	log.Println("NewProcesslist()")
+	pl := &Processlist{
+		db: db,
+	}
+	pl.SetConfig(cfg)
+
+	return pl
+}
+
+// Collect collects the threads and their current statements from the db.
+// There are no relative values as each collection is a snapshot.
+func (pl *Processlist) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(pl.db)
+	if err != nil {
+		return err
+	}
+
+	pl.Results = collected
+	pl.LastCollected = time.Now()
+	if pl.FirstCollected.IsZero() {
+		pl.FirstCollected = pl.LastCollected
+	}
+	pl.Totals = totals(pl.Results)
+
+	log.Println("Processlist.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (pl *Processlist) ResetStatistics() {
+	log.Println("processlist.Processlist.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (pl Processlist) HaveRelativeStats() bool {
+	return false
+}
@@ -0,0 +1,46 @@
+package processlist
+
+import (
+	"strings"
+)
+
+/* threads is joined to events_statements_current to find the statement each thread is running.
+
+mysql> select THREAD_ID, NAME, TYPE, PROCESSLIST_ID, PROCESSLIST_USER, PROCESSLIST_COMMAND, PROCESSLIST_TIME, PROCESSLIST_STATE from threads where PROCESSLIST_STATE is not null limit 2;
++-----------+---------------------------+------------+----------------+------------------+---------------------+------------------+------------------------+
+| THREAD_ID | NAME                      | TYPE       | PROCESSLIST_ID | PROCESSLIST_USER | PROCESSLIST_COMMAND | PROCESSLIST_TIME | PROCESSLIST_STATE      |
++-----------+---------------------------+------------+----------------+------------------+---------------------+------------------+------------------------+
+|        43 | thread/sql/event_scheduler| FOREGROUND |              6 | event_scheduler  | Daemon              |             3405 | Waiting on empty queue |
+|        52 | thread/sql/one_connection | FOREGROUND |             17 | app              | Query               |               12 | Sending data           |
++-----------+---------------------------+------------+----------------+------------------+---------------------+------------------+------------------------+
+
+Unlike SHOW PROCESSLIST reading threads does not take a mutex blocking the server.
+
+*/
+
+// Row contains the information of a single thread
+type Row struct {
+	ThreadID      uint64 // performance_schema thread id
+	ProcesslistID uint64 // connection id as shown in the processlist, 0 for background threads
+	Instrument    string // the thread's instrument, e.g. thread/sql/one_connection
+	Background    bool   // is this a background thread?
+	User          string
+	Host          string
+	DB            string
+	Command       string // e.g. Query or Daemon
+	Time          uint64 // seconds in the current state
+	State         string
+	Latency       uint64 // picoseconds spent in the current statement, 0 if none
+	Statement     string // the digest text of the current statement, or its text if it has no digest yet
+}
+
+// Name returns user@host of a connection, or the instrument of a background thread
+func (row Row) Name() string {
+	if row.Background || row.User == "" {
+		return strings.TrimPrefix(row.Instrument, "thread/")
+	}
+	if row.Host == "" {
+		return row.User
+	}
+	return row.User + "@" + row.Host
+}
@@ -0,0 +1,103 @@
+// Package processlist contains the library routines for managing the
+// threads from performance_schema.threads and events_statements_current.
+package processlist
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/sjmudd/anonymiser"
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// totals returns the totals of all rows, the latency being that of the longest statement
+func totals(rows Rows) Row {
+	total := Row{User: "Totals"}
+
+	for _, row := range rows {
+		if row.Latency > total.Latency {
+			total.Latency = row.Latency
+		}
+		if row.Time > total.Time {
+			total.Time = row.Time
+		}
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+// statement returns the digest text of a statement, or its text if it has no
+// digest yet and we are not anonymising, on a single line
+func statement(digestText, sqlText string) string {
+	text := digestText
+	if text == "" && !anonymiser.Enabled() {
+		text = sqlText
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	// only the threads which are doing something, ignoring our own connection
+	query := `SELECT t.THREAD_ID,
+	IFNULL(t.PROCESSLIST_ID, 0),
+	t.NAME,
+	t.TYPE = 'BACKGROUND',
+	IFNULL(t.PROCESSLIST_USER, ''),
+	IFNULL(t.PROCESSLIST_HOST, ''),
+	IFNULL(t.PROCESSLIST_DB, ''),
+	IFNULL(t.PROCESSLIST_COMMAND, ''),
+	IFNULL(t.PROCESSLIST_TIME, 0),
+	IFNULL(t.PROCESSLIST_STATE, ''),
+	IFNULL(esc.TIMER_WAIT, 0),
+	IFNULL(esc.DIGEST_TEXT, ''),
+	IFNULL(esc.SQL_TEXT, '')
+FROM performance_schema.threads t
+LEFT JOIN performance_schema.events_statements_current esc ON esc.THREAD_ID = t.THREAD_ID AND esc.END_EVENT_ID IS NULL
+WHERE (esc.THREAD_ID IS NOT NULL OR IFNULL(t.PROCESSLIST_STATE, '') <> '')
+AND (t.PROCESSLIST_ID IS NULL OR t.PROCESSLIST_ID <> CONNECTION_ID())`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		var digestText, sqlText string
+		if err := rows.Scan(
+			&r.ThreadID,
+			&r.ProcesslistID,
+			&r.Instrument,
+			&r.Background,
+			&r.User,
+			&r.Host,
+			&r.DB,
+			&r.Command,
+			&r.Time,
+			&r.State,
+			&r.Latency,
+			&digestText,
+			&sqlText); err != nil {
+			return nil, err
+		}
+		r.User = anonymiser.Anonymise("user", r.User)
+		r.DB = anonymiser.Anonymise("schema", r.DB)
+		r.Statement = statement(digestText, sqlText)
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,59 @@
+package processlist
+
+import (
+	"testing"
+
+	"github.com/sjmudd/anonymiser"
+)
+
+func TestTotals(t *testing.T) {
+	rows := Rows{
+		{ThreadID: 50, User: "app", Latency: 2000, Time: 3},
+		{ThreadID: 51, User: "app", Latency: 9000, Time: 1},
+		{ThreadID: 52, Instrument: "thread/sql/event_scheduler", Time: 300},
+	}
+
+	if total := rows.Totals(); total.Latency != 9000 || total.Time != 300 || total.Name() != "Totals" {
+		t.Errorf("Totals() failed: expected the longest latency 9000 and time 300 of Totals, got %+v", total)
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		row      Row
+		expected string
+	}{
+		{Row{User: "app", Host: "10.0.0.1"}, "app@10.0.0.1"},
+		{Row{User: "app"}, "app"},
+		{Row{Instrument: "thread/innodb/page_cleaner_thread", Background: true}, "innodb/page_cleaner_thread"},
+		{Row{Instrument: "thread/sql/replica_sql", User: "system user", Background: true}, "sql/replica_sql"},
+	}
+
+	for _, test := range tests {
+		if got := test.row.Name(); got != test.expected {
+			t.Errorf("%+v.Name() failed: expected %q, got %q", test.row, test.expected, got)
+		}
+	}
+}
+
+func TestStatement(t *testing.T) {
+	tests := []struct {
+		digestText string
+		sqlText    string
+		anonymise  bool
+		expected   string
+	}{
+		{"SELECT * FROM `t` WHERE `id` = ?", "SELECT * FROM t WHERE id = 1", false, "SELECT * FROM `t` WHERE `id` = ?"},
+		{"", "SELECT *\n  FROM t", false, "SELECT * FROM t"},
+		{"", "SELECT * FROM t", true, ""},
+		{"", "", false, ""},
+	}
+	defer anonymiser.Enable(anonymiser.Enabled())
+
+	for _, test := range tests {
+		anonymiser.Enable(test.anonymise)
+		if got := statement(test.digestText, test.sqlText); got != test.expected {
+			t.Errorf("statement(%q, %q) anonymising: %v failed: expected %q, got %q", test.digestText, test.sqlText, test.anonymise, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package replication
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// Replication holds a table of rows
+type Replication struct {
+	baseobject.BaseObject      // embedded
+	Results               Rows // the replication channels
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewReplication returns a replication object using given config and db
+func NewReplication(cfg *config.Config, db *sql.DB) *Replication {
+	log.Println("NewReplication()")
+	r := &Replication{
+		db: db,
+	}
+	r.SetConfig(cfg)
+
+	return r
+}
+
+// Collect collects the state of the replication channels from the db and stores their totals.
+// There are no relative values as each collection is a snapshot.
+func (r *Replication) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(r.db)
+	if err != nil {
+		return err
+	}
+
+	r.Results = collected
+	r.LastCollected = time.Now()
+	if r.FirstCollected.IsZero() {
+		r.FirstCollected = r.LastCollected
+	}
+	r.Totals = totals(r.Results)
+
+	log.Println("Replication.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (r *Replication) ResetStatistics() {
+	log.Println("replication.Replication.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (r Replication) HaveRelativeStats() bool {
+	return false
+}
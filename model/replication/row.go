@@ -0,0 +1,104 @@
+package replication
+
+import (
+	"strconv"
+	"strings"
+)
+
+/* SHOW REPLICA STATUS needs MySQL 8.0.22 or MariaDB 10.5.1, older servers use
+   SHOW SLAVE STATUS whose columns say master and slave instead of source and replica.
+
+mysql> SHOW REPLICA STATUS\G
+*************************** 1. row ***************************
+             Replica_IO_State: Waiting for source to send event
+                  Source_Host: db1.example.com
+                  Source_Port: 3306
+           Replica_IO_Running: Yes
+          Replica_SQL_Running: Yes
+                Last_IO_Error:
+               Last_SQL_Error:
+              Relay_Log_Space: 1284973
+        Seconds_Behind_Source: 0
+                 Channel_Name:
+...
+
+*/
+
+// defaultChannel is shown for the unnamed replication channel
+const defaultChannel = "(default)"
+
+// Row contains the state of a single replication channel
+type Row struct {
+	Channel       string
+	Source        string // host:port of the source
+	IORunning     string // Yes, No or Connecting
+	SQLRunning    string // Yes or No
+	Lag           uint64 // seconds behind the source
+	LagKnown      bool   // false if the lag is NULL, e.g. when the SQL thread is stopped
+	RelayLogSpace uint64 // bytes used by the relay logs
+	LastError     string // the last IO or SQL error
+	Workers       uint64 // number of applier workers, from performance_schema
+	WorkerErrors  uint64 // number of applier workers with an error
+	ApplyTime     uint64 // picoseconds taken by the slowest worker to apply its last transaction
+	Errors        uint64 // number of problems: threads not running, errors and workers with errors
+	Channels      uint64 // number of channels (1 unless this is a totals row)
+}
+
+// Name returns the channel name
+func (row Row) Name() string {
+	if row.Channel == "" {
+		return defaultChannel
+	}
+	return row.Channel
+}
+
+// countErrors returns the number of problems seen: threads not running, errors and workers with errors
+func (row Row) countErrors() uint64 {
+	errors := row.WorkerErrors
+	if row.IORunning != "Yes" {
+		errors++
+	}
+	if row.SQLRunning != "Yes" {
+		errors++
+	}
+	if row.LastError != "" {
+		errors++
+	}
+	return errors
+}
+
+// statusColumn returns the SHOW REPLICA STATUS name of a SHOW SLAVE STATUS
+// column, lower-cased, e.g. seconds_behind_source for Seconds_Behind_Master
+func statusColumn(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "master", "source")
+	return strings.ReplaceAll(name, "slave", "replica")
+}
+
+// newRow returns the row of a channel given the columns of its SHOW REPLICA STATUS
+// or SHOW SLAVE STATUS row, keyed as returned by statusColumn
+func newRow(status map[string]string) Row {
+	row := Row{
+		Channel:    status["channel_name"],
+		IORunning:  status["replica_io_running"],
+		SQLRunning: status["replica_sql_running"],
+		LastError:  status["last_sql_error"],
+		Channels:   1,
+	}
+	if row.Channel == "" {
+		row.Channel = status["connection_name"] // MariaDB's name for a channel
+	}
+	if host := status["source_host"]; host != "" {
+		row.Source = host + ":" + status["source_port"]
+	}
+	if row.LastError == "" {
+		row.LastError = status["last_io_error"]
+	}
+	if lag, err := strconv.ParseUint(status["seconds_behind_source"], 10, 64); err == nil {
+		row.Lag = lag
+		row.LagKnown = true
+	}
+	row.RelayLogSpace, _ = strconv.ParseUint(status["relay_log_space"], 10, 64)
+
+	return row
+}
@@ -0,0 +1,184 @@
+// Package replication contains the library routines for managing the
+// replication channels from SHOW REPLICA STATUS and performance_schema.
+package replication
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/ps-top/global"
+)
+
+const (
+	accessDeniedErrorNum      = 1227 // SHOW REPLICA STATUS needs the REPLICATION CLIENT privilege
+	syntaxErrorNum            = 1064 // SHOW REPLICA STATUS is not known before MySQL 8.0.22
+	tableDoesNotExistErrorNum = 1146 // replication_applier_status_by_worker needs MySQL 5.7
+	unknownColumnErrorNum     = 1054 // the transaction columns need MySQL 8.0
+)
+
+var useShowSlaveStatus bool // set if the server does not know SHOW REPLICA STATUS. Not protected by a mutex!
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// totals returns the totals of all rows, the lag and apply time being the largest seen
+func totals(rows Rows) Row {
+	total := Row{Channel: "Totals", LagKnown: true}
+
+	for _, row := range rows {
+		if row.Lag > total.Lag {
+			total.Lag = row.Lag
+		}
+		if row.ApplyTime > total.ApplyTime {
+			total.ApplyTime = row.ApplyTime
+		}
+		total.LagKnown = total.LagKnown && row.LagKnown
+		total.RelayLogSpace += row.RelayLogSpace
+		total.Workers += row.Workers
+		total.WorkerErrors += row.WorkerErrors
+		total.Errors += row.Errors
+		total.Channels += row.Channels
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+// showStatus returns the statement used to show the replication status
+func showStatus() string {
+	if useShowSlaveStatus {
+		return "SHOW SLAVE STATUS"
+	}
+	return "SHOW REPLICA STATUS"
+}
+
+// queryStatus runs SHOW REPLICA STATUS, falling back to SHOW SLAVE STATUS on older servers
+func queryStatus(dbh *sql.DB) (*sql.Rows, error) {
+	rows, err := dbh.Query(showStatus())
+	if err != nil && !useShowSlaveStatus && global.IsMysqlError(err, syntaxErrorNum) {
+		log.Println("replication.queryStatus() SHOW REPLICA STATUS not supported, using SHOW SLAVE STATUS:", err)
+		useShowSlaveStatus = true
+		rows, err = dbh.Query(showStatus())
+	}
+	return rows, err
+}
+
+// collectStatus returns a row for each replication channel, none if the server is not a replica
+func collectStatus(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	rows, err := queryStatus(dbh)
+	if err != nil {
+		// the view will not be available but we are called by the initial collection of all views
+		if global.IsMysqlError(err, accessDeniedErrorNum) {
+			log.Println("replication.collectStatus()", showStatus(), "not available, ignoring:", err)
+			return t, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		status := make(map[string]string, len(columns))
+		for i, column := range columns {
+			status[statusColumn(column)] = values[i].String
+		}
+		t = append(t, newRow(status))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// workers holds the applier worker statistics of a channel
+type workers struct {
+	count     uint64
+	errors    uint64
+	applyTime uint64 // picoseconds
+}
+
+// collectWorkers returns the applier worker statistics by channel, none if
+// replication_applier_status_by_worker is not available
+func collectWorkers(dbh *sql.DB) (map[string]workers, error) {
+	byChannel := make(map[string]workers)
+
+	// timestamps which have never been set are 0000-00-00 and give NULL
+	query := `SELECT CHANNEL_NAME,
+	COUNT(*),
+	SUM(LAST_ERROR_NUMBER <> 0),
+	IFNULL(MAX(GREATEST(0, TIMESTAMPDIFF(MICROSECOND, LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP, LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP))), 0)
+FROM performance_schema.replication_applier_status_by_worker
+GROUP BY CHANNEL_NAME`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		if global.IsMysqlError(err, tableDoesNotExistErrorNum) || global.IsMysqlError(err, unknownColumnErrorNum) {
+			log.Println("replication.collectWorkers() replication_applier_status_by_worker not available, ignoring:", err)
+			return byChannel, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var channel string
+		var w workers
+		var applyTime uint64 // in microseconds
+		if err := rows.Scan(&channel, &w.count, &w.errors, &applyTime); err != nil {
+			return nil, err
+		}
+		w.applyTime = applyTime * 1000000
+		byChannel[channel] = w
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return byChannel, nil
+}
+
+// addWorkers adds the applier worker statistics to the rows of their channels
+// and then counts the errors of each channel
+func (rows Rows) addWorkers(byChannel map[string]workers) {
+	for i := range rows {
+		if w, ok := byChannel[rows[i].Channel]; ok {
+			rows[i].Workers = w.count
+			rows[i].WorkerErrors = w.errors
+			rows[i].ApplyTime = w.applyTime
+		}
+		rows[i].Errors = rows[i].countErrors()
+	}
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	t, err := collectStatus(dbh)
+	if err != nil || len(t) == 0 {
+		return t, err
+	}
+	byChannel, err := collectWorkers(dbh)
+	if err != nil {
+		return nil, err
+	}
+	t.addWorkers(byChannel)
+
+	return t, nil
+}
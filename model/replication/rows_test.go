@@ -0,0 +1,71 @@
+package replication
+
+import (
+	"testing"
+)
+
+func TestStatusColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Seconds_Behind_Master", "seconds_behind_source"},
+		{"Seconds_Behind_Source", "seconds_behind_source"},
+		{"Slave_IO_Running", "replica_io_running"},
+		{"Master_Host", "source_host"},
+		{"Channel_Name", "channel_name"},
+	}
+	for _, test := range tests {
+		if got := statusColumn(test.name); got != test.expected {
+			t.Errorf("statusColumn(%q) failed: expected: %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestNewRow(t *testing.T) {
+	tests := []struct {
+		status   map[string]string
+		expected Row
+	}{
+		{
+			map[string]string{"channel_name": "", "source_host": "db1", "source_port": "3306", "replica_io_running": "Yes", "replica_sql_running": "Yes", "seconds_behind_source": "12", "relay_log_space": "1024"},
+			Row{Source: "db1:3306", IORunning: "Yes", SQLRunning: "Yes", Lag: 12, LagKnown: true, RelayLogSpace: 1024, Channels: 1},
+		},
+		{
+			map[string]string{"connection_name": "east", "source_host": "db2", "source_port": "3307", "replica_io_running": "Connecting", "replica_sql_running": "No", "seconds_behind_source": "", "last_io_error": "error connecting to source"},
+			Row{Channel: "east", Source: "db2:3307", IORunning: "Connecting", SQLRunning: "No", LastError: "error connecting to source", Channels: 1},
+		},
+		{
+			map[string]string{"channel_name": "west", "replica_io_running": "Yes", "replica_sql_running": "No", "seconds_behind_source": "", "last_io_error": "io", "last_sql_error": "Duplicate entry"},
+			Row{Channel: "west", IORunning: "Yes", SQLRunning: "No", LastError: "Duplicate entry", Channels: 1}, // the SQL error is shown first
+		},
+	}
+	for _, test := range tests {
+		if got := newRow(test.status); got != test.expected {
+			t.Errorf("newRow(%v) failed: expected: %+v, got %+v", test.status, test.expected, got)
+		}
+	}
+}
+
+func TestAddWorkersAndTotals(t *testing.T) {
+	rows := Rows{
+		{Channel: "", IORunning: "Yes", SQLRunning: "Yes", Lag: 3, LagKnown: true, Channels: 1},
+		{Channel: "east", IORunning: "Yes", SQLRunning: "No", LastError: "Duplicate entry", Channels: 1},
+	}
+	rows.addWorkers(map[string]workers{
+		"":     {count: 4, applyTime: 2000},
+		"east": {count: 2, errors: 1, applyTime: 5000},
+	})
+
+	if rows[0].Workers != 4 || rows[0].ApplyTime != 2000 || rows[0].Errors != 0 {
+		t.Errorf("addWorkers() failed for the default channel: %+v", rows[0])
+	}
+	if rows[1].Workers != 2 || rows[1].Errors != 3 { // SQL thread stopped, an error and a worker with an error
+		t.Errorf("addWorkers() failed for channel east: %+v", rows[1])
+	}
+
+	total := totals(rows)
+	if total.Lag != 3 || total.LagKnown || total.Workers != 6 || total.Errors != 3 || total.ApplyTime != 5000 || total.Channels != 2 {
+		t.Errorf("totals() failed: %+v", total)
+	}
+}
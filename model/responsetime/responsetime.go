@@ -0,0 +1,90 @@
+// Package responsetime provides library routines for ps-top
+// for managing the events_statements_histogram_global table.
+package responsetime
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// ResponseTime holds a table of rows
+type ResponseTime struct {
+	baseobject.BaseObject      // embedded
+	first                 Rows // initial data for relative values
+	last                  Rows // last loaded values
+	Results               Rows // results (maybe with subtraction)
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewResponseTime returns a response time object using given config and db
+func NewResponseTime(cfg *config.Config, db *sql.DB) *ResponseTime {
+	log.Println("NewResponseTime()")
+	rt := &ResponseTime{
+		db: db,
+	}
+	rt.SetConfig(cfg)
+
+	return rt
+}
+
+// Collect collects data from the db, updating first
+// values if needed, and then subtracting first values if we want
+// relative values, after which it stores totals.
+func (rt *ResponseTime) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(rt.db)
+	if err != nil {
+		return err
+	}
+
+	rt.last = collected
+	rt.LastCollected = time.Now()
+
+	// check if no first data or we need to reload initial characteristics
+	if (len(rt.first) == 0 && len(rt.last) > 0) || rt.first.needsRefresh(rt.last) {
+		rt.first = duplicateSlice(rt.last)
+		rt.FirstCollected = rt.LastCollected
+	}
+
+	rt.calculate()
+
+	log.Println("ResponseTime.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+func (rt *ResponseTime) calculate() {
+	results := Rows(duplicateSlice(rt.last))
+	if rt.WantRelativeStats() {
+		results.subtract(rt.first)
+	}
+
+	// only keep the buckets which have been used
+	rt.Results = make(Rows, 0, len(results))
+	for i := range results {
+		if results[i].Count > 0 {
+			rt.Results = append(rt.Results, results[i])
+		}
+	}
+
+	rt.Totals = totals(rt.Results)
+}
+
+// ResetStatistics resets the statistics to current values
+func (rt *ResponseTime) ResetStatistics() {
+	rt.first = duplicateSlice(rt.last)
+	rt.FirstCollected = rt.LastCollected
+
+	rt.calculate()
+}
+
+// HaveRelativeStats is true for this object
+func (rt ResponseTime) HaveRelativeStats() bool {
+	return true
+}
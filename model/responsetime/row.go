@@ -0,0 +1,45 @@
+// Package responsetime contains the library routines for managing the
+// events_statements_histogram_global table.
+package responsetime
+
+import (
+	"log"
+)
+
+/* This table exists in MySQL 8.0.19+
+
+CREATE TABLE `events_statements_histogram_global` (
+  `BUCKET_NUMBER` int unsigned NOT NULL,
+  `BUCKET_TIMER_LOW` bigint unsigned NOT NULL,
+  `BUCKET_TIMER_HIGH` bigint unsigned NOT NULL,
+  `COUNT_BUCKET` bigint unsigned NOT NULL,
+  `COUNT_BUCKET_AND_LOWER` bigint unsigned NOT NULL,
+  `BUCKET_QUANTILE` double(7,6) NOT NULL,
+  PRIMARY KEY (`BUCKET_NUMBER`)
+) ENGINE=PERFORMANCE_SCHEMA
+
+*/
+
+// Row contains a bucket from performance_schema.events_statements_histogram_global
+type Row struct {
+	Bucket    int
+	TimerLow  uint64 // lower bound of the bucket in picoseconds
+	TimerHigh uint64 // upper bound of the bucket in picoseconds
+	Count     uint64 // number of statements in this bucket
+}
+
+// duplicateSlice copies the full slice
+func duplicateSlice(slice []Row) []Row {
+	return append(make([]Row, len(slice)), slice...)
+}
+
+// subtract the countable values in one row from another
+func (row *Row) subtract(other Row) {
+	if row.Count >= other.Count {
+		row.Count -= other.Count
+	} else {
+		log.Println("WARNING: Row.subtract() - subtraction problem! (not subtracting)")
+		log.Println("row=", row)
+		log.Println("other=", other)
+	}
+}
@@ -0,0 +1,101 @@
+// Package responsetime contains the library routines for managing the
+// events_statements_histogram_global table.
+package responsetime
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/ps-top/global"
+)
+
+// tableDoesNotExistErrorNum is returned on servers without the histogram table (< 8.0.19)
+const tableDoesNotExistErrorNum = 1146
+
+// Rows contains a slice of Row
+type Rows []Row
+
+func totals(rows Rows) Row {
+	var total Row
+
+	for _, row := range rows {
+		total.Count += row.Count
+	}
+
+	return total
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	sql := "SELECT BUCKET_NUMBER, BUCKET_TIMER_LOW, BUCKET_TIMER_HIGH, COUNT_BUCKET FROM events_statements_histogram_global ORDER BY BUCKET_NUMBER"
+
+	rows, err := dbh.Query(sql)
+	if err != nil {
+		// the view will not be available but we are called by the initial collection of all views
+		if global.IsMysqlError(err, tableDoesNotExistErrorNum) {
+			log.Println("responsetime.collect() histogram table not available, ignoring:", err)
+			return t, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(
+			&r.Bucket,
+			&r.TimerLow,
+			&r.TimerHigh,
+			&r.Count); err != nil {
+			return nil, err
+		}
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// remove the initial values from those rows where there's a match
+// - if we find a row we can't match ignore it
+func (rows *Rows) subtract(initial Rows) {
+	initialByBucket := make(map[int]int)
+
+	for i := range initial {
+		initialByBucket[initial[i].Bucket] = i
+	}
+
+	for i := range *rows {
+		if initialIndex, ok := initialByBucket[(*rows)[i].Bucket]; ok {
+			(*rows)[i].subtract(initial[initialIndex])
+		}
+	}
+}
+
+// if the data in t2 is "newer", "has more values" than t then it needs refreshing.
+// check this by comparing totals.
+func (rows Rows) needsRefresh(otherRows Rows) bool {
+	return totals(rows).Count > totals(otherRows).Count
+}
+
+// Percentile returns the upper bound of the bucket containing the given
+// percentile (0 < percentile <= 1). The rows are expected to be ordered by bucket.
+func (rows Rows) Percentile(percentile float64) uint64 {
+	total := totals(rows).Count
+	if total == 0 {
+		return 0
+	}
+
+	var cumulative uint64
+	for _, row := range rows {
+		cumulative += row.Count
+		if float64(cumulative) >= percentile*float64(total) {
+			return row.TimerHigh
+		}
+	}
+
+	return rows[len(rows)-1].TimerHigh
+}
@@ -0,0 +1,31 @@
+package responsetime
+
+import (
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	rows := Rows{
+		{Bucket: 0, TimerLow: 0, TimerHigh: 10, Count: 50},
+		{Bucket: 1, TimerLow: 10, TimerHigh: 20, Count: 45},
+		{Bucket: 2, TimerLow: 20, TimerHigh: 30, Count: 4},
+		{Bucket: 3, TimerLow: 30, TimerHigh: 40, Count: 1},
+	}
+	tests := []struct {
+		rows       Rows
+		percentile float64
+		expected   uint64
+	}{
+		{nil, 0.5, 0},
+		{rows, 0.5, 10},
+		{rows, 0.51, 20},
+		{rows, 0.95, 20},
+		{rows, 0.99, 30},
+		{rows, 1.0, 40},
+	}
+	for _, test := range tests {
+		if got := test.rows.Percentile(test.percentile); got != test.expected {
+			t.Errorf("Percentile(%v) failed: expected: %v, got %v", test.percentile, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package stageslatency
+
+import (
+	"database/sql"
+	"log"
+	"sort"
+)
+
+/**************************************************************************
+
+CREATE TABLE `events_stages_current` (
+  `THREAD_ID` bigint unsigned NOT NULL,
+  `EVENT_ID` bigint unsigned NOT NULL,
+  `END_EVENT_ID` bigint unsigned DEFAULT NULL,
+  `EVENT_NAME` varchar(128) NOT NULL,
+  `SOURCE` varchar(64) DEFAULT NULL,
+  `TIMER_START` bigint unsigned DEFAULT NULL,
+  `TIMER_END` bigint unsigned DEFAULT NULL,
+  `TIMER_WAIT` bigint unsigned DEFAULT NULL,
+  `WORK_COMPLETED` bigint unsigned DEFAULT NULL,
+  `WORK_ESTIMATED` bigint unsigned DEFAULT NULL,
+  ...
+) ENGINE=PERFORMANCE_SCHEMA DEFAULT CHARSET=utf8mb4
+
+**************************************************************************/
+
+// unknownColumnErrorNum is returned before MySQL 5.7 which has no WORK_* columns
+const unknownColumnErrorNum = 1054
+
+// Progress holds a stage being executed which estimates the work it has to do,
+// e.g. an ALTER TABLE copying or sorting rows
+type Progress struct {
+	ThreadID      uint64
+	ProcesslistID uint64 // 0 for background threads
+	Name          string
+	TimerWait     uint64 // time spent in the stage so far
+	WorkCompleted uint64
+	WorkEstimated uint64
+}
+
+// Done returns the fraction of the estimated work which has been completed, at most 1
+func (p Progress) Done() float64 {
+	if p.WorkEstimated == 0 {
+		return 0
+	}
+	if p.WorkCompleted >= p.WorkEstimated {
+		return 1
+	}
+	return float64(p.WorkCompleted) / float64(p.WorkEstimated)
+}
+
+// Remaining returns the time in picoseconds the stage needs to finish if the
+// rest of the work is done at the rate so far, or 0 if it is not known
+func (p Progress) Remaining() uint64 {
+	if p.WorkCompleted == 0 || p.WorkCompleted >= p.WorkEstimated {
+		return 0
+	}
+	return uint64(float64(p.TimerWait) * float64(p.WorkEstimated-p.WorkCompleted) / float64(p.WorkCompleted))
+}
+
+// collectProgress returns the stages being executed which estimate their work,
+// the longest running first
+func collectProgress(dbh *sql.DB) ([]Progress, error) {
+	var progress []Progress
+
+	log.Println("events_stages_current.collectProgress()")
+	const query = `SELECT
+	s.THREAD_ID,
+	IFNULL(t.PROCESSLIST_ID, 0),
+	s.EVENT_NAME,
+	IFNULL(s.TIMER_WAIT, 0),
+	IFNULL(s.WORK_COMPLETED, 0),
+	s.WORK_ESTIMATED
+FROM performance_schema.events_stages_current s
+LEFT JOIN performance_schema.threads t ON t.THREAD_ID = s.THREAD_ID
+WHERE s.END_EVENT_ID IS NULL AND s.WORK_ESTIMATED > 0`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Progress
+		if err := rows.Scan(
+			&p.ThreadID,
+			&p.ProcesslistID,
+			&p.Name,
+			&p.TimerWait,
+			&p.WorkCompleted,
+			&p.WorkEstimated); err != nil {
+			return nil, err
+		}
+		p.Name = stageName(p.Name)
+		progress = append(progress, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(progress, func(i, j int) bool { return progress[i].TimerWait > progress[j].TimerWait })
+	log.Printf("recovered %v stage(s) in progress", len(progress))
+
+	return progress, nil
+}
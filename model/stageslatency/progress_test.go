@@ -0,0 +1,45 @@
+package stageslatency
+
+import (
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		progress  Progress
+		done      float64
+		remaining uint64
+	}{
+		{Progress{}, 0, 0},
+		{Progress{TimerWait: 1000, WorkEstimated: 100}, 0, 0}, // no work done yet so no estimate
+		{Progress{TimerWait: 1000, WorkCompleted: 25, WorkEstimated: 100}, 0.25, 3000},
+		{Progress{TimerWait: 1000, WorkCompleted: 100, WorkEstimated: 100}, 1, 0},
+		{Progress{TimerWait: 1000, WorkCompleted: 120, WorkEstimated: 100}, 1, 0}, // the estimate was too low
+	}
+
+	for _, test := range tests {
+		if got := test.progress.Done(); got != test.done {
+			t.Errorf("%+v.Done() failed: expected %v, got %v", test.progress, test.done, got)
+		}
+		if got := test.progress.Remaining(); got != test.remaining {
+			t.Errorf("%+v.Remaining() failed: expected %v, got %v", test.progress, test.remaining, got)
+		}
+	}
+}
+
+func TestStageName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"stage/sql/Sending data", "Sending data"},
+		{"stage/innodb/alter table (read PK and internal sort)", "stage/innodb/alter table (read PK and internal sort)"},
+		{"stage/sql/", "stage/sql/"},
+	}
+
+	for _, test := range tests {
+		if got := stageName(test.name); got != test.expected {
+			t.Errorf("stageName(%q) failed: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -3,15 +3,13 @@ package stageslatency
 import (
 	"database/sql"
 	"log"
-
-	"github.com/sjmudd/ps-top/mylog"
 )
 
 // Rows contains a slice of Rows
 type Rows []Row
 
 // select the rows into table
-func collect(dbh *sql.DB) Rows {
+func collect(dbh *sql.DB) (Rows, error) {
 	var t Rows
 
 	log.Println("events_stages_summary_global_by_event_name.collect()")
@@ -19,7 +17,7 @@ func collect(dbh *sql.DB) Rows {
 
 	rows, err := dbh.Query(sql)
 	if err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -29,23 +27,27 @@ func collect(dbh *sql.DB) Rows {
 			&r.Name,
 			&r.CountStar,
 			&r.SumTimerWait); err != nil {
-			mylog.Fatal(err)
-		}
-
-		// convert the stage name, removing any leading stage/sql/
-		if len(r.Name) > 10 && r.Name[0:10] == "stage/sql/" {
-			r.Name = r.Name[10:]
+			return nil, err
 		}
 
+		r.Name = stageName(r.Name)
 		t = append(t, r)
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	log.Printf("recovered %v row(s):", len(t))
 	log.Println(t)
 
-	return t
+	return t, nil
+}
+
+// stageName converts the stage name, removing any leading stage/sql/
+func stageName(name string) string {
+	if len(name) > 10 && name[0:10] == "stage/sql/" {
+		return name[10:]
+	}
+	return name
 }
 
 // if the data in t2 is "newer", "has more values" than t then it needs refreshing.
@@ -84,3 +86,8 @@ func (rows *Rows) subtract(initial Rows) {
 		}
 	}
 }
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
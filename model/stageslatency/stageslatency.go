@@ -8,6 +8,7 @@ import (
 
 	"github.com/sjmudd/ps-top/baseobject"
 	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/global"
 )
 
 /*
@@ -45,11 +46,13 @@ root@localhost [performance_schema]> select * from events_stages_summary_global_
 
 // StagesLatency provides a public view of object
 type StagesLatency struct {
-	baseobject.BaseObject      // embedded
-	first                 Rows // initial data for relative values
-	last                  Rows // last loaded values
-	Results               Rows // results (maybe with subtraction)
-	Totals                Row  // totals of results
+	baseobject.BaseObject            // embedded
+	first                 Rows       // initial data for relative values
+	last                  Rows       // last loaded values
+	Results               Rows       // results (maybe with subtraction)
+	Totals                Row        // totals of results
+	Progress              []Progress // the stages being executed which estimate their work
+	noProgress            bool       // is the progress of stages not available?
 	db                    *sql.DB
 }
 
@@ -67,9 +70,25 @@ func NewStagesLatency(cfg *config.Config, db *sql.DB) *StagesLatency {
 // Collect collects data from the db, updating initial
 // values if needed, and then subtracting initial values if we want
 // relative values, after which it stores totals.
-func (sl *StagesLatency) Collect() {
+func (sl *StagesLatency) Collect() error {
 	start := time.Now()
-	sl.last = collect(sl.db)
+	collected, err := collect(sl.db)
+	if err != nil {
+		return err
+	}
+
+	if !sl.noProgress {
+		progress, err := collectProgress(sl.db)
+		if global.IsMysqlError(err, unknownColumnErrorNum) {
+			log.Println("StagesLatency.Collect() the progress of stages needs MySQL 5.7, ignoring:", err)
+			sl.noProgress = true
+		} else if err != nil {
+			return err
+		}
+		sl.Progress = progress
+	}
+
+	sl.last = collected
 	sl.LastCollected = time.Now()
 	log.Println("t.current collected", len(sl.last), "row(s) from SELECT")
 
@@ -84,6 +103,8 @@ func (sl *StagesLatency) Collect() {
 	log.Println("t.initial.totals():", totals(sl.first))
 	log.Println("t.current.totals():", totals(sl.last))
 	log.Println("Table_io_waits_summary_by_table.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
 }
 
 // ResetStatistics  resets the statistics to current values
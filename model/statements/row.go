@@ -0,0 +1,71 @@
+package statements
+
+import (
+	"log"
+)
+
+/**************************************************************************
+
+CREATE TABLE `events_statements_summary_by_digest` (
+  `SCHEMA_NAME` varchar(64) DEFAULT NULL,
+  `DIGEST` varchar(64) DEFAULT NULL,
+  `DIGEST_TEXT` longtext,
+  `COUNT_STAR` bigint unsigned NOT NULL,
+  `SUM_TIMER_WAIT` bigint unsigned NOT NULL,
+  ...
+  `SUM_ROWS_SENT` bigint unsigned NOT NULL,
+  `SUM_ROWS_EXAMINED` bigint unsigned NOT NULL,
+  ...
+) ENGINE=PERFORMANCE_SCHEMA DEFAULT CHARSET=utf8mb4
+
+A row with a NULL DIGEST counts the statements which did not fit in the table.
+
+**************************************************************************/
+
+// othersText is shown for the row of statements which did not fit in the table
+const othersText = "<statements not digested>"
+
+// Row contains the statistics of one statement digest
+type Row struct {
+	Schema          string // the default database of the statements, if any
+	Digest          string // the digest hash, empty for statements which did not fit in the table
+	DigestText      string // the normalised statement
+	CountStar       uint64
+	SumTimerWait    uint64
+	SumRowsExamined uint64
+	SumRowsSent     uint64
+}
+
+// Name returns the digest text prefixed by the schema if known
+func (row Row) Name() string {
+	if row.Schema == "" {
+		return row.DigestText
+	}
+	return row.Schema + ": " + row.DigestText
+}
+
+// Key identifies the row as the same digest may be seen in different schemas
+func (row Row) Key() string {
+	return row.Schema + "." + row.Digest
+}
+
+// duplicateSlice copies the full slice
+func duplicateSlice(slice []Row) []Row {
+	return append(make([]Row, 0, len(slice)), slice...)
+}
+
+// subtract the countable values in one row from another
+func (row *Row) subtract(other Row) {
+	// check for issues here (we have a bug) and log it
+	// - this situation should not happen so there's a logic bug somewhere else
+	if row.SumTimerWait >= other.SumTimerWait && row.CountStar >= other.CountStar {
+		row.SumTimerWait -= other.SumTimerWait
+		row.CountStar -= other.CountStar
+		row.SumRowsExamined -= other.SumRowsExamined
+		row.SumRowsSent -= other.SumRowsSent
+	} else {
+		log.Println("WARNING: Row.subtract() - subtraction problem! (not subtracting)")
+		log.Println("row=", row)
+		log.Println("other=", other)
+	}
+}
@@ -0,0 +1,116 @@
+package statements
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/sjmudd/ps-top/global"
+)
+
+const unknownColumnErrorNum = 1054 // QUERY_SAMPLE_TEXT was added in MySQL 8.0
+
+// Rows contains a slice of Rows
+type Rows []Row
+
+// select the rows into table
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	log.Println("events_statements_summary_by_digest.collect()")
+	sql := `SELECT IFNULL(SCHEMA_NAME, ''), IFNULL(DIGEST, ''), IFNULL(DIGEST_TEXT, ''), COUNT_STAR, SUM_TIMER_WAIT, SUM_ROWS_EXAMINED, SUM_ROWS_SENT
+FROM events_statements_summary_by_digest
+WHERE SUM_TIMER_WAIT > 0`
+
+	rows, err := dbh.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(
+			&r.Schema,
+			&r.Digest,
+			&r.DigestText,
+			&r.CountStar,
+			&r.SumTimerWait,
+			&r.SumRowsExamined,
+			&r.SumRowsSent); err != nil {
+			return nil, err
+		}
+		if r.Digest == "" && r.DigestText == "" {
+			r.DigestText = othersText
+		}
+
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	log.Printf("recovered %v row(s):", len(t))
+
+	return t, nil
+}
+
+// if the data in t2 is "newer", "has more values" than t then it needs refreshing.
+// check this by comparing totals.
+func (rows Rows) needsRefresh(otherRows Rows) bool {
+	return totals(rows).SumTimerWait > totals(otherRows).SumTimerWait
+}
+
+// generate the totals of a table
+func totals(rows Rows) Row {
+	total := Row{DigestText: "Totals"}
+
+	for _, row := range rows {
+		total.SumTimerWait += row.SumTimerWait
+		total.CountStar += row.CountStar
+		total.SumRowsExamined += row.SumRowsExamined
+		total.SumRowsSent += row.SumRowsSent
+	}
+
+	return total
+}
+
+// remove the initial values from those rows where there's a match
+// - if we find a row we can't match ignore it
+func (rows *Rows) subtract(initial Rows) {
+	initialByKey := make(map[string]int)
+
+	// iterate over rows by schema and digest
+	for i := range initial {
+		initialByKey[initial[i].Key()] = i
+	}
+
+	for i := range *rows {
+		if initialIndex, ok := initialByKey[(*rows)[i].Key()]; ok {
+			(*rows)[i].subtract(initial[initialIndex])
+		}
+	}
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+// sampleQuery returns a sample of the statements of the digest seen in schema
+func sampleQuery(dbh *sql.DB, schema, digest string) (string, error) {
+	var sample string
+
+	query := `SELECT IFNULL(QUERY_SAMPLE_TEXT, '')
+FROM events_statements_summary_by_digest
+WHERE IFNULL(SCHEMA_NAME, '') = ? AND DIGEST = ?`
+
+	err := dbh.QueryRow(query, schema, digest).Scan(&sample)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", errors.New("the statement is no longer in events_statements_summary_by_digest")
+	case global.IsMysqlError(err, unknownColumnErrorNum):
+		return "", errors.New("sample queries are only collected from MySQL 8.0")
+	}
+
+	return sample, err
+}
@@ -0,0 +1,66 @@
+package statements
+
+import (
+	"testing"
+)
+
+func TestSubtract(t *testing.T) {
+	first := Rows{
+		{Schema: "shop", Digest: "a", CountStar: 10, SumTimerWait: 1000, SumRowsExamined: 100, SumRowsSent: 10},
+		{Schema: "test", Digest: "a", CountStar: 1, SumTimerWait: 50, SumRowsExamined: 5, SumRowsSent: 1},
+		{Schema: "shop", Digest: "b", CountStar: 5, SumTimerWait: 500},
+	}
+	last := Rows{
+		{Schema: "shop", Digest: "a", CountStar: 15, SumTimerWait: 1600, SumRowsExamined: 150, SumRowsSent: 15},
+		{Schema: "test", Digest: "a", CountStar: 1, SumTimerWait: 50, SumRowsExamined: 5, SumRowsSent: 1},   // the same digest in another schema
+		{Schema: "shop", Digest: "b", CountStar: 2, SumTimerWait: 200},                                      // went backwards so not subtracted
+		{Schema: "shop", Digest: "c", CountStar: 3, SumTimerWait: 300, SumRowsExamined: 30, SumRowsSent: 3}, // new since first
+	}
+	expected := Rows{
+		{Schema: "shop", Digest: "a", CountStar: 5, SumTimerWait: 600, SumRowsExamined: 50, SumRowsSent: 5},
+		{Schema: "test", Digest: "a"},
+		{Schema: "shop", Digest: "b", CountStar: 2, SumTimerWait: 200},
+		{Schema: "shop", Digest: "c", CountStar: 3, SumTimerWait: 300, SumRowsExamined: 30, SumRowsSent: 3},
+	}
+
+	results := Rows(duplicateSlice(last))
+	results.subtract(first)
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("subtract() failed: expected: %+v, got %+v", expected[i], results[i])
+		}
+	}
+	if last[0].CountStar != 15 {
+		t.Errorf("subtract() failed: the collected rows were changed: %+v", last[0])
+	}
+
+	total := totals(results)
+	if total.CountStar != 10 || total.SumTimerWait != 1100 || total.SumRowsExamined != 80 || total.SumRowsSent != 8 {
+		t.Errorf("totals() failed: expected 10/1100/80/8, got %+v", total)
+	}
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	first := Rows{{Digest: "a", CountStar: 10, SumTimerWait: 1000}}
+	if first.needsRefresh(Rows{{Digest: "a", CountStar: 11, SumTimerWait: 1100}}) {
+		t.Errorf("needsRefresh() failed: expected false when the values increase")
+	}
+	if !first.needsRefresh(Rows{{Digest: "a", CountStar: 1, SumTimerWait: 10}}) {
+		t.Errorf("needsRefresh() failed: expected true after the table is truncated")
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		row      Row
+		expected string
+	}{
+		{Row{Schema: "shop", DigestText: "SELECT ?"}, "shop: SELECT ?"},
+		{Row{DigestText: "COMMIT"}, "COMMIT"},
+	}
+	for _, test := range tests {
+		if got := test.row.Name(); got != test.expected {
+			t.Errorf("Name() failed: expected: %q, got %q", test.expected, got)
+		}
+	}
+}
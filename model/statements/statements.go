@@ -0,0 +1,115 @@
+// Package statements is the interface to events_statements_summary_by_digest
+package statements
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+/*
+
+mysql> select schema_name, digest_text, count_star, sum_timer_wait, sum_rows_examined, sum_rows_sent from events_statements_summary_by_digest order by sum_timer_wait desc limit 3;
++-------------+------------------------------------------------------+------------+-----------------+-------------------+---------------+
+| schema_name | digest_text                                          | count_star | sum_timer_wait  | sum_rows_examined | sum_rows_sent |
++-------------+------------------------------------------------------+------------+-----------------+-------------------+---------------+
+| shop        | SELECT * FROM `orders` WHERE `customer_id` = ?       |      48211 | 913328157000000 |         482110000 |        964220 |
+| shop        | UPDATE `stock` SET `quantity` = `quantity` - ? ...   |      12950 |  94721085000000 |             12950 |             0 |
+| NULL        | COMMIT                                               |      61161 |  23448031000000 |                 0 |             0 |
++-------------+------------------------------------------------------+------------+-----------------+-------------------+---------------+
+3 rows in set (0.01 sec)
+
+*/
+
+// Statements provides a public view of object
+type Statements struct {
+	baseobject.BaseObject      // embedded
+	first                 Rows // initial data for relative values
+	last                  Rows // last loaded values
+	Results               Rows // results (maybe with subtraction)
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewStatements returns a statements Statements
+func NewStatements(cfg *config.Config, db *sql.DB) *Statements {
+	log.Println("NewStatements()")
+	s := &Statements{
+		db: db,
+	}
+	s.SetConfig(cfg)
+
+	return s
+}
+
+// Collect collects data from the db, updating initial
+// values if needed, and then subtracting initial values if we want
+// relative values, after which it stores totals.
+func (s *Statements) Collect() error {
+	start := time.Now()
+	collected, err := collect(s.db)
+	if err != nil {
+		return err
+	}
+
+	s.last = collected
+	s.LastCollected = time.Now()
+	log.Println("t.current collected", len(s.last), "row(s) from SELECT")
+
+	// check if we need to update first or we need to reload initial characteristics,
+	// e.g. after TRUNCATE TABLE events_statements_summary_by_digest
+	if (len(s.first) == 0 && len(s.last) > 0) || s.first.needsRefresh(s.last) {
+		s.first = duplicateSlice(s.last)
+		s.FirstCollected = s.LastCollected
+	}
+
+	s.calculate()
+
+	log.Println("Statements.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// ResetStatistics resets the statistics to current values
+func (s *Statements) ResetStatistics() {
+	s.first = duplicateSlice(s.last)
+	s.FirstCollected = s.LastCollected
+
+	s.calculate()
+}
+
+// generate the results and totals
+func (s *Statements) calculate() {
+	s.Results = duplicateSlice(s.last)
+	if s.WantRelativeStats() {
+		s.Results.subtract(s.first)
+	}
+	s.Totals = totals(s.Results)
+}
+
+// HaveRelativeStats is true for this object
+func (s Statements) HaveRelativeStats() bool {
+	return true
+}
+
+// SampleQuery returns the schema and a sample of the statements of the
+// digest with the given key so the statement can be explained
+func (s *Statements) SampleQuery(key string) (string, string, error) {
+	for _, row := range s.last {
+		if row.Key() != key {
+			continue
+		}
+		if row.Digest == "" {
+			return "", "", errors.New("statements which were not digested can not be explained")
+		}
+		sample, err := sampleQuery(s.db, row.Schema, row.Digest)
+
+		return row.Schema, sample, err
+	}
+
+	return "", "", errors.New("the statement is no longer collected")
+}
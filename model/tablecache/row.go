@@ -0,0 +1,20 @@
+package tablecache
+
+// Row contains a single table cache status value
+type Row struct {
+	Name  string  // description of the value, e.g. "Open tables"
+	Value uint64  // the current value
+	Limit uint64  // the size of the cache if the value is a usage, otherwise 0
+	Rate  float64 // change per second since the previous collection if the value is a counter
+	// SmoothedRate is the change per second averaged over the last few collections
+	SmoothedRate float64
+	Counter      bool // is the value a counter (rather than a usage)?
+}
+
+// Used returns the fraction of the cache used
+func (row Row) Used() float64 {
+	if row.Limit == 0 {
+		return 0
+	}
+	return float64(row.Value) / float64(row.Limit)
+}
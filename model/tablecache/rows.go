@@ -0,0 +1,94 @@
+package tablecache
+
+import (
+	"time"
+
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+// variables holding the cache sizes
+const (
+	tableOpenCache       = "table_open_cache"
+	tableDefinitionCache = "table_definition_cache"
+)
+
+// values describes the status values we show and the variable holding their limit, if any
+var values = []struct {
+	name   string
+	status string
+	limit  string
+}{
+	{"Open tables", "open_tables", tableOpenCache},
+	{"Open table definitions", "open_table_definitions", tableDefinitionCache},
+	{"Opened tables", "opened_tables", ""},
+	{"Opened table definitions", "opened_table_definitions", ""},
+	{"Table open cache hits", "table_open_cache_hits", ""},
+	{"Table open cache misses", "table_open_cache_misses", ""},
+	{"Table open cache overflows", "table_open_cache_overflows", ""},
+}
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// statusNames returns the names of the status values we need
+func statusNames() []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.status)
+	}
+	return names
+}
+
+// newRows returns the rows given the current and previous status values, the interval
+// between them, the cache sizes and the smoothed rates, if any. Values not known to
+// the server are skipped.
+func newRows(current, previous map[string]int, interval time.Duration, limits map[string]int, smoothed *smooth.Rates) Rows {
+	var rows Rows
+
+	for _, v := range values {
+		value, ok := current[v.status]
+		if !ok {
+			continue
+		}
+		row := Row{
+			Name:    v.name,
+			Value:   uint64(value),
+			Counter: v.limit == "",
+		}
+		if row.Counter {
+			if old, ok := previous[v.status]; ok && value >= old {
+				row.Rate = lib.PerSecond(uint64(value-old), interval)
+			}
+			if smoothed != nil {
+				row.SmoothedRate, _ = smoothed.PerSecond(v.status)
+			}
+		} else {
+			row.Limit = uint64(limits[v.limit])
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// find returns the row with the given name
+func (rows Rows) find(name string) (Row, bool) {
+	for _, row := range rows {
+		if row.Name == name {
+			return row, true
+		}
+	}
+	return Row{}, false
+}
+
+// saturated returns true if the table cache is full and tables are still being opened
+func (rows Rows) saturated() bool {
+	open, ok := rows.find("Open tables")
+	if !ok || open.Limit == 0 || open.Value < open.Limit {
+		return false
+	}
+	opened, ok := rows.find("Opened tables")
+
+	return ok && opened.Rate > 0
+}
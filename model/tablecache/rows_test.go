@@ -0,0 +1,41 @@
+package tablecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+func TestNewRows(t *testing.T) {
+	previous := map[string]int{"open_tables": 100, "opened_tables": 1000}
+	current := map[string]int{"open_tables": 200, "opened_tables": 1100, "table_open_cache_hits": 50}
+	limits := map[string]int{tableOpenCache: 200}
+
+	smoothed := smooth.NewRates(2)
+	smoothed.Add(previous, map[string]int{"opened_tables": 700}, 10*time.Second)
+	smoothed.Add(current, previous, 10*time.Second)
+
+	rows := newRows(current, previous, 10*time.Second, limits, smoothed)
+	expected := Rows{
+		{Name: "Open tables", Value: 200, Limit: 200},
+		{Name: "Opened tables", Value: 1100, Rate: 10, SmoothedRate: 20, Counter: true},
+		{Name: "Table open cache hits", Value: 50, Counter: true},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("newRows() failed: expected: %+v, got %+v", expected, rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("newRows() failed: expected: %+v, got %+v", expected[i], rows[i])
+		}
+	}
+	if !rows.saturated() {
+		t.Errorf("saturated() failed: expected true for %+v", rows)
+	}
+
+	// no rate on the first collection so tables are not being opened
+	if rows := newRows(current, nil, 0, limits, nil); rows.saturated() {
+		t.Errorf("saturated() failed: expected false for %+v", rows)
+	}
+}
@@ -0,0 +1,113 @@
+// Package tablecache provides library routines for ps-top
+// for showing the usage and efficiency of the table cache.
+package tablecache
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/smooth"
+)
+
+// TableCache holds a table of rows
+type TableCache struct {
+	baseobject.BaseObject                // embedded
+	previous              map[string]int // status values of the previous collection, for rates
+	previousCollected     time.Time      // time of the previous collection
+	last                  map[string]int // last loaded status values
+	Results               Rows           // results
+	Totals                Row            // totals of results, not really meaningful here
+	HitRatio              float64        // fraction of table opens found in the cache
+	Saturated             bool           // the cache is full and tables are still being opened
+	smoothed              *smooth.Rates  // recent changes by status name
+	db                    *sql.DB
+}
+
+// NewTableCache returns a table cache object using given config and db
+func NewTableCache(cfg *config.Config, db *sql.DB) *TableCache {
+	log.Println("NewTableCache()")
+	tc := &TableCache{
+		db: db,
+	}
+	tc.SetConfig(cfg)
+
+	return tc
+}
+
+// Collect collects the table cache status values from the db and
+// calculates the rates since the previous collection.
+func (tc *TableCache) Collect() error {
+	start := time.Now()
+
+	collected, err := tc.Status().Values(statusNames()...)
+	if err != nil {
+		return err
+	}
+
+	tc.previous = tc.last
+	tc.previousCollected = tc.LastCollected
+	tc.last = collected
+	tc.LastCollected = time.Now()
+	if tc.FirstCollected.IsZero() {
+		tc.FirstCollected = tc.LastCollected
+	}
+
+	interval := time.Duration(0)
+	if !tc.previousCollected.IsZero() {
+		interval = tc.LastCollected.Sub(tc.previousCollected)
+	}
+	tc.updateSmoothedRates()
+	tc.Results = newRows(tc.last, tc.previous, interval, tc.limits(), tc.smoothed)
+	tc.Totals = Row{Name: "Totals"}
+	tc.HitRatio = hitRatio(tc.last)
+	tc.Saturated = tc.Results.saturated()
+
+	log.Println("TableCache.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// updateSmoothedRates records the change of each status value since the previous collection
+func (tc *TableCache) updateSmoothedRates() {
+	intervals := tc.SmoothIntervals()
+	if intervals < 1 || tc.previousCollected.IsZero() {
+		return
+	}
+	if tc.smoothed == nil {
+		tc.smoothed = smooth.NewRates(intervals)
+	}
+	tc.smoothed.Add(tc.last, tc.previous, tc.LastCollected.Sub(tc.previousCollected))
+}
+
+// limits returns the configured cache sizes. Note: the variables are only collected on startup.
+func (tc TableCache) limits() map[string]int {
+	limits := make(map[string]int)
+	for _, name := range []string{tableOpenCache, tableDefinitionCache} {
+		if value, ok := tc.Variables().GetInt(name); ok {
+			limits[name] = int(value)
+		}
+	}
+	return limits
+}
+
+// hitRatio returns the fraction of table opens satisfied by the table cache
+func hitRatio(values map[string]int) float64 {
+	hits := uint64(values["table_open_cache_hits"])
+	misses := uint64(values["table_open_cache_misses"])
+
+	return lib.Divide(hits, hits+misses)
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (tc *TableCache) ResetStatistics() {
+	log.Println("tablecache.TableCache.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (tc TableCache) HaveRelativeStats() bool {
+	return false
+}
@@ -8,9 +8,33 @@ import (
 
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/filter"
-	"github.com/sjmudd/ps-top/mylog"
 )
 
+// sysQuery returns the same columns as table_io_waits_summary_by_table from
+// sys.x$schema_table_statistics, for users who may not read performance_schema.
+// The view has no read and write totals so they are made from the operations.
+const sysQuery = `SELECT OBJECT_SCHEMA, OBJECT_NAME, COUNT_STAR, SUM_TIMER_WAIT, COUNT_READ, SUM_TIMER_READ, COUNT_WRITE, SUM_TIMER_WRITE, COUNT_FETCH, SUM_TIMER_FETCH, COUNT_INSERT, SUM_TIMER_INSERT, COUNT_UPDATE, SUM_TIMER_UPDATE, COUNT_DELETE, SUM_TIMER_DELETE
+FROM (
+	SELECT	table_schema AS OBJECT_SCHEMA,
+		table_name AS OBJECT_NAME,
+		rows_fetched + rows_inserted + rows_updated + rows_deleted AS COUNT_STAR,
+		total_latency AS SUM_TIMER_WAIT,
+		rows_fetched AS COUNT_READ,
+		fetch_latency AS SUM_TIMER_READ,
+		rows_inserted + rows_updated + rows_deleted AS COUNT_WRITE,
+		insert_latency + update_latency + delete_latency AS SUM_TIMER_WRITE,
+		rows_fetched AS COUNT_FETCH,
+		fetch_latency AS SUM_TIMER_FETCH,
+		rows_inserted AS COUNT_INSERT,
+		insert_latency AS SUM_TIMER_INSERT,
+		rows_updated AS COUNT_UPDATE,
+		update_latency AS SUM_TIMER_UPDATE,
+		rows_deleted AS COUNT_DELETE,
+		delete_latency AS SUM_TIMER_DELETE
+	FROM	sys.x$schema_table_statistics
+) AS s
+WHERE SUM_TIMER_WAIT > 0`
+
 // Rows contains a set of rows
 type Rows []Row
 
@@ -38,13 +62,16 @@ func totals(rows Rows) Row {
 	return total
 }
 
-func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) Rows {
+func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter, useSys bool) (Rows, error) {
 	var t Rows
 
-	log.Printf("collect(?,%q)\n", databaseFilter)
+	log.Printf("collect(?,%q,%v)\n", databaseFilter, useSys)
 
 	// we collect all information even if it's mainly empty as we may reference it later
 	sql := `SELECT OBJECT_SCHEMA, OBJECT_NAME, COUNT_STAR, SUM_TIMER_WAIT, COUNT_READ, SUM_TIMER_READ, COUNT_WRITE, SUM_TIMER_WRITE, COUNT_FETCH, SUM_TIMER_FETCH, COUNT_INSERT, SUM_TIMER_INSERT, COUNT_UPDATE, SUM_TIMER_UPDATE, COUNT_DELETE, SUM_TIMER_DELETE FROM table_io_waits_summary_by_table WHERE SUM_TIMER_WAIT > 0`
+	if useSys {
+		sql = sysQuery
+	}
 	args := []interface{}{}
 
 	// Apply the filter if provided and seems good.
@@ -58,7 +85,7 @@ func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) Rows {
 
 	rows, err := dbh.Query(sql, args...)
 	if err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -82,7 +109,7 @@ func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) Rows {
 			&r.SumTimerUpdate,
 			&r.CountDelete,
 			&r.SumTimerDelete); err != nil {
-			mylog.Fatal(err)
+			return nil, err
 		}
 		r.Name = lib.QualifiedTableName(schema, table)
 
@@ -90,10 +117,10 @@ func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) Rows {
 		t = append(t, r)
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 
-	return t
+	return t, nil
 }
 
 // remove the initial values from those rows where there's a match
@@ -120,3 +147,8 @@ func (rows *Rows) subtract(initial Rows) {
 func (rows Rows) needsRefresh(otherRows Rows) bool {
 	return totals(rows).SumTimerWait > totals(otherRows).SumTimerWait
 }
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
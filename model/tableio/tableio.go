@@ -8,6 +8,7 @@ import (
 
 	"github.com/sjmudd/ps-top/baseobject"
 	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/global"
 )
 
 // TableIo contains performance_schema.table_io_waits_summary_by_table data
@@ -19,6 +20,7 @@ type TableIo struct {
 	Results     Rows // results (maybe with subtraction)
 	Totals      Row  // totals of results
 	db          *sql.DB
+	useSys      bool // collect from the sys schema as performance_schema may not be read
 }
 
 // NewTableIo returns an i/o latency object with config and db handle
@@ -42,10 +44,20 @@ func (tiol *TableIo) ResetStatistics() {
 // Collect collects data from the db, updating initial values
 // if needed, and then subtracting initial values if we want relative
 // values, after which it stores totals.
-func (tiol *TableIo) Collect() {
+func (tiol *TableIo) Collect() error {
 	start := time.Now()
 
-	tiol.last = collect(tiol.db, tiol.DatabaseFilter())
+	collected, err := collect(tiol.db, tiol.DatabaseFilter(), tiol.useSys)
+	if err != nil && !tiol.useSys && global.IsAccessDenied(err) {
+		log.Println("TableIo.Collect() performance_schema is not accessible, using sys.x$schema_table_statistics:", err)
+		tiol.useSys = true
+		collected, err = collect(tiol.db, tiol.DatabaseFilter(), tiol.useSys)
+	}
+	if err != nil {
+		return err
+	}
+
+	tiol.last = collected
 	tiol.LastCollected = time.Now()
 
 	// check for no first data or need to reload initial characteristics
@@ -59,6 +71,8 @@ func (tiol *TableIo) Collect() {
 	log.Println("tiol.first.totals():", totals(tiol.first))
 	log.Println("tiol.last.totals():", totals(tiol.last))
 	log.Println("TableIo.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
 }
 
 func (tiol *TableIo) calculate() {
@@ -9,7 +9,6 @@ import (
 
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/filter"
-	"github.com/sjmudd/ps-top/mylog"
 )
 
 // Rows contains multiple rows
@@ -42,7 +41,7 @@ func totals(rows Rows) Row {
 // - filter out empty values
 // - merge rows with the same name into a single row
 // - change FILE_NAME into a more descriptive value.
-func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) Rows {
+func collect(dbh *sql.DB, databaseFilter *filter.DatabaseFilter) (Rows, error) {
 	var t Rows
 
 	sql := `
@@ -77,7 +76,7 @@ WHERE	COUNT_STAR > 0`
 
 	rows, err := dbh.Query(sql, args...)
 	if err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -101,17 +100,17 @@ WHERE	COUNT_STAR > 0`
 			&r.SumTimerWriteLowPriority,
 			&r.SumTimerWriteNormal,
 			&r.SumTimerWriteExternal); err != nil {
-			mylog.Fatal(err)
+			return nil, err
 		}
 		r.Name = lib.QualifiedTableName(schema, table)
 		// we collect all data as we may need it later
 		t = append(t, r)
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 
-	return t
+	return t, nil
 }
 
 // remove the initial values from those rows where there's a match
@@ -137,3 +136,8 @@ func (rows *Rows) subtract(initial Rows) {
 func (rows Rows) needsRefresh(otherRows Rows) bool {
 	return totals(rows).SumTimerWait > totals(otherRows).SumTimerWait
 }
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
@@ -38,9 +38,14 @@ func (tll *TableLocks) copyCurrentToInitial() {
 }
 
 // Collect data from the db, then merge it in.
-func (tll *TableLocks) Collect() {
+func (tll *TableLocks) Collect() error {
 	start := time.Now()
-	tll.current = collect(tll.db, tll.DatabaseFilter())
+	collected, err := collect(tll.db, tll.DatabaseFilter())
+	if err != nil {
+		return err
+	}
+
+	tll.current = collected
 	tll.LastCollected = time.Now()
 
 	// check for no data or check for reload initial characteristics
@@ -50,6 +55,8 @@ func (tll *TableLocks) Collect() {
 
 	tll.calculate()
 	log.Println("TableLocks.Collect() took:", time.Duration(time.Since(start)).String())
+
+	return nil
 }
 
 func (tll *TableLocks) calculate() {
@@ -0,0 +1,39 @@
+// Package threadactivity contains the library routines for summarising
+// performance_schema.threads by thread type.
+package threadactivity
+
+import (
+	"log"
+)
+
+// Row contains the activity of all threads of a given type (FOREGROUND or BACKGROUND)
+type Row struct {
+	Type             string
+	Threads          uint64 // current number of threads, never subtracted
+	WaitLatency      uint64 // sum of non-idle wait time of these threads
+	WaitCount        uint64
+	StatementLatency uint64 // sum of statement time of these threads
+	StatementCount   uint64
+}
+
+// duplicateSlice copies the full slice
+func duplicateSlice(slice []Row) []Row {
+	return append(make([]Row, len(slice)), slice...)
+}
+
+// subtract the countable values in one row from another.
+// The by-thread summaries lose the values of threads which have
+// disconnected so the values may go backwards. If they do we don't subtract.
+func (row *Row) subtract(other Row) {
+	if row.WaitLatency >= other.WaitLatency && row.WaitCount >= other.WaitCount &&
+		row.StatementLatency >= other.StatementLatency && row.StatementCount >= other.StatementCount {
+		row.WaitLatency -= other.WaitLatency
+		row.WaitCount -= other.WaitCount
+		row.StatementLatency -= other.StatementLatency
+		row.StatementCount -= other.StatementCount
+	} else {
+		log.Println("WARNING: Row.subtract() - values have gone backwards (not subtracting)")
+		log.Println("row=", row)
+		log.Println("other=", other)
+	}
+}
@@ -0,0 +1,97 @@
+// Package threadactivity contains the library routines for summarising
+// performance_schema.threads by thread type.
+package threadactivity
+
+import (
+	"database/sql"
+)
+
+// Rows contains a slice of Row
+type Rows []Row
+
+func totals(rows Rows) Row {
+	total := Row{Type: "Totals"}
+
+	for _, row := range rows {
+		total.Threads += row.Threads
+		total.WaitLatency += row.WaitLatency
+		total.WaitCount += row.WaitCount
+		total.StatementLatency += row.StatementLatency
+		total.StatementCount += row.StatementCount
+	}
+
+	return total
+}
+
+func collect(dbh *sql.DB) (Rows, error) {
+	var t Rows
+
+	// idle waits are excluded as they would swamp the foreground values
+	sql := `-- threadactivity
+SELECT	t.TYPE,
+	COUNT(*),
+	COALESCE(SUM(w.sumTimerWait), 0),
+	COALESCE(SUM(w.countStar), 0),
+	COALESCE(SUM(s.sumTimerWait), 0),
+	COALESCE(SUM(s.countStar), 0)
+FROM	threads t
+LEFT JOIN (
+	SELECT	THREAD_ID, SUM(SUM_TIMER_WAIT) AS sumTimerWait, SUM(COUNT_STAR) AS countStar
+	FROM	events_waits_summary_by_thread_by_event_name
+	WHERE	EVENT_NAME <> 'idle'
+	GROUP BY THREAD_ID
+) w ON w.THREAD_ID = t.THREAD_ID
+LEFT JOIN (
+	SELECT	THREAD_ID, SUM(SUM_TIMER_WAIT) AS sumTimerWait, SUM(COUNT_STAR) AS countStar
+	FROM	events_statements_summary_by_thread_by_event_name
+	GROUP BY THREAD_ID
+) s ON s.THREAD_ID = t.THREAD_ID
+GROUP BY t.TYPE`
+
+	rows, err := dbh.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(
+			&r.Type,
+			&r.Threads,
+			&r.WaitLatency,
+			&r.WaitCount,
+			&r.StatementLatency,
+			&r.StatementCount); err != nil {
+			return nil, err
+		}
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// remove the initial values from those rows where there's a match
+// - if we find a row we can't match ignore it
+func (rows *Rows) subtract(initial Rows) {
+	initialByType := make(map[string]int)
+
+	for i := range initial {
+		initialByType[initial[i].Type] = i
+	}
+
+	for i := range *rows {
+		if initialIndex, ok := initialByType[(*rows)[i].Type]; ok {
+			(*rows)[i].subtract(initial[initialIndex])
+		}
+	}
+}
+
+// if the data in t2 is "newer", "has more values" than t then it needs refreshing.
+// check this by comparing totals.
+func (rows Rows) needsRefresh(otherRows Rows) bool {
+	return totals(rows).WaitLatency > totals(otherRows).WaitLatency
+}
@@ -0,0 +1,82 @@
+// Package threadactivity provides library routines for ps-top
+// for summarising performance_schema.threads by thread type.
+package threadactivity
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// ThreadActivity holds a table of rows
+type ThreadActivity struct {
+	baseobject.BaseObject      // embedded
+	first                 Rows // initial data for relative values
+	last                  Rows // last loaded values
+	Results               Rows // results (maybe with subtraction)
+	Totals                Row  // totals of results
+	db                    *sql.DB
+}
+
+// NewThreadActivity returns a thread activity object using given config and db
+func NewThreadActivity(cfg *config.Config, db *sql.DB) *ThreadActivity {
+	log.Println("NewThreadActivity()")
+	ta := &ThreadActivity{
+		db: db,
+	}
+	ta.SetConfig(cfg)
+
+	return ta
+}
+
+// Collect collects data from the db, updating first
+// values if needed, and then subtracting first values if we want
+// relative values, after which it stores totals.
+func (ta *ThreadActivity) Collect() error {
+	start := time.Now()
+
+	collected, err := collect(ta.db)
+	if err != nil {
+		return err
+	}
+
+	ta.last = collected
+	ta.LastCollected = time.Now()
+
+	// check if no first data or we need to reload initial characteristics
+	if (len(ta.first) == 0 && len(ta.last) > 0) || ta.first.needsRefresh(ta.last) {
+		ta.first = duplicateSlice(ta.last)
+		ta.FirstCollected = ta.LastCollected
+	}
+
+	ta.calculate()
+
+	log.Println("ThreadActivity.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+func (ta *ThreadActivity) calculate() {
+	ta.Results = duplicateSlice(ta.last)
+	if ta.WantRelativeStats() {
+		ta.Results.subtract(ta.first)
+	}
+
+	ta.Totals = totals(ta.Results)
+}
+
+// ResetStatistics resets the statistics to current values
+func (ta *ThreadActivity) ResetStatistics() {
+	ta.first = duplicateSlice(ta.last)
+	ta.FirstCollected = ta.LastCollected
+
+	ta.calculate()
+}
+
+// HaveRelativeStats is true for this object
+func (ta ThreadActivity) HaveRelativeStats() bool {
+	return true
+}
@@ -0,0 +1,35 @@
+package transactions
+
+/* information_schema.INNODB_TRX is joined to performance_schema.threads
+   and events_transactions_current to find the owning thread.
+
+mysql> select trx_id, trx_state, trx_started, trx_mysql_thread_id, trx_rows_locked, trx_rows_modified from information_schema.innodb_trx;
++-----------------+-----------+---------------------+---------------------+-----------------+-------------------+
+| trx_id          | trx_state | trx_started         | trx_mysql_thread_id | trx_rows_locked | trx_rows_modified |
++-----------------+-----------+---------------------+---------------------+-----------------+-------------------+
+| 1793530         | RUNNING   | 2023-10-01 10:21:44 |                  12 |               3 |                 2 |
++-----------------+-----------+---------------------+---------------------+-----------------+-------------------+
+
+*/
+
+// Row contains the information of a single transaction
+type Row struct {
+	ID            string // InnoDB transaction id
+	State         string // InnoDB transaction state, e.g. RUNNING or LOCK WAIT
+	User          string
+	Host          string
+	Age           uint64 // seconds since the transaction started
+	RowsModified  uint64
+	RowsLocked    uint64
+	LockStructs   uint64 // number of lock structures, the number of locks held
+	ThreadID      uint64 // performance_schema thread id
+	ProcesslistID uint64 // connection id as shown in the processlist
+}
+
+// Name returns user@host of the owner of the transaction, or just the user if the host is unknown
+func (row Row) Name() string {
+	if row.Host == "" {
+		return row.User
+	}
+	return row.User + "@" + row.Host
+}
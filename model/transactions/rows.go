@@ -0,0 +1,94 @@
+// Package transactions contains the library routines for managing the
+// current transactions from information_schema.INNODB_TRX.
+package transactions
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/anonymiser"
+	"github.com/sjmudd/ps-top/global"
+)
+
+const accessDeniedErrorNum = 1227 // INNODB_TRX needs the PROCESS privilege
+
+// Rows contains a slice of Row
+type Rows []Row
+
+// totals returns the totals of all rows, the age being that of the oldest transaction
+func totals(rows Rows) Row {
+	total := Row{User: "Totals"}
+
+	for _, row := range rows {
+		if row.Age > total.Age {
+			total.Age = row.Age
+		}
+		total.RowsModified += row.RowsModified
+		total.RowsLocked += row.RowsLocked
+		total.LockStructs += row.LockStructs
+	}
+
+	return total
+}
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
+
+// collect returns the current transactions and whether INNODB_TRX may not
+// be read as the PROCESS privilege is missing
+func collect(dbh *sql.DB) (Rows, bool, error) {
+	var t Rows
+
+	query := `SELECT trx.trx_id,
+	trx.trx_state,
+	IFNULL(TIMESTAMPDIFF(SECOND, trx.trx_started, NOW()), 0),
+	trx.trx_rows_modified,
+	trx.trx_rows_locked,
+	trx.trx_lock_structs,
+	trx.trx_mysql_thread_id,
+	IFNULL(etc.THREAD_ID, IFNULL(t.THREAD_ID, 0)),
+	t.PROCESSLIST_USER,
+	t.PROCESSLIST_HOST
+FROM information_schema.INNODB_TRX trx
+LEFT JOIN performance_schema.threads t ON t.PROCESSLIST_ID = trx.trx_mysql_thread_id
+LEFT JOIN performance_schema.events_transactions_current etc ON etc.THREAD_ID = t.THREAD_ID AND etc.STATE = 'ACTIVE'`
+
+	rows, err := dbh.Query(query)
+	if err != nil {
+		// the view will not be available but we are called by the initial collection of all views
+		if global.IsMysqlError(err, accessDeniedErrorNum) {
+			log.Println("transactions.collect() INNODB_TRX not available, ignoring:", err)
+			return t, true, nil
+		}
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Row
+		var user, host sql.NullString
+		if err := rows.Scan(
+			&r.ID,
+			&r.State,
+			&r.Age,
+			&r.RowsModified,
+			&r.RowsLocked,
+			&r.LockStructs,
+			&r.ProcesslistID,
+			&r.ThreadID,
+			&user,
+			&host); err != nil {
+			return nil, false, err
+		}
+		r.User = anonymiser.Anonymise("user", user.String)
+		r.Host = host.String
+		t = append(t, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return t, false, nil
+}
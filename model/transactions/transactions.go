@@ -0,0 +1,65 @@
+// Package transactions provides library routines for ps-top
+// for showing the transactions currently in progress.
+package transactions
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/sjmudd/ps-top/baseobject"
+	"github.com/sjmudd/ps-top/config"
+)
+
+// Transactions holds a table of rows
+type Transactions struct {
+	baseobject.BaseObject      // embedded
+	Results               Rows // the transactions currently in progress
+	Totals                Row  // totals of results
+	Denied                bool // INNODB_TRX may not be read without the PROCESS privilege
+	db                    *sql.DB
+}
+
+// NewTransactions returns a transactions object using given config and db
+func NewTransactions(cfg *config.Config, db *sql.DB) *Transactions {
+	log.Println("NewTransactions()")
+	tr := &Transactions{
+		db: db,
+	}
+	tr.SetConfig(cfg)
+
+	return tr
+}
+
+// Collect collects the current transactions from the db and stores their totals.
+// There are no relative values as each collection is a snapshot.
+func (tr *Transactions) Collect() error {
+	start := time.Now()
+
+	collected, denied, err := collect(tr.db)
+	if err != nil {
+		return err
+	}
+
+	tr.Results = collected
+	tr.Denied = denied
+	tr.LastCollected = time.Now()
+	if tr.FirstCollected.IsZero() {
+		tr.FirstCollected = tr.LastCollected
+	}
+	tr.Totals = totals(tr.Results)
+
+	log.Println("Transactions.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
+}
+
+// ResetStatistics - NOT IMPLEMENTED
+func (tr *Transactions) ResetStatistics() {
+	log.Println("transactions.Transactions.ResetStatistics() NOT IMPLEMENTED")
+}
+
+// HaveRelativeStats returns if we have relative information
+func (tr Transactions) HaveRelativeStats() bool {
+	return false
+}
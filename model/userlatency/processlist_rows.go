@@ -7,14 +7,13 @@ import (
 	"log"
 
 	"github.com/sjmudd/anonymiser"
-	"github.com/sjmudd/ps-top/mylog"
 )
 
 // ProcesslistRows contains a slice of ProcesslistRow
 type ProcesslistRows []ProcesslistRow
 
 // get the output of I_S.PROCESSLIST - results only used internally
-func collect(dbh *sql.DB) ProcesslistRows {
+func collect(dbh *sql.DB) (ProcesslistRows, error) {
 	// we collect all information even if it's mainly empty as we may reference it later
 	const query = "SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO FROM INFORMATION_SCHEMA.PROCESSLIST"
 
@@ -32,7 +31,7 @@ func collect(dbh *sql.DB) ProcesslistRows {
 
 	rows, err := dbh.Query(query)
 	if err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -47,7 +46,7 @@ func collect(dbh *sql.DB) ProcesslistRows {
 			&time,
 			&state,
 			&info); err != nil {
-			mylog.Fatal(err)
+			return nil, err
 		}
 		r.ID = uint64(id.Int64)
 
@@ -69,8 +68,8 @@ func collect(dbh *sql.DB) ProcesslistRows {
 		t = append(t, r)
 	}
 	if err := rows.Err(); err != nil {
-		mylog.Fatal(err)
+		return nil, err
 	}
 
-	return t
+	return t, nil
 }
@@ -30,6 +30,9 @@ type Row struct {
 	Updates     uint64
 	Deletes     uint64
 	Other       uint64
+	Latency     uint64 // time spent executing statements in picoseconds
+	Statements  uint64 // number of statements executed
+	Memory      uint64 // memory currently used in bytes
 }
 
 // TotalTime returns Runtime + Sleeptime
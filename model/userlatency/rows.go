@@ -18,7 +18,15 @@ func totals(rows Rows) Row {
 		total.Updates += row.Updates
 		total.Deletes += row.Deletes
 		total.Other += row.Other
+		total.Latency += row.Latency
+		total.Statements += row.Statements
+		total.Memory += row.Memory
 	}
 
 	return total
 }
+
+// Totals returns the totals of the given rows
+func (rows Rows) Totals() Row {
+	return totals(rows)
+}
@@ -0,0 +1,142 @@
+package userlatency
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/anonymiser"
+	"github.com/sjmudd/ps-top/global"
+)
+
+// tableDoesNotExistErrorNum is returned by servers without the summary tables, e.g. MariaDB without the memory instrumentation
+const tableDoesNotExistErrorNum = 1146
+
+// stats holds the statement and memory statistics of a user or an account
+type stats struct {
+	latency    uint64 // time spent executing statements in picoseconds
+	statements uint64 // number of statements executed
+	memory     uint64 // memory currently used in bytes
+}
+
+// statsByName holds the stats of each user or account by the name shown
+type statsByName map[string]stats
+
+// subtract returns the latency and number of statements of s since those of
+// initial. A value which went down, e.g. after truncating the table, is used as it is.
+func (s stats) subtract(initial stats) stats {
+	if s.latency >= initial.latency && s.statements >= initial.statements {
+		s.latency -= initial.latency
+		s.statements -= initial.statements
+	}
+	return s
+}
+
+// accountName returns the name of an account as shown: user@host
+func accountName(user, host string) string {
+	return user + "@" + host
+}
+
+// statsQueries returns the queries returning the name and statement stats,
+// and the name and memory used, of each user or account. If useSys is set they
+// come from sys.x$user_summary which has no statistics by account, so no
+// queries are returned when grouping by account.
+func statsQueries(byAccount, useSys bool) (string, string) {
+	if useSys {
+		if byAccount {
+			return "", ""
+		}
+		return `SELECT user, '', statement_latency, statements
+FROM sys.x$user_summary
+WHERE user <> 'background'`,
+			`SELECT user, '', CAST(GREATEST(current_memory, 0) AS UNSIGNED)
+FROM sys.x$user_summary
+WHERE user <> 'background'`
+	}
+	if byAccount {
+		return `SELECT USER, IFNULL(HOST, ''), SUM(SUM_TIMER_WAIT), SUM(COUNT_STAR)
+FROM performance_schema.events_statements_summary_by_account_by_event_name
+WHERE USER IS NOT NULL
+GROUP BY USER, HOST`,
+			`SELECT USER, IFNULL(HOST, ''), CAST(GREATEST(SUM(CURRENT_NUMBER_OF_BYTES_USED), 0) AS UNSIGNED)
+FROM performance_schema.memory_summary_by_account_by_event_name
+WHERE USER IS NOT NULL
+GROUP BY USER, HOST`
+	}
+	return `SELECT USER, '', SUM(SUM_TIMER_WAIT), SUM(COUNT_STAR)
+FROM performance_schema.events_statements_summary_by_user_by_event_name
+WHERE USER IS NOT NULL
+GROUP BY USER`,
+		`SELECT USER, '', CAST(GREATEST(SUM(CURRENT_NUMBER_OF_BYTES_USED), 0) AS UNSIGNED)
+FROM performance_schema.memory_summary_by_user_by_event_name
+WHERE USER IS NOT NULL
+GROUP BY USER`
+}
+
+// collectStats returns the statement and memory statistics of each user or
+// account. Those which the server does not have are left as zero.
+func collectStats(dbh *sql.DB, byAccount, useSys bool) (statsByName, error) {
+	statementsQuery, memoryQuery := statsQueries(byAccount, useSys)
+	collected := make(statsByName)
+
+	name := func(user, host string) string {
+		user = anonymiser.Anonymise("user", user)
+		if byAccount {
+			return accountName(user, host)
+		}
+		return user
+	}
+
+	if err := queryStats(dbh, statementsQuery, func(user, host string, values []uint64) {
+		s := collected[name(user, host)]
+		s.latency, s.statements = values[0], values[1]
+		collected[name(user, host)] = s
+	}, 2); err != nil {
+		return nil, err
+	}
+	if err := queryStats(dbh, memoryQuery, func(user, host string, values []uint64) {
+		s := collected[name(user, host)]
+		s.memory = values[0]
+		collected[name(user, host)] = s
+	}, 1); err != nil {
+		return nil, err
+	}
+
+	return collected, nil
+}
+
+// queryStats runs query, if there is one, returning a user, a host and count values, giving them to add.
+// A missing table is logged and otherwise ignored.
+func queryStats(dbh *sql.DB, query string, add func(user, host string, values []uint64), count int) error {
+	if query == "" {
+		log.Println("userlatency.queryStats() no statistics are available")
+		return nil
+	}
+	rows, err := dbh.Query(query)
+	if global.IsMysqlError(err, tableDoesNotExistErrorNum) {
+		log.Println("userlatency.queryStats() ignoring:", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user, host string
+		values := make([]uint64, count)
+		dest := []interface{}{&user, &host}
+		nullable := make([]sql.NullFloat64, count) // the sums are decimals which may not fit an int64
+		for i := range nullable {
+			dest = append(dest, &nullable[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i := range nullable {
+			values[i] = uint64(nullable[i].Float64)
+		}
+		add(user, host, values)
+	}
+
+	return rows.Err()
+}
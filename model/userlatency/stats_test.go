@@ -0,0 +1,53 @@
+package userlatency
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatsSubtract(t *testing.T) {
+	tests := []struct {
+		current  stats
+		initial  stats
+		expected stats
+	}{
+		{stats{latency: 5000, statements: 10, memory: 1024}, stats{latency: 2000, statements: 4, memory: 512}, stats{latency: 3000, statements: 6, memory: 1024}},
+		{stats{latency: 5000, statements: 10}, stats{}, stats{latency: 5000, statements: 10}},
+		{stats{latency: 1000, statements: 2}, stats{latency: 2000, statements: 4}, stats{latency: 1000, statements: 2}}, // the table was truncated
+	}
+
+	for _, test := range tests {
+		if got := test.current.subtract(test.initial); got != test.expected {
+			t.Errorf("%+v.subtract(%+v) failed: expected %+v, got %+v", test.current, test.initial, test.expected, got)
+		}
+	}
+}
+
+func TestStatsQueries(t *testing.T) {
+	tests := []struct {
+		byAccount bool
+		useSys    bool
+		from      string // the table or view queried, empty if none
+	}{
+		{false, false, "performance_schema.events_statements_summary_by_user_by_event_name"},
+		{true, false, "performance_schema.events_statements_summary_by_account_by_event_name"},
+		{false, true, "sys.x$user_summary"},
+		{true, true, ""},
+	}
+
+	for _, test := range tests {
+		statements, memory := statsQueries(test.byAccount, test.useSys)
+		if test.from == "" {
+			if statements != "" || memory != "" {
+				t.Errorf("statsQueries(%v,%v) failed: expected no queries, got %q, %q", test.byAccount, test.useSys, statements, memory)
+			}
+			continue
+		}
+		if !strings.Contains(statements, test.from) {
+			t.Errorf("statsQueries(%v,%v) failed: expected a query of %s, got %q", test.byAccount, test.useSys, test.from, statements)
+		}
+		if memory == "" {
+			t.Errorf("statsQueries(%v,%v) failed: expected a memory query", test.byAccount, test.useSys)
+		}
+	}
+}
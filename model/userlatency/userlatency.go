@@ -10,24 +10,36 @@ import (
 
 	"github.com/sjmudd/ps-top/baseobject"
 	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/global"
 )
 
 type mapStringInt map[string]int
 
+// baseline holds the statement statistics relative values are calculated from
+type baseline struct {
+	stats     statsByName
+	collected time.Time
+}
+
 // UserLatency contains a table of rows
 type UserLatency struct {
 	baseobject.BaseObject
-	current ProcesslistRows // processlist
-	Results Rows            // results by user
-	Totals  Row             // totals of results
-	db      *sql.DB
+	current   ProcesslistRows   // processlist
+	stats     statsByName       // the statement and memory statistics last collected
+	first     map[bool]baseline // the statistics relative values are calculated from, by grouping by account or not
+	byAccount bool              // group by account (user@host) rather than by user
+	useSys    bool              // collect the statistics from the sys schema as performance_schema may not be read
+	Results   Rows              // results by user or account
+	Totals    Row               // totals of results
+	db        *sql.DB
 }
 
 // NewUserLatency returns a user latency object
 func NewUserLatency(cfg *config.Config, db *sql.DB) *UserLatency {
 	log.Println("NewUserLatency()")
 	ul := &UserLatency{
-		db: db,
+		db:    db,
+		first: make(map[bool]baseline),
 	}
 	ul.SetConfig(cfg)
 
@@ -37,16 +49,40 @@ func NewUserLatency(cfg *config.Config, db *sql.DB) *UserLatency {
 // Collect collects data from the db, updating initial
 // values if needed, and then subtracting initial values if we want
 // relative values, after which it stores totals.
-func (ul *UserLatency) Collect() {
+func (ul *UserLatency) Collect() error {
 	log.Println("UserLatency.Collect() - starting collection of data")
 	start := time.Now()
 
-	ul.current = collect(ul.db)
+	current, err := collect(ul.db)
+	if err != nil {
+		return err
+	}
+
+	stats, err := collectStats(ul.db, ul.byAccount, ul.useSys)
+	if err != nil && !ul.useSys && global.IsAccessDenied(err) {
+		log.Println("UserLatency.Collect() performance_schema is not accessible, using sys.x$user_summary:", err)
+		ul.useSys = true
+		stats, err = collectStats(ul.db, ul.byAccount, ul.useSys)
+	}
+	if err != nil {
+		return err
+	}
+
+	ul.current = current
+	ul.stats = stats
+	ul.LastCollected = time.Now()
 	log.Println("t.current collected", len(ul.current), "row(s) from SELECT")
 
+	if _, found := ul.first[ul.byAccount]; !found {
+		ul.first[ul.byAccount] = baseline{stats: stats, collected: ul.LastCollected}
+	}
+	ul.FirstCollected = ul.first[ul.byAccount].collected
+
 	ul.processlist2byUser()
 
 	log.Println("UserLatency.Collect() END, took:", time.Duration(time.Since(start)).String())
+
+	return nil
 }
 
 // return the hostname without the port part
@@ -86,6 +122,9 @@ func (ul *UserLatency) processlist2byUser() {
 		id := ul.current[i].ID
 		Username := ul.current[i].user // limit size for display
 		host := getHostname(ul.current[i].host)
+		if ul.byAccount {
+			Username = accountName(Username, host)
+		}
 		command := ul.current[i].command
 		db := ul.current[i].db
 		info := ul.current[i].info
@@ -109,7 +148,7 @@ func (ul *UserLatency) processlist2byUser() {
 			// create new row - RESET THE VALUES !!!!
 			rowp := new(Row)
 			row = *rowp
-			row.Username = ul.current[i].user
+			row.Username = Username
 			rowByUser[Username] = row
 		}
 		row.Connections++
@@ -162,6 +201,21 @@ func (ul *UserLatency) processlist2byUser() {
 		rowByUser[Username] = row
 	}
 
+	// add the statement and memory statistics, also of those not connected now
+	first := ul.first[ul.byAccount].stats
+	for name, s := range ul.stats {
+		if ul.WantRelativeStats() {
+			s = s.subtract(first[name])
+		}
+		row, found := rowByUser[name]
+		if !found && s.statements == 0 {
+			continue
+		}
+		row.Username = name
+		row.Latency, row.Statements, row.Memory = s.latency, s.statements, s.memory
+		rowByUser[name] = row
+	}
+
 	results := make(Rows, 0, len(rowByUser))
 	for _, v := range rowByUser {
 		results = append(results, v)
@@ -174,12 +228,29 @@ func (ul *UserLatency) processlist2byUser() {
 	log.Println("UserLatency.processlist2byUser() END")
 }
 
-// HaveRelativeStats returns if we have relative information
+// HaveRelativeStats returns if we have relative information, which the statement statistics are
 func (ul UserLatency) HaveRelativeStats() bool {
-	return false
+	return true
 }
 
-// ResetStatistics - NOT IMPLEMENTED
+// ResetStatistics resets the statement statistics to the current values
 func (ul *UserLatency) ResetStatistics() {
-	log.Println("userlatency.UserLatency.ResetStatistics() NOT IMPLEMENTED")
+	ul.first = map[bool]baseline{
+		ul.byAccount: {stats: ul.stats, collected: ul.LastCollected},
+	}
+	ul.FirstCollected = ul.LastCollected
+
+	ul.processlist2byUser()
+}
+
+// ByAccount returns whether the rows are grouped by account (user@host) rather than by user
+func (ul UserLatency) ByAccount() bool {
+	return ul.byAccount
+}
+
+// SetByAccount sets whether to group the rows by account (user@host) rather than by user.
+// The rows keep the previous grouping until collected again.
+func (ul *UserLatency) SetByAccount(byAccount bool) {
+	log.Println("UserLatency.SetByAccount(", byAccount, ")")
+	ul.byAccount = byAccount
 }
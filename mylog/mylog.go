@@ -10,6 +10,24 @@ import (
 	"os"
 )
 
+// cleanup is called before a fatal message is written to stderr, if set
+var cleanup func()
+
+// SetCleanup sets a function called before a fatal message is written to
+// stderr, e.g. to restore the terminal so the message can be seen.
+// nil removes it.
+func SetCleanup(f func()) {
+	cleanup = f
+}
+
+// runCleanup calls the cleanup function once, if set
+func runCleanup() {
+	if f := cleanup; f != nil {
+		cleanup = nil
+		f()
+	}
+}
+
 func setLoggingDestination(flags int, destination io.Writer) {
 	log.SetFlags(flags)
 	log.SetOutput(destination)
@@ -41,6 +59,7 @@ func SetupLogging(enable bool, logfile string) {
 func Fatal(v ...interface{}) {
 	log.Print(v...)
 
+	runCleanup()
 	setLoggingDestination(log.Ldate|log.Ltime|log.Lshortfile, os.Stderr)
 	log.Fatal(v...)
 }
@@ -49,6 +68,7 @@ func Fatal(v ...interface{}) {
 func Fatalf(format string, v ...interface{}) {
 	log.Printf(format, v...)
 
+	runCleanup()
 	setLoggingDestination(log.Ldate|log.Ltime|log.Lshortfile, os.Stderr)
 	log.Fatalf(format, v...)
 }
@@ -57,6 +77,7 @@ func Fatalf(format string, v ...interface{}) {
 func Fatalln(v ...interface{}) {
 	log.Println(v...)
 
+	runCleanup()
 	setLoggingDestination(log.Ldate|log.Ltime|log.Lshortfile, os.Stderr)
 	log.Fatalln(v...)
 }
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// Quoting determines when CSV fields are quoted
+type Quoting int
+
+// the possible ways of quoting CSV fields
+const (
+	QuoteMinimal Quoting = iota // only quote fields which need it, as encoding/csv does
+	QuoteAlways                 // quote every field
+	QuoteNever                  // never quote fields
+)
+
+// CSVOptions holds the settings used when writing CSV
+type CSVOptions struct {
+	Separator rune    // field separator, ',' if 0
+	Quote     Quoting // when to quote fields
+}
+
+// ParseQuoting returns the Quoting for the given name: always, minimal or never
+func ParseQuoting(name string) (Quoting, error) {
+	switch name {
+	case "", "minimal":
+		return QuoteMinimal, nil
+	case "always":
+		return QuoteAlways, nil
+	case "never":
+		return QuoteNever, nil
+	}
+	return QuoteMinimal, fmt.Errorf("unknown quoting %q, expected always, minimal or never", name)
+}
+
+// ParseSeparator returns the separator given as a string holding a single rune
+func ParseSeparator(separator string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(separator)
+	if size == 0 || size != len(separator) {
+		return 0, fmt.Errorf("separator %q must be a single character", separator)
+	}
+	if r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid separator %q", separator)
+	}
+	return r, nil
+}
+
+// CSV writes each row of data to w as a CSV record holding the collection
+// time (UTC), the view, the row name and the numeric values. If header is true a
+// record with the column names is written first.
+func CSV(w io.Writer, view string, data pstable.Data, options CSVOptions, header bool) error {
+	var records [][]string
+
+	if header {
+		records = append(records, append([]string{"time", "view", "name"}, data.Columns...))
+	}
+	collected := data.Collected.UTC().Format(time.RFC3339)
+	for _, row := range data.Rows {
+		record := make([]string, 0, 3+len(row.Values))
+		record = append(record, collected, view, row.Name)
+		for _, value := range row.Values {
+			record = append(record, strconv.FormatFloat(value, 'f', -1, 64))
+		}
+		records = append(records, record)
+	}
+
+	separator := options.Separator
+	if separator == 0 {
+		separator = ','
+	}
+	if options.Quote == QuoteMinimal {
+		cw := csv.NewWriter(w)
+		cw.Comma = separator
+		return cw.WriteAll(records)
+	}
+
+	for _, record := range records {
+		if _, err := io.WriteString(w, joinRecord(record, separator, options.Quote)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinRecord returns the fields of record separated by separator, quoting them if wanted
+func joinRecord(record []string, separator rune, quote Quoting) string {
+	fields := make([]string, len(record))
+	for i, field := range record {
+		if quote == QuoteAlways {
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+		fields[i] = field
+	}
+	return strings.Join(fields, string(separator))
+}
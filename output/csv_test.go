@@ -0,0 +1,85 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+func TestCSV(t *testing.T) {
+	data := pstable.Data{
+		Collected: time.Unix(1700000000, 0),
+		Columns:   []string{"sum_timer_wait", "count_star"},
+		Rows: []pstable.Row{
+			{Name: `db1.t1`, Values: []float64{1234567, 3}},
+			{Name: `db;"2".t2`, Values: []float64{0.5, 0}},
+		},
+	}
+	tests := []struct {
+		options  CSVOptions
+		header   bool
+		expected string
+	}{
+		{
+			CSVOptions{},
+			true,
+			"time,view,name,sum_timer_wait,count_star\n" +
+				"2023-11-14T22:13:20Z,table_io_latency,db1.t1,1234567,3\n" +
+				"2023-11-14T22:13:20Z,table_io_latency,\"db;\"\"2\"\".t2\",0.5,0\n",
+		},
+		{
+			CSVOptions{Separator: ';'},
+			false,
+			"2023-11-14T22:13:20Z;table_io_latency;db1.t1;1234567;3\n" +
+				"2023-11-14T22:13:20Z;table_io_latency;\"db;\"\"2\"\".t2\";0.5;0\n",
+		},
+		{
+			CSVOptions{Separator: ';', Quote: QuoteAlways},
+			false,
+			"\"2023-11-14T22:13:20Z\";\"table_io_latency\";\"db1.t1\";\"1234567\";\"3\"\n" +
+				"\"2023-11-14T22:13:20Z\";\"table_io_latency\";\"db;\"\"2\"\".t2\";\"0.5\";\"0\"\n",
+		},
+		{
+			CSVOptions{Quote: QuoteNever},
+			false,
+			"2023-11-14T22:13:20Z,table_io_latency,db1.t1,1234567,3\n" +
+				"2023-11-14T22:13:20Z,table_io_latency,db;\"2\".t2,0.5,0\n",
+		},
+	}
+
+	for _, test := range tests {
+		var b strings.Builder
+		if err := CSV(&b, "table_io_latency", data, test.options, test.header); err != nil {
+			t.Errorf("CSV(%+v) failed: %v", test.options, err)
+		}
+		if b.String() != test.expected {
+			t.Errorf("CSV(%+v) failed: expected:\n%s\ngot:\n%s", test.options, test.expected, b.String())
+		}
+	}
+}
+
+func TestParseSeparator(t *testing.T) {
+	tests := []struct {
+		separator string
+		expected  rune
+		ok        bool
+	}{
+		{",", ',', true},
+		{";", ';', true},
+		{"\t", '\t', true},
+		{"§", '§', true},
+		{"", 0, false},
+		{";;", 0, false},
+		{"\"", 0, false},
+		{"\n", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := ParseSeparator(test.separator)
+		if (err == nil) != test.ok || got != test.expected {
+			t.Errorf("ParseSeparator(%q) failed: expected: %q (ok: %v), got %q (err: %v)", test.separator, test.expected, test.ok, got, err)
+		}
+	}
+}
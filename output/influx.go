@@ -0,0 +1,77 @@
+// Package output writes collected data in formats suitable for other tools.
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// characters which must be escaped in InfluxDB line protocol
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	keyEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+)
+
+// Influx writes each row of data to w as a point in InfluxDB line protocol.
+// The row name is added as the "name" tag to the given tags and the collection
+// time is used as the timestamp. Rows without a name or any finite value are skipped.
+func Influx(w io.Writer, measurement string, tags map[string]string, data pstable.Data) error {
+	prefix := measurementEscaper.Replace(measurement) + influxTags(tags)
+	timestamp := data.Collected.UnixNano()
+
+	for _, row := range data.Rows {
+		if row.Name == "" {
+			continue
+		}
+		fields := influxFields(data.Columns, row.Values)
+		if fields == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s,name=%s %s %d\n", prefix, keyEscaper.Replace(row.Name), fields, timestamp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// influxTags returns the tags sorted by key as recommended by InfluxDB, skipping empty values
+func influxTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		if key != "" && key != "name" && tags[key] != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString("," + keyEscaper.Replace(key) + "=" + keyEscaper.Replace(tags[key]))
+	}
+
+	return b.String()
+}
+
+// influxFields returns the comma separated column=value pairs of a row.
+// Values which are not finite are skipped as line protocol can not hold them.
+func influxFields(columns []string, values []float64) string {
+	fields := make([]string, 0, len(columns))
+	for i := range columns {
+		if i >= len(values) {
+			break
+		}
+		if math.IsNaN(values[i]) || math.IsInf(values[i], 0) {
+			continue
+		}
+		fields = append(fields, keyEscaper.Replace(columns[i])+"="+strconv.FormatFloat(values[i], 'f', -1, 64))
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+func TestInflux(t *testing.T) {
+	collected := time.Unix(1700000000, 500)
+	tests := []struct {
+		measurement string
+		tags        map[string]string
+		data        pstable.Data
+		expected    string
+	}{
+		{
+			"ps_top_mutex_latency",
+			map[string]string{"host": "db1"},
+			pstable.Data{},
+			"",
+		},
+		{
+			"ps_top_mutex_latency",
+			map[string]string{"host": "db1", "empty": ""},
+			pstable.Data{
+				Collected: collected,
+				Columns:   []string{"sum_timer_wait", "count_star"},
+				Rows: []pstable.Row{
+					{Name: "wait/synch/mutex/innodb/buf_pool_mutex", Values: []float64{1234567, 3}},
+					{Name: "", Values: []float64{0, 0}},
+				},
+			},
+			"ps_top_mutex_latency,host=db1,name=wait/synch/mutex/innodb/buf_pool_mutex sum_timer_wait=1234567,count_star=3 1700000000000000500\n",
+		},
+		{
+			"ps top,view",
+			map[string]string{"server": "db 1", "host": "a=b,c"},
+			pstable.Data{
+				Collected: collected,
+				Columns:   []string{"rate per second"},
+				Rows:      []pstable.Row{{Name: "Lock wait, timeout", Values: []float64{0.25}}},
+			},
+			`ps\ top\,view,host=a\=b\,c,server=db\ 1,name=Lock\ wait\,\ timeout rate\ per\ second=0.25 1700000000000000500` + "\n",
+		},
+		{
+			"ps_top_replication",
+			nil,
+			pstable.Data{
+				Collected: collected,
+				Columns:   []string{"lag", "workers"},
+				Rows: []pstable.Row{
+					{Name: "db2", Values: []float64{math.NaN(), 4}},
+					{Name: "db3", Values: []float64{math.Inf(1), math.NaN()}},
+				},
+			},
+			"ps_top_replication,name=db2 workers=4 1700000000000000500\n",
+		},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		if err := Influx(&b, test.measurement, test.tags, test.data); err != nil {
+			t.Errorf("Influx(%q) failed: %v", test.measurement, err)
+			continue
+		}
+		if got := b.String(); got != test.expected {
+			t.Errorf("Influx(%q) failed: expected: %q, got %q", test.measurement, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// JSON writes data to w as a JSON document on a single line holding the
+// collection time (UTC), the host, the view, the rows and their totals. Each
+// row is an object of its name and its values keyed by column name, in the
+// order of the columns. Values which are not finite are written as null.
+func JSON(w io.Writer, host, view string, data pstable.Data) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"time":` + jsonString(data.Collected.UTC().Format(time.RFC3339)))
+	buf.WriteString(`,"host":` + jsonString(host))
+	buf.WriteString(`,"view":` + jsonString(view))
+	buf.WriteString(`,"rows":[`)
+	for i, row := range data.Rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONRow(&buf, data.Columns, row)
+	}
+	buf.WriteString(`],"totals":`)
+	writeJSONRow(&buf, data.Columns, data.Totals)
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeJSONRow writes the row as an object of its name and values by column
+func writeJSONRow(buf *bytes.Buffer, columns []string, row pstable.Row) {
+	buf.WriteString(`{"name":` + jsonString(row.Name))
+	for i, column := range columns {
+		buf.WriteString(`,` + jsonString(column) + `:`)
+		if i >= len(row.Values) || math.IsNaN(row.Values[i]) || math.IsInf(row.Values[i], 0) {
+			buf.WriteString("null")
+			continue
+		}
+		buf.WriteString(strconv.FormatFloat(row.Values[i], 'f', -1, 64))
+	}
+	buf.WriteByte('}')
+}
+
+// jsonString returns s as a JSON string, leaving <, > and & unescaped for readability
+func jsonString(s string) string {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s) // encoding a string can not fail
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
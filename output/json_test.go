@@ -0,0 +1,48 @@
+package output
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+func TestJSON(t *testing.T) {
+	data := pstable.Data{
+		Collected: time.Unix(1700000000, 0),
+		Columns:   []string{"sum_timer_wait", "count_star"},
+		Rows: []pstable.Row{
+			{Name: `db1.t1`, Values: []float64{1234567, 3}},
+			{Name: `<db "2">.t2`, Values: []float64{0.5, math.NaN()}},
+			{Name: `short`, Values: []float64{1}},
+		},
+		Totals: pstable.Row{Name: "Totals", Values: []float64{1234567.5, 3}},
+	}
+	expected := `{"time":"2023-11-14T22:13:20Z","host":"db1","view":"table_io_latency","rows":[` +
+		`{"name":"db1.t1","sum_timer_wait":1234567,"count_star":3},` +
+		`{"name":"<db \"2\">.t2","sum_timer_wait":0.5,"count_star":null},` +
+		`{"name":"short","sum_timer_wait":1,"count_star":null}],` +
+		`"totals":{"name":"Totals","sum_timer_wait":1234567.5,"count_star":3}}` + "\n"
+
+	var b strings.Builder
+	if err := JSON(&b, "db1", "table_io_latency", data); err != nil {
+		t.Fatalf("JSON() failed: %v", err)
+	}
+	if b.String() != expected {
+		t.Errorf("JSON() failed: expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+	if !json.Valid([]byte(b.String())) {
+		t.Errorf("JSON() failed: invalid JSON: %s", b.String())
+	}
+
+	b.Reset()
+	if err := JSON(&b, "", "commands", pstable.Data{Collected: time.Unix(1700000000, 0)}); err != nil {
+		t.Fatalf("JSON() failed: %v", err)
+	}
+	if expected := `{"time":"2023-11-14T22:13:20Z","host":"","view":"commands","rows":[],"totals":{"name":""}}` + "\n"; b.String() != expected {
+		t.Errorf("JSON() without rows failed: expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
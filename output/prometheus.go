@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// prometheusLabelEscaper escapes label values as required by the Prometheus text format
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Prometheus writes data to w in the Prometheus text exposition format. Each
+// column is written as the metric <prefix>_<column> with a sample for each row,
+// labelled with the given labels and the row name, e.g.
+// ps_top_table_io_latency_sum_timer_wait{host="db1",name="db1.t1"} 1234567.
+// Rows without a name and values which are not finite are skipped.
+func Prometheus(w io.Writer, prefix string, labels map[string]string, data pstable.Data) error {
+	var b strings.Builder
+
+	common := prometheusLabels(labels)
+	for i, column := range data.Columns {
+		metric := prometheusName(prefix + "_" + column)
+		fmt.Fprintf(&b, "# TYPE %s untyped\n", metric)
+		for _, row := range data.Rows {
+			if row.Name == "" || i >= len(row.Values) || math.IsNaN(row.Values[i]) || math.IsInf(row.Values[i], 0) {
+				continue
+			}
+			fmt.Fprintf(&b, "%s{%sname=\"%s\"} %s\n", metric, common, prometheusLabelEscaper.Replace(row.Name), strconv.FormatFloat(row.Values[i], 'g', -1, 64))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// prometheusLabels returns the labels sorted by name, each followed by a comma, skipping empty values
+func prometheusLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if name != "" && name != "name" && labels[name] != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=\"%s\",", prometheusName(name), prometheusLabelEscaper.Replace(labels[name]))
+	}
+	return b.String()
+}
+
+// prometheusName returns name with any characters not allowed in a Prometheus
+// metric or label name replaced by underscores
+func prometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+func TestPrometheus(t *testing.T) {
+	data := pstable.Data{
+		Collected: time.Unix(1700000000, 0),
+		Columns:   []string{"sum_timer_wait", "count_star"},
+		Rows: []pstable.Row{
+			{Name: `db1.t1`, Values: []float64{1234567, 3}},
+			{Name: `db"2".t\2`, Values: []float64{0.5, math.NaN()}},
+			{Name: ``, Values: []float64{1, 1}},
+		},
+	}
+	expected := "# TYPE ps_top_table_io_latency_sum_timer_wait untyped\n" +
+		"ps_top_table_io_latency_sum_timer_wait{host=\"db1\",name=\"db1.t1\"} 1.234567e+06\n" +
+		"ps_top_table_io_latency_sum_timer_wait{host=\"db1\",name=\"db\\\"2\\\".t\\\\2\"} 0.5\n" +
+		"# TYPE ps_top_table_io_latency_count_star untyped\n" +
+		"ps_top_table_io_latency_count_star{host=\"db1\",name=\"db1.t1\"} 3\n"
+
+	var b strings.Builder
+	if err := Prometheus(&b, "ps_top_table_io_latency", map[string]string{"host": "db1", "empty": ""}, data); err != nil {
+		t.Fatalf("Prometheus() failed: %v", err)
+	}
+	if b.String() != expected {
+		t.Errorf("Prometheus() failed: expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestPrometheusName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"ps_top_commands_rate", "ps_top_commands_rate"},
+		{"ps_top_response-time_p99.9", "ps_top_response_time_p99_9"},
+	}
+	for _, test := range tests {
+		if got := prometheusName(test.name); got != test.expected {
+			t.Errorf("prometheusName(%q) failed: expected: %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// sendTimeout limits how long we wait for the destination
+const sendTimeout = 10 * time.Second
+
+// Send sends body to the destination which may be empty for stdout,
+// an http:// or https:// url to POST to, or udp://host:port.
+func Send(destination string, body []byte) error {
+	if destination == "" {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return post(destination, body)
+	case "udp":
+		return sendUDP(u.Host, body)
+	}
+
+	return fmt.Errorf("unsupported destination %q, expected http://, https:// or udp://", destination)
+}
+
+// post sends the body to the given url
+func post(destination string, body []byte) error {
+	client := http.Client{Timeout: sendTimeout}
+
+	resp, err := client.Post(destination, "text/plain; charset=utf-8", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response from %s: %s", destination, resp.Status)
+	}
+
+	return nil
+}
+
+// sendUDP sends the body as a single datagram to address
+func sendUDP(address string, body []byte) error {
+	conn, err := net.DialTimeout("udp", address, sendTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(body)
+	return err
+}
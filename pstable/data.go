@@ -0,0 +1,29 @@
+package pstable
+
+import (
+	"time"
+)
+
+// Row holds the name and numeric values of a single row of Data
+type Row struct {
+	Name   string
+	Values []float64 // values in the same order as Data.Columns
+}
+
+// Data holds a generic copy of the collected rows so they can be exported
+type Data struct {
+	Collected time.Time // when the data was collected
+	Columns   []string  // the names of the numeric columns
+	Rows      []Row
+	Totals    Row
+}
+
+// Total returns the total of the named column and false if there is no such column
+func (d Data) Total(column string) (float64, bool) {
+	for i, name := range d.Columns {
+		if name == column && i < len(d.Totals.Values) {
+			return d.Totals.Values[i], true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,30 @@
+package pstable
+
+import (
+	"testing"
+)
+
+func TestDataTotal(t *testing.T) {
+	data := Data{
+		Columns: []string{"lag", "apply_time", "workers"},
+		Totals:  Row{Name: "Totals", Values: []float64{12, 3, 4}},
+	}
+	tests := []struct {
+		column   string
+		expected float64
+		found    bool
+	}{
+		{"lag", 12, true},
+		{"workers", 4, true},
+		{"errors", 0, false},
+	}
+	for _, test := range tests {
+		if got, found := data.Total(test.column); got != test.expected || found != test.found {
+			t.Errorf("Total(%q) failed: expected: %v, %v, got %v, %v", test.column, test.expected, test.found, got, found)
+		}
+	}
+
+	if _, found := (Data{Columns: []string{"lag"}}).Total("lag"); found {
+		t.Errorf("Total() failed: expected no total without values")
+	}
+}
@@ -0,0 +1,27 @@
+package pstable
+
+import (
+	"reflect"
+	"regexp"
+)
+
+// Filter keeps the rows of *rows, a pointer to a slice, whose name matches re,
+// using name to return the name of the i'th row. The kept rows are copied to a
+// new slice so one shared with other data is not changed. It returns false and
+// does nothing if re is nil.
+func Filter(rows interface{}, re *regexp.Regexp, name func(i int) string) bool {
+	if re == nil {
+		return false
+	}
+
+	slice := reflect.ValueOf(rows).Elem()
+	kept := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		if re.MatchString(name(i)) {
+			kept = reflect.Append(kept, slice.Index(i))
+		}
+	}
+	slice.Set(kept)
+
+	return true
+}
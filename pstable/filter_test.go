@@ -0,0 +1,36 @@
+package pstable
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+		filtered bool
+	}{
+		{"", "db1.t1 db1.t2 db2.t1", false},
+		{"^db1\\.", "db1.t1 db1.t2", true},
+		{"t1$", "db1.t1 db2.t1", true},
+		{"nothing", "", true},
+	}
+	for _, test := range tests {
+		shared := []string{"db1.t1", "db1.t2", "db2.t1"}
+		rows := shared
+		var re *regexp.Regexp
+		if test.filter != "" {
+			re = regexp.MustCompile(test.filter)
+		}
+
+		filtered := Filter(&rows, re, func(i int) string { return rows[i] })
+		if got := strings.Join(rows, " "); got != test.expected || filtered != test.filtered {
+			t.Errorf("Filter(%q) failed: expected: %q, %v, got: %q, %v", test.filter, test.expected, test.filtered, got, filtered)
+		}
+		if got := strings.Join(shared, " "); got != "db1.t1 db1.t2 db2.t1" {
+			t.Errorf("Filter(%q) failed: the original slice was changed to %q", test.filter, got)
+		}
+	}
+}
@@ -8,7 +8,8 @@ import (
 
 // Tabler is the interface for access to performance_schema rows
 type Tabler interface {
-	Collect() // Collect collects data for the table from the database
+	Collect() error // Collect collects data for the table from the database
+	Data() Data     // Data returns a generic copy of the collected rows
 	Description() string
 	EmptyRowContent() string
 	HaveRelativeStats() bool
@@ -21,3 +22,9 @@ type Tabler interface {
 	TotalRowContent() string
 	WantRelativeStats() bool
 }
+
+// RowKeyer is implemented by Tablers whose rows can be identified, e.g. to
+// act on the row selected on the screen after the data is collected again
+type RowKeyer interface {
+	RowKey(i int) (string, bool) // the key of the i-th row of RowContent, false if there is none
+}
@@ -0,0 +1,77 @@
+package pstable
+
+import (
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Sorter is implemented by a Tabler whose rows can be sorted on any of its Data columns
+type Sorter interface {
+	SortNext()          // sort on the next column
+	SortPrev()          // sort on the previous column
+	SortColumn() string // the column sorted on, empty for the Tabler's own order
+}
+
+// SortKey holds the Data column a Tabler's rows are sorted on and implements Sorter.
+// Column 0 is the Tabler's own order, 1 the first Data column and so on.
+type SortKey struct {
+	columns []string
+	column  int
+}
+
+// NewSortKey returns a SortKey for the given Data columns using the Tabler's own order
+func NewSortKey(columns []string) SortKey {
+	return SortKey{columns: columns}
+}
+
+// SortNext sorts on the next column, going back to the Tabler's own order after the last one
+func (sk *SortKey) SortNext() {
+	sk.column = (sk.column + 1) % (len(sk.columns) + 1)
+}
+
+// SortPrev sorts on the previous column, going to the last one from the Tabler's own order
+func (sk *SortKey) SortPrev() {
+	sk.column = (sk.column + len(sk.columns)) % (len(sk.columns) + 1)
+}
+
+// SortColumn returns the name of the column sorted on or an empty string if using the Tabler's own order
+func (sk SortKey) SortColumn() string {
+	if sk.column == 0 {
+		return ""
+	}
+	return sk.columns[sk.column-1]
+}
+
+// Sort sorts rows, a slice, on the selected column with the largest values first,
+// using values to return the Data values of the i'th row. Rows with the same value
+// stay in their current order and NaN values go last. Nothing is done when
+// using the Tabler's own order.
+func (sk SortKey) Sort(rows interface{}, values func(i int) Row) {
+	if sk.column == 0 {
+		return
+	}
+
+	n := reflect.ValueOf(rows).Len()
+	keys := make([]float64, n)
+	for i := range keys {
+		keys[i] = values(i).Values[sk.column-1]
+	}
+
+	sort.Stable(byKey{keys: keys, swap: reflect.Swapper(rows)})
+}
+
+// byKey sorts a slice by the given keys, largest first
+type byKey struct {
+	keys []float64
+	swap func(i, j int)
+}
+
+func (b byKey) Len() int { return len(b.keys) }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.swap(i, j)
+}
+func (b byKey) Less(i, j int) bool {
+	return b.keys[i] > b.keys[j] || (!math.IsNaN(b.keys[i]) && math.IsNaN(b.keys[j]))
+}
@@ -0,0 +1,64 @@
+package pstable
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSortKeyColumn(t *testing.T) {
+	sk := NewSortKey([]string{"a", "b"})
+	tests := []struct {
+		move     func()
+		expected string
+	}{
+		{func() {}, ""},
+		{sk.SortNext, "a"},
+		{sk.SortNext, "b"},
+		{sk.SortNext, ""},
+		{sk.SortPrev, "b"},
+		{sk.SortPrev, "a"},
+		{sk.SortPrev, ""},
+	}
+	for i, test := range tests {
+		test.move()
+		if got := sk.SortColumn(); got != test.expected {
+			t.Errorf("SortColumn() test %d failed: expected: %q, got: %q", i, test.expected, got)
+		}
+	}
+}
+
+func TestSortKeySort(t *testing.T) {
+	type row struct {
+		name          string
+		first, second float64
+	}
+	tests := []struct {
+		column   int
+		expected string
+	}{
+		{0, "abcd"}, // own order
+		{1, "dbca"},
+		{2, "bacd"}, // equal values keep their order, NaN last
+	}
+	for _, test := range tests {
+		rows := []row{
+			{"a", 1, 2},
+			{"b", 2, 3},
+			{"c", 2, 2},
+			{"d", 4, math.NaN()},
+		}
+		sk := NewSortKey([]string{"first", "second"})
+		for i := 0; i < test.column; i++ {
+			sk.SortNext()
+		}
+		sk.Sort(rows, func(i int) Row { return Row{Name: rows[i].name, Values: []float64{rows[i].first, rows[i].second}} })
+
+		got := ""
+		for _, r := range rows {
+			got += r.name
+		}
+		if got != test.expected {
+			t.Errorf("Sort() on column %d failed: expected: %q, got: %q", test.column, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	go_ini "github.com/vaughan0/go-ini"
+)
+
+// columnsSection is the section of ~/.pstoprc holding the columns chosen for
+// each view, in the order shown with the hidden columns prefixed by -
+// - e.g.
+// [columns]
+// table_io_latency = Latency %, Fetch, -Insert, -Update, -Delete
+const columnsSection = "columns"
+
+// Columns returns the settings of the [columns] section of ~/.pstoprc,
+// which are empty if there is no such file
+func Columns() (map[string]string, error) {
+	filename := modifyFilename(pstoprc)
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not read the columns: %v", err)
+	}
+	defer f.Close()
+
+	return loadColumns(f)
+}
+
+// loadColumns returns the settings of the [columns] section from the given ini data
+func loadColumns(r io.Reader) (map[string]string, error) {
+	i, err := go_ini.Load(r)
+	if err != nil {
+		return nil, fmt.Errorf("can not load the columns: %v", err)
+	}
+	return i.Section(columnsSection), nil
+}
+
+// SaveColumns saves the columns chosen for the named view in the [columns]
+// section of ~/.pstoprc, removing the setting if columns is empty. The rest
+// of the file is kept as it is.
+func SaveColumns(view, columns string) error {
+	filename := modifyFilename(pstoprc)
+	mode := fs.FileMode(0600)
+	data, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("can not save the columns: %v", err)
+	}
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(filename, []byte(setSetting(string(data), columnsSection, view, columns)), mode); err != nil {
+		return fmt.Errorf("can not save the columns: %v", err)
+	}
+	return nil
+}
+
+// setSetting returns the ini data with key of the given section set to value,
+// or removed if value is empty, adding the section at the end if needed
+func setSetting(data, section, key, value string) string {
+	lines := strings.SplitAfter(data, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	setting := key + " = " + value + "\n"
+
+	var current string
+	found, insert := false, -1 // insert the setting after this line of the section
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if current == section {
+				found, insert = true, i
+			}
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if name := strings.SplitN(line, "=", 2); len(name) == 2 && strings.TrimSpace(name[0]) == key {
+			if value == "" {
+				lines = append(lines[:i], lines[i+1:]...)
+			} else {
+				lines[i] = setting
+			}
+			return strings.Join(lines, "")
+		}
+		if line != "" {
+			insert = i
+		}
+	}
+	if value == "" {
+		return data
+	}
+
+	if !found {
+		if len(lines) > 0 {
+			if !strings.HasSuffix(lines[len(lines)-1], "\n") {
+				lines[len(lines)-1] += "\n"
+			}
+			lines = append(lines, "\n")
+		}
+		return strings.Join(append(lines, "["+section+"]\n", setting), "")
+	}
+	if !strings.HasSuffix(lines[insert], "\n") {
+		lines[insert] += "\n"
+	}
+	lines = append(lines[:insert+1], append([]string{setting}, lines[insert+1:]...)...)
+	return strings.Join(lines, "")
+}
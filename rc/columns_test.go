@@ -0,0 +1,36 @@
+package rc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetSetting(t *testing.T) {
+	tests := []struct {
+		data     string
+		key      string
+		value    string
+		expected string
+	}{
+		{"", "commands", "Ops, -%", "[columns]\ncommands = Ops, -%\n"},
+		{"[defaults]\nview = commands", "commands", "Ops", "[defaults]\nview = commands\n\n[columns]\ncommands = Ops\n"},
+		{"[columns]\ncommands = Ops\n\n[defaults]\nview = commands\n", "commands", "-Ops", "[columns]\ncommands = -Ops\n\n[defaults]\nview = commands\n"},
+		{"[columns]\ncommands = Ops\n\n[defaults]\nview = commands\n", "statements", "Latency", "[columns]\ncommands = Ops\nstatements = Latency\n\n[defaults]\nview = commands\n"},
+		{"[columns]\ncommands = Ops\nstatements = Latency\n", "commands", "", "[columns]\nstatements = Latency\n"},
+		{"[defaults]\nview = commands\n", "commands", "", "[defaults]\nview = commands\n"},
+	}
+
+	for _, test := range tests {
+		got := setSetting(test.data, columnsSection, test.key, test.value)
+		if got != test.expected {
+			t.Errorf("setSetting(%q, %q, %q) failed: expected %q, got %q", test.data, test.key, test.value, test.expected, got)
+			continue
+		}
+		columns, err := loadColumns(strings.NewReader(got))
+		if err != nil {
+			t.Errorf("loadColumns(%q) failed: %v", got, err)
+		} else if columns[test.key] != test.value {
+			t.Errorf("loadColumns(%q) failed: expected %s = %q, got %q", got, test.key, test.value, columns[test.key])
+		}
+	}
+}
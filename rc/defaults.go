@@ -0,0 +1,109 @@
+package rc
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	go_ini "github.com/vaughan0/go-ini"
+)
+
+// defaultsSection is the section of ~/.pstoprc holding default settings of the command line flags
+// - e.g.
+// [defaults]
+// interval = 5s
+// view = statements
+const defaultsSection = "defaults"
+
+// environmentPrefix is the prefix of the environment variables setting command line flags
+const environmentPrefix = "PS_TOP_"
+
+// Defaults returns the settings of the [defaults] section of ~/.pstoprc,
+// which are empty if there is no such file
+func Defaults() (map[string]string, error) {
+	filename := modifyFilename(pstoprc)
+
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not read defaults: %v", err)
+	}
+	defer f.Close()
+
+	return loadDefaults(f)
+}
+
+// loadDefaults returns the settings of the [defaults] section from the given ini data
+func loadDefaults(r io.Reader) (map[string]string, error) {
+	i, err := go_ini.Load(r)
+	if err != nil {
+		return nil, fmt.Errorf("can not load defaults: %v", err)
+	}
+
+	return i.Section(defaultsSection), nil
+}
+
+// EnvironmentVariable returns the name of the environment variable setting
+// the named flag, e.g. PS_TOP_DEFAULTS_FILE for --defaults-file
+func EnvironmentVariable(name string) string {
+	return environmentPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ApplyEnvironment sets each flag of flags which has not been set already
+// from its environment variable, if set. Passwords are not taken from the
+// environment: use MYSQL_PWD, a defaults-file or --askpass instead.
+func ApplyEnvironment(flags *flag.FlagSet, getenv func(string) string) error {
+	return apply(flags, func(name string) (string, string, bool) {
+		value := getenv(EnvironmentVariable(name))
+		return value, EnvironmentVariable(name), value != ""
+	})
+}
+
+// ApplyDefaults sets each flag of flags which has not been set already from
+// defaults, as returned by Defaults. Unknown settings and passwords are rejected.
+func ApplyDefaults(flags *flag.FlagSet, defaults map[string]string) error {
+	for name := range defaults {
+		if name == "password" {
+			return fmt.Errorf("passwords must not be stored in the [%s] of %s, use defaults-file, use-environment or --askpass instead", defaultsSection, pstoprc)
+		}
+		if flags.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q in the [%s] of %s", name, defaultsSection, pstoprc)
+		}
+	}
+
+	return apply(flags, func(name string) (string, string, bool) {
+		value, ok := defaults[name]
+		return value, "the [" + defaultsSection + "] of " + pstoprc, ok
+	})
+}
+
+// apply sets each flag which has not been set already, other than the
+// password, to the value returned by lookup if found
+func apply(flags *flag.FlagSet, lookup func(name string) (value, source string, ok bool)) error {
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] || f.Name == "password" {
+			return
+		}
+		value, source, ok := lookup(f.Name)
+		if !ok {
+			return
+		}
+		if setErr := flags.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s %q from %s: %v", f.Name, value, source, setErr)
+		}
+	})
+
+	return err
+}
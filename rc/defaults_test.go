@@ -0,0 +1,78 @@
+package rc
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// newFlags returns a flag set like that of ps-top with some flags given on the command line
+func newFlags(t *testing.T, args ...string) (*flag.FlagSet, *string, *int, *string, *string) {
+	t.Helper()
+	flags := flag.NewFlagSet("ps-top", flag.ContinueOnError)
+	host := flags.String("host", "", "")
+	port := flags.Int("port", 0, "")
+	view := flags.String("view", "", "")
+	password := flags.String("password", "", "")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags, host, port, view, password
+}
+
+func TestApplyEnvironmentAndDefaults(t *testing.T) {
+	defaults, err := loadDefaults(strings.NewReader("[defaults]\nhost = file-host\nport = 3307\nview = statements\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	environment := map[string]string{"PS_TOP_HOST": "env-host", "PS_TOP_PASSWORD": "secret"}
+	getenv := func(name string) string { return environment[name] }
+
+	flags, host, port, view, password := newFlags(t, "--view=commands")
+	if err := ApplyEnvironment(flags, getenv); err != nil {
+		t.Fatalf("ApplyEnvironment() failed: %v", err)
+	}
+	if err := ApplyDefaults(flags, defaults); err != nil {
+		t.Fatalf("ApplyDefaults() failed: %v", err)
+	}
+
+	// the command line takes precedence over the environment which takes precedence over the file
+	if *view != "commands" || *host != "env-host" || *port != 3307 || *password != "" {
+		t.Errorf("ApplyEnvironment() and ApplyDefaults() failed: expected commands/env-host/3307/\"\", got %q/%q/%d/%q", *view, *host, *port, *password)
+	}
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	tests := []struct {
+		defaults map[string]string
+		err      bool
+	}{
+		{nil, false},
+		{map[string]string{"port": "3306"}, false},
+		{map[string]string{"port": "notanumber"}, true},
+		{map[string]string{"password": "secret"}, true},
+		{map[string]string{"unknown": "value"}, true},
+	}
+
+	for _, test := range tests {
+		flags, _, _, _, _ := newFlags(t)
+		if err := ApplyDefaults(flags, test.defaults); (err != nil) != test.err {
+			t.Errorf("ApplyDefaults(%v) failed: expected error: %v, got %v", test.defaults, test.err, err)
+		}
+	}
+}
+
+func TestEnvironmentVariable(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"interval", "PS_TOP_INTERVAL"},
+		{"defaults-file", "PS_TOP_DEFAULTS_FILE"},
+	}
+	for _, test := range tests {
+		if got := EnvironmentVariable(test.name); got != test.expected {
+			t.Errorf("EnvironmentVariable(%q) failed: expected: %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package rc
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	go_ini "github.com/vaughan0/go-ini"
+)
+
+// profilePrefix is the prefix of sections in ~/.pstoprc holding connection profiles
+// - e.g.
+// [profile prod-a]
+// host = prod-a.example.com
+// user = monitor
+const profilePrefix = "profile "
+
+// Profile returns the settings of the named connection profile from ~/.pstoprc
+func Profile(name string) (map[string]string, error) {
+	filename := modifyFilename(pstoprc)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("can not read profile %q: %v", name, err)
+	}
+	defer f.Close()
+
+	return loadProfile(f, name)
+}
+
+// loadProfile returns the settings of the named profile from the given ini data
+func loadProfile(r io.Reader, name string) (map[string]string, error) {
+	i, err := go_ini.Load(r)
+	if err != nil {
+		return nil, fmt.Errorf("can not load profile %q: %v", name, err)
+	}
+
+	section, ok := i[profilePrefix+name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in %s", name, pstoprc)
+	}
+
+	return section, nil
+}
@@ -1,6 +1,7 @@
 package rc
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -45,3 +46,41 @@ func TestMunge(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadProfile(t *testing.T) {
+	data := `
+[munge]
+_[0-9]{8}$ = _YYYYMMDD
+
+[profile prod-a]
+host = prod-a.example.com
+port = 3307
+user = monitor
+`
+	tests := []struct {
+		name     string
+		expected map[string]string
+		err      bool
+	}{
+		{"prod-a", map[string]string{"host": "prod-a.example.com", "port": "3307", "user": "monitor"}, false},
+		{"prod-b", nil, true},
+		{"munge", nil, true},
+	}
+
+	for _, test := range tests {
+		got, err := loadProfile(strings.NewReader(data), test.name)
+		if (err != nil) != test.err {
+			t.Errorf("loadProfile(%q) failed: expected error: %v, got %v", test.name, test.err, err)
+			continue
+		}
+		if len(got) != len(test.expected) {
+			t.Errorf("loadProfile(%q) failed: expected: %v, got %v", test.name, test.expected, got)
+			continue
+		}
+		for k, v := range test.expected {
+			if got[k] != v {
+				t.Errorf("loadProfile(%q) failed: expected %s = %q, got %q", test.name, k, v, got[k])
+			}
+		}
+	}
+}
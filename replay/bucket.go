@@ -0,0 +1,207 @@
+// Package replay holds the routines for working with samples recorded by ps-top.
+package replay
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/collector"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// bucketKey identifies the samples of a view collected in the same bucket
+type bucketKey struct {
+	view  string
+	start time.Time
+}
+
+// counter returns true if the Data column holds a cumulative counter, e.g.
+// count_star or sum_timer_wait, rather than a value when it was collected
+func counter(column string) bool {
+	for _, prefix := range []string{"count", "sum_", "total_"} {
+		if strings.HasPrefix(column, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range []string{"_count", "_latency"} {
+		if strings.HasSuffix(column, suffix) {
+			return true
+		}
+	}
+	return column == "latency" || column == "statements"
+}
+
+// span holds the values of a row when a bucket starts and at its last sample
+type span struct {
+	first []float64
+	last  []float64
+}
+
+// bucket holds the sum of the values of the samples in a bucket and the
+// values its counters started and ended with
+type bucket struct {
+	result  collector.ViewResult
+	rows    map[string]int // position of each row name in result.Rows
+	samples int
+	from    time.Time        // when the counters were first seen, the last sample of the previous bucket if any
+	to      time.Time        // when the last sample was collected
+	spans   map[string]*span // the counters of each row
+	totals  span             // the counters of the totals
+}
+
+// newBucket returns an empty bucket starting at start whose counters
+// start from the end of the previous bucket of the view, if any
+func newBucket(sample collector.ViewResult, start time.Time, previous *bucket) *bucket {
+	b := &bucket{
+		result: collector.ViewResult{
+			View: sample.View,
+			Data: pstable.Data{
+				Collected: start,
+				Columns:   sample.Columns,
+				Totals:    pstable.Row{Name: sample.Totals.Name, Values: make([]float64, len(sample.Columns))},
+			},
+		},
+		rows:  make(map[string]int),
+		spans: make(map[string]*span),
+	}
+	if previous != nil {
+		b.from = previous.to
+		for name, s := range previous.spans {
+			b.spans[name] = &span{first: s.last, last: s.last}
+		}
+		b.totals = span{first: previous.totals.last, last: previous.totals.last}
+	}
+
+	return b
+}
+
+// add adds the sample's values to the bucket
+func (b *bucket) add(sample collector.ViewResult) {
+	b.samples++
+	if b.from.IsZero() {
+		b.from = sample.Collected
+	}
+	b.to = sample.Collected
+	for _, row := range sample.Rows {
+		i, ok := b.rows[row.Name]
+		if !ok {
+			i = len(b.result.Rows)
+			b.rows[row.Name] = i
+			b.result.Rows = append(b.result.Rows, pstable.Row{Name: row.Name, Values: make([]float64, len(b.result.Columns))})
+		}
+		addValues(b.result.Rows[i].Values, row.Values)
+		s, ok := b.spans[row.Name]
+		if !ok {
+			s = &span{first: row.Values}
+			b.spans[row.Name] = s
+		}
+		s.last = row.Values
+	}
+	addValues(b.result.Totals.Values, sample.Totals.Values)
+	if b.totals.first == nil {
+		b.totals.first = sample.Totals.Values
+	}
+	b.totals.last = sample.Totals.Values
+}
+
+// average returns the bucket's result with the values averaged over the
+// samples and the counters converted to their rate per second
+func (b *bucket) average() collector.ViewResult {
+	seconds := b.to.Sub(b.from).Seconds()
+	for i := range b.result.Rows {
+		divideValues(b.result.Rows[i].Values, b.samples)
+		b.rates(b.result.Rows[i].Values, *b.spans[b.result.Rows[i].Name], seconds)
+	}
+	divideValues(b.result.Totals.Values, b.samples)
+	b.rates(b.result.Totals.Values, b.totals, seconds)
+
+	return b.result
+}
+
+// rates replaces the values of the counter columns by their rate per second
+// over the span, counting from 0 if a counter went down as it was reset
+func (b *bucket) rates(values []float64, s span, seconds float64) {
+	for i, column := range b.result.Columns {
+		if !counter(column) || i >= len(values) {
+			continue
+		}
+		values[i] = 0
+		if seconds <= 0 || i >= len(s.first) || i >= len(s.last) {
+			continue
+		}
+		delta := s.last[i] - s.first[i]
+		if delta < 0 {
+			delta = s.last[i]
+		}
+		values[i] = delta / seconds
+	}
+}
+
+// Bucket aggregates the samples into buckets of the given size, e.g. a minute,
+// returning one sample per view and bucket in the order the buckets were first
+// seen. The values of each row are averaged over the samples in the bucket, rows
+// missing from a sample counting as 0, and the sample is timestamped with the
+// start of the bucket. Cumulative counters, see counter, are instead given as
+// their rate per second from the last sample of the view's previous bucket, or
+// the first sample of the view, to the last sample of the bucket. If size is not
+// positive the samples are returned unchanged.
+func Bucket(samples []collector.ViewResult, size time.Duration) []collector.ViewResult {
+	if size <= 0 {
+		return samples
+	}
+
+	var keys []bucketKey
+	buckets := make(map[bucketKey]*bucket)
+	latest := make(map[string]*bucket) // the latest bucket of each view
+	for _, sample := range samples {
+		key := bucketKey{view: sample.View, start: sample.Collected.Truncate(size)}
+		b, ok := buckets[key]
+		if !ok {
+			b = newBucket(sample, key.start, latest[sample.View])
+			buckets[key] = b
+			latest[sample.View] = b
+			keys = append(keys, key)
+		}
+		b.add(sample)
+	}
+
+	results := make([]collector.ViewResult, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, buckets[key].average())
+	}
+	return results
+}
+
+// Records returns the samples grouped by the time they were collected into
+// records of the given host and version, in the order the times were first
+// seen. It undoes Samples so bucketed samples may be replayed.
+func Records(samples []collector.ViewResult, host, version string) []Record {
+	var records []Record
+	position := make(map[time.Time]int)
+	for _, sample := range samples {
+		i, ok := position[sample.Collected]
+		if !ok {
+			i = len(records)
+			position[sample.Collected] = i
+			records = append(records, Record{Collected: sample.Collected, Host: host, Version: version})
+		}
+		records[i].Views = append(records[i].Views, sample)
+	}
+	return records
+}
+
+// addValues adds values to sum, ignoring any values without a column
+func addValues(sum, values []float64) {
+	for i := range values {
+		if i < len(sum) {
+			sum[i] += values[i]
+		}
+	}
+}
+
+// divideValues divides each of the values by n
+func divideValues(values []float64, n int) {
+	for i := range values {
+		values[i] /= float64(n)
+	}
+}
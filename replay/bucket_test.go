@@ -0,0 +1,140 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/collector"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+func sample(view string, collected time.Time, rows ...pstable.Row) collector.ViewResult {
+	return collector.ViewResult{
+		View: view,
+		Data: pstable.Data{
+			Collected: collected,
+			Columns:   []string{"count"},
+			Rows:      rows,
+			Totals:    pstable.Row{Name: "Totals", Values: []float64{float64(len(rows))}},
+		},
+	}
+}
+
+func row(name string, value float64) pstable.Row {
+	return pstable.Row{Name: name, Values: []float64{value}}
+}
+
+func TestBucket(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	samples := []collector.ViewResult{
+		sample("lock_errors", start.Add(10*time.Second), row("a", 10), row("b", 4)),
+		sample("table_cache", start.Add(10*time.Second), row("c", 1)),
+		sample("lock_errors", start.Add(40*time.Second), row("a", 20)),
+		sample("lock_errors", start.Add(70*time.Second), row("b", 6)),
+	}
+
+	expected := []collector.ViewResult{
+		sample("lock_errors", start, row("a", 15), row("b", 2)),
+		sample("table_cache", start, row("c", 1)),
+		sample("lock_errors", start.Add(time.Minute), row("b", 6)),
+	}
+	expected[0].Totals.Values = []float64{1.5}
+	for _, results := range [][]collector.ViewResult{samples, expected} {
+		for i := range results {
+			results[i].Columns = []string{"value"} // averaged rather than a counter
+		}
+	}
+
+	got := Bucket(samples, time.Minute)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Bucket() failed: expected: %+v, got %+v", expected, got)
+	}
+
+	if got := Bucket(samples, 0); !reflect.DeepEqual(got, samples) {
+		t.Errorf("Bucket(0) failed: expected the samples unchanged, got %+v", got)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	tests := []struct {
+		column   string
+		expected bool
+	}{
+		{"count_star", true},
+		{"count", true},
+		{"sum_timer_wait", true},
+		{"total_memory_ops", true},
+		{"wait_count", true},
+		{"statement_latency", true},
+		{"value", false},
+		{"rate", false},
+		{"current_bytes_used", false},
+		{"lag", false},
+	}
+	for _, test := range tests {
+		if got := counter(test.column); got != test.expected {
+			t.Errorf("counter(%q) failed: expected: %v, got %v", test.column, test.expected, got)
+		}
+	}
+}
+
+func TestBucketCounters(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	counters := func(collected time.Time, count, latency, current float64) collector.ViewResult {
+		values := []float64{count, latency, current}
+		return collector.ViewResult{
+			View: "table_io_latency",
+			Data: pstable.Data{
+				Collected: collected,
+				Columns:   []string{"count_star", "sum_timer_wait", "current"},
+				Rows:      []pstable.Row{{Name: "db.t", Values: values}},
+				Totals:    pstable.Row{Name: "Totals", Values: values},
+			},
+		}
+	}
+	samples := []collector.ViewResult{
+		counters(start, 100, 1000, 4),
+		counters(start.Add(20*time.Second), 140, 1400, 6),
+		counters(start.Add(40*time.Second), 200, 2200, 8),
+		counters(start.Add(60*time.Second), 320, 2800, 2), // in the next bucket
+		counters(start.Add(70*time.Second), 10, 50, 4),    // reset since
+	}
+
+	got := Bucket(samples, time.Minute)
+	if len(got) != 2 {
+		t.Fatalf("Bucket() failed: expected 2 buckets, got %d: %+v", len(got), got)
+	}
+
+	// 100 and 1200 over the 40s from the first sample, the current value averaged
+	expected := []float64{2.5, 30, 6}
+	if values := got[0].Rows[0].Values; !reflect.DeepEqual(values, expected) {
+		t.Errorf("Bucket() failed: expected the first bucket's values %v, got %v", expected, values)
+	}
+	if values := got[0].Totals.Values; !reflect.DeepEqual(values, expected) {
+		t.Errorf("Bucket() failed: expected the first bucket's totals %v, got %v", expected, values)
+	}
+
+	// over the 30s from the end of the first bucket, counting from 0 after the reset
+	expected = []float64{10.0 / 30, 50.0 / 30, 3}
+	if values := got[1].Rows[0].Values; !reflect.DeepEqual(values, expected) {
+		t.Errorf("Bucket() failed: expected the second bucket's values %v, got %v", expected, values)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	records := []Record{
+		{Collected: start, Host: "db1", Version: "8.0.36", Views: []collector.ViewResult{
+			sample("lock_errors", start, row("a", 10)),
+			sample("table_cache", start, row("c", 1)),
+		}},
+		{Collected: start.Add(time.Minute), Host: "db1", Version: "8.0.36", Views: []collector.ViewResult{
+			sample("lock_errors", start.Add(time.Minute), row("a", 20)),
+		}},
+	}
+
+	if got := Records(Samples(records), "db1", "8.0.36"); !reflect.DeepEqual(got, records) {
+		t.Errorf("Records(Samples()) failed: expected: %+v, got %+v", records, got)
+	}
+}
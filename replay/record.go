@@ -0,0 +1,165 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/sjmudd/ps-top/collector"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// Record holds the data of all views collected in one interval
+type Record struct {
+	Collected time.Time // when the interval's data was collected
+	Host      string    // the server's hostname
+	Version   string    // the server's version
+	Views     []collector.ViewResult
+}
+
+// jsonRecord is how a Record is written, one per line
+type jsonRecord struct {
+	Time    time.Time  `json:"time"`
+	Host    string     `json:"host"`
+	Version string     `json:"version"`
+	Views   []jsonView `json:"views"`
+}
+
+// jsonView is how a view's data is written
+type jsonView struct {
+	View      string    `json:"view"`
+	Collected time.Time `json:"collected"`
+	Columns   []string  `json:"columns"`
+	Rows      []jsonRow `json:"rows"`
+	Totals    jsonRow   `json:"totals"`
+}
+
+// jsonRow is how a row of a view is written
+type jsonRow struct {
+	Name   string  `json:"name"`
+	Values []value `json:"values"`
+}
+
+// value is a row's value, written as null if it is not finite
+type value float64
+
+// MarshalJSON writes the value or null if it is not finite
+func (v value) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(float64(v))
+}
+
+// UnmarshalJSON reads the value, null being read as NaN
+func (v *value) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*v = value(math.NaN())
+		return nil
+	}
+	var f float64
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	*v = value(f)
+	return nil
+}
+
+func toJSONRow(row pstable.Row) jsonRow {
+	values := make([]value, len(row.Values))
+	for i := range row.Values {
+		values[i] = value(row.Values[i])
+	}
+	return jsonRow{Name: row.Name, Values: values}
+}
+
+func fromJSONRow(row jsonRow) pstable.Row {
+	values := make([]float64, len(row.Values))
+	for i := range row.Values {
+		values[i] = float64(row.Values[i])
+	}
+	return pstable.Row{Name: row.Name, Values: values}
+}
+
+// Write writes the record to w as a JSON document on a single line
+func Write(w io.Writer, record Record) error {
+	jr := jsonRecord{
+		Time:    record.Collected.UTC(),
+		Host:    record.Host,
+		Version: record.Version,
+		Views:   make([]jsonView, 0, len(record.Views)),
+	}
+	for _, view := range record.Views {
+		jv := jsonView{
+			View:      view.View,
+			Collected: view.Collected.UTC(),
+			Columns:   view.Columns,
+			Rows:      make([]jsonRow, 0, len(view.Rows)),
+			Totals:    toJSONRow(view.Totals),
+		}
+		for _, row := range view.Rows {
+			jv.Rows = append(jv.Rows, toJSONRow(row))
+		}
+		jr.Views = append(jr.Views, jv)
+	}
+
+	b, err := json.Marshal(jr)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// Read reads the records written by Write from r, skipping empty lines
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // a line holds every view
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var jr jsonRecord
+		if err := json.Unmarshal(scanner.Bytes(), &jr); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		record := Record{
+			Collected: jr.Time,
+			Host:      jr.Host,
+			Version:   jr.Version,
+			Views:     make([]collector.ViewResult, 0, len(jr.Views)),
+		}
+		for _, jv := range jr.Views {
+			data := pstable.Data{
+				Collected: jv.Collected,
+				Columns:   jv.Columns,
+				Rows:      make([]pstable.Row, 0, len(jv.Rows)),
+				Totals:    fromJSONRow(jv.Totals),
+			}
+			for _, row := range jv.Rows {
+				data.Rows = append(data.Rows, fromJSONRow(row))
+			}
+			record.Views = append(record.Views, collector.ViewResult{View: jv.View, Data: data})
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Samples returns the data of every view of the records, e.g. to be given to Bucket
+func Samples(records []Record) []collector.ViewResult {
+	var samples []collector.ViewResult
+	for _, record := range records {
+		samples = append(samples, record.Views...)
+	}
+	return samples
+}
@@ -0,0 +1,67 @@
+package replay
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/collector"
+)
+
+func TestWriteRead(t *testing.T) {
+	collected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []Record{
+		{
+			Collected: collected,
+			Host:      "db1",
+			Version:   "8.0.36",
+			Views: []collector.ViewResult{
+				sample("table_io_latency", collected, row("db1.t1", 3), row("db1.t2", math.NaN())),
+				sample("commands", collected),
+			},
+		},
+		{
+			Collected: collected.Add(time.Second),
+			Host:      "db1",
+			Version:   "8.0.36",
+			Views:     []collector.ViewResult{sample("commands", collected.Add(time.Second), row("select", 10))},
+		},
+	}
+
+	var b bytes.Buffer
+	for _, record := range records {
+		if err := Write(&b, record); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if lines := strings.Count(b.String(), "\n"); lines != len(records) {
+		t.Errorf("Write() failed: expected %d lines, got %d", len(records), lines)
+	}
+	if !strings.Contains(b.String(), `{"name":"db1.t2","values":[null]}`) {
+		t.Errorf("Write() failed: NaN not written as null: %s", b.String())
+	}
+
+	got, err := Read(&b)
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("Read() failed: expected %d records, got %d", len(records), len(got))
+	}
+	if got[0].Host != "db1" || got[0].Version != "8.0.36" || !got[0].Collected.Equal(collected) {
+		t.Errorf("Read() failed: unexpected record %+v", got[0])
+	}
+	first := got[0].Views[0]
+	if first.View != "table_io_latency" || len(first.Rows) != 2 || first.Rows[0].Values[0] != 3 || !math.IsNaN(first.Rows[1].Values[0]) {
+		t.Errorf("Read() failed: unexpected view %+v", first)
+	}
+	if samples := Samples(got); len(samples) != 3 || samples[2].Rows[0].Name != "select" {
+		t.Errorf("Samples() failed: unexpected samples %+v", samples)
+	}
+
+	if _, err := Read(strings.NewReader("{\n")); err == nil {
+		t.Errorf("Read() of invalid data did not fail")
+	}
+}
@@ -0,0 +1,231 @@
+package replay
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/collector"
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// Source provides the recorded data one record at a time instead of collecting it from a server
+type Source struct {
+	records  []Record
+	position int
+}
+
+// NewSource returns a Source positioned at the first of the records
+func NewSource(records []Record) *Source {
+	return &Source{records: records}
+}
+
+// Next moves to the next record, returning false if already at the last one
+func (s *Source) Next() bool {
+	if s.position+1 >= len(s.records) {
+		return false
+	}
+	s.position++
+	return true
+}
+
+// Rewind moves back to the first record
+func (s *Source) Rewind() {
+	s.position = 0
+}
+
+// Current returns the current record or an empty one if there are none
+func (s *Source) Current() Record {
+	if len(s.records) == 0 {
+		return Record{}
+	}
+	return s.records[s.position]
+}
+
+// Views returns the names of the views found in the records in the order first seen
+func (s *Source) Views() []string {
+	var views []string
+	seen := make(map[string]bool)
+	for _, record := range s.records {
+		for _, view := range record.Views {
+			if !seen[view.View] {
+				seen[view.View] = true
+				views = append(views, view.View)
+			}
+		}
+	}
+	return views
+}
+
+// columns returns the columns recorded for the view
+func (s *Source) columns(view string) []string {
+	for _, record := range s.records {
+		if result, ok := find(record, view); ok {
+			return result.Columns
+		}
+	}
+	return nil
+}
+
+// find returns the data of the view in the record
+func find(record Record, view string) (collector.ViewResult, bool) {
+	for _, result := range record.Views {
+		if result.View == view {
+			return result, true
+		}
+	}
+	return collector.ViewResult{}, false
+}
+
+// Tabler shows the recorded data of a view in the current record of a Source.
+// The rows are shown generically, a column per value followed by the name, as
+// only the Data of each view is recorded and not the rows of its model. So the
+// view's own wrapper can not show them: its headings, units and derived columns
+// such as percentages are not shown when replaying.
+type Tabler struct {
+	pstable.SortKey
+	source *Source
+	view   string
+	cfg    *config.Config
+}
+
+// NewTabler returns a Tabler of the named view using cfg's name filter
+func (s *Source) NewTabler(view string, cfg *config.Config) *Tabler {
+	return &Tabler{
+		SortKey: pstable.NewSortKey(s.columns(view)),
+		source:  s,
+		view:    view,
+		cfg:     cfg,
+	}
+}
+
+// Collect does nothing as the Source provides the data
+func (t *Tabler) Collect() error { return nil }
+
+// ResetStatistics does nothing as the recorded values are shown unchanged
+func (t *Tabler) ResetStatistics() {}
+
+// Data returns the view's rows in the current record which match the name filter,
+// sorted on the chosen column. The totals are shown as recorded.
+func (t Tabler) Data() pstable.Data {
+	record := t.source.Current()
+	result, ok := find(record, t.view)
+	if !ok {
+		return pstable.Data{Collected: record.Collected, Columns: t.source.columns(t.view)}
+	}
+
+	data := result.Data
+	rows := data.Rows
+	if !pstable.Filter(&rows, t.cfg.NameFilter(), func(i int) string { return rows[i].Name }) {
+		rows = append([]pstable.Row(nil), rows...) // sort a copy, not the recorded rows
+	}
+	t.Sort(rows, func(i int) pstable.Row { return rows[i] })
+	data.Rows = rows
+
+	return data
+}
+
+// width returns the width of a column
+func width(column string) int {
+	if len(column) < 10 {
+		return 10
+	}
+	return len(column)
+}
+
+// formatValue formats a value right aligned to the width, showing counts like FormatCounter
+func formatValue(v float64, width int) string {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return fmt.Sprintf("%*s", width, "")
+	case v >= 0 && v == math.Trunc(v) && v < math.MaxUint64:
+		return lib.FormatCounter(uint64(v), width)
+	default:
+		return fmt.Sprintf("%*.2f", width, v)
+	}
+}
+
+// content returns a printable row
+func (t Tabler) content(columns []string, row pstable.Row) string {
+	var b strings.Builder
+	for i, column := range columns {
+		if i < len(row.Values) {
+			b.WriteString(formatValue(row.Values[i], width(column)))
+		} else {
+			b.WriteString(fmt.Sprintf("%*s", width(column), ""))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString("|" + row.Name)
+
+	return b.String()
+}
+
+// Headings returns the column names followed by the name of the rows
+func (t Tabler) Headings() string {
+	var b strings.Builder
+	for _, column := range t.source.columns(t.view) {
+		b.WriteString(fmt.Sprintf("%*s ", width(column), column))
+	}
+	b.WriteString("|Name")
+
+	return b.String()
+}
+
+// RowContent returns the rows to display
+func (t Tabler) RowContent() []string {
+	data := t.Data()
+	rows := make([]string, 0, len(data.Rows))
+	for _, row := range data.Rows {
+		rows = append(rows, t.content(data.Columns, row))
+	}
+	return rows
+}
+
+// TotalRowContent returns the recorded totals
+func (t Tabler) TotalRowContent() string {
+	data := t.Data()
+	return t.content(data.Columns, data.Totals)
+}
+
+// EmptyRowContent returns an empty row
+func (t Tabler) EmptyRowContent() string {
+	return t.content(t.source.columns(t.view), pstable.Row{})
+}
+
+// Len returns the number of rows
+func (t Tabler) Len() int {
+	return len(t.Data().Rows)
+}
+
+// Description describes the view and the position in the recording
+func (t Tabler) Description() string {
+	if _, ok := find(t.source.Current(), t.view); !ok {
+		return fmt.Sprintf("%s: not recorded in sample %d of %d", t.view, t.source.position+1, len(t.source.records))
+	}
+	return fmt.Sprintf("%s replayed: sample %d of %d collected at %s", t.view, t.source.position+1, len(t.source.records),
+		t.source.Current().Collected.Local().Format("2006-01-02 15:04:05"))
+}
+
+// HaveRelativeStats is false as the values are shown as recorded
+func (t Tabler) HaveRelativeStats() bool {
+	return false
+}
+
+// WantRelativeStats is false as the values are shown as recorded
+func (t Tabler) WantRelativeStats() bool {
+	return false
+}
+
+// FirstCollectTime returns when the current record's data was collected
+func (t Tabler) FirstCollectTime() time.Time {
+	return t.Data().Collected
+}
+
+// LastCollectTime returns when the current record's data was collected
+func (t Tabler) LastCollectTime() time.Time {
+	return t.Data().Collected
+}
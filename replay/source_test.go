@@ -0,0 +1,49 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjmudd/ps-top/collector"
+	"github.com/sjmudd/ps-top/config"
+)
+
+func TestSource(t *testing.T) {
+	collected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	source := NewSource([]Record{
+		{Collected: collected, Views: []collector.ViewResult{sample("commands", collected, row("select", 1), row("insert", 5))}},
+		{Collected: collected.Add(time.Second), Views: []collector.ViewResult{sample("memory_usage", collected)}},
+	})
+
+	if views := strings.Join(source.Views(), " "); views != "commands memory_usage" {
+		t.Errorf("Views() failed: got %q", views)
+	}
+
+	tabler := source.NewTabler("commands", config.NewConfig(nil, nil, nil, false))
+	if got := tabler.Headings(); got != "     count |Name" {
+		t.Errorf("Headings() failed: got %q", got)
+	}
+	tabler.SortNext()
+	expected := []string{"         5 |insert", "         1 |select"}
+	if got := tabler.RowContent(); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("RowContent() failed: expected %q, got %q", expected, got)
+	}
+	if data := tabler.Data(); data.Rows[0].Name != "insert" {
+		t.Errorf("Data() failed: unexpected rows %+v", data.Rows)
+	}
+
+	if !source.Next() {
+		t.Errorf("Next() failed at the first record")
+	}
+	if tabler.Len() != 0 || !strings.Contains(tabler.Description(), "not recorded in sample 2 of 2") {
+		t.Errorf("Description() failed: got %q", tabler.Description())
+	}
+	if source.Next() {
+		t.Errorf("Next() failed: moved past the last record")
+	}
+	source.Rewind()
+	if tabler.Len() != 2 {
+		t.Errorf("Rewind() failed: got %d rows", tabler.Len())
+	}
+}
@@ -10,12 +10,40 @@ import (
 	"github.com/gdamore/tcell/termbox"
 )
 
+// Highlight determines how the selected row is shown
+type Highlight int
+
+// Highlight* constants are the different ways of showing the selected row
+const (
+	HighlightReverse   Highlight = iota // reverse video (the default)
+	HighlightBold                       // bold text
+	HighlightUnderline                  // underlined text
+	HighlightColor                      // coloured background, reverse video if colours are disabled
+)
+
+var highlightNames = map[string]Highlight{
+	"reverse":   HighlightReverse,
+	"bold":      HighlightBold,
+	"underline": HighlightUnderline,
+	"color":     HighlightColor,
+}
+
+// ParseHighlight returns the Highlight with the given name
+func ParseHighlight(name string) (Highlight, error) {
+	if highlight, ok := highlightNames[name]; ok {
+		return highlight, nil
+	}
+	return HighlightReverse, fmt.Errorf("unknown highlight style %q, expected one of: reverse, bold, underline, color", name)
+}
+
 // Screen is a wrapper around termbox
 type Screen struct {
-	height int
-	width  int
-	bg     termbox.Attribute
-	fg     termbox.Attribute
+	height    int
+	width     int
+	bg        termbox.Attribute
+	fg        termbox.Attribute
+	noColor   bool
+	highlight Highlight
 }
 
 // NewScreen initialises a screen, clearing it, returning a *Screen
@@ -57,7 +85,51 @@ func (screen *Screen) InvertedPrintAt(x int, y int, text string) {
 	offset := 0
 	for c := range text {
 		if (x + offset) < screen.width {
-			termbox.SetCell(x+offset, y, rune(text[c]), screen.bg, screen.fg)
+			termbox.SetCell(x+offset, y, rune(text[c]), screen.fg|termbox.AttrReverse, screen.bg)
+			offset++
+		}
+	}
+	screen.Flush()
+}
+
+// SetNoColor uses the terminal's default colours rather than white on black
+func (screen *Screen) SetNoColor(noColor bool) {
+	screen.noColor = noColor
+	if noColor {
+		screen.fg = termbox.ColorDefault
+		screen.bg = termbox.ColorDefault
+	} else {
+		screen.fg = termbox.ColorWhite
+		screen.bg = termbox.ColorBlack
+	}
+}
+
+// SetHighlight sets how HighlightPrintAt shows text
+func (screen *Screen) SetHighlight(highlight Highlight) {
+	screen.highlight = highlight
+}
+
+// HighlightPrintAt displays text using the configured highlight
+// style (used for the selected row) at the location specified,
+// but does not try to display outside of the screen boundary.
+func (screen *Screen) HighlightPrintAt(x int, y int, text string) {
+	fg, bg := screen.fg|termbox.AttrReverse, screen.bg
+
+	switch screen.highlight {
+	case HighlightBold:
+		fg = screen.fg | termbox.AttrBold
+	case HighlightUnderline:
+		fg = screen.fg | termbox.AttrUnderline
+	case HighlightColor:
+		if !screen.noColor {
+			fg, bg = termbox.ColorBlack, termbox.ColorCyan
+		}
+	}
+
+	offset := 0
+	for c := range text {
+		if (x + offset) < screen.width {
+			termbox.SetCell(x+offset, y, rune(text[c]), fg, bg)
 			offset++
 		}
 	}
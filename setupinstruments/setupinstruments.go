@@ -37,13 +37,15 @@ func NewSetupInstruments(dbh *sql.DB) *SetupInstruments {
 	return &SetupInstruments{dbh: dbh}
 }
 
-// EnableMonitoring enables mutex and stage monitoring
+// EnableMonitoring enables mutex, stage, metadata lock and transaction monitoring
 func (si *SetupInstruments) EnableMonitoring() {
 	si.EnableMutexMonitoring()
 	si.EnableStageMonitoring()
+	si.EnableMetadataLockMonitoring()
+	si.EnableTransactionMonitoring()
 }
 
-// EnableStageMonitoring change settings to monitor stage/sql/%
+// EnableStageMonitoring change settings to monitor stage/sql/% and stage/innodb/alter%
 func (si *SetupInstruments) EnableStageMonitoring() {
 	log.Println("EnableStageMonitoring")
 	sqlMatch := "stage/sql/%"
@@ -52,6 +54,14 @@ func (si *SetupInstruments) EnableStageMonitoring() {
 	collecting := "Collecting setup_instruments stage/sql configuration settings"
 	updating := "Updating setup_instruments configuration for: stage/sql"
 
+	si.Configure(sqlSelect, collecting, updating)
+
+	// the InnoDB ALTER TABLE stages estimate their work so their progress can be shown
+	sqlMatch = "stage/innodb/alter%"
+	sqlSelect = "SELECT NAME, ENABLED, TIMED FROM setup_instruments WHERE NAME LIKE '" + sqlMatch + "' AND 'YES' NOT IN (ENABLED,TIMED)"
+	collecting = "Collecting setup_instruments stage/innodb/alter configuration settings"
+	updating = "Updating setup_instruments configuration for: stage/innodb/alter"
+
 	si.Configure(sqlSelect, collecting, updating)
 	log.Println("EnableStageMonitoring finishes")
 }
@@ -68,6 +78,29 @@ func (si *SetupInstruments) EnableMutexMonitoring() {
 	log.Println("EnableMutexMonitoring finishes")
 }
 
+// EnableMetadataLockMonitoring changes settings to monitor wait/lock/metadata/sql/mdl
+func (si *SetupInstruments) EnableMetadataLockMonitoring() {
+	log.Println("EnableMetadataLockMonitoring")
+	sqlMatch := "wait/lock/metadata/sql/mdl"
+	sqlSelect := "SELECT NAME, ENABLED, TIMED FROM setup_instruments WHERE NAME LIKE '" + sqlMatch + "' AND 'YES' NOT IN (ENABLED,TIMED)"
+	collecting := "Collecting setup_instruments wait/lock/metadata/sql/mdl configuration settings"
+	updating := "Updating setup_instruments configuration for: wait/lock/metadata/sql/mdl"
+
+	si.Configure(sqlSelect, collecting, updating)
+	log.Println("EnableMetadataLockMonitoring finishes")
+}
+
+// EnableTransactionMonitoring changes settings to monitor transaction
+func (si *SetupInstruments) EnableTransactionMonitoring() {
+	log.Println("EnableTransactionMonitoring")
+	sqlSelect := "SELECT NAME, ENABLED, TIMED FROM setup_instruments WHERE NAME = 'transaction' AND 'YES' NOT IN (ENABLED,TIMED)"
+	collecting := "Collecting setup_instruments transaction configuration settings"
+	updating := "Updating setup_instruments configuration for: transaction"
+
+	si.Configure(sqlSelect, collecting, updating)
+	log.Println("EnableTransactionMonitoring finishes")
+}
+
 // isExpectedError returns true if the error is in the expected list of errors
 // - we only match on the error number
 func isExpectedError(actualError string) bool {
@@ -0,0 +1,91 @@
+// Package smooth provides moving averages of rates over the last few collections.
+package smooth
+
+import (
+	"time"
+)
+
+// Rate keeps the most recent changes of a counter in a ring buffer
+// together with the intervals over which they happened.
+type Rate struct {
+	deltas    []float64
+	intervals []time.Duration
+	next      int // where the next value is stored
+	count     int // number of values stored
+}
+
+// NewRate returns a Rate averaging over the last size intervals
+func NewRate(size int) *Rate {
+	if size < 1 {
+		size = 1
+	}
+	return &Rate{
+		deltas:    make([]float64, size),
+		intervals: make([]time.Duration, size),
+	}
+}
+
+// Add records that the counter changed by delta during interval, replacing the oldest value if full
+func (r *Rate) Add(delta float64, interval time.Duration) {
+	r.deltas[r.next] = delta
+	r.intervals[r.next] = interval
+	r.next = (r.next + 1) % len(r.deltas)
+	if r.count < len(r.deltas) {
+		r.count++
+	}
+}
+
+// PerSecond returns the average change per second over the stored intervals
+func (r *Rate) PerSecond() float64 {
+	var delta float64
+	var interval time.Duration
+
+	for i := 0; i < r.count; i++ {
+		delta += r.deltas[i]
+		interval += r.intervals[i]
+	}
+	if interval <= 0 {
+		return 0
+	}
+
+	return delta / interval.Seconds()
+}
+
+// Rates keeps a Rate for each of a set of named counters, e.g. global status values
+type Rates struct {
+	size  int
+	rates map[string]*Rate
+}
+
+// NewRates returns Rates averaging each counter over the last size intervals
+func NewRates(size int) *Rates {
+	return &Rates{
+		size:  size,
+		rates: make(map[string]*Rate),
+	}
+}
+
+// Add records the change of each counter from previous to current during
+// interval. Counters not in previous or which went backwards are skipped.
+func (r *Rates) Add(current, previous map[string]int, interval time.Duration) {
+	for name, value := range current {
+		old, ok := previous[name]
+		if !ok || value < old {
+			continue
+		}
+		if _, ok := r.rates[name]; !ok {
+			r.rates[name] = NewRate(r.size)
+		}
+		r.rates[name].Add(float64(value-old), interval)
+	}
+}
+
+// PerSecond returns the average change per second of the named counter and
+// whether any change has been recorded
+func (r *Rates) PerSecond(name string) (float64, bool) {
+	rate, ok := r.rates[name]
+	if !ok {
+		return 0, false
+	}
+	return rate.PerSecond(), true
+}
@@ -0,0 +1,58 @@
+package smooth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRate(t *testing.T) {
+	type sample struct {
+		delta    float64
+		interval time.Duration
+	}
+	tests := []struct {
+		size     int
+		samples  []sample
+		expected float64
+	}{
+		{3, nil, 0},
+		{3, []sample{{10, time.Second}}, 10},
+		{3, []sample{{10, time.Second}, {0, time.Second}}, 5},
+		{3, []sample{{10, time.Second}, {0, time.Second}, {20, 2 * time.Second}}, 7.5},
+		{3, []sample{{100, time.Second}, {10, time.Second}, {0, time.Second}, {20, 2 * time.Second}}, 7.5},
+		{1, []sample{{10, time.Second}, {6, 2 * time.Second}}, 3},
+		{0, []sample{{10, time.Second}, {6, 2 * time.Second}}, 3},
+	}
+	for _, test := range tests {
+		r := NewRate(test.size)
+		for _, s := range test.samples {
+			r.Add(s.delta, s.interval)
+		}
+		if got := r.PerSecond(); got != test.expected {
+			t.Errorf("Rate(%d) with %v failed: expected: %v, got %v", test.size, test.samples, test.expected, got)
+		}
+	}
+}
+
+func TestRates(t *testing.T) {
+	r := NewRates(2)
+	r.Add(map[string]int{"a": 10, "b": 5}, nil, time.Second)
+	r.Add(map[string]int{"a": 20, "b": 3}, map[string]int{"a": 10, "b": 5}, time.Second)
+	r.Add(map[string]int{"a": 50, "b": 4, "c": 1}, map[string]int{"a": 20, "b": 3}, time.Second)
+
+	tests := []struct {
+		name     string
+		expected float64
+		found    bool
+	}{
+		{"a", 20, true}, // (10 + 30) / 2s
+		{"b", 1, true},  // the counter going backwards is skipped
+		{"c", 0, false}, // no previous value
+	}
+	for _, test := range tests {
+		got, found := r.PerSecond(test.name)
+		if got != test.expected || found != test.found {
+			t.Errorf("PerSecond(%q) failed: expected: %v, %v, got %v, %v", test.name, test.expected, test.found, got, found)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package statusline provides a compact single line summary of the server's activity.
+package statusline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/lib"
+)
+
+// Sample holds the values collected for the status line at a point in time
+type Sample struct {
+	Collected time.Time // when the sample was collected
+	Questions uint64    // the Questions global status counter
+	Connected uint64    // the number of connected threads
+	Running   uint64    // the number of running threads
+	Replica   bool      // are there replication applier workers?
+	Lag       uint64    // the largest applier worker lag in picoseconds
+}
+
+// Line returns the status line for the current sample of host. The rate of
+// queries is calculated from the previous sample if it has been collected.
+func Line(host string, previous, current Sample) string {
+	parts := []string{host + ":"}
+
+	if !previous.Collected.IsZero() && current.Questions >= previous.Questions {
+		qps := lib.PerSecond(current.Questions-previous.Questions, current.Collected.Sub(previous.Collected))
+		parts = append(parts, fmt.Sprintf("%.1f qps,", qps))
+	} else {
+		parts = append(parts, "- qps,")
+	}
+	parts = append(parts, fmt.Sprintf("%d connected, %d running", current.Connected, current.Running))
+	if current.Replica {
+		lag := "0 s"
+		if current.Lag > 0 {
+			lag = strings.TrimSpace(lib.FormatTime(current.Lag))
+		}
+		parts[len(parts)-1] += ","
+		parts = append(parts, "lag "+lag)
+	}
+
+	return strings.Join(parts, " ")
+}
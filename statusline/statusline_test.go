@@ -0,0 +1,42 @@
+package statusline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tests := []struct {
+		previous Sample
+		current  Sample
+		expected string
+	}{
+		{
+			Sample{},
+			Sample{Collected: start, Questions: 100, Connected: 12, Running: 3},
+			"db1: - qps, 12 connected, 3 running",
+		},
+		{
+			Sample{Collected: start, Questions: 100},
+			Sample{Collected: start.Add(2 * time.Second), Questions: 345, Connected: 12, Running: 3},
+			"db1: 122.5 qps, 12 connected, 3 running",
+		},
+		{
+			Sample{Collected: start, Questions: 100},
+			Sample{Collected: start.Add(time.Second), Questions: 100, Connected: 1, Running: 1, Replica: true, Lag: 1500000000000},
+			"db1: 0.0 qps, 1 connected, 1 running, lag 1.50 s",
+		},
+		{
+			Sample{},
+			Sample{Collected: start, Connected: 1, Running: 1, Replica: true},
+			"db1: - qps, 1 connected, 1 running, lag 0 s",
+		},
+	}
+
+	for _, test := range tests {
+		if got := Line("db1", test.previous, test.current); got != test.expected {
+			t.Errorf("Line(%+v, %+v) failed: expected: %q, got %q", test.previous, test.current, test.expected, got)
+		}
+	}
+}
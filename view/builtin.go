@@ -0,0 +1,228 @@
+package view
+
+import (
+	"database/sql"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/pstable"
+	"github.com/sjmudd/ps-top/table"
+	"github.com/sjmudd/ps-top/wrapper/activetransactions"
+	"github.com/sjmudd/ps-top/wrapper/applierworkers"
+	"github.com/sjmudd/ps-top/wrapper/commands"
+	"github.com/sjmudd/ps-top/wrapper/diagnostics"
+	"github.com/sjmudd/ps-top/wrapper/fileinfolatency"
+	"github.com/sjmudd/ps-top/wrapper/indexio"
+	"github.com/sjmudd/ps-top/wrapper/innodb"
+	"github.com/sjmudd/ps-top/wrapper/lockerrors"
+	"github.com/sjmudd/ps-top/wrapper/memoryusage"
+	"github.com/sjmudd/ps-top/wrapper/metadatalocks"
+	"github.com/sjmudd/ps-top/wrapper/mutexlatency"
+	"github.com/sjmudd/ps-top/wrapper/processlist"
+	"github.com/sjmudd/ps-top/wrapper/replication"
+	"github.com/sjmudd/ps-top/wrapper/responsetime"
+	"github.com/sjmudd/ps-top/wrapper/stageslatency"
+	"github.com/sjmudd/ps-top/wrapper/statements"
+	"github.com/sjmudd/ps-top/wrapper/tablecache"
+	"github.com/sjmudd/ps-top/wrapper/tableiolatency"
+	"github.com/sjmudd/ps-top/wrapper/tableioops"
+	"github.com/sjmudd/ps-top/wrapper/tablelocklatency"
+	"github.com/sjmudd/ps-top/wrapper/threadactivity"
+	"github.com/sjmudd/ps-top/wrapper/transactions"
+	"github.com/sjmudd/ps-top/wrapper/userlatency"
+)
+
+// register the views of ps-top in the order they are shown
+func init() {
+	Register(Definition{
+		Code:     ViewLatency,
+		Name:     "table_io_latency",
+		Table:    table.NewAccess("performance_schema", "table_io_waits_summary_by_table"),
+		Fallback: table.NewAccess("sys", "x$schema_table_statistics"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return tableiolatency.NewTableIoLatency(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:     ViewOps,
+		Name:     "table_io_ops",
+		Table:    table.NewAccess("performance_schema", "table_io_waits_summary_by_table"),
+		Fallback: table.NewAccess("sys", "x$schema_table_statistics"),
+		Shared:   true,
+		New: func(cfg *config.Config, db *sql.DB, tablers Tablers) pstable.Tabler {
+			// share the backend/metrics of table_io_latency if there is one
+			if latency, ok := tablers[ViewLatency].(*tableiolatency.Wrapper); ok {
+				return tableioops.NewTableIoOps(latency)
+			}
+			return tableioops.NewTableIoOps(tableiolatency.NewTableIoLatency(cfg, db))
+		},
+	})
+	Register(Definition{
+		Code:  ViewIndexIO,
+		Name:  "index_io",
+		Table: table.NewAccess("performance_schema", "table_io_waits_summary_by_index_usage"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return indexio.NewIndexIo(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:     ViewIO,
+		Name:     "file_io_latency",
+		Table:    table.NewAccess("performance_schema", "file_summary_by_instance"),
+		Fallback: table.NewAccess("sys", "x$io_global_by_file_by_latency"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return fileinfolatency.NewFileSummaryByInstance(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewLocks,
+		Name:  "table_lock_latency",
+		Table: table.NewAccess("performance_schema", "table_lock_waits_summary_by_table"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return tablelocklatency.NewTableLockLatency(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewUsers,
+		Name:  "user_latency",
+		Table: table.NewAccess("information_schema", "processlist"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return userlatency.NewUserLatency(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewProcesslist,
+		Name:  "processlist",
+		Table: table.NewAccess("performance_schema", "threads"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return processlist.NewProcesslist(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewMutex,
+		Name:  "mutex_latency",
+		Table: table.NewAccess("performance_schema", "events_waits_summary_global_by_event_name"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return mutexlatency.NewMutexLatency(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewStages,
+		Name:  "stages_latency",
+		Table: table.NewAccess("performance_schema", "events_stages_summary_global_by_event_name"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return stageslatency.NewStagesLatency(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewMemory,
+		Name:  "memory_usage",
+		Table: table.NewAccess("performance_schema", "memory_summary_global_by_event_name"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return memoryusage.NewMemoryUsage(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewThreadActivity,
+		Name:  "thread_activity",
+		Table: table.NewAccess("performance_schema", "threads"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return threadactivity.NewThreadActivity(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewResponseTime,
+		Name:  "response_time",
+		Table: table.NewAccess("performance_schema", "events_statements_histogram_global"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return responsetime.NewResponseTime(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewLockErrors,
+		Name:  "lock_errors",
+		Table: table.NewAccess("performance_schema", "events_errors_summary_global_by_error"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return lockerrors.NewLockErrors(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewTransactions,
+		Name:  "transactions",
+		Table: table.NewAccess("information_schema", "INNODB_TRX"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return transactions.NewTransactions(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewActiveTransactions,
+		Name:  "active_transactions",
+		Table: table.NewAccess("performance_schema", "events_transactions_current"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return activetransactions.NewActiveTransactions(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewMetadataLocks,
+		Name:  "metadata_locks",
+		Table: table.NewAccess("performance_schema", "metadata_locks"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return metadatalocks.NewMetadataLocks(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewApplierWorkers,
+		Name:  "applier_workers",
+		Table: table.NewAccess("performance_schema", "replication_applier_status_by_worker"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return applierworkers.NewApplierWorkers(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewTableCache,
+		Name:  "table_cache",
+		Table: table.NewAccess("performance_schema", "global_status"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return tablecache.NewTableCache(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewInnoDB,
+		Name:  "innodb",
+		Table: table.NewAccess("performance_schema", "global_status"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return innodb.NewInnoDB(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewDiagnostics,
+		Name:  "diagnostics",
+		Table: table.NewAccess("", ""),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return diagnostics.NewDiagnostics(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewCommands,
+		Name:  "commands",
+		Table: table.NewAccess("performance_schema", "global_status"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return commands.NewCommands(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewStatements,
+		Name:  "statements",
+		Table: table.NewAccess("performance_schema", "events_statements_summary_by_digest"),
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return statements.NewStatements(cfg, db)
+		},
+	})
+	Register(Definition{
+		Code:  ViewReplication,
+		Name:  "replication",
+		Table: table.NewAccess("", ""), // SHOW REPLICA STATUS is not a table
+		New: func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler {
+			return replication.NewReplication(cfg, db)
+		},
+	})
+}
@@ -0,0 +1,81 @@
+package view
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/pstable"
+	"github.com/sjmudd/ps-top/table"
+)
+
+// Tablers holds the objects collecting the data of each view from one server
+type Tablers map[Code]pstable.Tabler
+
+// Definition describes a view which may be shown
+type Definition struct {
+	Code  Code         // the view's code, given by Register if zero
+	Name  string       // the name used to choose the view, e.g. with --view
+	Table table.Access // the table which must be SELECTable to show the view
+	// Fallback is the sys schema view used instead of Table if the user is
+	// denied access to Table. It is not checked if it has no name.
+	Fallback table.Access
+	// Shared is set if the view's data is collected by the view registered
+	// before it so it is not collected again when collecting every view.
+	Shared bool
+	// New returns the object collecting the view's data from db. tablers
+	// holds the views of the same server created before it and may be nil.
+	New func(cfg *config.Config, db *sql.DB, tablers Tablers) pstable.Tabler
+}
+
+var (
+	definitions []Definition // the registered views in the order they are shown
+	lastCode    = ViewNone   // the highest registered code
+)
+
+// Register adds a view after those already registered and returns its code.
+// Views must be registered before the views are set up.
+func Register(def Definition) Code {
+	if names != nil {
+		log.Panicf("view.Register(%q) called after the views were set up", def.Name)
+	}
+	if def.Code == ViewNone {
+		def.Code = lastCode + 1
+	}
+	for _, registered := range definitions {
+		if registered.Code == def.Code || registered.Name == def.Name {
+			log.Panicf("view.Register(%q) the view is already registered", def.Name)
+		}
+	}
+	if def.Code > lastCode {
+		lastCode = def.Code
+	}
+	definitions = append(definitions, def)
+
+	return def.Code
+}
+
+// Definitions returns the registered views in the order they are shown
+func Definitions() []Definition {
+	return append([]Definition(nil), definitions...)
+}
+
+// Lookup returns the registered view with the given name
+func Lookup(name string) (Definition, bool) {
+	for _, def := range definitions {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// NewTablers returns the objects collecting the data of every registered view from db
+func NewTablers(cfg *config.Config, db *sql.DB) Tablers {
+	tablers := make(Tablers)
+	for _, def := range definitions {
+		tablers[def.Code] = def.New(cfg, db, tablers)
+	}
+
+	return tablers
+}
@@ -0,0 +1,88 @@
+package view
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/pstable"
+	"github.com/sjmudd/ps-top/table"
+)
+
+func TestBuiltinViews(t *testing.T) {
+	registered := make(map[Code]bool)
+	for _, def := range Definitions() {
+		if def.Name == "" || def.New == nil {
+			t.Errorf("view %d is registered without a name or New", def.Code)
+		}
+		registered[def.Code] = true
+	}
+	for code := ViewLatency; code <= ViewProcesslist; code++ {
+		if !registered[code] {
+			t.Errorf("view %d is not registered", code)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved, savedLast := definitions, lastCode
+	defer func() { definitions, lastCode = saved, savedLast }()
+
+	newTabler := func(cfg *config.Config, db *sql.DB, _ Tablers) pstable.Tabler { return nil }
+
+	code := Register(Definition{Name: "extra_view", Table: table.NewAccess("performance_schema", "threads"), New: newTabler})
+	if code != savedLast+1 {
+		t.Errorf("Register() failed: expected code %d, got %d", savedLast+1, code)
+	}
+	if def, ok := Lookup("extra_view"); !ok || def.Code != code {
+		t.Errorf("Lookup() failed: expected code %d, got %+v, %v", code, def, ok)
+	}
+	if last := Definitions()[len(Definitions())-1]; last.Code != code {
+		t.Errorf("Definitions() failed: expected the new view last, got %q", last.Name)
+	}
+
+	for _, def := range []Definition{
+		{Name: "extra_view", New: newTabler},
+		{Code: ViewLatency, Name: "another_view", New: newTabler},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q, %d) failed: expected a panic registering it twice", def.Name, def.Code)
+				}
+			}()
+			Register(def)
+		}()
+	}
+}
+
+func TestNamesOrder(t *testing.T) {
+	savedNames, savedTables, savedFallbacks := names, tables, fallbacks
+	savedNext, savedPrev := nextView, prevView
+	defer func() {
+		names, tables, fallbacks = savedNames, savedTables, savedFallbacks
+		nextView, prevView = savedNext, savedPrev
+	}()
+
+	var expectedNames []string
+	var expectedCodes []Code
+	for _, def := range Definitions() {
+		expectedNames = append(expectedNames, def.Name)
+		expectedCodes = append(expectedCodes, def.Code)
+	}
+	setupNames()
+	if err := recordedViews(expectedNames); err != nil {
+		t.Fatalf("recordedViews() failed: %v", err)
+	}
+
+	if got := Names(); !reflect.DeepEqual(got, expectedNames) {
+		t.Errorf("Names() failed: expected the registration order %q, got %q", expectedNames, got)
+	}
+	if got := Codes(); !reflect.DeepEqual(got, expectedCodes) {
+		t.Errorf("Codes() failed: expected the registration order %v, got %v", expectedCodes, got)
+	}
+	if expectedCodes[2] != ViewIndexIO {
+		t.Errorf("Definitions() failed: expected index_io to be registered third, got %v", expectedCodes[2])
+	}
+}
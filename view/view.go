@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 
+	"github.com/sjmudd/ps-top/global"
 	"github.com/sjmudd/ps-top/mylog"
 	"github.com/sjmudd/ps-top/table"
 )
@@ -12,17 +13,33 @@ import (
 // Code represents the type of information to view (as an int)
 type Code int
 
-// View* constants represent different views we can see
+// View* constants represent the views of ps-top, registered in builtin.go.
+// Other views are given the codes after these when registered.
 const (
-	ViewNone    Code = iota // view nothing (should never be set)
-	ViewLatency             // view the table latency information
-	ViewOps                 // view the table information by number of operations
-	ViewIO                  // view the file I/O information
-	ViewLocks               // view lock information
-	ViewUsers               // view user information
-	ViewMutex               // view mutex information
-	ViewStages              // view SQL stages information
-	ViewMemory              // view memory usage (5.7 only)
+	ViewNone               Code = iota // view nothing (should never be set)
+	ViewLatency                        // view the table latency information
+	ViewOps                            // view the table information by number of operations
+	ViewIO                             // view the file I/O information
+	ViewLocks                          // view lock information
+	ViewUsers                          // view user information
+	ViewMutex                          // view mutex information
+	ViewStages                         // view SQL stages information
+	ViewMemory                         // view memory usage (5.7 only)
+	ViewThreadActivity                 // view foreground / background thread activity
+	ViewResponseTime                   // view the statement response time distribution
+	ViewLockErrors                     // view deadlocks and lock wait timeouts
+	ViewTransactions                   // view the current transactions and their age
+	ViewMetadataLocks                  // view the granted and pending metadata locks
+	ViewApplierWorkers                 // view the replication applier workers
+	ViewTableCache                     // view the table cache usage and efficiency
+	ViewDiagnostics                    // view ps-top's own resource usage
+	ViewCommands                       // view the Com_* counters by command
+	ViewStatements                     // view the statement digests
+	ViewReplication                    // view the replication channels and their lag
+	ViewActiveTransactions             // view the active transactions and their isolation level
+	ViewInnoDB                         // view the InnoDB buffer pool and engine status
+	ViewIndexIO                        // view the table i/o by index
+	ViewProcesslist                    // view what each thread is executing
 )
 
 // View holds the integer type of view (maybe need to fix this setup)
@@ -31,9 +48,10 @@ type View struct {
 }
 
 var (
-	setup  bool                  // not protected by a mutex!
-	names  map[Code]string       // map View* to a string name
-	tables map[Code]table.Access // map a view to a table name and whether it's selectable or not
+	setup     bool                  // not protected by a mutex!
+	names     map[Code]string       // map View* to a string name
+	tables    map[Code]table.Access // map a view to a table name and whether it's selectable or not
+	fallbacks map[Code]table.Access // map a view to the sys view used if its table may not be read
 
 	nextView map[Code]Code // map from one view to the next taking into account invalid views
 	prevView map[Code]Code // map from one view to the next taking into account invalid views
@@ -44,29 +62,26 @@ func SetupAndValidate(name string, db *sql.DB) View {
 	log.Printf("view.SetupAndValidate(%q,%v)", name, db)
 
 	if !setup {
-		names = map[Code]string{
-			ViewLatency: "table_io_latency",
-			ViewOps:     "table_io_ops",
-			ViewIO:      "file_io_latency",
-			ViewLocks:   "table_lock_latency",
-			ViewUsers:   "user_latency",
-			ViewMutex:   "mutex_latency",
-			ViewStages:  "stages_latency",
-			ViewMemory:  "memory_usage",
+		setupNames()
+		if err := validateViews(db); err != nil {
+			mylog.Fatal(err)
 		}
+	}
 
-		tables = map[Code]table.Access{
-			ViewLatency: table.NewAccess("performance_schema", "table_io_waits_summary_by_table"),
-			ViewOps:     table.NewAccess("performance_schema", "table_io_waits_summary_by_table"),
-			ViewIO:      table.NewAccess("performance_schema", "file_summary_by_instance"),
-			ViewLocks:   table.NewAccess("performance_schema", "table_lock_waits_summary_by_table"),
-			ViewUsers:   table.NewAccess("information_schema", "processlist"),
-			ViewMutex:   table.NewAccess("performance_schema", "events_waits_summary_global_by_event_name"),
-			ViewStages:  table.NewAccess("performance_schema", "events_stages_summary_global_by_event_name"),
-			ViewMemory:  table.NewAccess("performance_schema", "memory_summary_global_by_event_name"),
-		}
+	var v View
 
-		if err := validateViews(db); err != nil {
+	v.SetByName(name) // if empty will use the default
+	return v
+}
+
+// SetupRecorded setups the view configuration for replaying recorded data.
+// Only the views which were recorded may be chosen.
+func SetupRecorded(name string, recorded []string) View {
+	log.Printf("view.SetupRecorded(%q,%v)", name, recorded)
+
+	if !setup {
+		setupNames()
+		if err := recordedViews(recorded); err != nil {
 			mylog.Fatal(err)
 		}
 	}
@@ -77,20 +92,71 @@ func SetupAndValidate(name string, db *sql.DB) View {
 	return v
 }
 
+// setupNames sets up the names of the registered views and the tables they use
+func setupNames() {
+	names = make(map[Code]string)
+	tables = make(map[Code]table.Access)
+	fallbacks = make(map[Code]table.Access)
+	for _, def := range definitions {
+		names[def.Code] = def.Name
+		tables[def.Code] = def.Table
+		fallbacks[def.Code] = def.Fallback
+	}
+}
+
+// recordedViews marks the views which were not recorded as unavailable. If none were recorded we give an error
+func recordedViews(recorded []string) error {
+	found := make(map[string]bool)
+	for _, name := range recorded {
+		found[name] = true
+	}
+
+	var count int
+	for _, def := range definitions {
+		v := def.Code
+		ta := tables[v]
+		if found[names[v]] {
+			ta.SetSelectError(nil)
+			count++
+		} else {
+			ta.SetSelectError(errors.New("not recorded"))
+		}
+		tables[v] = ta
+	}
+	if count == 0 {
+		return errors.New("none of the recorded views are known. Giving up")
+	}
+	log.Println(count, "of", len(names), "view(s) were recorded, continuing")
+
+	setPrevAndNextViews()
+
+	return nil
+}
+
 // validateViews check which views are readable. If none are we give a fatal error
 func validateViews(dbh *sql.DB) error {
 	var count int
 	var status string
 	log.Println("Validating access to views...")
 
-	// determine which of the defined views is valid because the underlying table access works
-	for v := range names {
+	// determine which of the defined views is valid because the underlying table access works,
+	// in the order they are registered
+	for _, def := range definitions {
+		v := def.Code
 		ta := tables[v]
 		e := ta.CheckSelectError(dbh)
+		if fallback := fallbacks[v]; e != nil && global.IsAccessDenied(e) && fallback.Name() != "" {
+			if fallback.CheckSelectError(dbh) == nil {
+				log.Println(v.String() + ": " + ta.Name() + " IS NOT SELECTable, using " + fallback.Name())
+				ta, e = fallback, nil
+			}
+		}
 		suffix := ""
 		if e == nil {
 			status = "is"
-			count++
+			if ta.Name() != "" {
+				count++ // views without a table don't show anything from MySQL
+			}
 		} else {
 			status = "IS NOT"
 			suffix = " " + e.Error()
@@ -132,9 +198,13 @@ func setPrevAndNextViews() {
 		prevView[v] = ViewNone
 	}
 
-	// Cleaner way to do this? Probably. Fix later.
-	prevCodeOrder := []Code{ViewMemory, ViewStages, ViewMutex, ViewUsers, ViewLocks, ViewIO, ViewOps, ViewLatency}
-	nextCodeOrder := []Code{ViewLatency, ViewOps, ViewIO, ViewLocks, ViewUsers, ViewMutex, ViewStages, ViewMemory}
+	// the views are shown in the order they were registered
+	nextCodeOrder := make([]Code, 0, len(definitions))
+	prevCodeOrder := make([]Code, len(definitions))
+	for i, def := range definitions {
+		nextCodeOrder = append(nextCodeOrder, def.Code)
+		prevCodeOrder[len(definitions)-1-i] = def.Code
+	}
 	prevView = setValidByValues(prevCodeOrder)
 	nextView = setValidByValues(nextCodeOrder)
 
@@ -237,14 +307,36 @@ func (v *View) SetByName(name string) {
 
 	// suggest what should be used
 	allViews := ""
-	for i := range names {
-		allViews = allViews + " " + names[i]
+	for _, def := range definitions {
+		allViews = allViews + " " + def.Name
 	}
 
 	// no need for now to strip off leading space from allViews.
 	mylog.Fatal("Asked for a view name, '", name, "' which doesn't exist. Try one of:", allViews)
 }
 
+// Names returns the names of the selectable views in the order they are registered
+func Names() []string {
+	var selectable []string
+
+	for _, code := range Codes() {
+		selectable = append(selectable, names[code])
+	}
+	return selectable
+}
+
+// Codes returns the codes of the selectable views in the order they are registered
+func Codes() []Code {
+	var selectable []Code
+
+	for _, def := range definitions {
+		if _, ok := names[def.Code]; ok && tables[def.Code].SelectError() == nil {
+			selectable = append(selectable, def.Code)
+		}
+	}
+	return selectable
+}
+
 // Get returns the Code version of the current view
 func (v View) Get() Code {
 	return v.code
@@ -0,0 +1,44 @@
+package wait
+
+import (
+	"time"
+)
+
+// thresholds of the fraction of the interval spent collecting
+const (
+	expensiveCollection = 0.10 // lengthen the interval if collecting takes longer than this
+	cheapCollection     = 0.02 // shorten the interval if collecting takes less than this and the server is busy
+)
+
+// Adaptive holds the bounds of an interval adjusted to the cost of collecting data
+type Adaptive struct {
+	Min time.Duration // the shortest interval to use
+	Max time.Duration // the longest interval to use
+}
+
+// Next returns the interval to use after a collection which took the given time
+// with the current interval. The interval is doubled if collecting takes a
+// significant fraction of it, to avoid adding load to the server, and halved if
+// collecting is cheap and the server is busy so changes are seen sooner. The
+// result is kept within the bounds.
+func (a Adaptive) Next(current, took time.Duration, busy bool) time.Duration {
+	next := current
+	if current > 0 {
+		load := float64(took) / float64(current)
+		switch {
+		case load > expensiveCollection:
+			next = current * 2
+		case load < cheapCollection && busy:
+			next = current / 2
+		}
+	}
+
+	if next > a.Max {
+		next = a.Max
+	}
+	if next < a.Min {
+		next = a.Min
+	}
+
+	return next
+}
@@ -0,0 +1,43 @@
+package wait
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveNext(t *testing.T) {
+	adaptive := Adaptive{Min: time.Second, Max: 30 * time.Second}
+	tests := []struct {
+		current  time.Duration
+		took     time.Duration
+		busy     bool
+		expected time.Duration
+	}{
+		{10 * time.Second, 2 * time.Second, false, 20 * time.Second},            // expensive
+		{20 * time.Second, 5 * time.Second, true, 30 * time.Second},             // expensive, capped at the maximum
+		{10 * time.Second, 100 * time.Millisecond, true, 5 * time.Second},       // cheap and busy
+		{3 * time.Second, 10 * time.Millisecond, true, 1500 * time.Millisecond}, // not rounded to a second
+		{time.Second, 10 * time.Millisecond, true, time.Second},                 // kept at the minimum
+		{10 * time.Second, 100 * time.Millisecond, false, 10 * time.Second},     // cheap but quiet
+		{10 * time.Second, 500 * time.Millisecond, true, 10 * time.Second},      // neither cheap nor expensive
+		{time.Minute, 100 * time.Millisecond, false, 30 * time.Second},          // outside the bounds
+		{0, time.Second, false, time.Second},                                    // no interval yet
+	}
+
+	for _, test := range tests {
+		if got := adaptive.Next(test.current, test.took, test.busy); got != test.expected {
+			t.Errorf("Next(%v, %v, %v) failed: expected: %v, got %v", test.current, test.took, test.busy, test.expected, got)
+		}
+	}
+}
+
+func TestAdaptiveNextSubsecond(t *testing.T) {
+	adaptive := Adaptive{Min: 250 * time.Millisecond, Max: time.Minute}
+
+	if got := adaptive.Next(500*time.Millisecond, 100*time.Millisecond, false); got != time.Second {
+		t.Errorf("Next(500ms, 100ms) failed: expected: 1s, got %v", got)
+	}
+	if got := adaptive.Next(500*time.Millisecond, time.Millisecond, true); got != 250*time.Millisecond {
+		t.Errorf("Next(500ms, 1ms) failed: expected: 250ms, got %v", got)
+	}
+}
@@ -0,0 +1,31 @@
+package wait
+
+import (
+	"time"
+)
+
+// Backoff gives the delays between attempts to do something which keeps
+// failing, e.g. reconnecting to MySQL, starting at Min and doubling up to Max
+type Backoff struct {
+	Min  time.Duration // the first delay
+	Max  time.Duration // the longest delay
+	next time.Duration // the delay Next returns, 0 to start again from Min
+}
+
+// Next returns the delay before the next attempt
+func (b *Backoff) Next() time.Duration {
+	if b.next < b.Min {
+		b.next = b.Min
+	}
+	delay := b.next
+	if b.next *= 2; b.next > b.Max {
+		b.next = b.Max
+	}
+
+	return delay
+}
+
+// Reset starts the delays again from Min, e.g. after an attempt succeeds
+func (b *Backoff) Reset() {
+	b.next = 0
+}
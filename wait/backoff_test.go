@@ -0,0 +1,22 @@
+package wait
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffNext(t *testing.T) {
+	backoff := Backoff{Min: time.Second, Max: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+
+	for i, want := range expected {
+		if got := backoff.Next(); got != want {
+			t.Errorf("Next() call %d failed: expected: %v, got %v", i+1, want, got)
+		}
+	}
+
+	backoff.Reset()
+	if got := backoff.Next(); got != time.Second {
+		t.Errorf("Next() after Reset() failed: expected: %v, got %v", time.Second, got)
+	}
+}
@@ -0,0 +1,183 @@
+// Package activetransactions holds the routines which manage the active transactions
+package activetransactions
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/activetransactions"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"duration", "thread_id", "processlist_id"}
+
+// Wrapper wraps an ActiveTransactions struct
+type Wrapper struct {
+	pstable.SortKey
+	at *activetransactions.ActiveTransactions
+}
+
+// NewActiveTransactions creates a wrapper around activetransactions.ActiveTransactions
+func NewActiveTransactions(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		at:      activetransactions.NewActiveTransactions(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (atw *Wrapper) ResetStatistics() {
+	atw.at.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (atw *Wrapper) Collect() error {
+	if err := atw.at.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&atw.at.Results, atw.at.NameFilter(), func(i int) string { return atw.values(atw.at.Results[i]).Name }) {
+		atw.at.Totals = atw.at.Results.Totals()
+	}
+
+	sort.Sort(byDuration(atw.at.Results))
+	atw.Sort(atw.at.Results, func(i int) pstable.Row { return atw.values(atw.at.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (atw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(atw.at.Results))
+
+	for i := range atw.at.Results {
+		rows = append(rows, atw.content(atw.at.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (atw Wrapper) TotalRowContent() string {
+	return atw.content(atw.at.Totals)
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (atw Wrapper) OthersRowContent(shown int) string {
+	others := atw.at.Results[shown:].Totals()
+	others.User = lib.OthersName(len(atw.at.Results) - shown)
+
+	return atw.content(others)
+}
+
+// Len return the length of the result set
+func (atw Wrapper) Len() int {
+	return len(atw.at.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (atw Wrapper) EmptyRowContent() string {
+	var empty activetransactions.Row
+
+	return atw.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (atw Wrapper) HaveRelativeStats() bool {
+	return atw.at.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (atw Wrapper) FirstCollectTime() time.Time {
+	return atw.at.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (atw Wrapper) LastCollectTime() time.Time {
+	return atw.at.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (atw Wrapper) WantRelativeStats() bool {
+	return atw.at.WantRelativeStats()
+}
+
+// Description returns a description of the table including the
+// number and average duration of the completed transactions
+func (atw Wrapper) Description() string {
+	summary := atw.at.Summary
+	average := ""
+	if summary.Count > 0 {
+		average = ", avg " + lib.FormatTime(summary.SumTimerWait/summary.Count)
+	}
+
+	return fmt.Sprintf("Active Transactions (events_transactions_current) %d rows, %s completed%s",
+		len(atw.at.Results), lib.FormatAmount(summary.Count), average)
+}
+
+// Headings returns the headings for a table
+func (atw Wrapper) Headings() string {
+	return fmt.Sprintf("%10s|%-16s %-10s %4s|%8s %8s|%s",
+		"Duration", "Isolation", "Access", "Auto", "Thread", "Conn", "User")
+}
+
+// Data returns a generic copy of the collected rows
+func (atw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: atw.at.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(atw.at.Results)),
+		Totals:    atw.values(atw.at.Totals),
+	}
+	for i := range atw.at.Results {
+		data.Rows = append(data.Rows, atw.values(atw.at.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (atw Wrapper) values(row activetransactions.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name(),
+		Values: []float64{
+			float64(row.Duration),
+			float64(row.ThreadID),
+			float64(row.ProcesslistID),
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (atw Wrapper) content(row activetransactions.Row) string {
+	isolation := row.IsolationLevel
+	if len(isolation) > 16 {
+		isolation = isolation[0:16]
+	}
+
+	return fmt.Sprintf("%10s|%-16s %-10s %4s|%8s %8s|%s",
+		lib.FormatTime(row.Duration),
+		isolation,
+		row.AccessMode,
+		row.Autocommit,
+		lib.FormatID(row.ThreadID, 8),
+		lib.FormatID(row.ProcesslistID, 8),
+		row.Name())
+}
+
+type byDuration activetransactions.Rows
+
+func (rows byDuration) Len() int      { return len(rows) }
+func (rows byDuration) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by duration (descending) and then thread id
+func (rows byDuration) Less(i, j int) bool {
+	return (rows[i].Duration > rows[j].Duration) ||
+		((rows[i].Duration == rows[j].Duration) && (rows[i].ThreadID < rows[j].ThreadID))
+}
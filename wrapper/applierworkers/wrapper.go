@@ -0,0 +1,193 @@
+// Package applierworkers holds the routines which manage the replication applier worker information
+package applierworkers
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/applierworkers"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"lag", "apply_time", "thread_id", "error_number", "workers", "errors"}
+
+// Wrapper wraps an ApplierWorkers struct
+type Wrapper struct {
+	pstable.SortKey
+	aw *applierworkers.ApplierWorkers
+}
+
+// NewApplierWorkers creates a wrapper around applierworkers.ApplierWorkers
+func NewApplierWorkers(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		aw:      applierworkers.NewApplierWorkers(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (aww *Wrapper) ResetStatistics() {
+	aww.aw.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (aww *Wrapper) Collect() error {
+	if err := aww.aw.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&aww.aw.Results, aww.aw.NameFilter(), func(i int) string { return aww.values(aww.aw.Results[i]).Name }) {
+		aww.aw.Totals = aww.aw.Results.Totals()
+	}
+
+	sort.Sort(byLag(aww.aw.Results))
+	aww.Sort(aww.aw.Results, func(i int) pstable.Row { return aww.values(aww.aw.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (aww Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(aww.aw.Results))
+
+	for i := range aww.aw.Results {
+		rows = append(rows, aww.content(aww.aw.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (aww Wrapper) TotalRowContent() string {
+	return aww.summary(aww.aw.Totals, "Totals")
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (aww Wrapper) OthersRowContent(shown int) string {
+	return aww.summary(aww.aw.Results[shown:].Totals(), lib.OthersName(len(aww.aw.Results)-shown))
+}
+
+// Len return the length of the result set
+func (aww Wrapper) Len() int {
+	return len(aww.aw.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (aww Wrapper) EmptyRowContent() string {
+	var empty applierworkers.Row
+
+	return aww.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (aww Wrapper) HaveRelativeStats() bool {
+	return aww.aw.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (aww Wrapper) FirstCollectTime() time.Time {
+	return aww.aw.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (aww Wrapper) LastCollectTime() time.Time {
+	return aww.aw.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (aww Wrapper) WantRelativeStats() bool {
+	return aww.aw.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (aww Wrapper) Description() string {
+	return fmt.Sprintf("Replication Applier Workers (replication_applier_status_by_worker) %d workers, %d with errors",
+		aww.aw.Totals.Workers, aww.aw.Totals.Errors)
+}
+
+// Headings returns the headings for a table
+func (aww Wrapper) Headings() string {
+	return fmt.Sprintf("%10s %10s|%-3s|%-16s|%s", "Lag", "Apply Time", "On?", "Channel:Worker", "Last Applied / Error")
+}
+
+// Data returns a generic copy of the collected rows
+func (aww Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: aww.aw.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(aww.aw.Results)),
+		Totals:    aww.values(aww.aw.Totals),
+	}
+	for i := range aww.aw.Results {
+		data.Rows = append(data.Rows, aww.values(aww.aw.Results[i]))
+	}
+	data.Totals.Name = "Totals"
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (aww Wrapper) values(row applierworkers.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name(),
+		Values: []float64{
+			float64(row.Lag),
+			float64(row.ApplyTime),
+			float64(row.ThreadID),
+			float64(row.ErrorNumber),
+			float64(row.Workers),
+			float64(row.Errors),
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (aww Wrapper) content(row applierworkers.Row) string {
+	var name, detail string
+	if row.Channel != "" || row.Workers > 0 {
+		name = row.Name()
+	}
+	switch {
+	case row.ErrorNumber != 0:
+		detail = fmt.Sprintf("Error %d: %s", row.ErrorNumber, row.ErrorMessage)
+	case row.Applying != "":
+		detail = "applying " + row.Applying
+	default:
+		detail = row.LastApplied
+	}
+
+	return fmt.Sprintf("%10s %10s|%-3s|%-16s|%s",
+		lib.FormatTime(row.Lag),
+		lib.FormatTime(row.ApplyTime),
+		row.State,
+		name,
+		detail)
+}
+
+// summary returns a printable row with the number of workers and errors of row
+func (aww Wrapper) summary(row applierworkers.Row, name string) string {
+	return fmt.Sprintf("%10s %10s|%-3s|%-16s|%s",
+		lib.FormatTime(row.Lag),
+		lib.FormatTime(row.ApplyTime),
+		"",
+		"",
+		fmt.Sprintf("%s: %d workers, %d with errors", name, row.Workers, row.Errors))
+}
+
+type byLag applierworkers.Rows
+
+func (rows byLag) Len() int      { return len(rows) }
+func (rows byLag) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort workers with errors first, then by lag (descending) and finally by name
+func (rows byLag) Less(i, j int) bool {
+	return (rows[i].Errors > rows[j].Errors) ||
+		((rows[i].Errors == rows[j].Errors) && (rows[i].Lag > rows[j].Lag)) ||
+		((rows[i].Errors == rows[j].Errors) && (rows[i].Lag == rows[j].Lag) && (rows[i].Name() < rows[j].Name()))
+}
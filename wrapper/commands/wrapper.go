@@ -0,0 +1,182 @@
+// Package commands holds the routines which manage the Com_* global status counters
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/commands"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"rate", "count", "smoothed_rate"}
+
+// Wrapper wraps a Commands struct
+type Wrapper struct {
+	pstable.SortKey
+	c *commands.Commands
+}
+
+// NewCommands creates a wrapper around commands.Commands
+func NewCommands(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		c:       commands.NewCommands(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (cw *Wrapper) ResetStatistics() {
+	cw.c.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (cw *Wrapper) Collect() error {
+	if err := cw.c.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&cw.c.Results, cw.c.NameFilter(), func(i int) string { return cw.values(cw.c.Results[i]).Name }) {
+		cw.c.Totals = cw.c.Results.Totals()
+	}
+
+	sort.Sort(byRate(cw.c.Results))
+	cw.Sort(cw.c.Results, func(i int) pstable.Row { return cw.values(cw.c.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (cw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(cw.c.Results))
+
+	for i := range cw.c.Results {
+		rows = append(rows, cw.content(cw.c.Results[i], cw.c.Totals))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (cw Wrapper) TotalRowContent() string {
+	return cw.content(cw.c.Totals, cw.c.Totals)
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (cw Wrapper) OthersRowContent(shown int) string {
+	others := cw.c.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(cw.c.Results) - shown)
+
+	return cw.content(others, cw.c.Totals)
+}
+
+// Len return the length of the result set
+func (cw Wrapper) Len() int {
+	return len(cw.c.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (cw Wrapper) EmptyRowContent() string {
+	var empty commands.Row
+
+	return cw.content(empty, empty)
+}
+
+// HaveRelativeStats is true for this object
+func (cw Wrapper) HaveRelativeStats() bool {
+	return cw.c.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (cw Wrapper) FirstCollectTime() time.Time {
+	return cw.c.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (cw Wrapper) LastCollectTime() time.Time {
+	return cw.c.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (cw Wrapper) WantRelativeStats() bool {
+	return cw.c.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (cw Wrapper) Description() string {
+	return fmt.Sprintf("Commands (global_status Com_*) %d commands executed", len(cw.c.Results))
+}
+
+// Headings returns the headings for a table
+func (cw Wrapper) Headings() string {
+	rate := "Rate/s"
+	if cw.c.WantSmoothedRates() {
+		rate = "Avg/s"
+	}
+	return fmt.Sprintf("%8s %6s %10s %6s|%s", rate, "%", "Count", "%", "Command")
+}
+
+// Data returns a generic copy of the collected rows
+func (cw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: cw.c.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(cw.c.Results)),
+		Totals:    cw.values(cw.c.Totals),
+	}
+	for i := range cw.c.Results {
+		data.Rows = append(data.Rows, cw.values(cw.c.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (cw Wrapper) values(row commands.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			row.Rate,
+			float64(row.Count),
+			row.SmoothedRate,
+		},
+	}
+}
+
+// content generate a printable result for a row, given the totals
+func (cw Wrapper) content(row, totals commands.Row) string {
+	value, total := row.Rate, totals.Rate
+	if cw.c.WantSmoothedRates() {
+		value, total = row.SmoothedRate, totals.SmoothedRate
+	}
+	rate, ratePct := "", ""
+	if value > 0 {
+		rate = fmt.Sprintf("%8.2f", value)
+		ratePct = lib.FormatPct(value / total)
+	}
+
+	return fmt.Sprintf("%8s %6s %10s %6s|%s",
+		rate,
+		ratePct,
+		lib.FormatCounter(row.Count, 10),
+		lib.FormatPct(lib.Divide(row.Count, totals.Count)),
+		row.Name)
+}
+
+type byRate commands.Rows
+
+func (rows byRate) Len() int      { return len(rows) }
+func (rows byRate) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by rate (descending), then count (descending) and finally name
+func (rows byRate) Less(i, j int) bool {
+	return (rows[i].Rate > rows[j].Rate) ||
+		((rows[i].Rate == rows[j].Rate) && (rows[i].Count > rows[j].Count)) ||
+		((rows[i].Rate == rows[j].Rate) && (rows[i].Count == rows[j].Count) && (rows[i].Name < rows[j].Name))
+}
@@ -0,0 +1,147 @@
+// Package diagnostics holds the routines which manage ps-top's own resource usage information
+package diagnostics
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/diagnostics"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// Wrapper wraps a Diagnostics struct
+type Wrapper struct {
+	d *diagnostics.Diagnostics
+}
+
+// NewDiagnostics creates a wrapper around diagnostics.Diagnostics
+func NewDiagnostics(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		d: diagnostics.NewDiagnostics(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (dw *Wrapper) ResetStatistics() {
+	dw.d.ResetStatistics()
+}
+
+// Collect data from the Go runtime. The rows are kept in a fixed order.
+func (dw *Wrapper) Collect() error {
+	return dw.d.Collect()
+}
+
+// RowContent returns the rows we need for displaying
+func (dw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(dw.d.Results))
+
+	for i := range dw.d.Results {
+		rows = append(rows, dw.content(dw.d.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns the Go version ps-top was built with as there are no totals
+func (dw Wrapper) TotalRowContent() string {
+	return fmt.Sprintf("%10s %10s|%s", "", "", "Built with "+runtime.Version())
+}
+
+// Len return the length of the result set
+func (dw Wrapper) Len() int {
+	return len(dw.d.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (dw Wrapper) EmptyRowContent() string {
+	var empty diagnostics.Row
+
+	return dw.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (dw Wrapper) HaveRelativeStats() bool {
+	return dw.d.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (dw Wrapper) FirstCollectTime() time.Time {
+	return dw.d.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (dw Wrapper) LastCollectTime() time.Time {
+	return dw.d.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (dw Wrapper) WantRelativeStats() bool {
+	return dw.d.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (dw Wrapper) Description() string {
+	return "ps-top Diagnostics (Go runtime) resource usage of ps-top itself"
+}
+
+// Headings returns the headings for a table
+func (dw Wrapper) Headings() string {
+	return fmt.Sprintf("%10s %10s|%s", "Value", "Rate/s", "Name")
+}
+
+// Data returns a generic copy of the collected rows
+func (dw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: dw.d.LastCollected,
+		Columns:   []string{"value", "rate"},
+		Rows:      make([]pstable.Row, 0, len(dw.d.Results)),
+		Totals:    dw.values(dw.d.Totals),
+	}
+	for i := range dw.d.Results {
+		data.Rows = append(data.Rows, dw.values(dw.d.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (dw Wrapper) values(row diagnostics.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.Value),
+			row.Rate,
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (dw Wrapper) content(row diagnostics.Row) string {
+	var rate string
+	if row.Counter {
+		rate = format(row.Kind, row.Rate)
+	}
+
+	return fmt.Sprintf("%10s %10s|%s",
+		format(row.Kind, float64(row.Value)),
+		rate,
+		row.Name)
+}
+
+// format returns the value formatted according to its kind
+func format(kind diagnostics.Kind, value float64) string {
+	switch kind {
+	case diagnostics.KindBytes:
+		return lib.FormatAmount(uint64(value))
+	case diagnostics.KindDuration:
+		return lib.FormatDuration(time.Duration(value))
+	}
+	if value != float64(uint64(value)) {
+		return fmt.Sprintf("%.1f", value)
+	}
+	return lib.FormatCounter(uint64(value), 10)
+}
@@ -10,17 +10,23 @@ import (
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/fileinfo"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"count_star", "count_read", "count_write", "count_misc", "sum_timer_wait", "sum_timer_read", "sum_timer_write", "sum_timer_misc", "sum_number_of_bytes_read", "sum_number_of_bytes_write"}
+
 // Wrapper wraps a FileIoLatency struct  representing the contents of the data collected from file_summary_by_instance, but adding formatting for presentation in the terminal
 type Wrapper struct {
+	pstable.SortKey
 	fiol *fileinfo.FileIoLatency
 }
 
 // NewFileSummaryByInstance creates a wrapper around FileIoLatency
 func NewFileSummaryByInstance(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		fiol: fileinfo.NewFileSummaryByInstance(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		fiol:    fileinfo.NewFileSummaryByInstance(cfg, db),
 	}
 }
 
@@ -30,9 +36,20 @@ func (fiolw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (fiolw *Wrapper) Collect() {
-	fiolw.fiol.Collect()
+func (fiolw *Wrapper) Collect() error {
+	if err := fiolw.fiol.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&fiolw.fiol.Results, fiolw.fiol.NameFilter(), func(i int) string { return fiolw.values(fiolw.fiol.Results[i]).Name }) {
+		fiolw.fiol.Totals = fiolw.fiol.Results.Totals()
+	}
+
 	sort.Sort(byLatency(fiolw.fiol.Results))
+	fiolw.Sort(fiolw.fiol.Results, func(i int) pstable.Row { return fiolw.values(fiolw.fiol.Results[i]) })
+
+	return nil
 }
 
 // Headings returns the headings for a table
@@ -68,6 +85,14 @@ func (fiolw Wrapper) TotalRowContent() string {
 	return fiolw.content(fiolw.fiol.Totals, fiolw.fiol.Totals)
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (fiolw Wrapper) OthersRowContent(shown int) string {
+	others := fiolw.fiol.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(fiolw.fiol.Results) - shown)
+
+	return fiolw.content(others, fiolw.fiol.Totals)
+}
+
 // Len return the length of the result set
 func (fiolw Wrapper) Len() int {
 	return len(fiolw.fiol.Results)
@@ -113,6 +138,40 @@ func (fiolw Wrapper) WantRelativeStats() bool {
 	return fiolw.fiol.WantRelativeStats()
 }
 
+// Data returns a generic copy of the collected rows
+func (fiolw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: fiolw.fiol.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(fiolw.fiol.Results)),
+		Totals:    fiolw.values(fiolw.fiol.Totals),
+	}
+	for i := range fiolw.fiol.Results {
+		data.Rows = append(data.Rows, fiolw.values(fiolw.fiol.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (fiolw Wrapper) values(row fileinfo.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.CountStar),
+			float64(row.CountRead),
+			float64(row.CountWrite),
+			float64(row.CountMisc),
+			float64(row.SumTimerWait),
+			float64(row.SumTimerRead),
+			float64(row.SumTimerWrite),
+			float64(row.SumTimerMisc),
+			float64(row.SumNumberOfBytesRead),
+			float64(row.SumNumberOfBytesWrite),
+		},
+	}
+}
+
 // content generate a printable result for a row, given the totals
 func (fiolw Wrapper) content(row, totals fileinfo.Row) string {
 	var name = row.Name
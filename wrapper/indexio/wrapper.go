@@ -0,0 +1,190 @@
+// Package indexio holds the routines which manage the index i/o statistics.
+package indexio
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/indexio"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"sum_timer_wait", "sum_timer_read", "sum_timer_write", "count_star", "count_read", "count_write"}
+
+// Wrapper represents the contents of the data collected related to index i/o statistics
+type Wrapper struct {
+	pstable.SortKey
+	iio *indexio.IndexIo
+}
+
+// NewIndexIo creates a wrapper around index i/o statistics
+func NewIndexIo(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		iio:     indexio.NewIndexIo(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (iiow *Wrapper) ResetStatistics() {
+	iiow.iio.ResetStatistics()
+}
+
+// Collect data from the db, then merge it in.
+func (iiow *Wrapper) Collect() error {
+	if err := iiow.iio.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&iiow.iio.Results, iiow.iio.NameFilter(), func(i int) string { return iiow.iio.Results[i].Name() }) {
+		iiow.iio.Totals = iiow.iio.Results.Totals()
+	}
+
+	sort.Sort(byLatency(iiow.iio.Results))
+	iiow.Sort(iiow.iio.Results, func(i int) pstable.Row { return iiow.values(iiow.iio.Results[i]) })
+
+	return nil
+}
+
+// Headings returns the latency headings as a string
+func (iiow Wrapper) Headings() string {
+	return fmt.Sprintf("%10s %6s|%6s %6s|%8s %8s %8s|%s",
+		"Latency",
+		"%",
+		"Read",
+		"Write",
+		"Ops",
+		"Reads",
+		"Writes",
+		"Table.Index")
+}
+
+// RowContent returns the rows we need for displaying
+func (iiow Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(iiow.iio.Results))
+
+	for i := range iiow.iio.Results {
+		rows = append(rows, iiow.content(iiow.iio.Results[i], iiow.iio.Totals))
+	}
+
+	return rows
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (iiow Wrapper) OthersRowContent(shown int) string {
+	others := iiow.iio.Results[shown:].Totals()
+	others.Table = lib.OthersName(len(iiow.iio.Results) - shown)
+
+	return iiow.content(others, iiow.iio.Totals)
+}
+
+// Len return the length of the result set
+func (iiow Wrapper) Len() int {
+	return len(iiow.iio.Results)
+}
+
+// TotalRowContent returns all the totals
+func (iiow Wrapper) TotalRowContent() string {
+	return iiow.content(iiow.iio.Totals, iiow.iio.Totals)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (iiow Wrapper) EmptyRowContent() string {
+	var empty indexio.Row
+
+	return iiow.content(empty, empty)
+}
+
+// Description returns a description of the table
+func (iiow Wrapper) Description() string {
+	return fmt.Sprintf("Index I/O (table_io_waits_summary_by_index_usage) %d rows, %d unused indexes",
+		len(iiow.iio.Results), iiow.iio.Results.Unused())
+}
+
+// HaveRelativeStats is true for this object
+func (iiow Wrapper) HaveRelativeStats() bool {
+	return iiow.iio.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time of the first collection
+func (iiow Wrapper) FirstCollectTime() time.Time {
+	return iiow.iio.FirstCollected
+}
+
+// LastCollectTime returns the time of the last collection
+func (iiow Wrapper) LastCollectTime() time.Time {
+	return iiow.iio.LastCollected
+}
+
+// WantRelativeStats returns if we want to see relative stats
+func (iiow Wrapper) WantRelativeStats() bool {
+	return iiow.iio.WantRelativeStats()
+}
+
+// Data returns a generic copy of the collected rows
+func (iiow Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: iiow.iio.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(iiow.iio.Results)),
+		Totals:    iiow.values(iiow.iio.Totals),
+	}
+	for i := range iiow.iio.Results {
+		data.Rows = append(data.Rows, iiow.values(iiow.iio.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (iiow Wrapper) values(row indexio.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name(),
+		Values: []float64{
+			float64(row.SumTimerWait),
+			float64(row.SumTimerRead),
+			float64(row.SumTimerWrite),
+			float64(row.CountStar),
+			float64(row.CountRead),
+			float64(row.CountWrite),
+		},
+	}
+}
+
+// content returns the printable result
+func (iiow Wrapper) content(row, totals indexio.Row) string {
+	name := row.Name()
+	if row.Unused {
+		name += " [unused]"
+	}
+
+	return fmt.Sprintf("%10s %6s|%6s %6s|%8s %8s %8s|%s",
+		lib.FormatTime(row.SumTimerWait),
+		lib.FormatPct(lib.Divide(row.SumTimerWait, totals.SumTimerWait)),
+		lib.FormatPct(lib.Divide(row.SumTimerRead, row.SumTimerWait)),
+		lib.FormatPct(lib.Divide(row.SumTimerWrite, row.SumTimerWait)),
+		lib.FormatAmount(row.CountStar),
+		lib.FormatAmount(row.CountRead),
+		lib.FormatAmount(row.CountWrite),
+		name)
+}
+
+// for sorting
+type byLatency indexio.Rows
+
+// sort the indexio.Rows by latency
+func (rows byLatency) Len() int      { return len(rows) }
+func (rows byLatency) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by value (descending) but also by "name" (ascending) if the values are the same
+func (rows byLatency) Less(i, j int) bool {
+	return (rows[i].SumTimerWait > rows[j].SumTimerWait) ||
+		((rows[i].SumTimerWait == rows[j].SumTimerWait) &&
+			(rows[i].Name() < rows[j].Name()))
+}
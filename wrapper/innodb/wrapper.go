@@ -0,0 +1,140 @@
+// Package innodb holds the routines which manage the InnoDB buffer pool and engine status
+package innodb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/innodb"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// Wrapper wraps an InnoDB struct
+type Wrapper struct {
+	i *innodb.InnoDB
+}
+
+// NewInnoDB creates a wrapper around innodb.InnoDB
+func NewInnoDB(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		i: innodb.NewInnoDB(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (iw *Wrapper) ResetStatistics() {
+	iw.i.ResetStatistics()
+}
+
+// Collect data from the db. The rows are kept in a fixed order.
+func (iw *Wrapper) Collect() error {
+	return iw.i.Collect()
+}
+
+// RowContent returns the rows we need for displaying
+func (iw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(iw.i.Results))
+
+	for i := range iw.i.Results {
+		rows = append(rows, iw.content(iw.i.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns an empty row as the metrics can not be added up
+func (iw Wrapper) TotalRowContent() string {
+	return fmt.Sprintf("%12s|%s", "", "")
+}
+
+// Len return the length of the result set
+func (iw Wrapper) Len() int {
+	return len(iw.i.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (iw Wrapper) EmptyRowContent() string {
+	return fmt.Sprintf("%12s|%s", "", "")
+}
+
+// HaveRelativeStats is true for this object
+func (iw Wrapper) HaveRelativeStats() bool {
+	return iw.i.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (iw Wrapper) FirstCollectTime() time.Time {
+	return iw.i.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (iw Wrapper) LastCollectTime() time.Time {
+	return iw.i.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (iw Wrapper) WantRelativeStats() bool {
+	return iw.i.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (iw Wrapper) Description() string {
+	hitRatio := "n/a"
+	if ratio, ok := iw.i.HitRatio(); ok {
+		hitRatio = lib.FormatPct(ratio)
+	}
+
+	return fmt.Sprintf("InnoDB (global_status, INNODB_METRICS) buffer pool hit ratio %s", hitRatio)
+}
+
+// Headings returns the headings for a table
+func (iw Wrapper) Headings() string {
+	return fmt.Sprintf("%12s|%s", "Value", "Metric")
+}
+
+// Data returns a generic copy of the collected rows
+func (iw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: iw.i.LastCollected,
+		Columns:   []string{"value", "smoothed_value"},
+		Rows:      make([]pstable.Row, 0, len(iw.i.Results)),
+		Totals:    iw.values(iw.i.Totals),
+	}
+	for i := range iw.i.Results {
+		data.Rows = append(data.Rows, iw.values(iw.i.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (iw Wrapper) values(row innodb.Row) pstable.Row {
+	return pstable.Row{
+		Name:   row.Name,
+		Values: []float64{row.Value, row.SmoothedValue},
+	}
+}
+
+// content generate a printable result for a row
+func (iw Wrapper) content(row innodb.Row) string {
+	var value string
+	switch row.Kind {
+	case innodb.KindRatio:
+		value = lib.FormatPct(row.Value)
+	case innodb.KindBytes:
+		value = lib.FormatBytes(uint64(row.Value))
+	case innodb.KindRate:
+		rate := row.Value
+		if iw.i.WantSmoothedRates() {
+			rate = row.SmoothedValue
+		}
+		value = fmt.Sprintf("%.1f/s", rate)
+	default:
+		value = lib.FormatCounter(uint64(row.Value), 12)
+	}
+
+	return fmt.Sprintf("%12s|%s", value, row.Name)
+}
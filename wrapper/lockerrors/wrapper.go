@@ -0,0 +1,172 @@
+// Package lockerrors holds the routines which manage the deadlock and lock wait timeout information
+package lockerrors
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/lockerrors"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"count", "rate", "smoothed_rate"}
+
+// Wrapper wraps a LockErrors struct
+type Wrapper struct {
+	pstable.SortKey
+	le *lockerrors.LockErrors
+}
+
+// NewLockErrors creates a wrapper around lockerrors.LockErrors
+func NewLockErrors(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		le:      lockerrors.NewLockErrors(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (lew *Wrapper) ResetStatistics() {
+	lew.le.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (lew *Wrapper) Collect() error {
+	if err := lew.le.Collect(); err != nil {
+		return err
+	}
+
+	sort.Sort(byRate(lew.le.Results))
+	lew.Sort(lew.le.Results, func(i int) pstable.Row { return lew.values(lew.le.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (lew Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(lew.le.Results))
+
+	for i := range lew.le.Results {
+		rows = append(rows, lew.content(lew.le.Results[i], lew.le.Totals))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (lew Wrapper) TotalRowContent() string {
+	return lew.content(lew.le.Totals, lew.le.Totals)
+}
+
+// Len return the length of the result set
+func (lew Wrapper) Len() int {
+	return len(lew.le.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (lew Wrapper) EmptyRowContent() string {
+	var empty lockerrors.Row
+
+	return lew.content(empty, empty)
+}
+
+// HaveRelativeStats is true for this object
+func (lew Wrapper) HaveRelativeStats() bool {
+	return lew.le.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (lew Wrapper) FirstCollectTime() time.Time {
+	return lew.le.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (lew Wrapper) LastCollectTime() time.Time {
+	return lew.le.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (lew Wrapper) WantRelativeStats() bool {
+	return lew.le.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (lew Wrapper) Description() string {
+	return "Deadlocks and Lock Wait Timeouts (events_errors_summary_global_by_error)"
+}
+
+// Headings returns the headings for a table
+func (lew Wrapper) Headings() string {
+	rate := "Rate/s"
+	if lew.le.WantSmoothedRates() {
+		rate = "Avg/s"
+	}
+	return fmt.Sprintf("%10s %6s %8s|%-19s|%s", "Count", "%", rate, "Last Seen", "Error")
+}
+
+// Data returns a generic copy of the collected rows
+func (lew Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: lew.le.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(lew.le.Results)),
+		Totals:    lew.values(lew.le.Totals),
+	}
+	for i := range lew.le.Results {
+		data.Rows = append(data.Rows, lew.values(lew.le.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (lew Wrapper) values(row lockerrors.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.Raised),
+			float64(row.Rate),
+			float64(row.SmoothedRate),
+		},
+	}
+}
+
+// content generate a printable result for a row, given the totals
+func (lew Wrapper) content(row, totals lockerrors.Row) string {
+	value := row.Rate
+	if lew.le.WantSmoothedRates() {
+		value = row.SmoothedRate
+	}
+	rate := ""
+	if value > 0 {
+		rate = fmt.Sprintf("%8.2f", value)
+	}
+	lastSeen := row.LastSeen
+	if len(lastSeen) > 19 {
+		lastSeen = lastSeen[0:19] // drop any fractional seconds
+	}
+
+	return fmt.Sprintf("%10s %6s %8s|%-19s|%s",
+		lib.FormatAmount(row.Raised),
+		lib.FormatPct(lib.Divide(row.Raised, totals.Raised)),
+		rate,
+		lastSeen,
+		row.Name)
+}
+
+type byRate lockerrors.Rows
+
+func (rows byRate) Len() int      { return len(rows) }
+func (rows byRate) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by rate (descending), then count (descending) and finally name
+func (rows byRate) Less(i, j int) bool {
+	return (rows[i].Rate > rows[j].Rate) ||
+		((rows[i].Rate == rows[j].Rate) && (rows[i].Raised > rows[j].Raised)) ||
+		((rows[i].Rate == rows[j].Rate) && (rows[i].Raised == rows[j].Raised) && (rows[i].Name < rows[j].Name))
+}
@@ -1,4 +1,4 @@
-// Package memoryusage holds the routines which manage the file_summary_by_instance table.
+// Package memoryusage holds the routines which manage the memory_summary_global_by_event_name table.
 package memoryusage
 
 import (
@@ -10,17 +10,23 @@ import (
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/memoryusage"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
-// Wrapper wraps a FileIoLatency struct  representing the contents of the data collected from file_summary_by_instance, but adding formatting for presentation in the terminal
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"current_count_used", "high_count_used", "total_memory_ops", "current_bytes_used", "high_bytes_used", "total_bytes_managed", "change_bytes_used"}
+
+// Wrapper wraps a MemoryUsage struct representing the contents of the data collected from memory_summary_global_by_event_name, but adding formatting for presentation in the terminal
 type Wrapper struct {
+	pstable.SortKey
 	mu *memoryusage.MemoryUsage
 }
 
 // NewMemoryUsage creates a wrapper around MemoryUsage
 func NewMemoryUsage(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		mu: memoryusage.NewMemoryUsage(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		mu:      memoryusage.NewMemoryUsage(cfg, db),
 	}
 }
 
@@ -30,15 +36,26 @@ func (muw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (muw *Wrapper) Collect() {
-	muw.mu.Collect()
+func (muw *Wrapper) Collect() error {
+	if err := muw.mu.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&muw.mu.Results, muw.mu.NameFilter(), func(i int) string { return muw.values(muw.mu.Results[i]).Name }) {
+		muw.mu.Totals = muw.mu.Results.Totals()
+	}
+
 	sort.Sort(byBytes(muw.mu.Results))
+	muw.Sort(muw.mu.Results, func(i int) pstable.Row { return muw.values(muw.mu.Results[i]) })
+
+	return nil
 }
 
 // Headings returns the headings for a table
 func (muw Wrapper) Headings() string {
-	return "CurBytes         %  High Bytes|MemOps          %|CurAlloc       %   HiAlloc|Memory Area"
-	//      1234567890  100.0%  1234567890|123456789  100.0%|12345678  100.0%  12345678|Some memory name
+	return "CurBytes         %  High Bytes      Change|MemOps          %|CurAlloc       %   HiAlloc|Memory Area"
+	//      1234567890  100.0%  1234567890  1234567890|123456789  100.0%|12345678  100.0%  12345678|Some memory name
 }
 
 // RowContent returns the rows we need for displaying
@@ -57,6 +74,14 @@ func (muw Wrapper) TotalRowContent() string {
 	return muw.content(muw.mu.Totals, muw.mu.Totals)
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (muw Wrapper) OthersRowContent(shown int) string {
+	others := muw.mu.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(muw.mu.Results) - shown)
+
+	return muw.content(others, muw.mu.Totals)
+}
+
 // Len return the length of the result set
 func (muw Wrapper) Len() int {
 	return len(muw.mu.Results)
@@ -79,7 +104,7 @@ func (muw Wrapper) Description() string {
 		}
 	}
 
-	return fmt.Sprintf("File I/O Latency (file_summary_by_instance) %4d row(s)    ", count)
+	return fmt.Sprintf("Memory Usage (memory_summary_global_by_event_name) %4d row(s)    ", count)
 }
 
 // HaveRelativeStats is true for this object
@@ -102,6 +127,37 @@ func (muw Wrapper) WantRelativeStats() bool {
 	return muw.mu.WantRelativeStats()
 }
 
+// Data returns a generic copy of the collected rows
+func (muw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: muw.mu.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(muw.mu.Results)),
+		Totals:    muw.values(muw.mu.Totals),
+	}
+	for i := range muw.mu.Results {
+		data.Rows = append(data.Rows, muw.values(muw.mu.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (muw Wrapper) values(row memoryusage.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.CurrentCountUsed),
+			float64(row.HighCountUsed),
+			float64(row.TotalMemoryOps),
+			float64(row.CurrentBytesUsed),
+			float64(row.HighBytesUsed),
+			float64(row.TotalBytesManaged),
+			float64(row.ChangeBytesUsed),
+		},
+	}
+}
+
 // content generate a printable result for a row, given the totals
 func (muw Wrapper) content(row, totals memoryusage.Row) string {
 	// assume the data is empty so hide it.
@@ -110,10 +166,11 @@ func (muw Wrapper) content(row, totals memoryusage.Row) string {
 		name = ""
 	}
 
-	return fmt.Sprintf("%10s  %6s  %10s|%10s %6s|%8s  %6s  %8s|%s",
+	return fmt.Sprintf("%10s  %6s  %10s  %10s|%10s %6s|%8s  %6s  %8s|%s",
 		lib.SignedFormatAmount(row.CurrentBytesUsed),
 		lib.FormatPct(lib.SignedDivide(row.CurrentBytesUsed, totals.CurrentBytesUsed)),
 		lib.SignedFormatAmount(row.HighBytesUsed),
+		lib.SignedFormatAmount(row.ChangeBytesUsed),
 		lib.SignedFormatAmount(row.TotalMemoryOps),
 		lib.FormatPct(lib.SignedDivide(row.TotalMemoryOps, totals.TotalMemoryOps)),
 		lib.SignedFormatAmount(row.CurrentCountUsed),
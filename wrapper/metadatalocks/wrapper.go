@@ -0,0 +1,184 @@
+// Package metadatalocks holds the routines which manage the metadata lock information
+package metadatalocks
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/metadatalocks"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"granted", "pending", "thread_id", "processlist_id", "blocked_by"}
+
+// Wrapper wraps a MetadataLocks struct
+type Wrapper struct {
+	pstable.SortKey
+	ml *metadatalocks.MetadataLocks
+}
+
+// NewMetadataLocks creates a wrapper around metadatalocks.MetadataLocks
+func NewMetadataLocks(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		ml:      metadatalocks.NewMetadataLocks(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (mlw *Wrapper) ResetStatistics() {
+	mlw.ml.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (mlw *Wrapper) Collect() error {
+	if err := mlw.ml.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&mlw.ml.Results, mlw.ml.NameFilter(), func(i int) string { return mlw.values(mlw.ml.Results[i]).Name }) {
+		mlw.ml.Totals = mlw.ml.Results.Totals()
+	}
+
+	sort.Sort(byPending(mlw.ml.Results))
+	mlw.Sort(mlw.ml.Results, func(i int) pstable.Row { return mlw.values(mlw.ml.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (mlw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(mlw.ml.Results))
+
+	for i := range mlw.ml.Results {
+		rows = append(rows, mlw.content(mlw.ml.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (mlw Wrapper) TotalRowContent() string {
+	return mlw.summary(mlw.ml.Totals, "Totals")
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (mlw Wrapper) OthersRowContent(shown int) string {
+	return mlw.summary(mlw.ml.Results[shown:].Totals(), lib.OthersName(len(mlw.ml.Results)-shown))
+}
+
+// Len return the length of the result set
+func (mlw Wrapper) Len() int {
+	return len(mlw.ml.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (mlw Wrapper) EmptyRowContent() string {
+	var empty metadatalocks.Row
+
+	return mlw.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (mlw Wrapper) HaveRelativeStats() bool {
+	return mlw.ml.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (mlw Wrapper) FirstCollectTime() time.Time {
+	return mlw.ml.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (mlw Wrapper) LastCollectTime() time.Time {
+	return mlw.ml.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (mlw Wrapper) WantRelativeStats() bool {
+	return mlw.ml.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (mlw Wrapper) Description() string {
+	return fmt.Sprintf("Metadata Locks (metadata_locks) %d granted, %d pending", mlw.ml.Totals.Granted, mlw.ml.Totals.Pending)
+}
+
+// Headings returns the headings for a table
+func (mlw Wrapper) Headings() string {
+	return fmt.Sprintf("%-7s %-19s %-11s|%8s %8s|%10s|%-8s %s",
+		"Status", "Lock Type", "Duration", "Thread", "Conn", "Blocked By", "Type", "Object")
+}
+
+// Data returns a generic copy of the collected rows
+func (mlw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: mlw.ml.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(mlw.ml.Results)),
+		Totals:    mlw.values(mlw.ml.Totals),
+	}
+	for i := range mlw.ml.Results {
+		data.Rows = append(data.Rows, mlw.values(mlw.ml.Results[i]))
+	}
+	data.Totals.Name = "Totals"
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (mlw Wrapper) values(row metadatalocks.Row) pstable.Row {
+	return pstable.Row{
+		Name: fmt.Sprintf("%s %s %d", row.Object(), row.LockType, row.ThreadID),
+		Values: []float64{
+			float64(row.Granted),
+			float64(row.Pending),
+			float64(row.ThreadID),
+			float64(row.ProcesslistID),
+			float64(row.BlockedBy),
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (mlw Wrapper) content(row metadatalocks.Row) string {
+	var object string
+	if row.ObjectType != "" {
+		object = row.Object()
+	}
+
+	return fmt.Sprintf("%-7s %-19s %-11s|%8s %8s|%10s|%-8s %s",
+		row.Status,
+		row.LockType,
+		row.Duration,
+		lib.FormatID(row.ThreadID, 8),
+		lib.FormatID(row.ProcesslistID, 8),
+		lib.FormatID(row.BlockedBy, 10),
+		row.ObjectType,
+		object)
+}
+
+// summary returns a printable row with the number of granted and pending locks of row
+func (mlw Wrapper) summary(row metadatalocks.Row, name string) string {
+	return fmt.Sprintf("%-7s %-19s %-11s|%8s %8s|%10s|%-8s %s",
+		"", "", "", "", "", "", "",
+		fmt.Sprintf("%s: %d granted, %d pending", name, row.Granted, row.Pending))
+}
+
+type byPending metadatalocks.Rows
+
+func (rows byPending) Len() int      { return len(rows) }
+func (rows byPending) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort pending locks first, then by object and finally by thread
+func (rows byPending) Less(i, j int) bool {
+	return (rows[i].IsPending() && !rows[j].IsPending()) ||
+		((rows[i].IsPending() == rows[j].IsPending()) && (rows[i].Object() < rows[j].Object())) ||
+		((rows[i].IsPending() == rows[j].IsPending()) && (rows[i].Object() == rows[j].Object()) && (rows[i].ThreadID < rows[j].ThreadID))
+}
@@ -10,17 +10,23 @@ import (
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/mutexlatency"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"sum_timer_wait", "count_star"}
+
 // Wrapper wraps a MutexLatency struct
 type Wrapper struct {
+	pstable.SortKey
 	ml *mutexlatency.MutexLatency
 }
 
 // NewMutexLatency creates a wrapper around mutexlatency.MutexLatency
 func NewMutexLatency(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		ml: mutexlatency.NewMutexLatency(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		ml:      mutexlatency.NewMutexLatency(cfg, db),
 	}
 }
 
@@ -30,9 +36,20 @@ func (mlw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (mlw *Wrapper) Collect() {
-	mlw.ml.Collect()
+func (mlw *Wrapper) Collect() error {
+	if err := mlw.ml.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&mlw.ml.Results, mlw.ml.NameFilter(), func(i int) string { return mlw.values(mlw.ml.Results[i]).Name }) {
+		mlw.ml.Totals = mlw.ml.Results.Totals()
+	}
+
 	sort.Sort(byLatency(mlw.ml.Results))
+	mlw.Sort(mlw.ml.Results, func(i int) pstable.Row { return mlw.values(mlw.ml.Results[i]) })
+
+	return nil
 }
 
 // RowContent returns the rows we need for displaying
@@ -51,6 +68,14 @@ func (mlw Wrapper) TotalRowContent() string {
 	return mlw.content(mlw.ml.Totals, mlw.ml.Totals)
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (mlw Wrapper) OthersRowContent(shown int) string {
+	others := mlw.ml.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(mlw.ml.Results) - shown)
+
+	return mlw.content(others, mlw.ml.Totals)
+}
+
 // Len return the length of the result set
 func (mlw Wrapper) Len() int {
 	return len(mlw.ml.Results)
@@ -99,6 +124,32 @@ func (mlw Wrapper) Headings() string {
 	return fmt.Sprintf("%10s %8s %8s|%s", "Latency", "MtxCnt", "%", "Mutex Name")
 }
 
+// Data returns a generic copy of the collected rows
+func (mlw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: mlw.ml.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(mlw.ml.Results)),
+		Totals:    mlw.values(mlw.ml.Totals),
+	}
+	for i := range mlw.ml.Results {
+		data.Rows = append(data.Rows, mlw.values(mlw.ml.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (mlw Wrapper) values(row mutexlatency.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.SumTimerWait),
+			float64(row.CountStar),
+		},
+	}
+}
+
 // content generate a printable result for a row, given the totals
 func (mlw Wrapper) content(row, totals mutexlatency.Row) string {
 	name := row.Name
@@ -0,0 +1,187 @@
+// Package processlist holds the routines which manage the processlist information
+package processlist
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/processlist"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"latency", "time", "thread_id", "processlist_id"}
+
+// Wrapper wraps a Processlist struct
+type Wrapper struct {
+	pstable.SortKey
+	pl *processlist.Processlist
+}
+
+// NewProcesslist creates a wrapper around processlist.Processlist
+func NewProcesslist(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		pl:      processlist.NewProcesslist(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (plw *Wrapper) ResetStatistics() {
+	plw.pl.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (plw *Wrapper) Collect() error {
+	if err := plw.pl.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&plw.pl.Results, plw.pl.NameFilter(), func(i int) string { return plw.pl.Results[i].Name() }) {
+		plw.pl.Totals = plw.pl.Results.Totals()
+	}
+
+	sort.Sort(byLatency(plw.pl.Results))
+	plw.Sort(plw.pl.Results, func(i int) pstable.Row { return plw.values(plw.pl.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (plw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(plw.pl.Results))
+
+	for i := range plw.pl.Results {
+		rows = append(rows, plw.content(plw.pl.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (plw Wrapper) TotalRowContent() string {
+	return plw.summary(plw.pl.Totals, "Totals", len(plw.pl.Results))
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (plw Wrapper) OthersRowContent(shown int) string {
+	others := plw.pl.Results[shown:]
+	return plw.summary(others.Totals(), lib.OthersName(len(others)), len(others))
+}
+
+// Len return the length of the result set
+func (plw Wrapper) Len() int {
+	return len(plw.pl.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (plw Wrapper) EmptyRowContent() string {
+	return plw.summary(processlist.Row{}, "", 0)
+}
+
+// HaveRelativeStats is true for this object
+func (plw Wrapper) HaveRelativeStats() bool {
+	return plw.pl.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (plw Wrapper) FirstCollectTime() time.Time {
+	return plw.pl.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (plw Wrapper) LastCollectTime() time.Time {
+	return plw.pl.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (plw Wrapper) WantRelativeStats() bool {
+	return plw.pl.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (plw Wrapper) Description() string {
+	return fmt.Sprintf("Processlist (threads) %d active threads", len(plw.pl.Results))
+}
+
+// Headings returns the headings for a table
+func (plw Wrapper) Headings() string {
+	return fmt.Sprintf("%10s %10s|%8s %8s|%-7s %-30s|%s",
+		"Latency", "Time", "Thread", "Conn", "Command", "State", "Thread/User: Statement")
+}
+
+// Data returns a generic copy of the collected rows
+func (plw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: plw.pl.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(plw.pl.Results)),
+		Totals:    plw.values(plw.pl.Totals),
+	}
+	for i := range plw.pl.Results {
+		data.Rows = append(data.Rows, plw.values(plw.pl.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (plw Wrapper) values(row processlist.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name(),
+		Values: []float64{
+			float64(row.Latency),
+			float64(row.Time),
+			float64(row.ThreadID),
+			float64(row.ProcesslistID),
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (plw Wrapper) content(row processlist.Row) string {
+	name := row.Name()
+	if row.Statement != "" {
+		name += ": " + row.Statement
+	}
+
+	return fmt.Sprintf("%10s %10s|%8s %8s|%-7s %-30.30s|%s",
+		lib.FormatTime(row.Latency),
+		lib.FormatDuration(time.Duration(row.Time)*time.Second),
+		lib.FormatID(row.ThreadID, 8),
+		lib.FormatID(row.ProcesslistID, 8),
+		row.Command,
+		row.State,
+		name)
+}
+
+// summary returns a printable row with the longest latency and time of row and the number of threads
+func (plw Wrapper) summary(row processlist.Row, name string, threads int) string {
+	var description string
+	if name != "" {
+		description = fmt.Sprintf("%s: %d threads", name, threads)
+	}
+
+	return fmt.Sprintf("%10s %10s|%8s %8s|%-7s %-30s|%s",
+		lib.FormatTime(row.Latency),
+		lib.FormatDuration(time.Duration(row.Time)*time.Second),
+		"", "", "", "",
+		description)
+}
+
+type byLatency processlist.Rows
+
+func (rows byLatency) Len() int      { return len(rows) }
+func (rows byLatency) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort the longest running statements first, then by time in the current state and finally by thread
+func (rows byLatency) Less(i, j int) bool {
+	return (rows[i].Latency > rows[j].Latency) ||
+		((rows[i].Latency == rows[j].Latency) && (rows[i].Time > rows[j].Time)) ||
+		((rows[i].Latency == rows[j].Latency) && (rows[i].Time == rows[j].Time) && (rows[i].ThreadID < rows[j].ThreadID))
+}
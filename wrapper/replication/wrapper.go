@@ -0,0 +1,219 @@
+// Package replication holds the routines which manage the replication channels
+package replication
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/replication"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"lag", "io_running", "sql_running", "workers", "apply_time", "errors", "relay_log_space"}
+
+// Wrapper wraps a Replication struct
+type Wrapper struct {
+	pstable.SortKey
+	r *replication.Replication
+}
+
+// NewReplication creates a wrapper around replication.Replication
+func NewReplication(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		r:       replication.NewReplication(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (rw *Wrapper) ResetStatistics() {
+	rw.r.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (rw *Wrapper) Collect() error {
+	if err := rw.r.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&rw.r.Results, rw.r.NameFilter(), func(i int) string { return rw.values(rw.r.Results[i]).Name }) {
+		rw.r.Totals = rw.r.Results.Totals()
+	}
+
+	sort.Sort(byErrors(rw.r.Results))
+	rw.Sort(rw.r.Results, func(i int) pstable.Row { return rw.values(rw.r.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (rw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(rw.r.Results))
+
+	for i := range rw.r.Results {
+		rows = append(rows, rw.content(rw.r.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (rw Wrapper) TotalRowContent() string {
+	return rw.content(rw.r.Totals)
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (rw Wrapper) OthersRowContent(shown int) string {
+	others := rw.r.Results[shown:].Totals()
+	others.Channel = lib.OthersName(len(rw.r.Results) - shown)
+
+	return rw.content(others)
+}
+
+// Len return the length of the result set
+func (rw Wrapper) Len() int {
+	return len(rw.r.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (rw Wrapper) EmptyRowContent() string {
+	var empty replication.Row
+
+	return rw.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (rw Wrapper) HaveRelativeStats() bool {
+	return rw.r.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (rw Wrapper) FirstCollectTime() time.Time {
+	return rw.r.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (rw Wrapper) LastCollectTime() time.Time {
+	return rw.r.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (rw Wrapper) WantRelativeStats() bool {
+	return rw.r.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (rw Wrapper) Description() string {
+	if len(rw.r.Results) == 0 {
+		return "Replication (SHOW REPLICA STATUS) not a replica"
+	}
+	return fmt.Sprintf("Replication (SHOW REPLICA STATUS) %d channel(s), %d error(s)", len(rw.r.Results), rw.r.Totals.Errors)
+}
+
+// Headings returns the headings for a table
+func (rw Wrapper) Headings() string {
+	return fmt.Sprintf("%8s %-10s %-10s|%7s %10s %6s|%10s|%-24s|%s",
+		"Lag", "IO Thread", "SQL Thread", "Workers", "Apply Time", "Errors", "Relay Log", "Source", "Channel / Last Error")
+}
+
+// Data returns a generic copy of the collected rows
+func (rw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: rw.r.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(rw.r.Results)),
+		Totals:    rw.values(rw.r.Totals),
+	}
+	for i := range rw.r.Results {
+		data.Rows = append(data.Rows, rw.values(rw.r.Results[i]))
+	}
+
+	return data
+}
+
+// running returns 1 if the thread is running, otherwise 0
+func running(state string) float64 {
+	if state == "Yes" {
+		return 1
+	}
+	return 0
+}
+
+// values returns the row's name and numeric values in the order of the Data columns.
+// An unknown lag is NaN.
+func (rw Wrapper) values(row replication.Row) pstable.Row {
+	lag := math.NaN()
+	if row.LagKnown {
+		lag = float64(row.Lag)
+	}
+
+	return pstable.Row{
+		Name: row.Name(),
+		Values: []float64{
+			lag,
+			running(row.IORunning),
+			running(row.SQLRunning),
+			float64(row.Workers),
+			float64(row.ApplyTime),
+			float64(row.Errors),
+			float64(row.RelayLogSpace),
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (rw Wrapper) content(row replication.Row) string {
+	lag := ""
+	switch {
+	case row.Channels == 0:
+	case row.LagKnown:
+		lag = lib.SecToTime(row.Lag)
+	default:
+		lag = "NULL"
+	}
+	name := ""
+	if row.Channels > 0 {
+		name = row.Name()
+		if row.LastError != "" {
+			name += ": " + row.LastError
+		}
+	}
+	source := row.Source
+	if len(source) > 24 {
+		source = source[0:24]
+	}
+	relayLog := ""
+	if row.RelayLogSpace > 0 {
+		relayLog = lib.FormatBytes(row.RelayLogSpace)
+	}
+
+	return fmt.Sprintf("%8s %-10s %-10s|%7s %10s %6s|%10s|%-24s|%s",
+		lag,
+		row.IORunning,
+		row.SQLRunning,
+		lib.FormatCounter(row.Workers, 7),
+		lib.FormatTime(row.ApplyTime),
+		lib.FormatCounter(row.Errors, 6),
+		relayLog,
+		source,
+		name)
+}
+
+type byErrors replication.Rows
+
+func (rows byErrors) Len() int      { return len(rows) }
+func (rows byErrors) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by errors (descending), then lag (descending) and finally channel name
+func (rows byErrors) Less(i, j int) bool {
+	return (rows[i].Errors > rows[j].Errors) ||
+		((rows[i].Errors == rows[j].Errors) && (rows[i].Lag > rows[j].Lag)) ||
+		((rows[i].Errors == rows[j].Errors) && (rows[i].Lag == rows[j].Lag) && (rows[i].Channel < rows[j].Channel))
+}
@@ -0,0 +1,151 @@
+// Package responsetime holds the routines which manage the server wide statement latency distribution
+package responsetime
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/responsetime"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// barWidth is the width of the distribution bar of a bucket containing all statements
+const barWidth = 40
+
+// Wrapper wraps a ResponseTime struct
+type Wrapper struct {
+	rt *responsetime.ResponseTime
+}
+
+// NewResponseTime creates a wrapper around responsetime.ResponseTime
+func NewResponseTime(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		rt: responsetime.NewResponseTime(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (rtw *Wrapper) ResetStatistics() {
+	rtw.rt.ResetStatistics()
+}
+
+// Collect data from the db. The rows are kept in bucket order.
+func (rtw *Wrapper) Collect() error {
+	return rtw.rt.Collect()
+}
+
+// RowContent returns the rows we need for displaying
+func (rtw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(rtw.rt.Results))
+
+	var cumulative uint64
+	for i := range rtw.rt.Results {
+		cumulative += rtw.rt.Results[i].Count
+		rows = append(rows, rtw.content(rtw.rt.Results[i], rtw.rt.Totals, cumulative))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (rtw Wrapper) TotalRowContent() string {
+	return rtw.content(rtw.rt.Totals, rtw.rt.Totals, rtw.rt.Totals.Count)
+}
+
+// Len return the length of the result set
+func (rtw Wrapper) Len() int {
+	return len(rtw.rt.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (rtw Wrapper) EmptyRowContent() string {
+	var empty responsetime.Row
+
+	return rtw.content(empty, empty, 0)
+}
+
+// HaveRelativeStats is true for this object
+func (rtw Wrapper) HaveRelativeStats() bool {
+	return rtw.rt.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (rtw Wrapper) FirstCollectTime() time.Time {
+	return rtw.rt.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (rtw Wrapper) LastCollectTime() time.Time {
+	return rtw.rt.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (rtw Wrapper) WantRelativeStats() bool {
+	return rtw.rt.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (rtw Wrapper) Description() string {
+	results := rtw.rt.Results
+
+	return fmt.Sprintf("Statement Response Time (events_statements_histogram_global) p50: %s p95: %s p99: %s",
+		strings.TrimSpace(lib.FormatTime(results.Percentile(0.50))),
+		strings.TrimSpace(lib.FormatTime(results.Percentile(0.95))),
+		strings.TrimSpace(lib.FormatTime(results.Percentile(0.99))))
+}
+
+// Headings returns the headings for a table
+func (rtw Wrapper) Headings() string {
+	return fmt.Sprintf("%10s %10s|%10s %6s %6s|%s", "From", "To", "Count", "%", "Cum%", "Distribution")
+}
+
+// Data returns a generic copy of the collected rows
+func (rtw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: rtw.rt.LastCollected,
+		Columns:   []string{"timer_low", "timer_high", "count"},
+		Rows:      make([]pstable.Row, 0, len(rtw.rt.Results)),
+		Totals:    rtw.values(rtw.rt.Totals),
+	}
+	for i := range rtw.rt.Results {
+		data.Rows = append(data.Rows, rtw.values(rtw.rt.Results[i]))
+	}
+	data.Totals.Name = "Totals"
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (rtw Wrapper) values(row responsetime.Row) pstable.Row {
+	return pstable.Row{
+		Name: strconv.Itoa(row.Bucket),
+		Values: []float64{
+			float64(row.TimerLow),
+			float64(row.TimerHigh),
+			float64(row.Count),
+		},
+	}
+}
+
+// and the number of statements in this and lower buckets
+// content generate a printable result for a row, given the totals
+func (rtw Wrapper) content(row, totals responsetime.Row, cumulative uint64) string {
+	pct := lib.Divide(row.Count, totals.Count)
+	bar := ""
+	if row != totals {
+		bar = strings.Repeat("#", int(pct*barWidth+0.5))
+	}
+
+	return fmt.Sprintf("%10s %10s|%10s %6s %6s|%s",
+		lib.FormatTime(row.TimerLow),
+		lib.FormatTime(row.TimerHigh),
+		lib.FormatAmount(row.Count),
+		lib.FormatPct(pct),
+		lib.FormatPct(lib.Divide(cumulative, totals.Count)),
+		bar)
+}
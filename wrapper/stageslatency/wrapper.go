@@ -5,22 +5,32 @@ import (
 	"database/sql"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/stageslatency"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
+// progressBarWidth is the width of the bar showing how much of a stage in progress is done
+const progressBarWidth = 20
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"sum_timer_wait", "count_star"}
+
 // Wrapper wraps a Stages struct
 type Wrapper struct {
+	pstable.SortKey
 	sl *stageslatency.StagesLatency
 }
 
 // NewStagesLatency creates a wrapper around stageslatency
 func NewStagesLatency(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		sl: stageslatency.NewStagesLatency(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		sl:      stageslatency.NewStagesLatency(cfg, db),
 	}
 }
 
@@ -30,9 +40,21 @@ func (slw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (slw *Wrapper) Collect() {
-	slw.sl.Collect()
+func (slw *Wrapper) Collect() error {
+	if err := slw.sl.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&slw.sl.Results, slw.sl.NameFilter(), func(i int) string { return slw.values(slw.sl.Results[i]).Name }) {
+		slw.sl.Totals = slw.sl.Results.Totals()
+	}
+	pstable.Filter(&slw.sl.Progress, slw.sl.NameFilter(), func(i int) string { return slw.sl.Progress[i].Name })
+
 	sort.Sort(byLatency(slw.sl.Results))
+	slw.Sort(slw.sl.Results, func(i int) pstable.Row { return slw.values(slw.sl.Results[i]) })
+
+	return nil
 }
 
 // Headings returns the headings for a table
@@ -41,10 +63,13 @@ func (slw Wrapper) Headings() string {
 
 }
 
-// RowContent returns the rows we need for displaying
+// RowContent returns the rows we need for displaying, the stages in progress first
 func (slw Wrapper) RowContent() []string {
-	rows := make([]string, 0, len(slw.sl.Results))
+	rows := make([]string, 0, slw.Len())
 
+	for i := range slw.sl.Progress {
+		rows = append(rows, slw.progressContent(slw.sl.Progress[i]))
+	}
 	for i := range slw.sl.Results {
 		rows = append(rows, slw.content(slw.sl.Results[i], slw.sl.Totals))
 	}
@@ -57,9 +82,21 @@ func (slw Wrapper) TotalRowContent() string {
 	return slw.content(slw.sl.Totals, slw.sl.Totals)
 }
 
-// Len return the length of the result set
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (slw Wrapper) OthersRowContent(shown int) string {
+	shown -= len(slw.sl.Progress) // the stages in progress are not summarised
+	if shown < 0 {
+		shown = 0
+	}
+	others := slw.sl.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(slw.sl.Results) - shown)
+
+	return slw.content(others, slw.sl.Totals)
+}
+
+// Len return the length of the result set including the stages in progress
 func (slw Wrapper) Len() int {
-	return len(slw.sl.Results)
+	return len(slw.sl.Progress) + len(slw.sl.Results)
 }
 
 // EmptyRowContent returns an empty string of data (for filling in)
@@ -78,7 +115,11 @@ func (slw Wrapper) Description() string {
 		}
 	}
 
-	return fmt.Sprintf("SQL Stage Latency (events_stages_summary_global_by_event_name) %d rows", count)
+	description := fmt.Sprintf("SQL Stage Latency (events_stages_summary_global_by_event_name) %d rows", count)
+	if len(slw.sl.Progress) > 0 {
+		description += fmt.Sprintf(", %d in progress", len(slw.sl.Progress))
+	}
+	return description
 }
 
 // HaveRelativeStats is true for this object
@@ -101,6 +142,32 @@ func (slw Wrapper) WantRelativeStats() bool {
 	return slw.sl.WantRelativeStats()
 }
 
+// Data returns a generic copy of the collected rows
+func (slw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: slw.sl.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(slw.sl.Results)),
+		Totals:    slw.values(slw.sl.Totals),
+	}
+	for i := range slw.sl.Results {
+		data.Rows = append(data.Rows, slw.values(slw.sl.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (slw Wrapper) values(row stageslatency.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.SumTimerWait),
+			float64(row.CountStar),
+		},
+	}
+}
+
 // generate a printable result
 func (slw Wrapper) content(row, totals stageslatency.Row) string {
 	name := row.Name
@@ -115,6 +182,26 @@ func (slw Wrapper) content(row, totals stageslatency.Row) string {
 		name)
 }
 
+// progressContent returns a printable stage in progress with the time
+// spent so far, how much is done and the estimated time left
+func (slw Wrapper) progressContent(p stageslatency.Progress) string {
+	name := lib.ProgressBar(p.Done(), progressBarWidth) + " " + p.Name
+	if p.ProcesslistID > 0 {
+		name += fmt.Sprintf(", connection %d", p.ProcesslistID)
+	} else {
+		name += fmt.Sprintf(", thread %d", p.ThreadID)
+	}
+	if remaining := p.Remaining(); remaining > 0 {
+		name += ", ETA " + strings.TrimSpace(lib.FormatTime(remaining))
+	}
+
+	return fmt.Sprintf("%10s %6s %8s|%s",
+		lib.FormatTime(p.TimerWait),
+		lib.FormatPct(p.Done()),
+		"",
+		name)
+}
+
 type byLatency stageslatency.Rows
 
 func (rows byLatency) Len() int      { return len(rows) }
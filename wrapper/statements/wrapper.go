@@ -0,0 +1,219 @@
+// Package statements holds the routines which manage the statement digests.
+package statements
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/explain"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/statements"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"sum_timer_wait", "count_star", "sum_rows_examined", "sum_rows_sent"}
+
+// Wrapper wraps a Statements struct
+type Wrapper struct {
+	pstable.SortKey
+	s  *statements.Statements
+	db *sql.DB
+}
+
+// NewStatements creates a wrapper around statements
+func NewStatements(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		s:       statements.NewStatements(cfg, db),
+		db:      db,
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (sw *Wrapper) ResetStatistics() {
+	sw.s.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (sw *Wrapper) Collect() error {
+	if err := sw.s.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&sw.s.Results, sw.s.NameFilter(), func(i int) string { return sw.values(sw.s.Results[i]).Name }) {
+		sw.s.Totals = sw.s.Results.Totals()
+	}
+
+	sort.Sort(byLatency(sw.s.Results))
+	sw.Sort(sw.s.Results, func(i int) pstable.Row { return sw.values(sw.s.Results[i]) })
+
+	return nil
+}
+
+// Headings returns the headings for a table
+func (sw Wrapper) Headings() string {
+	return fmt.Sprintf("%10s %6s %8s %10s|%8s %8s %6s|%s",
+		"Latency", "%", "Count", "Avg Lat", "Examined", "Sent", "Ex/Snt", "Statement")
+}
+
+// RowContent returns the rows we need for displaying
+func (sw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(sw.s.Results))
+
+	for i := range sw.s.Results {
+		rows = append(rows, sw.content(sw.s.Results[i], sw.s.Totals))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (sw Wrapper) TotalRowContent() string {
+	return sw.content(sw.s.Totals, sw.s.Totals)
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (sw Wrapper) OthersRowContent(shown int) string {
+	others := sw.s.Results[shown:].Totals()
+	others.DigestText = lib.OthersName(len(sw.s.Results) - shown)
+
+	return sw.content(others, sw.s.Totals)
+}
+
+// RowKey returns the key of the i-th row, identifying its digest
+func (sw Wrapper) RowKey(i int) (string, bool) {
+	if i < 0 || i >= len(sw.s.Results) {
+		return "", false
+	}
+	return sw.s.Results[i].Key(), true
+}
+
+// Explain returns the query plan of a sample of the statements of the
+// digest with the given key, preceded by the query explained
+func (sw Wrapper) Explain(key string) ([]string, error) {
+	schema, query, err := sw.s.SampleQuery(key)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := explain.Explain(sw.db, schema, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{"Query: " + query, ""}, plan...), nil
+}
+
+// Len return the length of the result set
+func (sw Wrapper) Len() int {
+	return len(sw.s.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (sw Wrapper) EmptyRowContent() string {
+	var empty statements.Row
+
+	return sw.content(empty, empty)
+}
+
+// Description describe the statements
+func (sw Wrapper) Description() string {
+	var count int
+	for row := range sw.s.Results {
+		if sw.s.Results[row].CountStar > 0 {
+			count++
+		}
+	}
+
+	return fmt.Sprintf("Statement Digests (events_statements_summary_by_digest) %d rows", count)
+}
+
+// HaveRelativeStats is true for this object
+func (sw Wrapper) HaveRelativeStats() bool {
+	return sw.s.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (sw Wrapper) FirstCollectTime() time.Time {
+	return sw.s.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (sw Wrapper) LastCollectTime() time.Time {
+	return sw.s.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (sw Wrapper) WantRelativeStats() bool {
+	return sw.s.WantRelativeStats()
+}
+
+// Data returns a generic copy of the collected rows
+func (sw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: sw.s.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(sw.s.Results)),
+		Totals:    sw.values(sw.s.Totals),
+	}
+	for i := range sw.s.Results {
+		data.Rows = append(data.Rows, sw.values(sw.s.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (sw Wrapper) values(row statements.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name(),
+		Values: []float64{
+			float64(row.SumTimerWait),
+			float64(row.CountStar),
+			float64(row.SumRowsExamined),
+			float64(row.SumRowsSent),
+		},
+	}
+}
+
+// generate a printable result
+func (sw Wrapper) content(row, totals statements.Row) string {
+	name := row.Name()
+	if row.CountStar == 0 && name != "Totals" {
+		name = ""
+	}
+	var average uint64
+	if row.CountStar > 0 {
+		average = row.SumTimerWait / row.CountStar
+	}
+	ratio := ""
+	if row.SumRowsSent > 0 {
+		ratio = lib.FormatCounter(uint64(lib.Divide(row.SumRowsExamined, row.SumRowsSent)), 6)
+	}
+
+	return fmt.Sprintf("%10s %6s %8s %10s|%8s %8s %6s|%s",
+		lib.FormatTime(row.SumTimerWait),
+		lib.FormatPct(lib.Divide(row.SumTimerWait, totals.SumTimerWait)),
+		lib.FormatCounter(row.CountStar, 8),
+		lib.FormatTime(average),
+		lib.FormatCounter(row.SumRowsExamined, 8),
+		lib.FormatCounter(row.SumRowsSent, 8),
+		ratio,
+		name)
+}
+
+type byLatency statements.Rows
+
+func (rows byLatency) Len() int      { return len(rows) }
+func (rows byLatency) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by latency (descending), then count (descending) and finally by name
+func (rows byLatency) Less(i, j int) bool {
+	return (rows[i].SumTimerWait > rows[j].SumTimerWait) ||
+		((rows[i].SumTimerWait == rows[j].SumTimerWait) && (rows[i].CountStar > rows[j].CountStar)) ||
+		((rows[i].SumTimerWait == rows[j].SumTimerWait) && (rows[i].CountStar == rows[j].CountStar) && (rows[i].Name() < rows[j].Name()))
+}
@@ -0,0 +1,163 @@
+// Package tablecache holds the routines which manage the table cache information
+package tablecache
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/tablecache"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// Wrapper wraps a TableCache struct
+type Wrapper struct {
+	tc *tablecache.TableCache
+}
+
+// NewTableCache creates a wrapper around tablecache.TableCache
+func NewTableCache(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		tc: tablecache.NewTableCache(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (tcw *Wrapper) ResetStatistics() {
+	tcw.tc.ResetStatistics()
+}
+
+// Collect data from the db. The rows are kept in a fixed order.
+func (tcw *Wrapper) Collect() error {
+	return tcw.tc.Collect()
+}
+
+// RowContent returns the rows we need for displaying
+func (tcw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(tcw.tc.Results))
+
+	for i := range tcw.tc.Results {
+		rows = append(rows, tcw.content(tcw.tc.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns a summary of the table cache efficiency
+func (tcw Wrapper) TotalRowContent() string {
+	state := "ok"
+	if tcw.tc.Saturated {
+		state = "SATURATED: table_open_cache is full and tables are still being opened"
+	}
+
+	return fmt.Sprintf("%10s %10s %6s %10s|%s",
+		"",
+		"",
+		"",
+		"",
+		fmt.Sprintf("Hit ratio: %s, %s", lib.FormatPct(tcw.tc.HitRatio), state))
+}
+
+// Len return the length of the result set
+func (tcw Wrapper) Len() int {
+	return len(tcw.tc.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (tcw Wrapper) EmptyRowContent() string {
+	var empty tablecache.Row
+
+	return tcw.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (tcw Wrapper) HaveRelativeStats() bool {
+	return tcw.tc.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (tcw Wrapper) FirstCollectTime() time.Time {
+	return tcw.tc.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (tcw Wrapper) LastCollectTime() time.Time {
+	return tcw.tc.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (tcw Wrapper) WantRelativeStats() bool {
+	return tcw.tc.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (tcw Wrapper) Description() string {
+	saturated := ""
+	if tcw.tc.Saturated {
+		saturated = ", SATURATED"
+	}
+
+	return fmt.Sprintf("Table Cache (global_status) hit ratio %s%s", lib.FormatPct(tcw.tc.HitRatio), saturated)
+}
+
+// Headings returns the headings for a table
+func (tcw Wrapper) Headings() string {
+	rate := "Rate/s"
+	if tcw.tc.WantSmoothedRates() {
+		rate = "Avg/s"
+	}
+	return fmt.Sprintf("%10s %10s %6s %10s|%s", "Value", "Limit", "Used", rate, "Status")
+}
+
+// Data returns a generic copy of the collected rows
+func (tcw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: tcw.tc.LastCollected,
+		Columns:   []string{"value", "limit", "used", "rate", "smoothed_rate"},
+		Rows:      make([]pstable.Row, 0, len(tcw.tc.Results)),
+		Totals:    tcw.values(tcw.tc.Totals),
+	}
+	for i := range tcw.tc.Results {
+		data.Rows = append(data.Rows, tcw.values(tcw.tc.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (tcw Wrapper) values(row tablecache.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.Value),
+			float64(row.Limit),
+			row.Used(),
+			row.Rate,
+			row.SmoothedRate,
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (tcw Wrapper) content(row tablecache.Row) string {
+	var limit, used, rate string
+	if row.Counter {
+		value := row.Rate
+		if tcw.tc.WantSmoothedRates() {
+			value = row.SmoothedRate
+		}
+		rate = fmt.Sprintf("%.1f", value)
+	} else if row.Limit > 0 {
+		limit = lib.FormatCounter(row.Limit, 10)
+		used = lib.FormatPct(row.Used())
+	}
+
+	return fmt.Sprintf("%10s %10s %6s %10s|%s",
+		lib.FormatCounter(row.Value, 10),
+		limit,
+		used,
+		rate,
+		row.Name)
+}
@@ -10,17 +10,23 @@ import (
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/tableio"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"sum_timer_wait", "sum_timer_fetch", "sum_timer_insert", "sum_timer_update", "sum_timer_delete"}
+
 // Wrapper represents the contents of the data collected related to tableio statistics
 type Wrapper struct {
+	pstable.SortKey
 	tiol *tableio.TableIo
 }
 
 // NewTableIoLatency creates a wrapper around tableio statistics
 func NewTableIoLatency(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		tiol: tableio.NewTableIo(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		tiol:    tableio.NewTableIo(cfg, db),
 	}
 }
 
@@ -35,11 +41,21 @@ func (tiolw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (tiolw *Wrapper) Collect() {
-	tiolw.tiol.Collect()
+func (tiolw *Wrapper) Collect() error {
+	if err := tiolw.tiol.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&tiolw.tiol.Results, tiolw.tiol.NameFilter(), func(i int) string { return tiolw.values(tiolw.tiol.Results[i]).Name }) {
+		tiolw.tiol.Totals = tiolw.tiol.Results.Totals()
+	}
 
 	// sort the results by latency (might be needed in other places)
 	sort.Sort(byLatency(tiolw.tiol.Results))
+	tiolw.Sort(tiolw.tiol.Results, func(i int) pstable.Row { return tiolw.values(tiolw.tiol.Results[i]) })
+
+	return nil
 }
 
 // Headings returns the latency headings as a string
@@ -65,6 +81,14 @@ func (tiolw Wrapper) RowContent() []string {
 	return rows
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (tiolw Wrapper) OthersRowContent(shown int) string {
+	others := tiolw.tiol.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(tiolw.tiol.Results) - shown)
+
+	return tiolw.content(others, tiolw.tiol.Totals)
+}
+
 // Len return the length of the result set
 func (tiolw Wrapper) Len() int {
 	return len(tiolw.tiol.Results)
@@ -114,6 +138,35 @@ func (tiolw Wrapper) WantRelativeStats() bool {
 	return tiolw.tiol.WantRelativeStats()
 }
 
+// Data returns a generic copy of the collected rows
+func (tiolw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: tiolw.tiol.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(tiolw.tiol.Results)),
+		Totals:    tiolw.values(tiolw.tiol.Totals),
+	}
+	for i := range tiolw.tiol.Results {
+		data.Rows = append(data.Rows, tiolw.values(tiolw.tiol.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (tiolw Wrapper) values(row tableio.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.SumTimerWait),
+			float64(row.SumTimerFetch),
+			float64(row.SumTimerInsert),
+			float64(row.SumTimerUpdate),
+			float64(row.SumTimerDelete),
+		},
+	}
+}
+
 // latencyRowContents reutrns the printable result
 func (tiolw Wrapper) content(row, totals tableio.Row) string {
 	// assume the data is empty so hide it.
@@ -8,18 +8,24 @@ import (
 
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/tableio"
+	"github.com/sjmudd/ps-top/pstable"
 	"github.com/sjmudd/ps-top/wrapper/tableiolatency"
 )
 
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"count_star", "count_fetch", "count_insert", "count_update", "count_delete"}
+
 // Wrapper represents a wrapper around tableiolatency
 type Wrapper struct {
+	pstable.SortKey
 	tiol *tableio.TableIo
 }
 
 // NewTableIoOps creates a wrapper around TableIo, sharing the same connection with the tableiolatency wrapper
 func NewTableIoOps(latency *tableiolatency.Wrapper) *Wrapper {
 	return &Wrapper{
-		tiol: latency.Tiol(),
+		SortKey: pstable.NewSortKey(columns),
+		tiol:    latency.Tiol(),
 	}
 }
 
@@ -29,11 +35,21 @@ func (tiolw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (tiolw *Wrapper) Collect() {
-	tiolw.tiol.Collect()
+func (tiolw *Wrapper) Collect() error {
+	if err := tiolw.tiol.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&tiolw.tiol.Results, tiolw.tiol.NameFilter(), func(i int) string { return tiolw.values(tiolw.tiol.Results[i]).Name }) {
+		tiolw.tiol.Totals = tiolw.tiol.Results.Totals()
+	}
 
 	// sort the results by ops
 	sort.Sort(byOperations(tiolw.tiol.Results))
+	tiolw.Sort(tiolw.tiol.Results, func(i int) pstable.Row { return tiolw.values(tiolw.tiol.Results[i]) })
+
+	return nil
 }
 
 // Headings returns the headings by operations as a string
@@ -59,6 +75,14 @@ func (tiolw Wrapper) RowContent() []string {
 	return rows
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (tiolw Wrapper) OthersRowContent(shown int) string {
+	others := tiolw.tiol.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(tiolw.tiol.Results) - shown)
+
+	return tiolw.content(others, tiolw.tiol.Totals)
+}
+
 // Len return the length of the result set
 func (tiolw Wrapper) Len() int {
 	return len(tiolw.tiol.Results)
@@ -108,6 +132,35 @@ func (tiolw Wrapper) WantRelativeStats() bool {
 	return tiolw.tiol.WantRelativeStats()
 }
 
+// Data returns a generic copy of the collected rows
+func (tiolw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: tiolw.tiol.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(tiolw.tiol.Results)),
+		Totals:    tiolw.values(tiolw.tiol.Totals),
+	}
+	for i := range tiolw.tiol.Results {
+		data.Rows = append(data.Rows, tiolw.values(tiolw.tiol.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (tiolw Wrapper) values(row tableio.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.CountStar),
+			float64(row.CountFetch),
+			float64(row.CountInsert),
+			float64(row.CountUpdate),
+			float64(row.CountDelete),
+		},
+	}
+}
+
 // generate a printable result for ops
 func (tiolw Wrapper) content(row, totals tableio.Row) string {
 	// assume the data is empty so hide it.
@@ -10,17 +10,23 @@ import (
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/tablelocks"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"sum_timer_wait", "sum_timer_read", "sum_timer_write", "sum_timer_read_with_shared_locks", "sum_timer_read_high_priority", "sum_timer_read_no_insert", "sum_timer_read_normal", "sum_timer_read_external", "sum_timer_write_allow_write", "sum_timer_write_concurrent_insert", "sum_timer_write_low_priority", "sum_timer_write_normal", "sum_timer_write_external"}
+
 // Wrapper wraps a TableLockLatency struct
 type Wrapper struct {
+	pstable.SortKey
 	tl *tablelocks.TableLocks
 }
 
 // NewTableLockLatency creates a wrapper around TableLockLatency
 func NewTableLockLatency(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		tl: tablelocks.NewTableLocks(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		tl:      tablelocks.NewTableLocks(cfg, db),
 	}
 }
 
@@ -30,9 +36,20 @@ func (tlw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then merge it in.
-func (tlw *Wrapper) Collect() {
-	tlw.tl.Collect()
+func (tlw *Wrapper) Collect() error {
+	if err := tlw.tl.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&tlw.tl.Results, tlw.tl.NameFilter(), func(i int) string { return tlw.values(tlw.tl.Results[i]).Name }) {
+		tlw.tl.Totals = tlw.tl.Results.Totals()
+	}
+
 	sort.Sort(byLatency(tlw.tl.Results))
+	tlw.Sort(tlw.tl.Results, func(i int) pstable.Row { return tlw.values(tlw.tl.Results[i]) })
+
+	return nil
 }
 
 // Headings returns the headings for a table
@@ -61,6 +78,14 @@ func (tlw Wrapper) TotalRowContent() string {
 	return tlw.content(tlw.tl.Totals, tlw.tl.Totals)
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (tlw Wrapper) OthersRowContent(shown int) string {
+	others := tlw.tl.Results[shown:].Totals()
+	others.Name = lib.OthersName(len(tlw.tl.Results) - shown)
+
+	return tlw.content(others, tlw.tl.Totals)
+}
+
 // Len return the length of the result set
 func (tlw Wrapper) Len() int {
 	return len(tlw.tl.Results)
@@ -98,6 +123,43 @@ func (tlw Wrapper) WantRelativeStats() bool {
 	return tlw.tl.WantRelativeStats()
 }
 
+// Data returns a generic copy of the collected rows
+func (tlw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: tlw.tl.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(tlw.tl.Results)),
+		Totals:    tlw.values(tlw.tl.Totals),
+	}
+	for i := range tlw.tl.Results {
+		data.Rows = append(data.Rows, tlw.values(tlw.tl.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (tlw Wrapper) values(row tablelocks.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Name,
+		Values: []float64{
+			float64(row.SumTimerWait),
+			float64(row.SumTimerRead),
+			float64(row.SumTimerWrite),
+			float64(row.SumTimerReadWithSharedLocks),
+			float64(row.SumTimerReadHighPriority),
+			float64(row.SumTimerReadNoInsert),
+			float64(row.SumTimerReadNormal),
+			float64(row.SumTimerReadExternal),
+			float64(row.SumTimerWriteAllowWrite),
+			float64(row.SumTimerWriteConcurrentInsert),
+			float64(row.SumTimerWriteLowPriority),
+			float64(row.SumTimerWriteNormal),
+			float64(row.SumTimerWriteExternal),
+		},
+	}
+}
+
 // content generate a printable result for a row, given the totals
 func (tlw Wrapper) content(row, totals tablelocks.Row) string {
 	// assume the data is empty so hide it.
@@ -0,0 +1,169 @@
+// Package threadactivity holds the routines which manage the foreground / background thread activity
+package threadactivity
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/threadactivity"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"threads", "wait_latency", "wait_count", "statement_latency", "statement_count"}
+
+// Wrapper wraps a ThreadActivity struct
+type Wrapper struct {
+	pstable.SortKey
+	ta *threadactivity.ThreadActivity
+}
+
+// NewThreadActivity creates a wrapper around threadactivity.ThreadActivity
+func NewThreadActivity(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		ta:      threadactivity.NewThreadActivity(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (taw *Wrapper) ResetStatistics() {
+	taw.ta.ResetStatistics()
+}
+
+// Collect data from the db, then merge it in.
+func (taw *Wrapper) Collect() error {
+	if err := taw.ta.Collect(); err != nil {
+		return err
+	}
+	sort.Sort(byType(taw.ta.Results))
+	taw.Sort(taw.ta.Results, func(i int) pstable.Row { return taw.values(taw.ta.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (taw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(taw.ta.Results))
+
+	for i := range taw.ta.Results {
+		rows = append(rows, taw.content(taw.ta.Results[i], taw.ta.Totals))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (taw Wrapper) TotalRowContent() string {
+	return taw.content(taw.ta.Totals, taw.ta.Totals)
+}
+
+// Len return the length of the result set
+func (taw Wrapper) Len() int {
+	return len(taw.ta.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (taw Wrapper) EmptyRowContent() string {
+	var empty threadactivity.Row
+
+	return taw.content(empty, empty)
+}
+
+// HaveRelativeStats is true for this object
+func (taw Wrapper) HaveRelativeStats() bool {
+	return taw.ta.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (taw Wrapper) FirstCollectTime() time.Time {
+	return taw.ta.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (taw Wrapper) LastCollectTime() time.Time {
+	return taw.ta.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (taw Wrapper) WantRelativeStats() bool {
+	return taw.ta.WantRelativeStats()
+}
+
+// Description returns a description of the table
+func (taw Wrapper) Description() string {
+	return fmt.Sprintf("Thread Activity by type (threads) %d thread(s)", taw.ta.Totals.Threads)
+}
+
+// Headings returns the headings for a table
+func (taw Wrapper) Headings() string {
+	return fmt.Sprintf("%7s|%10s %6s %8s|%10s %6s %8s|%s",
+		"Threads", "WaitTime", "%", "Waits", "StmtTime", "%", "Stmts", "Thread Type")
+}
+
+// Data returns a generic copy of the collected rows
+func (taw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: taw.ta.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(taw.ta.Results)),
+		Totals:    taw.values(taw.ta.Totals),
+	}
+	for i := range taw.ta.Results {
+		data.Rows = append(data.Rows, taw.values(taw.ta.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (taw Wrapper) values(row threadactivity.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Type,
+		Values: []float64{
+			float64(row.Threads),
+			float64(row.WaitLatency),
+			float64(row.WaitCount),
+			float64(row.StatementLatency),
+			float64(row.StatementCount),
+		},
+	}
+}
+
+// content generate a printable result for a row, given the totals
+func (taw Wrapper) content(row, totals threadactivity.Row) string {
+	return fmt.Sprintf("%7s|%10s %6s %8s|%10s %6s %8s|%s",
+		lib.FormatAmount(row.Threads),
+		lib.FormatTime(row.WaitLatency),
+		lib.FormatPct(lib.Divide(row.WaitLatency, totals.WaitLatency)),
+		lib.FormatAmount(row.WaitCount),
+		lib.FormatTime(row.StatementLatency),
+		lib.FormatPct(lib.Divide(row.StatementLatency, totals.StatementLatency)),
+		lib.FormatAmount(row.StatementCount),
+		typeName(row.Type))
+}
+
+// typeName returns a more descriptive name for the thread type
+func typeName(threadType string) string {
+	switch threadType {
+	case "FOREGROUND":
+		return "foreground (client)"
+	case "BACKGROUND":
+		return "background (internal)"
+	}
+	return threadType
+}
+
+type byType threadactivity.Rows
+
+func (rows byType) Len() int      { return len(rows) }
+func (rows byType) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// foreground threads are shown first
+func (rows byType) Less(i, j int) bool {
+	return rows[i].Type > rows[j].Type
+}
@@ -0,0 +1,189 @@
+// Package transactions holds the routines which manage the current transactions
+package transactions
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sjmudd/ps-top/config"
+	"github.com/sjmudd/ps-top/lib"
+	"github.com/sjmudd/ps-top/model/transactions"
+	"github.com/sjmudd/ps-top/pstable"
+)
+
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"age", "rows_modified", "rows_locked", "lock_structs", "thread_id", "processlist_id"}
+
+// Wrapper wraps a Transactions struct
+type Wrapper struct {
+	pstable.SortKey
+	tr *transactions.Transactions
+}
+
+// NewTransactions creates a wrapper around transactions.Transactions
+func NewTransactions(cfg *config.Config, db *sql.DB) *Wrapper {
+	return &Wrapper{
+		SortKey: pstable.NewSortKey(columns),
+		tr:      transactions.NewTransactions(cfg, db),
+	}
+}
+
+// ResetStatistics resets the statistics to last values
+func (trw *Wrapper) ResetStatistics() {
+	trw.tr.ResetStatistics()
+}
+
+// Collect data from the db, then sort the results.
+func (trw *Wrapper) Collect() error {
+	if err := trw.tr.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&trw.tr.Results, trw.tr.NameFilter(), func(i int) string { return trw.values(trw.tr.Results[i]).Name }) {
+		trw.tr.Totals = trw.tr.Results.Totals()
+	}
+
+	sort.Sort(byAge(trw.tr.Results))
+	trw.Sort(trw.tr.Results, func(i int) pstable.Row { return trw.values(trw.tr.Results[i]) })
+
+	return nil
+}
+
+// RowContent returns the rows we need for displaying
+func (trw Wrapper) RowContent() []string {
+	rows := make([]string, 0, len(trw.tr.Results))
+
+	for i := range trw.tr.Results {
+		rows = append(rows, trw.content(trw.tr.Results[i]))
+	}
+
+	return rows
+}
+
+// TotalRowContent returns all the totals
+func (trw Wrapper) TotalRowContent() string {
+	return trw.content(trw.tr.Totals)
+}
+
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (trw Wrapper) OthersRowContent(shown int) string {
+	others := trw.tr.Results[shown:].Totals()
+	others.User = lib.OthersName(len(trw.tr.Results) - shown)
+
+	return trw.content(others)
+}
+
+// Len return the length of the result set
+func (trw Wrapper) Len() int {
+	return len(trw.tr.Results)
+}
+
+// EmptyRowContent returns an empty string of data (for filling in)
+func (trw Wrapper) EmptyRowContent() string {
+	var empty transactions.Row
+
+	return trw.content(empty)
+}
+
+// HaveRelativeStats is true for this object
+func (trw Wrapper) HaveRelativeStats() bool {
+	return trw.tr.HaveRelativeStats()
+}
+
+// FirstCollectTime returns the time the first value was collected
+func (trw Wrapper) FirstCollectTime() time.Time {
+	return trw.tr.FirstCollected
+}
+
+// LastCollectTime returns the time the last value was collected
+func (trw Wrapper) LastCollectTime() time.Time {
+	return trw.tr.LastCollected
+}
+
+// WantRelativeStats indiates if we want relative statistics
+func (trw Wrapper) WantRelativeStats() bool {
+	return trw.tr.WantRelativeStats()
+}
+
+// Description returns a description of the table
+// or why none are shown if INNODB_TRX may not be read
+func (trw Wrapper) Description() string {
+	if trw.tr.Denied {
+		return "Current Transactions (INNODB_TRX) needs the PROCESS privilege, see active_transactions"
+	}
+	return fmt.Sprintf("Current Transactions (INNODB_TRX) %d rows", len(trw.tr.Results))
+}
+
+// Headings returns the headings for a table
+func (trw Wrapper) Headings() string {
+	return fmt.Sprintf("%8s %-12s|%8s %8s %6s|%8s %8s|%s",
+		"Age", "State", "Modified", "Locked", "Locks", "Thread", "Conn", "User")
+}
+
+// Data returns a generic copy of the collected rows
+func (trw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: trw.tr.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(trw.tr.Results)),
+		Totals:    trw.values(trw.tr.Totals),
+	}
+	for i := range trw.tr.Results {
+		data.Rows = append(data.Rows, trw.values(trw.tr.Results[i]))
+	}
+	data.Totals.Name = trw.tr.Totals.Name()
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (trw Wrapper) values(row transactions.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.ID,
+		Values: []float64{
+			float64(row.Age),
+			float64(row.RowsModified),
+			float64(row.RowsLocked),
+			float64(row.LockStructs),
+			float64(row.ThreadID),
+			float64(row.ProcesslistID),
+		},
+	}
+}
+
+// content generate a printable result for a row
+func (trw Wrapper) content(row transactions.Row) string {
+	age := ""
+	if row.Age > 0 {
+		age = lib.SecToTime(row.Age)
+	}
+	state := row.State
+	if len(state) > 12 {
+		state = state[0:12]
+	}
+
+	return fmt.Sprintf("%8s %-12s|%8s %8s %6s|%8s %8s|%s",
+		age,
+		state,
+		lib.FormatAmount(row.RowsModified),
+		lib.FormatAmount(row.RowsLocked),
+		lib.FormatCounter(row.LockStructs, 6),
+		lib.FormatID(row.ThreadID, 8),
+		lib.FormatID(row.ProcesslistID, 8),
+		row.Name())
+}
+
+type byAge transactions.Rows
+
+func (rows byAge) Len() int      { return len(rows) }
+func (rows byAge) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+
+// sort by age (descending), then rows modified (descending) and finally transaction id
+func (rows byAge) Less(i, j int) bool {
+	return (rows[i].Age > rows[j].Age) ||
+		((rows[i].Age == rows[j].Age) && (rows[i].RowsModified > rows[j].RowsModified)) ||
+		((rows[i].Age == rows[j].Age) && (rows[i].RowsModified == rows[j].RowsModified) && (rows[i].ID < rows[j].ID))
+}
@@ -10,17 +10,23 @@ import (
 	"github.com/sjmudd/ps-top/config"
 	"github.com/sjmudd/ps-top/lib"
 	"github.com/sjmudd/ps-top/model/userlatency"
+	"github.com/sjmudd/ps-top/pstable"
 )
 
+// columns holds the names of the Data columns, which the rows may also be sorted on
+var columns = []string{"runtime", "sleeptime", "connections", "active", "hosts", "dbs", "selects", "inserts", "updates", "deletes", "other", "latency", "statements", "memory"}
+
 // Wrapper wraps a UserLatency struct
 type Wrapper struct {
+	pstable.SortKey
 	ul *userlatency.UserLatency
 }
 
 // NewUserLatency creates a wrapper around UserLatency
 func NewUserLatency(cfg *config.Config, db *sql.DB) *Wrapper {
 	return &Wrapper{
-		ul: userlatency.NewUserLatency(cfg, db),
+		SortKey: pstable.NewSortKey(columns),
+		ul:      userlatency.NewUserLatency(cfg, db),
 	}
 }
 
@@ -30,9 +36,20 @@ func (ulw *Wrapper) ResetStatistics() {
 }
 
 // Collect data from the db, then sort the results.
-func (ulw *Wrapper) Collect() {
-	ulw.ul.Collect()
+func (ulw *Wrapper) Collect() error {
+	if err := ulw.ul.Collect(); err != nil {
+		return err
+	}
+
+	// keep only the rows whose name matches the filter, if any
+	if pstable.Filter(&ulw.ul.Results, ulw.ul.NameFilter(), func(i int) string { return ulw.values(ulw.ul.Results[i]).Name }) {
+		ulw.ul.Totals = ulw.ul.Results.Totals()
+	}
+
 	sort.Sort(byTotalTime(ulw.ul.Results))
+	ulw.Sort(ulw.ul.Results, func(i int) pstable.Row { return ulw.values(ulw.ul.Results[i]) })
+
+	return nil
 }
 
 // RowContent returns the rows we need for displaying
@@ -51,6 +68,14 @@ func (ulw Wrapper) TotalRowContent() string {
 	return ulw.content(ulw.ul.Totals, ulw.ul.Totals)
 }
 
+// OthersRowContent returns a row summarising the rows after the first shown rows
+func (ulw Wrapper) OthersRowContent(shown int) string {
+	others := ulw.ul.Results[shown:].Totals()
+	others.Username = lib.OthersName(len(ulw.ul.Results) - shown)
+
+	return ulw.content(others, ulw.ul.Totals)
+}
+
 // Len return the length of the result set
 func (ulw Wrapper) Len() int {
 	return len(ulw.ul.Results)
@@ -91,31 +116,85 @@ func (ulw Wrapper) Description() string {
 			count++
 		}
 	}
-	return fmt.Sprintf("Activity by Username (processlist) %d rows", count)
+	if ulw.ul.ByAccount() {
+		return fmt.Sprintf("Activity by Account (processlist and summaries by account) %d rows", count)
+	}
+	return fmt.Sprintf("Activity by Username (processlist and summaries by user) %d rows", count)
 }
 
 // Headings returns the headings for a table
 func (ulw Wrapper) Headings() string {
-	return fmt.Sprintf("%-10s %6s|%-10s %6s|%4s %4s|%5s %3s|%3s %3s %3s %3s %3s|%s",
-		"Run Time", "%", "Sleeping", "%", "Conn", "Actv", "Hosts", "DBs", "Sel", "Ins", "Upd", "Del", "Oth", "User")
+	name := "User"
+	if ulw.ul.ByAccount() {
+		name = "Account"
+	}
+	return fmt.Sprintf("%-10s %6s|%-10s %6s|%4s %4s|%5s %3s|%3s %3s %3s %3s %3s|%10s %6s %8s|%10s|%s",
+		"Run Time", "%", "Sleeping", "%", "Conn", "Actv", "Hosts", "DBs", "Sel", "Ins", "Upd", "Del", "Oth", "Latency", "%", "Stmts", "Memory", name)
+}
+
+// ToggleByAccount switches between grouping the rows by user and by account (user@host)
+func (ulw *Wrapper) ToggleByAccount() {
+	ulw.ul.SetByAccount(!ulw.ul.ByAccount())
+}
+
+// Data returns a generic copy of the collected rows
+func (ulw Wrapper) Data() pstable.Data {
+	data := pstable.Data{
+		Collected: ulw.ul.LastCollected,
+		Columns:   columns,
+		Rows:      make([]pstable.Row, 0, len(ulw.ul.Results)),
+		Totals:    ulw.values(ulw.ul.Totals),
+	}
+	for i := range ulw.ul.Results {
+		data.Rows = append(data.Rows, ulw.values(ulw.ul.Results[i]))
+	}
+
+	return data
+}
+
+// values returns the row's name and numeric values in the order of the Data columns
+func (ulw Wrapper) values(row userlatency.Row) pstable.Row {
+	return pstable.Row{
+		Name: row.Username,
+		Values: []float64{
+			float64(row.Runtime),
+			float64(row.Sleeptime),
+			float64(row.Connections),
+			float64(row.Active),
+			float64(row.Hosts),
+			float64(row.Dbs),
+			float64(row.Selects),
+			float64(row.Inserts),
+			float64(row.Updates),
+			float64(row.Deletes),
+			float64(row.Other),
+			float64(row.Latency),
+			float64(row.Statements),
+			float64(row.Memory),
+		},
+	}
 }
 
 // content generate a printable result for a row, given the totals
 func (ulw Wrapper) content(row, totals userlatency.Row) string {
-	return fmt.Sprintf("%10s %6s|%10s %6s|%4s %4s|%5s %3s|%3s %3s %3s %3s %3s|%s",
+	return fmt.Sprintf("%10s %6s|%10s %6s|%4s %4s|%5s %3s|%3s %3s %3s %3s %3s|%10s %6s %8s|%10s|%s",
 		formatSeconds(row.Runtime),
 		lib.FormatPct(lib.Divide(row.Runtime, totals.Runtime)),
 		formatSeconds(row.Sleeptime),
 		lib.FormatPct(lib.Divide(row.Sleeptime, totals.Sleeptime)),
-		lib.FormatCounter(int(row.Connections), 4),
-		lib.FormatCounter(int(row.Active), 4),
-		lib.FormatCounter(int(row.Hosts), 5),
-		lib.FormatCounter(int(row.Dbs), 3),
-		lib.FormatCounter(int(row.Selects), 3),
-		lib.FormatCounter(int(row.Inserts), 3),
-		lib.FormatCounter(int(row.Updates), 3),
-		lib.FormatCounter(int(row.Deletes), 3),
-		lib.FormatCounter(int(row.Other), 3),
+		lib.FormatCounter(row.Connections, 4),
+		lib.FormatCounter(row.Active, 4),
+		lib.FormatCounter(row.Hosts, 5),
+		lib.FormatCounter(row.Dbs, 3),
+		lib.FormatCounter(row.Selects, 3),
+		lib.FormatCounter(row.Inserts, 3),
+		lib.FormatCounter(row.Updates, 3),
+		lib.FormatCounter(row.Deletes, 3),
+		lib.FormatCounter(row.Other, 3),
+		lib.FormatTime(row.Latency),
+		lib.FormatPct(lib.Divide(row.Latency, totals.Latency)),
+		lib.FormatAmount(row.Statements),
+		lib.FormatBytes(row.Memory),
 		row.Username)
 }
 
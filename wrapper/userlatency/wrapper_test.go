@@ -2,6 +2,8 @@ package userlatency
 
 import (
 	"testing"
+
+	"github.com/sjmudd/ps-top/model/userlatency"
 )
 
 func TestFormatSeconds(t *testing.T) {
@@ -28,3 +30,17 @@ func TestFormatSeconds(t *testing.T) {
 		}
 	}
 }
+
+func TestOthersRowContent(t *testing.T) {
+	results := userlatency.Rows{
+		{Username: "user1", Runtime: 100, Connections: 5},
+		{Username: "user2", Runtime: 50, Connections: 3},
+		{Username: "user3", Runtime: 20, Connections: 2},
+	}
+	w := Wrapper{ul: &userlatency.UserLatency{Results: results, Totals: results.Totals()}}
+
+	expected := w.content(userlatency.Row{Username: "<others: 2 rows>", Runtime: 70, Connections: 5}, w.ul.Totals)
+	if got := w.OthersRowContent(1); got != expected {
+		t.Errorf("OthersRowContent(1) expected: %q, got: %q", expected, got)
+	}
+}